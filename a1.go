@@ -0,0 +1,25 @@
+package main
+
+import "fmt"
+
+// columnName converts the zero-based column index to the A1 notation column
+// name: 0 is A, 25 is Z, 26 is AA.
+func columnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
+
+// cellName returns the A1 notation of the cell at the zero-based column index
+// and the one-based row.
+func cellName(column int, row int) string {
+	return fmt.Sprintf("%s%d", columnName(column), row)
+}
+
+// rangeName returns the A1 notation of the range between the cells.
+func rangeName(startColumn int, startRow int, endColumn int, endRow int) string {
+	return fmt.Sprintf("%s:%s", cellName(startColumn, startRow), cellName(endColumn, endRow))
+}
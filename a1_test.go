@@ -0,0 +1,42 @@
+package main
+
+import "testing"
+
+func TestColumnName(t *testing.T) {
+	tests := []struct {
+		column int
+		name   string
+	}{
+		{1, "A"},
+		{26, "Z"},
+		{27, "AA"},
+		{28, "AB"},
+		{52, "AZ"},
+		{53, "BA"},
+		{702, "ZZ"},
+		{703, "AAA"},
+	}
+	for _, tt := range tests {
+		// the column numbers are one-based, the indexes are zero-based
+		if got := columnName(tt.column - 1); got != tt.name {
+			t.Errorf("columnName(%d) = %s, want %s", tt.column-1, got, tt.name)
+		}
+	}
+}
+
+func TestRangeName(t *testing.T) {
+	tests := []struct {
+		startColumn, startRow, endColumn, endRow int
+		name                                     string
+	}{
+		{0, 1, 12, 14, "A1:M14"},
+		{0, 1, 26, 3, "A1:AA3"},
+		{25, 2, 52, 40, "Z2:BA40"},
+		{701, 1, 702, 1, "ZZ1:AAA1"},
+	}
+	for _, tt := range tests {
+		if got := rangeName(tt.startColumn, tt.startRow, tt.endColumn, tt.endRow); got != tt.name {
+			t.Errorf("rangeName(%d, %d, %d, %d) = %s, want %s", tt.startColumn, tt.startRow, tt.endColumn, tt.endRow, got, tt.name)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// scoreAdjustment is a manual change of the team score by the referees, e.g.
+// a penalty for the phone use. The adjustments are added to the totals of the
+// rounds.
+type scoreAdjustment struct {
+	Team   string    `json:"team" yaml:"team"`
+	Points float64   `json:"points" yaml:"points"`
+	Reason string    `json:"reason" yaml:"reason"`
+	Time   time.Time `json:"time" yaml:"time"`
+}
+
+// sumAdjustments returns the net adjustment of every adjusted team.
+func sumAdjustments(adjustments []scoreAdjustment) map[string]float64 {
+	sums := make(map[string]float64)
+	for _, adj := range adjustments {
+		sums[adj.Team] += adj.Points
+	}
+	return sums
+}
+
+// adjustedTeams returns the teams of the net adjustments in the alphabetical
+// order.
+func adjustedTeams(sums map[string]float64) []string {
+	teams := make([]string, 0, len(sums))
+	for team := range sums {
+		teams = append(teams, team)
+	}
+	sort.Strings(teams)
+	return teams
+}
+
+// formatAdjustment formats the points with the sign, e.g. "+1" or "-0.5".
+func formatAdjustment(points float64) string {
+	if points > 0 {
+		return "+" + formatPoints(points)
+	}
+	return formatPoints(points)
+}
+
+// applyAdjustments adds the stored adjustments of the game teams to the
+// totals.
+func (a *app) applyAdjustments(total map[string]float64) error {
+	adjustments, err := a.store.getAdjustments()
+	if err != nil {
+		return err
+	}
+	for team, points := range sumAdjustments(adjustments) {
+		if _, ok := total[team]; ok {
+			total[team] += points
+		}
+	}
+	return nil
+}
+
+type adjustmentsResult struct {
+	Adjustments []scoreAdjustment `json:"adjustments" yaml:"adjustments"`
+}
+
+func (r *adjustmentsResult) String() string {
+	if len(r.Adjustments) == 0 {
+		return "No score adjustment is made\n"
+	}
+	t := &table{header: []string{"Time", "Team", "Points", "Reason"}}
+	for _, adj := range r.Adjustments {
+		t.addRow(plainCell(adj.Time.Format("15:04:05")), plainCell(adj.Team), plainCell(formatAdjustment(adj.Points)), plainCell(adj.Reason))
+	}
+	return t.String()
+}
+
+// CmdAdjust adds points to the team score or takes them away:
+// "adjust <team> <+/-points> <reason>". A team name with spaces is quoted.
+func (a *app) CmdAdjust(cmdStr string) (*adjustmentsResult, error) {
+	sSplitted := splitArgs(cmdStr)
+	if len(sSplitted) < 4 {
+		return nil, fmt.Errorf("expected the team, the points and the reason of the adjustment")
+	}
+	team := sSplitted[1]
+	found := false
+	for _, t := range a.config.Teams {
+		if t == team {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("team %s is unknown", team)
+	}
+	points, err := strconv.ParseFloat(sSplitted[2], 64)
+	if err != nil || points == 0 {
+		return nil, fmt.Errorf("failed to parse argument %s as the points of the adjustment, expected e.g. -1 or +0.5", sSplitted[2])
+	}
+	reason := strings.TrimSpace(strings.Join(sSplitted[3:], " "))
+	if len(reason) == 0 {
+		return nil, fmt.Errorf("expected the reason of the adjustment")
+	}
+	adj := scoreAdjustment{Team: team, Points: points, Reason: reason, Time: time.Now()}
+	if err := a.store.saveAdjustment(adj); err != nil {
+		return nil, err
+	}
+	if err := a.store.appendEvent(fmt.Sprintf("adjust %s %s: %s", team, formatAdjustment(points), reason)); err != nil {
+		return nil, err
+	}
+	return &adjustmentsResult{Adjustments: []scoreAdjustment{adj}}, nil
+}
+
+// CmdAdjustments lists the score adjustments in the order they are made:
+// "adjustments".
+func (a *app) CmdAdjustments() (*adjustmentsResult, error) {
+	adjustments, err := a.store.getAdjustments()
+	if err != nil {
+		return nil, err
+	}
+	return &adjustmentsResult{Adjustments: adjustments}, nil
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+// AnswerCellsConfig restricts what the teams can enter into the answer cells,
+// so that the manager spreadsheet import is not broken by the team input.
+type AnswerCellsConfig struct {
+	// MaxLength is the maximum length of an answer, it is not limited if
+	// zero.
+	MaxLength int
+	// RejectFormulas rejects the answers entered as formulas.
+	RejectFormulas bool
+}
+
+// answerCellRule returns the validation rule of a free-text answer cell, nil
+// if the answers are not restricted.
+func (c *AnswerCellsConfig) answerCellRule(cell string) *sheets.DataValidationRule {
+	conditions := make([]string, 0, 2)
+	if c.MaxLength > 0 {
+		conditions = append(conditions, fmt.Sprintf("LEN(%s)<=%d", cell, c.MaxLength))
+	}
+	if c.RejectFormulas {
+		conditions = append(conditions, fmt.Sprintf("NOT(ISFORMULA(%s))", cell))
+	}
+	if len(conditions) == 0 {
+		return nil
+	}
+	rule := &sheets.DataValidationRule{
+		Condition: &sheets.BooleanCondition{
+			Type:   "CUSTOM_FORMULA",
+			Values: []*sheets.ConditionValue{{UserEnteredValue: fmt.Sprintf("=AND(%s)", strings.Join(conditions, ", "))}},
+		},
+		Strict: true,
+	}
+	if c.MaxLength > 0 {
+		rule.InputMessage = tr("answerCell.maxLength", c.MaxLength)
+	}
+	return rule
+}
+
+// choicesRule returns the validation rule of a multiple-choice answer cell, a
+// dropdown of the choices.
+func choicesRule(choices []string) *sheets.DataValidationRule {
+	values := make([]*sheets.ConditionValue, len(choices))
+	for i, choice := range choices {
+		values[i] = &sheets.ConditionValue{UserEnteredValue: choice}
+	}
+	return &sheets.DataValidationRule{
+		Condition: &sheets.BooleanCondition{
+			Type:   "ONE_OF_LIST",
+			Values: values,
+		},
+		ShowCustomUi: true,
+		Strict:       true,
+	}
+}
+
+// validateTeamAnswerCells sets the data validation rules of the answer cells
+// of the team spreadsheet. The blitz answer cells join the sub-answers with a
+// formula and are left as they are.
+func (a *app) validateTeamAnswerCells(team *sheets.Spreadsheet, batch *setupBatch) error {
+	var questions map[int]*question
+	if len(a.config.Questions.File) != 0 {
+		var err error
+		questions, err = a.config.Questions.load()
+		if err != nil {
+			return err
+		}
+	}
+	var sheetID int64
+	if len(team.Sheets) != 0 {
+		sheetID = team.Sheets[0].Properties.SheetId
+	}
+	requests := make([]*sheets.Request, 0)
+	for round := 0; round <= a.config.NumberOfQuestions; round++ {
+		if a.config.subAnswersCount(round) > 1 {
+			continue
+		}
+		column, row, err := a.getTeamRoundCellPosition(round)
+		if err != nil {
+			// no warm-up question
+			continue
+		}
+		rule := a.config.AnswerCells.answerCellRule(cellName(column, row))
+		if q, ok := questions[a.config.questionNumber(round)]; ok && len(q.Choices) != 0 {
+			rule = choicesRule(q.Choices)
+		}
+		if rule == nil {
+			continue
+		}
+		requests = append(requests, &sheets.Request{
+			SetDataValidation: &sheets.SetDataValidationRequest{
+				Range: &sheets.GridRange{
+					SheetId:          sheetID,
+					StartColumnIndex: int64(column),
+					EndColumnIndex:   int64(column + 1),
+					StartRowIndex:    int64(row - 1),
+					EndRowIndex:      int64(row),
+				},
+				Rule: rule,
+			},
+		})
+	}
+	batch.addRequests(requests...)
+	return nil
+}
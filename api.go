@@ -0,0 +1,99 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"log"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+type apiRequest struct {
+	Command string `json:"command"`
+}
+
+type apiResponse struct {
+	Result interface{} `json:"result,omitempty"`
+	Error  string      `json:"error,omitempty"`
+}
+
+func (a *app) serveAPI() {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/commands", a.handleAPICommand)
+	log.Printf("serving the API on %s", a.config.APIAddr)
+	if err := a.listenAndServe(&http.Server{Addr: a.config.APIAddr, Handler: mux}); err != nil {
+		log.Printf("[ERR]: API server stopped: %v", err)
+	}
+}
+
+// handleAPICommand runs a command sent as {"command": "fetch 3"} and responds
+// with its result serialized the same way as the REPL JSON output.
+func (a *app) handleAPICommand(w http.ResponseWriter, r *http.Request) {
+	if !a.isAPIAuthorized(r) {
+		writeAPIResponse(w, http.StatusUnauthorized, &apiResponse{Error: "unauthorized"})
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeAPIResponse(w, http.StatusMethodNotAllowed, &apiResponse{Error: "only POST is allowed"})
+		return
+	}
+	var req apiRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeAPIResponse(w, http.StatusBadRequest, &apiResponse{Error: err.Error()})
+		return
+	}
+	res, err := a.executeNonInteractive(strings.TrimSpace(req.Command))
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch err.(type) {
+		case *errorUnknownCommand, *errorInvalidArguments, *errorInteractiveCommand:
+			status = http.StatusBadRequest
+		case *errorReadOnly:
+			status = http.StatusForbidden
+		case *errorBusy:
+			status = http.StatusConflict
+		}
+		switch errorClass(err) {
+		case errRoundNotFound:
+			status = http.StatusNotFound
+		case errQuotaExceeded:
+			status = http.StatusTooManyRequests
+		case errNotAuthorized:
+			status = http.StatusBadGateway
+		}
+		log.Printf("[ERR]: API command \"%s\" failed: %v", req.Command, err)
+		writeAPIResponse(w, status, &apiResponse{Error: err.Error()})
+		return
+	}
+	resp := &apiResponse{}
+	if v := reflect.ValueOf(res); res != nil && !(v.Kind() == reflect.Ptr && v.IsNil()) {
+		resp.Result = res
+		if viewer, ok := res.(outputViewer); ok {
+			resp.Result = viewer.outputView()
+		}
+	}
+	writeAPIResponse(w, http.StatusOK, resp)
+}
+
+func (a *app) isAPIAuthorized(r *http.Request) bool {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "Bearer ") {
+		return false
+	}
+	token := []byte(strings.TrimPrefix(auth, "Bearer "))
+	for _, t := range a.config.APITokens {
+		if subtle.ConstantTimeCompare(token, []byte(t)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+func writeAPIResponse(w http.ResponseWriter, status int, resp *apiResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Printf("[ERR]: failed to write the API response: %v", err)
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	apiRetries     = 3
+	apiRetryDelay  = time.Second
+	apiRetryFactor = 2
+)
+
+// retryTransport retries the Google API requests refused with 429 and the
+// reads failed with a server error, waiting longer after every attempt. The
+// writes failed with a server error are not retried as they may have been
+// applied.
+type retryTransport struct {
+	base    http.RoundTripper
+	metrics *metrics
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	delay := apiRetryDelay
+	for attempt := 0; ; attempt++ {
+		resp, err := t.base.RoundTrip(req)
+		if err != nil || attempt == apiRetries || !retryable(req, resp) {
+			return resp, err
+		}
+		if req.Body != nil {
+			if req.GetBody == nil {
+				return resp, nil
+			}
+			body, err := req.GetBody()
+			if err != nil {
+				return resp, nil
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+		resp.Body.Close()
+		atomic.AddUint64(&t.metrics.apiRetries, 1)
+		select {
+		case <-time.After(delay):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+		delay *= apiRetryFactor
+	}
+}
+
+func retryable(req *http.Request, resp *http.Response) bool {
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	return resp.StatusCode >= 500 && (req.Method == http.MethodGet || req.Method == http.MethodHead)
+}
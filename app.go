@@ -4,20 +4,28 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"log"
+	"net/http"
 	"os"
 	"path"
-	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/gmail/v1"
 	"google.golang.org/api/option"
+	"google.golang.org/api/script/v1"
 	"google.golang.org/api/sheets/v4"
 )
 
+var errManagerSpreadsheetNotFound = fmt.Errorf("the manager spreadsheet is not found, run resumeSetup to complete the game setup")
+
 type gameSpreadsheets struct {
 	manager *sheets.Spreadsheet
 	teams   map[string]*sheets.Spreadsheet
@@ -26,302 +34,785 @@ type gameSpreadsheets struct {
 type app struct {
 	config  *Config
 	service *sheets.Service
-	bolt    *boltManager
+	script  *script.Service
+	drive   *drive.Service
+	gmail   *gmail.Service
+	store   gameStore
+	metrics *metrics
+	timer   roundTimer
+	// metadata caches the spreadsheets sheets and protected ranges
+	metadata *metadataCache
+	values   *valuesCache
+	conn     *connectivity
+	tokens   *tokenKeeper
+	health   healthState
+	// events are pushed to the dashboard
+	events *eventHub
+	// offline holds the operations postponed until the API is reachable
+	offline offlineQueue
+	// engineMu serializes the commands run from the REPL and the API
+	engineMu sync.Mutex
+	// interactiveCmd is the interactive command running in the REPL, the
+	// API runs only the read-only commands meanwhile. It is guarded by
+	// engineMu.
+	interactiveCmd string
+	// cmdCtx is the context of the running non-interactive command
+	ctxMu  sync.Mutex
+	cmdCtx context.Context
+	// session is saved and reported on the shutdown
+	session session
+}
+
+// apiClients are the Google API clients, they are shared by all the games run
+// by the process so that the requests go through the same rate limiter.
+type apiClients struct {
+	sheets  *sheets.Service
+	script  *script.Service
+	drive   *drive.Service
+	gmail   *gmail.Service
+	metrics *metrics
+	conn    *connectivity
+	tokens  *tokenKeeper
+}
+
+// newAPIClients authorizes the clients for the configurations, the token is
+// stored in tokenDir.
+func newAPIClients(configs []*Config, credsFile string, tokenDir string) (*apiClients, error) {
+	scopes := []string{sheets.SpreadsheetsScope}
+	needsScript := false
+	needsDrive := false
+	needsGmail := false
+	needsTemplate := false
+	requestsPerMinute := 0
+	readOnly := false
+	var callTimeout time.Duration
+	for _, config := range configs {
+		readOnly = readOnly || config.ReadOnly
+		if config.callTimeout() > callTimeout {
+			callTimeout = config.callTimeout()
+		}
+		needsScript = needsScript || config.CaptureSubmissionTime
+		needsDrive = needsDrive || config.Drive.enabled() || len(config.TemplateSpreadsheetID) != 0 || config.Report.Upload || config.Sharing.enabled()
+		needsTemplate = needsTemplate || len(config.TemplateSpreadsheetID) != 0
+		needsGmail = needsGmail || config.Mail.useGmail()
+		if config.RequestsPerMinute > 0 && (requestsPerMinute == 0 || config.RequestsPerMinute < requestsPerMinute) {
+			requestsPerMinute = config.RequestsPerMinute
+		}
+	}
+	if needsScript {
+		scopes = append(scopes, script.ScriptProjectsScope, scriptAppScope)
+	}
+	if needsDrive {
+		scopes = append(scopes, drive.DriveFileScope)
+	}
+	if needsTemplate {
+		// the drive.file scope does not grant the access to the template
+		// that is not created by the application
+		scopes = append(scopes, drive.DriveReadonlyScope)
+	}
+	if needsGmail {
+		scopes = append(scopes, gmail.GmailSendScope)
+	}
+	for _, config := range configs {
+		scopes = mergeScopes(scopes, config.Scopes)
+	}
+	tok, oauthConfig, err := getOauth2Token(credsFile, tokenDir, scopes)
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	appMetrics := newMetrics()
+	conn := newConnectivity()
+	tokens := newTokenKeeper(oauthConfig, tok, tokenDir)
+	go tokens.keepFresh()
+	var transport http.RoundTripper = &connectivityTransport{
+		base: &retryTransport{
+			base: &metricsTransport{
+				base: newRateLimitedTransport(&oauth2.Transport{
+					Source: tokens,
+					Base:   http.DefaultTransport,
+				}, requestsPerMinute),
+				metrics: appMetrics,
+			},
+			metrics: appMetrics,
+		},
+		conn: conn,
+	}
+	if readOnly {
+		transport = &readOnlyTransport{base: transport}
+	}
+	httpClient := option.WithHTTPClient(&http.Client{
+		Timeout:   callTimeout,
+		Transport: transport,
+	})
+	service, err := sheets.NewService(ctx, httpClient)
+	if err != nil {
+		return nil, err
+	}
+	clients := &apiClients{
+		sheets:  service,
+		metrics: appMetrics,
+		conn:    conn,
+		tokens:  tokens,
+	}
+	if needsScript {
+		clients.script, err = script.NewService(ctx, httpClient)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if needsDrive {
+		clients.drive, err = drive.NewService(ctx, httpClient)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if needsGmail {
+		clients.gmail, err = gmail.NewService(ctx, httpClient)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return clients, nil
 }
 
 func newApp(config *Config) (*app, error) {
 	if config == nil {
 		return nil, fmt.Errorf("internal error: config passed to newApp cannot be nil")
 	}
-	if err := checkOutputDir(config.NewGame, config.OutputDir); err != nil {
+	if err := checkOutputDir(config.NewGame, config.Force, config.OutputDir); err != nil {
 		return nil, err
 	}
-	tok, oauthConfig, err := getOauth2Token(config.CredsFile, config.OutputDir)
+	clients, err := newAPIClients([]*Config{config}, config.CredsFile, config.OutputDir)
 	if err != nil {
 		return nil, err
 	}
-	ctx := context.Background()
-	service, err := sheets.NewService(ctx, option.WithTokenSource(oauthConfig.TokenSource(ctx, tok)))
+	return newAppWithClients(config, clients)
+}
+
+func newAppWithClients(config *Config, clients *apiClients) (*app, error) {
+	app := &app{
+		config:  config,
+		service: clients.sheets,
+		script:  clients.script,
+		drive:   clients.drive,
+		gmail:   clients.gmail,
+		metrics:  clients.metrics,
+		metadata: newMetadataCache(config.MetadataCacheSeconds),
+		values:   newValuesCache(config.MetadataCacheSeconds),
+		conn:     clients.conn,
+		tokens:   clients.tokens,
+		events:   newEventHub(),
+	}
+	store, err := newGameStore(config)
 	if err != nil {
 		return nil, err
 	}
-	dbFile := path.Join(config.OutputDir, "bolt-db")
-	app := &app{
-		config:  config,
-		service: service,
-		bolt: &boltManager{
-			dbFile: dbFile,
-		},
+	app.store = store
+	app.metadata.store = store
+	app.values.store = store
+	if !config.NewGame {
+		teams, err := app.store.getTeams()
+		if err != nil {
+			app.close()
+			return nil, err
+		}
+		if teams != nil {
+			config.Teams = teams
+		}
+	} else if err := app.replaceStoredGame(); err != nil {
+		app.close()
+		return nil, err
 	}
 	return app, nil
 }
 
+// replaceStoredGame deletes the game kept by a store outside of the output
+// dir, e.g. an SQLite file, when a new game is created with --force, so that
+// the data of the two games is not mixed.
+func (a *app) replaceStoredGame() error {
+	teams, err := a.store.getTeams()
+	if err != nil {
+		return err
+	}
+	if len(teams) == 0 {
+		return nil
+	}
+	if !a.config.Force {
+		return fmt.Errorf("the store %s already holds a game, run without --newGame to continue it, or add --force to delete it and create a new one", a.config.Store)
+	}
+	return a.store.reset()
+}
+
 func (a *app) Run() error {
+	if err := a.start(); err != nil {
+		return err
+	}
+	return runREPL(a.config.OutputFormat, a.healthIndicator, func(cmdStr string) (*app, string, error) {
+		return a, cmdStr, nil
+	})
+}
+
+// start creates the game spreadsheets for a new game and starts the optional
+// servers.
+func (a *app) start() error {
 	if a.config.NewGame {
+		if err := a.checkDuplicateGame(); err != nil {
+			return err
+		}
+		ctx, stop := interruptContext()
+		a.setCommandContext(ctx)
 		_, err := a.CreateGameSpreadsheets()
+		a.setCommandContext(nil)
+		interrupted := ctx.Err() != nil
+		stop()
 		if err != nil {
-			return err
+			if !interrupted {
+				return err
+			}
+			fmt.Println("Game setup is interrupted, complete it with resumeSetup")
 		}
+	} else {
+		a.warnIfStale()
+	}
+	go a.watchConnectivity()
+	go a.watchHealth()
+	if len(a.config.HTTPAddr) != 0 {
+		go a.serveHTTP()
 	}
+	if len(a.config.APIAddr) != 0 {
+		go a.serveAPI()
+	}
+	return nil
+}
+
+// runREPL reads the commands from the standard input, resolve returns the
+// game a command is addressed to and the command itself. A nil game means
+// that the command is handled by resolve. The status is shown in the prompt.
+func runREPL(outputFormat string, status func() string, resolve func(cmdStr string) (*app, string, error)) error {
 	for {
-		fmt.Print("Enter command: ")
+		fmt.Print(tr("prompt.enterCommand", status()))
 		reader := bufio.NewReader(os.Stdin)
 		cmdStr, err := reader.ReadString('\n')
 		if err != nil {
-			return fmt.Errorf("failed to scan the command: %v", err)
+			return fmt.Errorf("failed to scan the command: %w", err)
 		}
 		cmdStr = cmdStr[:len(cmdStr)-1]
 		fmt.Println()
-		cmdErr := func(cmd string, err error) error {
-			return fmt.Errorf("command \"%s\" failed: %v", cmd, err)
+		cmdStr, format := extractOutputFormat(cmdStr, outputFormat)
+		if getCommand(cmdStr) == "exit" {
+			return nil
 		}
-		switch cmd := getCommand(cmdStr); cmd {
-		case "listURLs":
-			if err := a.CmdListURLs(); err != nil {
-				return cmdErr(cmdStr, err)
-			}
-		case "fetch":
-			if err := a.CmdFetchResults(cmdStr); err != nil {
-				return cmdErr(cmdStr, err)
-			}
-		case "get":
-			if err := a.CmdGetResults(cmdStr); err != nil {
-				return cmdErr(cmdStr, err)
-			}
-		case "check":
-			if err := a.CmdCheckResults(cmdStr); err != nil {
-				return cmdErr(cmdStr, err)
-			}
-		case "total":
-			if err := a.CmdGetTotal(); err != nil {
-				return cmdErr(cmdStr, err)
+		a, cmdStr, err := resolve(cmdStr)
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		if a == nil {
+			continue
+		}
+		// Ctrl-C cancels a non-interactive command, the interactive ones
+		// read the standard input and are stopped by the shutdown
+		ctx, stop := context.Background(), func() {}
+		if !a.isInteractive(cmdStr) {
+			ctx, stop = interruptContext()
+		}
+		res, err := a.executeContext(ctx, cmdStr)
+		interrupted := ctx.Err() != nil
+		stop()
+		if err != nil {
+			switch err.(type) {
+			case *errorUnknownCommand, *errorInvalidArguments, *errorReadOnly:
+				fmt.Println(err)
+				continue
 			}
-		case "exit":
-			return nil
-		default:
-			if len(cmd) == 0 {
-				fmt.Printf("got an empty command\n")
+			if interrupted {
+				fmt.Print(tr("prompt.interrupted", cmdStr, err))
 				continue
 			}
-			fmt.Printf("unknown command: %s\n", cmd)
+			return fmt.Errorf("command \"%s\" failed: %w", cmdStr, err)
+		}
+		if res == nil {
 			continue
 		}
+		if err := printResult(res, format); err != nil {
+			return err
+		}
 	}
 }
 
-func (a *app) CmdListURLs() error {
+func (a *app) CmdListURLs() (*listURLsResult, error) {
 	sheets, err := a.GetGameSpreadsheets()
 	if err != nil {
-		return err
+		return nil, err
 	}
-	fmt.Println(sheets)
-	return nil
+	return newListURLsResult(sheets), nil
 }
 
-func (a *app) CmdGetTotal() error {
-	var firstInd int
-	if a.config.HasWarmUpQuestion {
-		firstInd = 1
+func (a *app) CmdGetTotal() (*totalResult, error) {
+	total, err := a.computeTotals()
+	if err != nil {
+		return nil, err
+	}
+	res := &totalResult{
+		Totals: make([]teamTotal, 0, len(a.config.Teams)),
 	}
-	total := make(map[string]int)
 	for _, team := range a.config.Teams {
+		res.Totals = append(res.Totals, teamTotal{Team: team, Score: total[team], OutOfCompetition: a.config.OutOfCompetition[team]})
+	}
+	return res, nil
+}
+
+// computeTotals returns the totals of the teams, computed by the scoring
+// plugin if it is configured, with the score adjustments applied.
+func (a *app) computeTotals() (map[string]float64, error) {
+	totals, err := a.pluginTotals()
+	if err != nil {
+		return nil, err
+	}
+	if totals == nil {
+		if totals, err = a.roundTotals(); err != nil {
+			return nil, err
+		}
+	}
+	if err := a.applyAdjustments(totals); err != nil {
+		return nil, err
+	}
+	return totals, nil
+}
+
+// roundTotals computes the totals from the stored results of the rounds.
+func (a *app) roundTotals() (map[string]float64, error) {
+	allMeta, err := a.store.getAllRoundMeta()
+	if err != nil {
+		return nil, err
+	}
+	allResults, err := a.store.getAllRoundResults()
+	if err != nil {
+		return nil, err
+	}
+	resultsByRound := make(map[int]*roundResults, len(allResults))
+	for _, results := range allResults {
+		resultsByRound[results.Round] = results
+	}
+	return sumTotals(a.config.Teams, a.scoredRounds(), resultsByRound, allMeta), nil
+}
+
+// sumTotals adds up the points of the teams in the scored rounds.
+func sumTotals(teams []string, rounds []int, allResults map[int]*roundResults, allMeta map[int]*roundMeta) map[string]float64 {
+	total := make(map[string]float64, len(teams))
+	for _, team := range teams {
 		total[team] = 0
 	}
-	for i := firstInd; i < a.config.NumberOfQuestions; i++ {
-		results, err := a.bolt.getRoundResults(i)
-		if err != nil {
-			if err.Error() == fmt.Sprintf("round %d results are not found", i) {
-				continue
+	for _, i := range rounds {
+		meta := allMeta[i]
+		if meta != nil && meta.Void == roundVoidAll {
+			// the point of a voided question is given to every team,
+			// whether it has answered or not
+			for team := range total {
+				total[team]++
 			}
-			return err
+			continue
+		}
+		results, ok := allResults[i]
+		if !ok {
+			continue
 		}
 		for team, res := range results.Results {
 			if _, ok := total[team]; !ok {
-				return fmt.Errorf("team %s is unknown", team)
-			}
-			if res.Status == ResponseStatusOK {
-				total[team]++
+				// the team has been removed from the game
+				continue
 			}
+			total[team] += meta.points(res.Status)
 		}
 	}
-	for team, count := range total {
-		fmt.Printf("Team %s: %d\n", team, count)
+	return total
+}
+
+func (a *app) CmdFetchResults(cmdStr string) (*roundResults, error) {
+	round, err := a.getRoundNumber(cmdStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse fetchResp request: %w", err)
 	}
-	return nil
+	return a.fetchRound(round)
 }
 
-func (a *app) CmdFetchResults(cmdStr string) error {
-	round, err := getRoundNumber(cmdStr)
+// fetchRound fetches and stores the round answers, the results are nil if the
+// fetch is queued until the Google API is reachable.
+func (a *app) fetchRound(round int) (*roundResults, error) {
+	if err := a.checkRoundClosed(round); err != nil {
+		return nil, err
+	}
+	if !a.conn.isOnline() {
+		a.offline.queueFetch(round)
+		fmt.Printf("The Google API is unreachable, the round %d fetch is queued until it is reachable again\n", a.config.questionNumber(round))
+		return nil, nil
+	}
+	results, quarantined, err := a.fetchSettledRoundResults(a.commandContext(), round)
 	if err != nil {
-		return fmt.Errorf("failed to parse fetchResp request: %v", err)
+		if !a.conn.isOnline() {
+			a.offline.queueFetch(round)
+			fmt.Printf("The Google API is unreachable, the round %d fetch is queued until it is reachable again\n", a.config.questionNumber(round))
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to fetch round results: %w", err)
+	}
+	return a.storeFetchedResults(round, results, quarantined)
+}
+
+// storeFetchedResults stores the fetched round answers as not checked yet.
+func (a *app) storeFetchedResults(round int, results map[string]string, quarantined []quarantinedEntry) (*roundResults, error) {
+	if err := a.store.saveResponseRevisions(round, results, time.Now()); err != nil {
+		return nil, fmt.Errorf("failed to store the response history: %w", err)
+	}
+	var submissionTimes map[string]time.Time
+	if a.config.CaptureSubmissionTime {
+		var err error
+		submissionTimes, err = a.fetchTeamsSubmissionTimes(round)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch submission times: %w", err)
+		}
 	}
-	results, err := a.fetchRoundResults(round)
+	previousResults, err := a.store.getRoundResults(round)
 	if err != nil {
-		return fmt.Errorf("failed to fetch round results: %v", err)
+		if !errors.Is(err, errRoundNotFound) {
+			return nil, err
+		}
+		previousResults = &roundResults{}
 	}
 	resultsToStore := make(map[string]*roundResponse)
 	for team, resp := range results {
+		version := 0
+		if previousResp, ok := previousResults.Results[team]; ok {
+			version = previousResp.Version + 1
+		}
 		resultsToStore[team] = &roundResponse{
-			Response: resp,
-			Status:   ResponseStatusNotChecked,
+			Response:    resp,
+			Status:      ResponseStatusNotChecked,
+			SubmittedAt: submissionTimes[team],
+			Version:     version,
 		}
 	}
-	storeReq := &roundResults{
-		Round:   round,
-		Results: resultsToStore,
+	if count := a.config.subAnswersCount(round); count > 1 {
+		subResponses, err := a.fetchBlitzSubResponses(round)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch the blitz answers: %w", err)
+		}
+		for team, resp := range resultsToStore {
+			resp.SubResponses = subResponses[team]
+			resp.SubStatuses = make([]ResponseStatus, count)
+			for i := range resp.SubStatuses {
+				resp.SubStatuses[i] = ResponseStatusNotChecked
+			}
+		}
 	}
-	if err := a.bolt.saveRoundResults(storeReq); err != nil {
-		return fmt.Errorf("failed to store round results: %v", err)
+	// the answers entered by the jury are not replaced by the fetched ones
+	for team, previousResp := range previousResults.Results {
+		if kept := keepManualResponse(a.config.questionNumber(round), team, previousResp, results[team]); kept != nil {
+			resultsToStore[team] = kept
+		}
 	}
-	fmt.Println(storeReq)
-	return nil
+	// the previous answers of the quarantined teams are kept until their
+	// values are fixed
+	for _, q := range quarantined {
+		if previousResp, ok := previousResults.Results[q.Team]; ok {
+			if _, fetched := resultsToStore[q.Team]; !fetched {
+				resultsToStore[q.Team] = previousResp
+			}
+		}
+	}
+	storeReq := &roundResults{
+		Round:       round,
+		Results:     resultsToStore,
+		Quarantined: quarantined,
+		Question:    a.config.questionNumber(round),
+	}
+	if err := a.store.saveRoundResults(storeReq); err != nil {
+		return nil, fmt.Errorf("failed to store round results: %w", err)
+	}
+	a.publishResults(eventTypeAnswers, storeReq)
+	a.notifyRound(webhookEventRoundFetched, storeReq)
+	logHistoryError(a.config.questionNumber(round), a.updateHistory(storeReq))
+	a.validateAnswers(round, results)
+	return storeReq, nil
 }
 
 //TODO: refactor as two calls: to get round results and to store round results
 func (a *app) CmdCheckResults(cmdStr string) error {
-	round, err := getRoundNumber(cmdStr)
+	round, err := a.getRoundNumber(cmdStr)
 	if err != nil {
-		return fmt.Errorf("failed to parse check request: %v", err)
+		return fmt.Errorf("failed to parse check request: %w", err)
 	}
-	results, err := a.bolt.getRoundResults(round)
+	return a.checkRound(round)
+}
+
+// checkRound runs the interactive check of the round and stores the verdicts.
+func (a *app) checkRound(round int) error {
+	results, err := a.store.getRoundResults(round)
 	if err != nil {
 		return err
 	}
-	if err := checkResults(results); err != nil {
+	if err := a.checkResults(results); err != nil {
 		return err
 	}
-	if err := a.bolt.saveRoundResults(results); err != nil {
-		return fmt.Errorf("failed to store round results: %v", err)
+	stored, saveErr := a.store.saveVerdicts(results)
+	if stored == nil {
+		return fmt.Errorf("failed to store round results: %w", saveErr)
+	}
+	a.publishResults(eventTypeStatuses, stored)
+	a.notifyRound(webhookEventRoundChecked, stored)
+	if err := a.store.saveCheckProgress(round, nil); err != nil {
+		return err
+	}
+	if _, err := a.markStatuses(stored); err != nil {
+		if a.conn.isOnline() {
+			return fmt.Errorf("failed to mark the statuses in the manager spreadsheet: %w", err)
+		}
+		a.offline.queueStatuses(round)
+		fmt.Printf("The Google API is unreachable, the round %d statuses are saved and will be written to the manager spreadsheet once it is reachable again\n", a.config.questionNumber(round))
+	}
+	if saveErr != nil {
+		if conflictErr, ok := saveErr.(*errorVerdictConflict); ok {
+			fmt.Println(conflictErr)
+			return nil
+		}
+		return saveErr
 	}
 	return nil
 }
 
-func checkResults(results *roundResults) error {
-	reader := bufio.NewReader(os.Stdin)
-	fmt.Printf("Checking results for the round %d\n", results.Round)
-	for team, result := range results.Results {
-		fmt.Printf("Team %s, response: %s, previous status: %v\n", team, result.Response, result.Status)
-		for {
-			statusStr, err := reader.ReadString('\n')
-			if err != nil {
-				return fmt.Errorf("failed to scan the command: %v", err)
-			}
-			statusStr = statusStr[:len(statusStr)-1]
-
-			switch statusStr {
-			case "+":
-				results.Results[team].Status = ResponseStatusOK
-			case "-":
-				results.Results[team].Status = ResponseStatusKO
-			case "?":
-				results.Results[team].Status = ResponseStatusInQuestion
-			case "":
-				results.Results[team].Status = ResponseStatusNotChecked
-			default:
-				fmt.Println("Unknown status, try again")
-				continue
-			}
-			break
+func (a *app) CmdCrossCheck(cmdStr string) (*crossCheckResult, error) {
+	round, err := a.getRoundNumber(cmdStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse crosscheck request: %w", err)
+	}
+	managerResults, _, err := a.fetchRoundResults(round)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch round results from the manager spreadsheet: %w", err)
+	}
+	teamsResults, _, err := a.fetchTeamsRoundResults(round)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch round results from the teams spreadsheets: %w", err)
+	}
+	res := &crossCheckResult{
+		Round:      a.config.questionNumber(round),
+		Mismatches: make([]crossCheckMismatch, 0),
+	}
+	for _, team := range a.config.Teams {
+		managerResp := managerResults[team]
+		teamResp := teamsResults[team]
+		if managerResp == teamResp {
+			continue
 		}
+		res.Mismatches = append(res.Mismatches, crossCheckMismatch{
+			Team:            team,
+			ManagerResponse: managerResp,
+			TeamResponse:    teamResp,
+		})
 	}
-	return nil
+	return res, nil
 }
 
-func (a *app) CmdGetResults(cmdStr string) error {
-	round, err := getRoundNumber(cmdStr)
+func (a *app) CmdGetResults(cmdStr string) (*roundResults, error) {
+	round, err := a.getRoundNumber(cmdStr)
 	if err != nil {
-		return fmt.Errorf("failed to parse fetch request: %v", err)
+		return nil, fmt.Errorf("failed to parse fetch request: %w", err)
 	}
-	roundResults, err := a.bolt.getRoundResults(round)
+	roundResults, err := a.store.getRoundResults(round)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	fmt.Println(roundResults)
-	return nil
+	if roundResults.Meta, err = a.store.getRoundMeta(round); err != nil {
+		return nil, err
+	}
+	return a.numberResults(roundResults), nil
 }
 
-func getRoundNumber(cmdStr string) (int, error) {
-	sSplitted := strings.Split(cmdStr, " ")
+func (a *app) getRoundNumber(cmdStr string) (int, error) {
+	sSplitted := splitArgs(cmdStr)
 	if len(sSplitted) != 2 {
 		return 0, fmt.Errorf("expected 1 argument, got %d", len(sSplitted)-1)
 	}
-	roundNumberStr := sSplitted[1]
-	round64, err := strconv.ParseInt(roundNumberStr, 0, 0)
-	if err != nil {
-		return 0, fmt.Errorf("failed to parse argument %s as a round number: %v", roundNumberStr, err)
-	}
-	return int(round64), nil
+	return a.parseRound(sSplitted[1])
 }
 
 func (a *app) CreateGameSpreadsheets() (*gameSpreadsheets, error) {
-	sheets := &gameSpreadsheets{}
-	var err error
-	sheets.manager, err = a.createManagerSpreadsheet()
-	if err != nil {
-		return nil, err
-	}
-	sheets.teams, err = a.createTeamsSpreadsheets()
-	if err != nil {
+	if err := a.store.saveTeams(a.config.Teams); err != nil {
 		return nil, err
 	}
-	if err := a.bolt.saveSpreadsheets(newStoreGameSpreadsheets(sheets)); err != nil {
-		return nil, err
+	sheets := &gameSpreadsheets{
+		teams: make(map[string]*sheets.Spreadsheet, len(a.config.Teams)),
 	}
-	if err := a.fillGameSheets(sheets); err != nil {
+	if err := a.completeSetup(sheets); err != nil {
 		return nil, err
 	}
-	return sheets, err
+	return sheets, nil
 }
 
 func (a *app) GetGameSpreadsheets() (*storeGameSpreadsheets, error) {
-	spreadsheets, err := a.bolt.getSpreadsheets()
+	spreadsheets, err := a.store.getSpreadsheets()
 	if err != nil {
 		return nil, err
 	}
 	return spreadsheets, nil
 }
 
-func (a *app) fillGameSheets(sheets *gameSpreadsheets) error {
-	if err := a.fillManagerSpreadsheet(sheets.manager); err != nil {
-		return err
+func newGameSpreadsheets(storeSheets *storeGameSpreadsheets) *gameSpreadsheets {
+	gameSheets := &gameSpreadsheets{
+		teams: make(map[string]*sheets.Spreadsheet, len(storeSheets.teams)),
+	}
+	if storeSheets.manager != nil {
+		gameSheets.manager = storeSheets.manager.toSpreadsheet()
+	}
+	for team, teamSheet := range storeSheets.teams {
+		gameSheets.teams[team] = teamSheet.toSpreadsheet()
+	}
+	return gameSheets
+}
+
+// completeSetup creates and fills the game spreadsheets that are missing from
+// gameSheets. Every created spreadsheet and every completed filling step is
+// persisted right away, so that a failed setup can be resumed. The team
+// spreadsheets are created and filled concurrently.
+func (a *app) completeSetup(gameSheets *gameSpreadsheets) error {
+	defer a.logSetupCost(time.Now(), a.apiCalls())
+	var err error
+	if gameSheets.manager == nil {
+		gameSheets.manager, err = a.createManagerSpreadsheet()
+		if err != nil {
+			return err
+		}
+		storeSheets := &storeGameSpreadsheets{
+			manager: newStoreSpreadsheet(gameSheets.manager),
+		}
+		if err := a.store.saveSpreadsheets(storeSheets); err != nil {
+			return err
+		}
+	}
+	missing := make([]string, 0)
+	for _, team := range a.config.Teams {
+		if _, ok := gameSheets.teams[team]; !ok {
+			missing = append(missing, team)
+		}
 	}
-	for _, sheet := range sheets.teams {
-		if err := a.fillTeamSpreadsheet(sheet); err != nil {
+	var teamsMu sync.Mutex
+	err = a.forEachTeam(missing, func(team string) error {
+		teamSheet, err := a.createTeamSpreadsheet(team)
+		if err != nil {
 			return err
 		}
+		storeSheets := &storeGameSpreadsheets{
+			teams: map[string]*storeSpreadsheet{
+				team: newStoreSpreadsheet(teamSheet),
+			},
+		}
+		if err := a.store.saveTeamsSpreadsheets(storeSheets); err != nil {
+			return err
+		}
+		teamsMu.Lock()
+		gameSheets.teams[team] = teamSheet
+		teamsMu.Unlock()
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	steps, err := a.store.getSetupSteps()
+	if err != nil {
+		return err
+	}
+	unfilled := make([]string, 0)
+	for _, team := range a.config.Teams {
+		if !steps[setupStepTeamFilled(team)] {
+			unfilled = append(unfilled, team)
+		}
 	}
-	if err := a.linkManagerTeams(sheets); err != nil {
+	err = a.forEachTeam(unfilled, func(team string) error {
+		if err := a.fillTeamSpreadsheet(team, gameSheets.teams[team]); err != nil {
+			return err
+		}
+		return a.store.markSetupStep(setupStepTeamFilled(team))
+	})
+	if err != nil {
 		return err
 	}
+	if !steps[setupStepManagerFilled] {
+		if err := a.fillManagerSpreadsheet(gameSheets.manager); err != nil {
+			return err
+		}
+		if err := a.store.markSetupStep(setupStepManagerFilled); err != nil {
+			return err
+		}
+	}
+	if !steps[setupStepManagerLinked] {
+		if err := a.linkManagerTeams(gameSheets); err != nil {
+			return err
+		}
+		if err := a.store.markSetupStep(setupStepManagerLinked); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+type resumeSetupResult struct {
+	Manager string            `json:"manager" yaml:"manager"`
+	Teams   map[string]string `json:"teams" yaml:"teams"`
+}
+
+func (r *resumeSetupResult) String() string {
+	var sb strings.Builder
+	sb.WriteString("Game setup is complete\n")
+	sb.WriteString((&listURLsResult{Manager: r.Manager, Teams: r.Teams}).String())
+	return sb.String()
+}
+
+func (a *app) CmdResumeSetup() (*resumeSetupResult, error) {
+	storeSheets, err := a.GetGameSpreadsheets()
+	if err != nil {
+		return nil, err
+	}
+	gameSheets := newGameSpreadsheets(storeSheets)
+	if err := a.completeSetup(gameSheets); err != nil {
+		return nil, err
+	}
+	res := &resumeSetupResult{
+		Manager: gameSheets.manager.SpreadsheetUrl,
+		Teams:   make(map[string]string, len(gameSheets.teams)),
+	}
+	for _, team := range a.config.Teams {
+		res.Teams[team] = gameSheets.teams[team].SpreadsheetUrl
+	}
+	return res, nil
+}
+
 func (a *app) linkManagerTeams(gameSheets *gameSpreadsheets) error {
 	groups, err := a.createLinkManagerTeamsGroups(gameSheets)
 	if err != nil {
 		return err
 	}
+	if a.config.MaskAnswers {
+		if err := a.maskLinks(gameSheets.manager.SpreadsheetId, groups); err != nil {
+			return err
+		}
+	}
 	valuesService := sheets.NewSpreadsheetsValuesService(a.service)
 	_, err = valuesService.BatchUpdate(gameSheets.manager.SpreadsheetId, &sheets.BatchUpdateValuesRequest{
 		ValueInputOption: "USER_ENTERED",
 		Data:             groups,
-	}).Do()
+	}).Context(a.commandContext()).Do()
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (a *app) getLinkRange(offset int, length int) (string, error) {
-	if length > 24 {
-		return "", fmt.Errorf("group length must be inferior to 25")
-	}
+func (a *app) getLinkRange(offset int, length int) string {
 	startRow := offset*(len(a.config.Teams)+2) + 2
 	endRow := startRow + len(a.config.Teams)
-	startColumn := int('B')
+	startColumn := 1
 	endColumn := startColumn + length
-	r := fmt.Sprintf("%c%d:%c%d", rune(startColumn), startRow, rune(endColumn), endRow)
-	return r, nil
+	return rangeName(startColumn, startRow, endColumn, endRow)
 }
 
 func (a *app) fillManagerSpreadsheet(manager *sheets.Spreadsheet) error {
@@ -333,27 +824,56 @@ func (a *app) fillManagerSpreadsheet(manager *sheets.Spreadsheet) error {
 	_, err = valuesService.BatchUpdate(manager.SpreadsheetId, &sheets.BatchUpdateValuesRequest{
 		ValueInputOption: "USER_ENTERED",
 		Data:             groups,
-	}).Do()
+	}).Context(a.commandContext()).Do()
 	if err != nil {
 		return err
 	}
-	return nil
+	return a.fillTotalsSheet(manager.SpreadsheetId)
 }
 
-func (a *app) fillTeamSpreadsheet(team *sheets.Spreadsheet) error {
+func (a *app) fillTeamSpreadsheet(teamName string, team *sheets.Spreadsheet) error {
 	groups, err := a.createTeamAnswerGroups()
 	if err != nil {
 		return err
 	}
-	ranges, err := a.getTeamAnswerGridRanges()
-	if err != nil {
+	batch := &setupBatch{}
+	batch.addValues(groups...)
+	// the template spreadsheet is formatted already
+	if len(a.config.TemplateSpreadsheetID) == 0 {
+		if err := a.drawTeamAnswerBorders(batch); err != nil {
+			return err
+		}
+	}
+	if err := a.fillBlitzSheet(team, batch); err != nil {
 		return err
 	}
-	valuesService := sheets.NewSpreadsheetsValuesService(a.service)
-	_, err = valuesService.BatchUpdate(team.SpreadsheetId, &sheets.BatchUpdateValuesRequest{
-		ValueInputOption: "USER_ENTERED",
-		Data:             groups,
-	}).Do()
+	if err := a.validateTeamAnswerCells(team, batch); err != nil {
+		return err
+	}
+	a.hideTeamQuestions(team, batch)
+	if err := a.fillTeamSheetExtras(teamName, team, batch); err != nil {
+		return err
+	}
+	if err := batch.flush(a, team.SpreadsheetId); err != nil {
+		return fmt.Errorf("failed to fill the team %s spreadsheet: %w", teamName, err)
+	}
+	var sheetID int64
+	if len(team.Sheets) != 0 {
+		sheetID = team.Sheets[0].Properties.SheetId
+	}
+	if err := a.shareTeamSpreadsheet(teamName, team.SpreadsheetId, sheetID); err != nil {
+		return err
+	}
+	if a.config.CaptureSubmissionTime {
+		if err := a.installSubmissionTimeScript(team); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *app) drawTeamAnswerBorders(batch *setupBatch) error {
+	ranges, err := a.getTeamAnswerGridRanges()
 	if err != nil {
 		return err
 	}
@@ -372,10 +892,7 @@ func (a *app) fillTeamSpreadsheet(team *sheets.Spreadsheet) error {
 			},
 		}
 	}
-	spreadsheetsService := sheets.NewSpreadsheetsService(a.service)
-	_, err = spreadsheetsService.BatchUpdate(team.SpreadsheetId, &sheets.BatchUpdateSpreadsheetRequest{
-		Requests: updateBordersRequests,
-	}).Do()
+	batch.addRequests(updateBordersRequests...)
 	return nil
 }
 
@@ -384,19 +901,16 @@ func (a *app) createManagerAnswerGroups() ([]*sheets.ValueRange, error) {
 		return nil, nil
 	}
 	teamsCol := make([]interface{}, len(a.config.Teams)+1)
-	teamsCol[0] = "Teams"
+	teamsCol[0] = a.config.Labels.TeamsHeader
 	for i, team := range a.config.Teams {
 		teamsCol[i+1] = team
 	}
 	groups, err := a.createGroups(func(length int, currQuestionIndex int, groups []*sheets.ValueRange) ([]*sheets.ValueRange, error) {
-		r, err := a.getManagerRange(len(groups), length)
-		if err != nil {
-			return nil, err
-		}
+		r := a.getManagerRange(len(groups), length)
 		values := make([][]interface{}, length+1)
 		values[0] = teamsCol
 		for j := 1; j < length+1; j++ {
-			values[j] = []interface{}{currQuestionIndex + j}
+			values[j] = []interface{}{a.config.questionNumber(currQuestionIndex + j)}
 		}
 		g := &sheets.ValueRange{
 			MajorDimension: "COLUMNS",
@@ -417,17 +931,18 @@ func (a *app) createLinkManagerTeamsGroups(gameSheets *gameSpreadsheets) ([]*she
 		return nil, nil
 	}
 	groups, err := a.createGroups(func(length int, currQuestionIndex int, groups []*sheets.ValueRange) ([]*sheets.ValueRange, error) {
-		r, err := a.getLinkRange(len(groups), length)
-		if err != nil {
-			return nil, err
-		}
+		r := a.getLinkRange(len(groups), length)
 		values := make([][]interface{}, length)
-		currTeamRow := 2 + 3*len(groups)
 		for i := 0; i < length; i++ {
-			currColumn := int('A') + i
+			column, row, err := a.getTeamRoundCellPosition(currQuestionIndex + i + 1)
+			if err != nil {
+				return nil, err
+			}
 			values[i] = make([]interface{}, len(a.config.Teams))
 			for j := 0; j < len(a.config.Teams); j++ {
-				values[i][j] = fmt.Sprintf("=IMPORTRANGE(\"%s\", \"Sheet1!%c%d\")", gameSheets.teams[a.config.Teams[j]].SpreadsheetUrl, rune(currColumn), currTeamRow)
+				teamSheet := gameSheets.teams[a.config.Teams[j]]
+				teamRange := sheetRange(firstSheetTitle(teamSheet), cellName(column, row))
+				values[i][j] = fmt.Sprintf("=IMPORTRANGE(\"%s\", \"%s\")", teamSheet.SpreadsheetUrl, strings.ReplaceAll(teamRange, "\"", "\"\""))
 			}
 		}
 		g := &sheets.ValueRange{
@@ -449,14 +964,14 @@ func (a *app) createTeamAnswerGroups() ([]*sheets.ValueRange, error) {
 		return nil, nil
 	}
 	groups, err := a.createGroups(func(length int, currQuestionIndex int, groups []*sheets.ValueRange) ([]*sheets.ValueRange, error) {
-		r, err := a.getTeamRange(len(groups), length)
-		if err != nil {
-			return nil, err
+		if a.config.Layout.vertical() {
+			return append(groups, a.verticalTeamAnswerGroup(length, currQuestionIndex)), nil
 		}
+		r := a.getTeamRange(len(groups), length)
 		values := make([][]interface{}, 2)
 		values[0] = make([]interface{}, length)
 		for j := 0; j < length; j++ {
-			values[0][j] = currQuestionIndex + j + 1
+			values[0][j] = a.config.questionNumber(currQuestionIndex + j + 1)
 		}
 		currQuestionIndex += length
 		g := &sheets.ValueRange{
@@ -477,7 +992,9 @@ func (a *app) getTeamAnswerGridRanges() ([]*sheets.GridRange, error) {
 	if a.config.NumberOfQuestions < 0 && !a.config.HasWarmUpQuestion {
 		return nil, nil
 	}
-	questionsGroupLength := 12
+	if a.config.Layout.vertical() {
+		return a.verticalTeamAnswerGridRanges(), nil
+	}
 	questionGroupsCount := a.config.NumberOfQuestions / questionsGroupLength
 	if a.config.NumberOfQuestions%questionsGroupLength != 0 {
 		questionGroupsCount++
@@ -524,16 +1041,12 @@ func (a *app) getTeamAnswerGridRanges() ([]*sheets.GridRange, error) {
 	return ranges, nil
 }
 
-func (a *app) getTeamRange(offset int, length int) (string, error) {
-	if length > 25 {
-		return "", fmt.Errorf("group length must be inferior to 25")
-	}
+func (a *app) getTeamRange(offset int, length int) string {
 	startRow := offset*3 + 1
 	endRow := startRow + 1
-	startColumn := int('A')
+	startColumn := 0
 	endColumn := startColumn + length
-	r := fmt.Sprintf("%c%d:%c%d", rune(startColumn), startRow, rune(endColumn), endRow)
-	return r, nil
+	return rangeName(startColumn, startRow, endColumn, endRow)
 }
 
 func (a *app) createGroups(createGroupFn func(length int, currQuestionIndex int, groups []*sheets.ValueRange) ([]*sheets.ValueRange, error)) ([]*sheets.ValueRange, error) {
@@ -546,14 +1059,14 @@ func (a *app) createGroups(createGroupFn func(length int, currQuestionIndex int,
 		}
 	}
 	currQuestionIndex++
-	quot := a.config.NumberOfQuestions / 12
+	quot := a.config.NumberOfQuestions / questionsGroupLength
 	for i := 0; i < quot; i++ {
-		if groups, err = createGroupFn(12, currQuestionIndex, groups); err != nil {
+		if groups, err = createGroupFn(questionsGroupLength, currQuestionIndex, groups); err != nil {
 			return nil, err
 		}
-		currQuestionIndex += 12
+		currQuestionIndex += questionsGroupLength
 	}
-	rem := a.config.NumberOfQuestions % 12
+	rem := a.config.NumberOfQuestions % questionsGroupLength
 	if rem != 0 {
 		if groups, err = createGroupFn(rem, currQuestionIndex, groups); err != nil {
 			return nil, err
@@ -563,59 +1076,79 @@ func (a *app) createGroups(createGroupFn func(length int, currQuestionIndex int,
 	return groups, nil
 }
 
-func (a *app) getManagerRange(offset int, length int) (string, error) {
-	if length > 25 {
-		return "", fmt.Errorf("group length must be inferior to 25")
-	}
+func (a *app) getManagerRange(offset int, length int) string {
 	startRow := offset*(len(a.config.Teams)+2) + 1
 	endRow := startRow + len(a.config.Teams) + 1
-	startColumn := int('A')
+	startColumn := 0
 	endColumn := startColumn + length
-	r := fmt.Sprintf("%c%d:%c%d", rune(startColumn), startRow, rune(endColumn), endRow)
-	return r, nil
+	return rangeName(startColumn, startRow, endColumn, endRow)
 }
 
 func (a *app) createManagerSpreadsheet() (*sheets.Spreadsheet, error) {
-	sheet := &sheets.Spreadsheet{
-		Properties: &sheets.SpreadsheetProperties{
-			Title: fmt.Sprintf("%s-manager", a.config.GameName),
-		},
-	}
-	createdSpreadsheet, err := a.service.Spreadsheets.Create(sheet).Do()
+	createdSpreadsheet, err := a.createSpreadsheet(a.config.Labels.managerTitle(a.config.GameName))
 	if err != nil {
 		return nil, err
 	}
 	log.Printf("created the manager spreadsheet: %s", createdSpreadsheet.SpreadsheetUrl)
+	a.moveToGameFolder(createdSpreadsheet.SpreadsheetId)
 	return createdSpreadsheet, err
 }
 
-func (a *app) createTeamsSpreadsheets() (map[string]*sheets.Spreadsheet, error) {
-	teamsSpreadsheets := make(map[string]*sheets.Spreadsheet, len(a.config.Teams))
-	for _, team := range a.config.Teams {
-		sheet := &sheets.Spreadsheet{
-			Properties: &sheets.SpreadsheetProperties{
-				Title: fmt.Sprintf("%s: команда %s", a.config.GameName, team),
+// createSpreadsheet creates an empty spreadsheet, or a copy of the template
+// spreadsheet if it is configured.
+func (a *app) createSpreadsheet(title string) (*sheets.Spreadsheet, error) {
+	if len(a.config.TemplateSpreadsheetID) != 0 {
+		return a.copyTemplateSpreadsheet(title)
+	}
+	return a.service.Spreadsheets.Create(a.newSpreadsheet(title)).Context(a.commandContext()).Do()
+}
+
+func (a *app) newSpreadsheet(title string) *sheets.Spreadsheet {
+	sheet := &sheets.Spreadsheet{
+		Properties: &sheets.SpreadsheetProperties{
+			Title:  title,
+			Locale: a.config.Locale,
+		},
+	}
+	if len(a.config.Labels.SheetName) != 0 {
+		sheet.Sheets = []*sheets.Sheet{
+			{
+				Properties: &sheets.SheetProperties{
+					Title: a.config.Labels.SheetName,
+				},
 			},
 		}
-		createdSpreadsheet, err := a.service.Spreadsheets.Create(sheet).Do()
-		if err != nil {
-			return teamsSpreadsheets, err
-		}
-		log.Printf("created the team %s spreadsheet: %s", team, createdSpreadsheet.SpreadsheetUrl)
-		teamsSpreadsheets[team] = createdSpreadsheet
 	}
-	return teamsSpreadsheets, nil
+	return sheet
 }
 
-func (a *app) fetchRoundResults(round int) (map[string]string, error) {
-	gameSpreadsheets, err := a.GetGameSpreadsheets()
+func (a *app) createTeamSpreadsheet(team string) (*sheets.Spreadsheet, error) {
+	createdSpreadsheet, err := a.createSpreadsheet(a.config.Labels.teamTitle(a.config.GameName, team))
 	if err != nil {
 		return nil, err
 	}
+	log.Printf("created the team %s spreadsheet: %s", team, createdSpreadsheet.SpreadsheetUrl)
+	a.moveToGameFolder(createdSpreadsheet.SpreadsheetId)
+	return createdSpreadsheet, nil
+}
+
+// fetchRoundResults reads the round answers from the manager spreadsheet. The
+// values that do not fit the expected layout are quarantined, the answers of
+// the other teams are returned anyway.
+func (a *app) fetchRoundResults(round int) (map[string]string, []quarantinedEntry, error) {
+	defer a.metrics.observeFetch(time.Now())
+	gameSpreadsheets, err := a.GetGameSpreadsheets()
+	if err != nil {
+		return nil, nil, err
+	}
+	if gameSpreadsheets.manager == nil {
+		return nil, nil, errManagerSpreadsheetNotFound
+	}
 	roundRange, err := a.getRoundRange(round)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
+	roundRange.SheetId = gameSpreadsheets.manager.SheetID
 	valuesService := sheets.NewSpreadsheetsValuesService(a.service)
 	resp, err := valuesService.BatchGetByDataFilter(gameSpreadsheets.manager.ID, &sheets.BatchGetValuesByDataFilterRequest{
 		DataFilters: []*sheets.DataFilter{
@@ -624,36 +1157,181 @@ func (a *app) fetchRoundResults(round int) (map[string]string, error) {
 			},
 		},
 		MajorDimension: "COLUMNS",
-	}).Do()
+	}).Context(a.commandContext()).Do()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	if len(resp.ValueRanges) != 1 {
-		return nil, fmt.Errorf("unexpected response value range length: %d", len(resp.ValueRanges))
+		return nil, nil, fmt.Errorf("unexpected response value range length: %d", len(resp.ValueRanges))
 	}
 	log.Println(resp.ValueRanges[0].ValueRange)
-	if len(resp.ValueRanges[0].ValueRange.Values) != 1 {
-		return nil, fmt.Errorf("unexpected length of ValueRange values: %d", len(resp.ValueRanges[0].ValueRange.Values))
+	values := resp.ValueRanges[0].ValueRange.Values
+	var quarantined []quarantinedEntry
+	var resultsIface []interface{}
+	if len(values) != 0 {
+		resultsIface = values[0]
+		for _, column := range values[1:] {
+			for _, v := range column {
+				quarantined = append(quarantined, quarantine("", v, "value outside of the round column"))
+			}
+		}
 	}
-	resultsIface := resp.ValueRanges[0].ValueRange.Values[0]
-	results := make(map[string]string, len(resultsIface))
+	results := make(map[string]string, len(a.config.Teams))
 	for i, r := range resultsIface {
+		if i >= len(a.config.Teams) {
+			quarantined = append(quarantined, quarantine("", r, fmt.Sprintf("row %d is outside of the teams rows", i+1)))
+			continue
+		}
+		team := a.config.Teams[i]
 		rStr, ok := r.(string)
 		if !ok {
-			return nil, fmt.Errorf("received value %v could not be cast to string", r)
+			quarantined = append(quarantined, quarantine(team, r, fmt.Sprintf("value of type %T is not a string", r)))
+			continue
+		}
+		results[team] = rStr
+	}
+	return results, quarantined, nil
+}
+
+// fetchSettledRoundResults waits for the settle delay before fetching the
+// round results, as IMPORTRANGE values lag behind the team spreadsheets. Then,
+// during the settle window, the results are re-fetched for as long as the
+// number of non-empty answers keeps increasing. The waits end early when ctx
+// is cancelled.
+func (a *app) fetchSettledRoundResults(ctx context.Context, round int) (map[string]string, []quarantinedEntry, error) {
+	settle := a.config.FetchSettle
+	if settle.DelaySeconds > 0 {
+		log.Printf("waiting %d seconds for the answers to settle", settle.DelaySeconds)
+		if err := sleepContext(ctx, time.Duration(settle.DelaySeconds)*time.Second); err != nil {
+			return nil, nil, err
+		}
+	}
+	results, quarantined, err := a.fetchRoundResults(round)
+	if err != nil {
+		return nil, nil, err
+	}
+	if settle.WindowSeconds <= 0 {
+		return results, quarantined, nil
+	}
+	interval := time.Duration(settle.IntervalSeconds) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	deadline := time.Now().Add(time.Duration(settle.WindowSeconds) * time.Second)
+	for time.Now().Add(interval).Before(deadline) {
+		if err := sleepContext(ctx, interval); err != nil {
+			return nil, nil, err
+		}
+		refetched, refetchedQuarantined, err := a.fetchRoundResults(round)
+		if err != nil {
+			return nil, nil, err
+		}
+		if countNonEmpty(refetched) <= countNonEmpty(results) {
+			break
+		}
+		log.Printf("the number of answers increased from %d to %d, re-fetching", countNonEmpty(results), countNonEmpty(refetched))
+		results, quarantined = refetched, refetchedQuarantined
+	}
+	return results, quarantined, nil
+}
+
+func countNonEmpty(results map[string]string) int {
+	count := 0
+	for _, r := range results {
+		if len(strings.TrimSpace(r)) != 0 {
+			count++
+		}
+	}
+	return count
+}
+
+func (a *app) fetchTeamsRoundResults(round int) (map[string]string, []quarantinedEntry, error) {
+	gameSpreadsheets, err := a.GetGameSpreadsheets()
+	if err != nil {
+		return nil, nil, err
+	}
+	column, row, err := a.getTeamRoundCellPosition(round)
+	if err != nil {
+		return nil, nil, err
+	}
+	cells := make([]string, len(a.config.Teams))
+	for i, team := range a.config.Teams {
+		teamSheet, ok := gameSpreadsheets.teams[team]
+		if !ok {
+			return nil, nil, fmt.Errorf("spreadsheet of the team %s is not found", team)
 		}
-		results[a.config.Teams[i]] = rStr
+		cells[i] = sheetRange(teamSheet.toSpreadsheet().Sheets[0].Properties.Title, cellName(column, row))
+	}
+	// the team spreadsheets are read concurrently, at most FetchConcurrency
+	// at a time
+	ctx := a.commandContext()
+	valuesService := sheets.NewSpreadsheetsValuesService(a.service)
+	values := make([]*sheets.ValueRange, len(a.config.Teams))
+	errs := make([]error, len(a.config.Teams))
+	slots := make(chan struct{}, a.config.fetchConcurrency())
+	var wg sync.WaitGroup
+	for i, team := range a.config.Teams {
+		wg.Add(1)
+		go func(i int, spreadsheetID string) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			values[i], errs[i] = valuesService.Get(spreadsheetID, cells[i]).Context(ctx).Do()
+		}(i, gameSpreadsheets.teams[team].ID)
+	}
+	wg.Wait()
+	results := make(map[string]string, len(a.config.Teams))
+	var quarantined []quarantinedEntry
+	for i, team := range a.config.Teams {
+		if errs[i] != nil {
+			return nil, nil, fmt.Errorf("failed to read the team %s spreadsheet: %w", team, errs[i])
+		}
+		resp := values[i]
+		if len(resp.Values) == 0 || len(resp.Values[0]) == 0 {
+			results[team] = ""
+			continue
+		}
+		rStr, ok := resp.Values[0][0].(string)
+		if !ok {
+			quarantined = append(quarantined, quarantine(team, resp.Values[0][0], fmt.Sprintf("value of type %T is not a string", resp.Values[0][0])))
+			continue
+		}
+		results[team] = rStr
+	}
+	return results, quarantined, nil
+}
+
+// getTeamRoundCellPosition returns the zero-based column index and the row of
+// the team answer cell of the round.
+func (a *app) getTeamRoundCellPosition(round int) (int, int, error) {
+	if round < 0 || round > a.config.NumberOfQuestions {
+		return 0, 0, a.config.errRoundOutOfRange(round)
+	}
+	if round == 0 && !a.config.HasWarmUpQuestion {
+		return 0, 0, fmt.Errorf("round 0 is invalid as the game does not have a warm-up question")
 	}
-	return results, nil
+	if a.config.Layout.vertical() {
+		return 1, a.verticalQuestionRow(round), nil
+	}
+	if round == 0 {
+		return 0, 2, nil
+	}
+	groupIndex := (round - 1) / questionsGroupLength
+	if a.config.HasWarmUpQuestion {
+		groupIndex++
+	}
+	column := (round - 1) % questionsGroupLength
+	row := groupIndex*3 + 2
+	return column, row, nil
 }
 
 func (a *app) getRoundRange(round int) (*sheets.GridRange, error) {
-	if round < 0 || round >= a.config.NumberOfQuestions {
-		return nil, fmt.Errorf("round %d is out of range [0; %d]", round, a.config.NumberOfQuestions)
+	if round < 0 || round > a.config.NumberOfQuestions {
+		return nil, a.config.errRoundOutOfRange(round)
 	}
 	if round == 0 {
 		if !a.config.HasWarmUpQuestion {
-			return nil, fmt.Errorf("round %d is invalid as the game does not have a warm-up question", round)
+			return nil, fmt.Errorf("round 0 is invalid as the game does not have a warm-up question")
 		}
 		gr := &sheets.GridRange{
 			StartRowIndex:    1,
@@ -670,14 +1348,13 @@ func (a *app) getRoundRange(round int) (*sheets.GridRange, error) {
 	if a.config.HasWarmUpQuestion {
 		firstGroupRow += groupWidth + gapWidth
 	}
-	questionsCountInGroup := 12
-	groupIndex := round / questionsCountInGroup
+	groupIndex := (round - 1) / questionsGroupLength
 	groupRow := firstGroupRow + groupIndex*(groupWidth+gapWidth)
 	firstResultRow := groupRow + 1
 	lastResultRow := groupRow + len(a.config.Teams)
-	questionMod := round % questionsCountInGroup
+	questionMod := round % questionsGroupLength
 	if questionMod == 0 {
-		questionMod = questionsCountInGroup
+		questionMod = questionsGroupLength
 	}
 	gr := &sheets.GridRange{
 		StartRowIndex:    int64(firstResultRow),
@@ -688,19 +1365,36 @@ func (a *app) getRoundRange(round int) (*sheets.GridRange, error) {
 	return gr, nil
 }
 
-func getOauth2Token(credsFile string, outputDir string) (*oauth2.Token, *oauth2.Config, error) {
-	b, err := ioutil.ReadFile(credsFile)
+// gameToken is the cached OAuth token with the scopes it is granted.
+type gameToken struct {
+	oauth2.Token
+	// Scopes are empty for the tokens cached before the scopes were recorded,
+	// such tokens are taken as granted the spreadsheets scope only.
+	Scopes []string `json:"scopes,omitempty"`
+}
+
+func (t *gameToken) grantedScopes() []string {
+	if len(t.Scopes) == 0 {
+		return []string{sheets.SpreadsheetsScope}
+	}
+	return t.Scopes
+}
+
+// getOauth2Token returns the cached token if it is granted the scopes,
+// otherwise the consent is requested for the scopes along with the ones
+// granted before.
+func getOauth2Token(credsFile string, outputDir string, scopes []string) (*oauth2.Token, *oauth2.Config, error) {
+	b, _, err := readSecretFile(credsFile)
 	if err != nil {
-		return nil, nil, fmt.Errorf("unable to read google sheets API credentials file %s: %v", credsFile, err)
+		return nil, nil, fmt.Errorf("unable to read google sheets API credentials file %s: %w", credsFile, err)
 	}
-	// If modifying these scopes, delete your previously saved token.json.
-	oauth2Config, err := google.ConfigFromJSON(b, "https://www.googleapis.com/auth/spreadsheets")
+	oauth2Config, err := google.ConfigFromJSON(b, scopes...)
 	if err != nil {
-		return nil, nil, fmt.Errorf("unable to parse client secret file %s to oauth2 config: %v", credsFile, err)
+		return nil, nil, fmt.Errorf("unable to parse client secret file %s to oauth2 config: %w", credsFile, err)
 	}
 	gameFiles, err := ioutil.ReadDir(outputDir)
 	if err != nil {
-		return nil, nil, fmt.Errorf("unable to read the game dir %s: %v", outputDir, err)
+		return nil, nil, fmt.Errorf("unable to read the game dir %s: %w", outputDir, err)
 	}
 	for _, f := range gameFiles {
 		if f.Name() != "secret-token" {
@@ -710,31 +1404,64 @@ func getOauth2Token(credsFile string, outputDir string) (*oauth2.Token, *oauth2.
 		if err != nil {
 			return nil, nil, err
 		}
-		return tok, oauth2Config, nil
+		missing := missingScopes(tok.grantedScopes(), scopes)
+		if len(missing) == 0 {
+			return &tok.Token, oauth2Config, nil
+		}
+		fmt.Printf("The saved authorization does not cover the scopes %s required by the configuration, the consent is requested again\n", strings.Join(missing, ", "))
+		oauth2Config.Scopes = mergeScopes(tok.grantedScopes(), scopes)
+		break
 	}
 	tok, err := getTokenFromWeb(oauth2Config)
 	if err != nil {
 		return nil, nil, err
 	}
-	if err := saveGameToken(outputDir, tok); err != nil {
+	if err := saveGameToken(outputDir, &gameToken{Token: *tok, Scopes: oauth2Config.Scopes}); err != nil {
 		return nil, nil, err
 	}
 	return tok, oauth2Config, nil
 }
 
-func getTokenFromFile(file string) (*oauth2.Token, error) {
-	tokenFile, err := os.Open(file)
+func getTokenFromFile(file string) (*gameToken, error) {
+	b, plain, err := readSecretFile(file)
 	if err != nil {
-		return nil, fmt.Errorf("unable to read token file %s: %v", file, err)
+		return nil, fmt.Errorf("unable to read token file %s: %w", file, err)
+	}
+	tok := gameToken{}
+	if err := json.Unmarshal(b, &tok); err != nil {
+		return nil, fmt.Errorf("failed to decode the token file %s: %w", file, err)
 	}
-	defer tokenFile.Close()
-	tok := oauth2.Token{}
-	if err := json.NewDecoder(tokenFile).Decode(&tok); err != nil {
-		return nil, fmt.Errorf("failed to decode the token file %s: %v", file, err)
+	if plain && secretsEncryptionEnabled {
+		if err := writeSecretFile(file, b); err != nil {
+			return nil, fmt.Errorf("failed to encrypt the token file %s: %w", file, err)
+		}
 	}
 	return &tok, nil
 }
 
+// missingScopes returns the required scopes that are not granted.
+func missingScopes(granted []string, required []string) []string {
+	missing := make([]string, 0)
+	for _, scope := range required {
+		found := false
+		for _, g := range granted {
+			if g == scope {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, scope)
+		}
+	}
+	return missing
+}
+
+// mergeScopes appends the scopes that are not listed yet.
+func mergeScopes(scopes []string, more []string) []string {
+	return append(scopes, missingScopes(scopes, more)...)
+}
+
 func getTokenFromWeb(config *oauth2.Config) (*oauth2.Token, error) {
 	authURL := config.AuthCodeURL("state-token", oauth2.AccessTypeOffline)
 	fmt.Printf("Go to the following link in your browser then type the "+
@@ -742,39 +1469,48 @@ func getTokenFromWeb(config *oauth2.Config) (*oauth2.Token, error) {
 
 	var authCode string
 	if _, err := fmt.Scan(&authCode); err != nil {
-		return nil, fmt.Errorf("unable to read the authorization code: %v", err)
+		return nil, fmt.Errorf("unable to read the authorization code: %w", err)
 	}
 
 	tok, err := config.Exchange(context.TODO(), authCode)
 	if err != nil {
-		return nil, fmt.Errorf("unable to retrieve token from web: %v", err)
+		return nil, fmt.Errorf("%w: unable to retrieve token from web: %v", errNotAuthorized, err)
 	}
 	return tok, nil
 }
 
-func saveGameToken(outputDir string, token *oauth2.Token) error {
+func saveGameToken(outputDir string, token *gameToken) error {
 	tokFile := path.Join(outputDir, "secret-token")
-	f, err := os.OpenFile(tokFile, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	b, err := json.Marshal(token)
 	if err != nil {
-		log.Fatalf("Unable to cache oauth token: %v", err)
+		return fmt.Errorf("unable to encode the game token: %w", err)
 	}
-	defer f.Close()
-	if err := json.NewEncoder(f).Encode(token); err != nil {
-		return fmt.Errorf("unable to same the game token to %s: %v", tokFile, err)
+	if err := writeSecretFile(tokFile, b); err != nil {
+		return fmt.Errorf("unable to same the game token to %s: %w", tokFile, err)
 	}
 	return nil
 }
 
-func checkOutputDir(isNewGame bool, outputDir string) error {
+// checkOutputDir prepares the output dir of a new game. A game already
+// created in the dir is archived if force is set, otherwise it is an error.
+func checkOutputDir(isNewGame bool, force bool, outputDir string) error {
 	if !isNewGame {
 		return nil
 	}
+	if _, err := os.Stat(path.Join(outputDir, "bolt-db")); err == nil {
+		if !force {
+			return &errorGameExists{where: outputDir}
+		}
+		if err := archiveExistingGame(outputDir); err != nil {
+			return err
+		}
+	}
 	files, err := ioutil.ReadDir(outputDir)
 	if err != nil {
 		if pErr, ok := err.(*os.PathError); ok {
 			if pErr.Op == "open" && pErr.Path == outputDir && pErr.Err.Error() == "no such file or directory" {
 				if err := os.MkdirAll(outputDir, 0755); err != nil {
-					return fmt.Errorf("failed to create a new game directory %s: %v", outputDir, err)
+					return fmt.Errorf("failed to create a new game directory %s: %w", outputDir, err)
 				}
 				return nil
 			}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+// testTeams returns the given number of team names.
+func testTeams(count int) []string {
+	teams := make([]string, count)
+	for i := range teams {
+		teams[i] = fmt.Sprintf("team %d", i+1)
+	}
+	return teams
+}
+
+func TestGetTeamRange(t *testing.T) {
+	a := &app{config: &Config{NumberOfQuestions: 90, Teams: testTeams(3)}}
+	tests := []struct {
+		offset, length int
+		name           string
+	}{
+		{0, 1, "A1:B2"},
+		{0, questionsGroupLength, "A1:M2"},
+		{1, questionsGroupLength, "A4:M5"},
+		{7, 6, "A22:G23"},
+		{100, questionsGroupLength, "A301:M302"},
+	}
+	for _, tt := range tests {
+		if got := a.getTeamRange(tt.offset, tt.length); got != tt.name {
+			t.Errorf("getTeamRange(%d, %d) = %s, want %s", tt.offset, tt.length, got, tt.name)
+		}
+	}
+}
+
+func TestGetManagerRange(t *testing.T) {
+	tests := []struct {
+		teams, offset, length int
+		name                  string
+	}{
+		{3, 0, questionsGroupLength, "A1:M5"},
+		{3, 1, questionsGroupLength, "A6:M10"},
+		{3, 3, 6, "A16:G20"},
+		{150, 0, 1, "A1:B152"},
+		{150, 8, questionsGroupLength, "A1217:M1368"},
+		{1000, 2, 5, "A2005:F3006"},
+	}
+	for _, tt := range tests {
+		a := &app{config: &Config{Teams: testTeams(tt.teams)}}
+		if got := a.getManagerRange(tt.offset, tt.length); got != tt.name {
+			t.Errorf("getManagerRange(%d, %d) with %d teams = %s, want %s", tt.offset, tt.length, tt.teams, got, tt.name)
+		}
+	}
+}
+
+func TestGetLinkRange(t *testing.T) {
+	tests := []struct {
+		teams, offset, length int
+		name                  string
+	}{
+		{3, 0, questionsGroupLength, "B2:N5"},
+		{3, 1, questionsGroupLength, "B7:N10"},
+		{150, 0, 1, "B2:C152"},
+		{150, 8, 5, "B1218:G1368"},
+		{1000, 2, questionsGroupLength, "B2006:N3006"},
+	}
+	for _, tt := range tests {
+		a := &app{config: &Config{Teams: testTeams(tt.teams)}}
+		if got := a.getLinkRange(tt.offset, tt.length); got != tt.name {
+			t.Errorf("getLinkRange(%d, %d) with %d teams = %s, want %s", tt.offset, tt.length, tt.teams, got, tt.name)
+		}
+	}
+}
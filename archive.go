@@ -0,0 +1,174 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"time"
+)
+
+const (
+	archiveVersion  = 1
+	archiveGameFile = "game.json"
+)
+
+type gameArchive struct {
+	Version   int
+	CreatedAt time.Time
+	Config    *Config
+	Buckets   map[string][]archiveEntry
+	// Sequences are the sequences of the buckets, the adjustments, the audit
+	// log, the event log and the undo journal key their entries by them.
+	Sequences map[string]uint64
+}
+
+type archiveResult struct {
+	Message string `json:"message" yaml:"message"`
+}
+
+func (r *archiveResult) String() string {
+	return r.Message
+}
+
+func (a *app) CmdArchive(cmdStr string) (*archiveResult, error) {
+	sSplitted := splitArgs(cmdStr)
+	if len(sSplitted) != 3 {
+		return nil, fmt.Errorf("expected 2 arguments (save or load and the archive file), got %d", len(sSplitted)-1)
+	}
+	file := sSplitted[2]
+	switch sSplitted[1] {
+	case "save":
+		if err := a.saveArchive(file); err != nil {
+			return nil, err
+		}
+		return &archiveResult{Message: fmt.Sprintf("Game is archived to %s", file)}, nil
+	case "load":
+		if err := a.loadArchive(file); err != nil {
+			return nil, err
+		}
+		return &archiveResult{Message: fmt.Sprintf("Game is loaded from %s", file)}, nil
+	default:
+		return nil, fmt.Errorf("unknown archive subcommand %s, expected save or load", sSplitted[1])
+	}
+}
+
+func (a *app) saveArchive(file string) error {
+	b, err := a.boltStore()
+	if err != nil {
+		return err
+	}
+	buckets, sequences, err := b.dumpBuckets()
+	if err != nil {
+		return err
+	}
+	archive := &gameArchive{
+		Version:   archiveVersion,
+		CreatedAt: time.Now(),
+		Config:    withoutSecrets(a.config),
+		Buckets:   buckets,
+		Sequences: sequences,
+	}
+	f, err := os.Create(file)
+	if err != nil {
+		return fmt.Errorf("failed to create the archive file %s: %w", file, err)
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	w, err := zw.Create(archiveGameFile)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(archive); err != nil {
+		return fmt.Errorf("failed to write the archive: %w", err)
+	}
+	return zw.Close()
+}
+
+// withoutSecrets returns a copy of the configuration without the SMTP
+// password, the webhook secrets and the API tokens, so that the archives can
+// be shared.
+func withoutSecrets(c *Config) *Config {
+	archived := *c
+	archived.Mail.SMTP.Password = ""
+	archived.APITokens = nil
+	archived.Webhooks = make([]WebhookConfig, len(c.Webhooks))
+	for i, w := range c.Webhooks {
+		w.Secret = ""
+		archived.Webhooks[i] = w
+	}
+	return &archived
+}
+
+// readArchive reads and checks the game archive.
+func readArchive(file string) (*gameArchive, error) {
+	zr, err := zip.OpenReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the archive file %s: %w", file, err)
+	}
+	defer zr.Close()
+	var archive *gameArchive
+	for _, f := range zr.File {
+		if f.Name != archiveGameFile {
+			continue
+		}
+		r, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		archive = &gameArchive{}
+		err = json.NewDecoder(r).Decode(archive)
+		r.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the archive: %w", err)
+		}
+	}
+	if archive == nil {
+		return nil, fmt.Errorf("%s is not found in the archive %s", archiveGameFile, file)
+	}
+	if archive.Version != archiveVersion {
+		return nil, fmt.Errorf("unsupported archive version %d, expected %d", archive.Version, archiveVersion)
+	}
+	if archive.Config == nil {
+		return nil, fmt.Errorf("the archive does not contain the game configuration")
+	}
+	return archive, nil
+}
+
+func (a *app) loadArchive(file string) error {
+	archive, err := readArchive(file)
+	if err != nil {
+		return err
+	}
+	b, err := a.boltStore()
+	if err != nil {
+		return err
+	}
+	if err := b.restoreBuckets(archive.Buckets, archive.Sequences); err != nil {
+		return fmt.Errorf("failed to restore the game data: %w", err)
+	}
+	if archive.Config.GameName != a.config.GameName || archive.Config.NumberOfQuestions != a.config.NumberOfQuestions || archive.Config.HasWarmUpQuestion != a.config.HasWarmUpQuestion {
+		log.Printf("the archived game %s differs from the configured one, restart with the archived configuration", archive.Config.GameName)
+	}
+	a.config.Teams = archive.Config.Teams
+	if teams, err := a.store.getTeams(); err == nil && teams != nil {
+		a.config.Teams = teams
+	}
+	configFile := path.Join(a.config.OutputDir, "archived-config.json")
+	cf, err := os.Create(configFile)
+	if err != nil {
+		return fmt.Errorf("failed to save the archived configuration: %w", err)
+	}
+	defer cf.Close()
+	enc := json.NewEncoder(cf)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(withoutSecrets(archive.Config)); err != nil {
+		return fmt.Errorf("failed to save the archived configuration: %w", err)
+	}
+	log.Printf("saved the archived game configuration to %s", configFile)
+	return nil
+}
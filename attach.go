@@ -0,0 +1,204 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+var spreadsheetURLIDRegexp = regexp.MustCompile(`/spreadsheets/d/([a-zA-Z0-9-_]+)`)
+
+type attachResult struct {
+	Manager string            `json:"manager" yaml:"manager"`
+	Teams   map[string]string `json:"teams" yaml:"teams"`
+}
+
+func (r *attachResult) String() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Attached the manager spreadsheet: %s\n", r.Manager))
+	for team, url := range r.Teams {
+		sb.WriteString(fmt.Sprintf("\tteam %s: %s\n", team, url))
+	}
+	return sb.String()
+}
+
+// CmdAttach reconnects the game to existing spreadsheets, e.g. when the bolt
+// database is lost: "attach manager=<id> team:<name>=<id>...". The IDs can be
+// given as the spreadsheet URLs. The spreadsheets are checked against the
+// layout the configuration produces before the game record is rebuilt, the
+// teams roster becomes the attached teams in the given order.
+func (a *app) CmdAttach(cmdStr string) (*attachResult, error) {
+	managerID, teamIDs, teams, err := parseAttachArgs(splitArgs(cmdStr)[1:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse attach request: %w", err)
+	}
+	prevTeams := a.config.Teams
+	a.config.Teams = teams
+	storeSheets, err := a.checkAttachedSpreadsheets(managerID, teamIDs)
+	if err != nil {
+		a.config.Teams = prevTeams
+		return nil, err
+	}
+	if err := a.store.saveSpreadsheets(storeSheets); err != nil {
+		return nil, err
+	}
+	if err := a.store.saveTeams(teams); err != nil {
+		return nil, err
+	}
+	steps := []string{setupStepManagerFilled, setupStepManagerLinked}
+	for _, team := range teams {
+		steps = append(steps, setupStepTeamFilled(team))
+	}
+	manager, err := a.getSpreadsheetMetadata(a.commandContext(), managerID)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := manager.sheetByTitle(statusesSheetTitle); ok {
+		steps = append(steps, setupStepStatusesSheet)
+	}
+	for _, step := range steps {
+		if err := a.store.markSetupStep(step); err != nil {
+			return nil, err
+		}
+	}
+	if err := a.store.appendEvent(fmt.Sprintf("attached the manager spreadsheet %s and %d team spreadsheets", managerID, len(teams))); err != nil {
+		return nil, err
+	}
+	res := &attachResult{
+		Manager: storeSheets.manager.URL,
+		Teams:   make(map[string]string, len(storeSheets.teams)),
+	}
+	for team, sheet := range storeSheets.teams {
+		res.Teams[team] = sheet.URL
+	}
+	return res, nil
+}
+
+func parseAttachArgs(args []string) (string, map[string]string, []string, error) {
+	var managerID string
+	teamIDs := make(map[string]string)
+	teams := make([]string, 0)
+	for _, arg := range args {
+		eq := strings.LastIndex(arg, "=")
+		if eq == -1 {
+			return "", nil, nil, fmt.Errorf("expected manager=<id> or team:<name>=<id>, got %s", arg)
+		}
+		key, id := arg[:eq], spreadsheetIDFromArg(arg[eq+1:])
+		if len(id) == 0 {
+			return "", nil, nil, fmt.Errorf("spreadsheet ID cannot be empty in %s", arg)
+		}
+		switch {
+		case key == "manager":
+			managerID = id
+		case strings.HasPrefix(key, "team:"):
+			team := strings.TrimSpace(strings.TrimPrefix(key, "team:"))
+			if len(team) == 0 {
+				return "", nil, nil, fmt.Errorf("team name cannot be empty in %s", arg)
+			}
+			if _, ok := teamIDs[team]; ok {
+				return "", nil, nil, fmt.Errorf("team %s is given several times", team)
+			}
+			teamIDs[team] = id
+			teams = append(teams, team)
+		default:
+			return "", nil, nil, fmt.Errorf("expected manager=<id> or team:<name>=<id>, got %s", arg)
+		}
+	}
+	if len(managerID) == 0 {
+		return "", nil, nil, fmt.Errorf("the manager spreadsheet ID is required")
+	}
+	if len(teams) == 0 {
+		return "", nil, nil, fmt.Errorf("at least one team spreadsheet ID is required")
+	}
+	return managerID, teamIDs, teams, nil
+}
+
+// spreadsheetIDFromArg accepts either a spreadsheet ID or its URL.
+func spreadsheetIDFromArg(arg string) string {
+	if m := spreadsheetURLIDRegexp.FindStringSubmatch(arg); m != nil {
+		return m[1]
+	}
+	return arg
+}
+
+// checkAttachedSpreadsheets fetches the spreadsheets and checks that their
+// answer groups match the configured layout.
+func (a *app) checkAttachedSpreadsheets(managerID string, teamIDs map[string]string) (*storeGameSpreadsheets, error) {
+	managerGroups, err := a.createManagerAnswerGroups()
+	if err != nil {
+		return nil, err
+	}
+	manager, err := a.fetchAttachedSpreadsheet(managerID, managerGroups, "COLUMNS")
+	if err != nil {
+		return nil, fmt.Errorf("the manager spreadsheet %s does not match the game: %w", managerID, err)
+	}
+	teamGroups, err := a.createTeamAnswerGroups()
+	if err != nil {
+		return nil, err
+	}
+	storeSheets := &storeGameSpreadsheets{
+		manager: manager,
+		teams:   make(map[string]*storeSpreadsheet, len(teamIDs)),
+	}
+	for _, team := range a.config.Teams {
+		teamSheet, err := a.fetchAttachedSpreadsheet(teamIDs[team], teamGroups, "ROWS")
+		if err != nil {
+			return nil, fmt.Errorf("the team %s spreadsheet %s does not match the game: %w", team, teamIDs[team], err)
+		}
+		storeSheets.teams[team] = teamSheet
+	}
+	return storeSheets, nil
+}
+
+func (a *app) fetchAttachedSpreadsheet(id string, expected []*sheets.ValueRange, majorDimension string) (*storeSpreadsheet, error) {
+	spreadsheet, err := a.service.Spreadsheets.Get(id).
+		Fields("spreadsheetId,spreadsheetUrl,sheets.properties(sheetId,title)").Context(a.commandContext()).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the spreadsheet: %w", err)
+	}
+	title := firstSheetTitle(spreadsheet)
+	ranges := make([]string, len(expected))
+	for i, g := range expected {
+		ranges[i] = sheetRange(title, g.Range)
+	}
+	valuesService := sheets.NewSpreadsheetsValuesService(a.service)
+	resp, err := valuesService.BatchGet(id).Ranges(ranges...).MajorDimension(majorDimension).Context(a.commandContext()).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the answer groups: %w", err)
+	}
+	if len(resp.ValueRanges) != len(expected) {
+		return nil, fmt.Errorf("expected %d answer groups, got %d", len(expected), len(resp.ValueRanges))
+	}
+	for i, g := range expected {
+		if err := compareLayoutValues(g.Values, resp.ValueRanges[i].Values); err != nil {
+			return nil, fmt.Errorf("range %s: %w", g.Range, err)
+		}
+	}
+	log.Printf("the spreadsheet %s matches the game layout", spreadsheet.SpreadsheetUrl)
+	return newStoreSpreadsheet(spreadsheet), nil
+}
+
+// compareLayoutValues checks that the fetched values contain the expected
+// ones, the formulas and the answers are not compared.
+func compareLayoutValues(expected [][]interface{}, actual [][]interface{}) error {
+	for i, line := range expected {
+		for j, value := range line {
+			s, ok := value.(string)
+			if ok && strings.HasPrefix(s, "=") {
+				continue
+			}
+			want := fmt.Sprint(value)
+			got := ""
+			if i < len(actual) && j < len(actual[i]) {
+				got = fmt.Sprint(actual[i][j])
+			}
+			if want != got {
+				return fmt.Errorf("expected \"%s\", got \"%s\"", want, got)
+			}
+		}
+	}
+	return nil
+}
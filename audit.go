@@ -0,0 +1,268 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	auditOriginREPL = "repl"
+	auditOriginAPI  = "api"
+)
+
+// auditEntry records an executed command, who ran it and the data it changed,
+// so that the disputes can be resolved after the game.
+type auditEntry struct {
+	Time       time.Time     `json:"time" yaml:"time"`
+	Command    string        `json:"command" yaml:"command"`
+	Args       []string      `json:"args,omitempty" yaml:"args,omitempty"`
+	Origin     string        `json:"origin" yaml:"origin"`
+	User       string        `json:"user" yaml:"user"`
+	DurationMs int64         `json:"durationMs" yaml:"durationMs"`
+	Error      string        `json:"error,omitempty" yaml:"error,omitempty"`
+	Changes    []auditChange `json:"changes,omitempty" yaml:"changes,omitempty"`
+}
+
+// auditChange is a change of a team response, of its status or a score
+// adjustment made by a command.
+type auditChange struct {
+	// Round is the question number of the changed round.
+	Round int    `json:"round,omitempty" yaml:"round,omitempty"`
+	Team  string `json:"team" yaml:"team"`
+	Field string `json:"field" yaml:"field"`
+	Old   string `json:"old,omitempty" yaml:"old,omitempty"`
+	New   string `json:"new,omitempty" yaml:"new,omitempty"`
+}
+
+func (c auditChange) String() string {
+	if c.Field == "adjustment" {
+		return fmt.Sprintf("team %s: adjustment %s", c.Team, c.New)
+	}
+	return fmt.Sprintf("round %d, team %s: %s \"%s\" -> \"%s\"", c.Round, c.Team, c.Field, c.Old, c.New)
+}
+
+// auditSnapshot is the data compared before and after a command to find its
+// changes.
+type auditSnapshot struct {
+	results     map[int]*roundResults
+	adjustments int
+}
+
+func (a *app) takeAuditSnapshot() (*auditSnapshot, error) {
+	all, err := a.store.getAllRoundResults()
+	if err != nil {
+		return nil, err
+	}
+	adjustments, err := a.store.getAdjustments()
+	if err != nil {
+		return nil, err
+	}
+	s := &auditSnapshot{results: make(map[int]*roundResults, len(all)), adjustments: len(adjustments)}
+	for _, r := range all {
+		s.results[r.Round] = r
+	}
+	return s, nil
+}
+
+// auditChanges returns the changes of the responses, of the statuses and the
+// adjustments made since the snapshot.
+func (a *app) auditChanges(before *auditSnapshot) ([]auditChange, error) {
+	after, err := a.takeAuditSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	rounds := make(map[int]bool)
+	for round := range before.results {
+		rounds[round] = true
+	}
+	for round := range after.results {
+		rounds[round] = true
+	}
+	sortedRounds := make([]int, 0, len(rounds))
+	for round := range rounds {
+		sortedRounds = append(sortedRounds, round)
+	}
+	sort.Ints(sortedRounds)
+	changes := make([]auditChange, 0)
+	for _, round := range sortedRounds {
+		changes = append(changes, diffRoundResults(a.config.questionNumber(round), before.results[round], after.results[round])...)
+	}
+	if after.adjustments > before.adjustments {
+		adjustments, err := a.store.getAdjustments()
+		if err != nil {
+			return nil, err
+		}
+		for _, adj := range adjustments[before.adjustments:] {
+			changes = append(changes, auditChange{
+				Team:  adj.Team,
+				Field: "adjustment",
+				New:   fmt.Sprintf("%s (%s)", formatAdjustment(adj.Points), adj.Reason),
+			})
+		}
+	}
+	return changes, nil
+}
+
+func diffRoundResults(question int, before *roundResults, after *roundResults) []auditChange {
+	responses := func(r *roundResults) map[string]*roundResponse {
+		if r == nil {
+			return nil
+		}
+		return r.Results
+	}
+	old, current := responses(before), responses(after)
+	teams := make(map[string]bool)
+	for team := range old {
+		teams[team] = true
+	}
+	for team := range current {
+		teams[team] = true
+	}
+	sortedTeams := make([]string, 0, len(teams))
+	for team := range teams {
+		sortedTeams = append(sortedTeams, team)
+	}
+	sort.Strings(sortedTeams)
+	changes := make([]auditChange, 0)
+	for _, team := range sortedTeams {
+		var oldResponse, newResponse, oldStatus, newStatus string
+		if r, ok := old[team]; ok && r != nil {
+			oldResponse, oldStatus = r.Response, r.Status.String()
+		}
+		if r, ok := current[team]; ok && r != nil {
+			newResponse, newStatus = r.Response, r.Status.String()
+		}
+		if oldResponse != newResponse {
+			changes = append(changes, auditChange{Round: question, Team: team, Field: "response", Old: oldResponse, New: newResponse})
+		}
+		if oldStatus != newStatus {
+			changes = append(changes, auditChange{Round: question, Team: team, Field: "status", Old: oldStatus, New: newStatus})
+		}
+	}
+	return changes
+}
+
+// startAudit begins the audit entry of the command. The data is compared
+// before and after the commands that may change it.
+func (a *app) startAudit(cmdStr string, origin string) (*auditEntry, *auditSnapshot) {
+	if a.config.ReadOnly {
+		return nil, nil
+	}
+	args := splitArgs(cmdStr)
+	entry := &auditEntry{
+		Time:   time.Now(),
+		Origin: origin,
+		User:   currentUserName(),
+	}
+	if len(args) != 0 {
+		entry.Command, entry.Args = args[0], args[1:]
+	}
+	if readOnlyCommands[entry.Command] {
+		return entry, nil
+	}
+	snapshot, err := a.takeAuditSnapshot()
+	if err != nil {
+		log.Printf("[ERR]: failed to read the data audited by the command %s: %v", entry.Command, err)
+	}
+	return entry, snapshot
+}
+
+// finishAudit stores the audit entry with the result of the command and its
+// changes. An audit failure does not fail the command.
+func (a *app) finishAudit(entry *auditEntry, snapshot *auditSnapshot, cmdErr error) {
+	if entry == nil {
+		return
+	}
+	entry.DurationMs = time.Since(entry.Time).Milliseconds()
+	if cmdErr != nil {
+		entry.Error = cmdErr.Error()
+	}
+	if snapshot != nil {
+		changes, err := a.auditChanges(snapshot)
+		if err != nil {
+			log.Printf("[ERR]: failed to find the changes made by the command %s: %v", entry.Command, err)
+		}
+		entry.Changes = changes
+	}
+	if err := a.store.saveAuditEntry(entry); err != nil {
+		log.Printf("[ERR]: failed to record the command %s in the audit log: %v", entry.Command, err)
+	}
+}
+
+type auditResult struct {
+	Entries []*auditEntry `json:"entries" yaml:"entries"`
+}
+
+func (r *auditResult) String() string {
+	if len(r.Entries) == 0 {
+		return "No command is recorded\n"
+	}
+	t := &table{header: []string{"Time", "User", "Command", "Changes"}}
+	for _, e := range r.Entries {
+		command := strings.TrimSpace(e.Command + " " + strings.Join(e.Args, " "))
+		if len(e.Error) != 0 {
+			command += " (failed)"
+		}
+		user := e.User
+		if e.Origin != auditOriginREPL {
+			user = fmt.Sprintf("%s (%s)", e.User, e.Origin)
+		}
+		first := ""
+		if len(e.Changes) != 0 {
+			first = e.Changes[0].String()
+		}
+		t.addRow(plainCell(e.Time.Format("2006-01-02 15:04:05")), plainCell(user), plainCell(command), plainCell(first))
+		for i := 1; i < len(e.Changes); i++ {
+			t.addRow(plainCell(""), plainCell(""), plainCell(""), plainCell(e.Changes[i].String()))
+		}
+	}
+	return t.String()
+}
+
+// CmdAudit shows the audit log of the executed commands: "audit [round|team]".
+// With a round or a team only the commands that changed it or were given it
+// as an argument are shown, along with their changes of it.
+func (a *app) CmdAudit(cmdStr string) (*auditResult, error) {
+	entries, err := a.store.getAuditEntries()
+	if err != nil {
+		return nil, err
+	}
+	filter := strings.TrimSpace(strings.Join(splitArgs(cmdStr)[1:], " "))
+	if len(filter) == 0 {
+		return &auditResult{Entries: entries}, nil
+	}
+	round, roundErr := a.parseRound(filter)
+	matches := func(c auditChange) bool {
+		if roundErr == nil {
+			return c.Round == a.config.questionNumber(round) && c.Field != "adjustment"
+		}
+		return c.Team == filter
+	}
+	res := &auditResult{Entries: make([]*auditEntry, 0)}
+	for _, e := range entries {
+		filtered := *e
+		filtered.Changes = make([]auditChange, 0)
+		for _, c := range e.Changes {
+			if matches(c) {
+				filtered.Changes = append(filtered.Changes, c)
+			}
+		}
+		if len(filtered.Changes) == 0 && !auditArgsMention(e.Args, filter) {
+			continue
+		}
+		res.Entries = append(res.Entries, &filtered)
+	}
+	return res, nil
+}
+
+func auditArgsMention(args []string, filter string) bool {
+	for _, arg := range args {
+		if arg == filter {
+			return true
+		}
+	}
+	return filter == strings.Join(args, " ")
+}
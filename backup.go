@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// backupTimeFormat is the timestamp of the backup files, it sorts in the
+// chronological order.
+const backupTimeFormat = "20060102T150405.000000000"
+
+const backupFilePrefix = "bolt-db-"
+
+func backupFileName(t time.Time) string {
+	return backupFilePrefix + t.UTC().Format(backupTimeFormat)
+}
+
+// backup copies the database to the backups directory and removes the oldest
+// copies beyond the configured count.
+func (b *boltManager) backup(db *bolt.DB) {
+	if len(b.backupDir) == 0 || b.backupCount < 0 {
+		return
+	}
+	b.backupMu.Lock()
+	defer b.backupMu.Unlock()
+	if err := os.MkdirAll(b.backupDir, 0755); err != nil {
+		log.Printf("[ERR]: failed to create the backups directory %s: %v", b.backupDir, err)
+		return
+	}
+	backupFile := path.Join(b.backupDir, backupFileName(time.Now()))
+	err := db.View(func(tx *bolt.Tx) error {
+		return tx.CopyFile(backupFile, 0600)
+	})
+	if err != nil {
+		log.Printf("[ERR]: failed to back up the database to %s: %v", backupFile, err)
+		return
+	}
+	backups, err := b.listBackups()
+	if err != nil {
+		log.Printf("[ERR]: failed to list the database backups: %v", err)
+		return
+	}
+	for len(backups) > b.backupCount {
+		if err := os.Remove(path.Join(b.backupDir, backupFilePrefix+backups[0])); err != nil {
+			log.Printf("[ERR]: failed to remove the database backup %s: %v", backups[0], err)
+			return
+		}
+		backups = backups[1:]
+	}
+}
+
+// listBackups returns the timestamps of the backups, the oldest first.
+func (b *boltManager) listBackups() ([]string, error) {
+	files, err := ioutil.ReadDir(b.backupDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	backups := make([]string, 0, len(files))
+	for _, f := range files {
+		if f.IsDir() || !strings.HasPrefix(f.Name(), backupFilePrefix) {
+			continue
+		}
+		backups = append(backups, strings.TrimPrefix(f.Name(), backupFilePrefix))
+	}
+	sort.Strings(backups)
+	return backups, nil
+}
+
+// restore replaces the database with the backup, the current database is
+// backed up first so that the restore can be reverted. The backup is read
+// before that, as the new copy may push it out of the backups.
+func (b *boltManager) restore(timestamp string) error {
+	backups, err := b.listBackups()
+	if err != nil {
+		return fmt.Errorf("failed to list the backups: %w", err)
+	}
+	found := false
+	for _, backup := range backups {
+		if backup == timestamp {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("backup %s is not found", timestamp)
+	}
+	data, err := ioutil.ReadFile(path.Join(b.backupDir, backupFilePrefix+timestamp))
+	if err != nil {
+		return err
+	}
+	b.dbMu.Lock()
+	defer b.dbMu.Unlock()
+	if b.db == nil {
+		return fmt.Errorf("the database %s is closed", b.dbFile)
+	}
+	b.backup(b.db)
+	restoredFile := b.dbFile + ".restore"
+	if err := ioutil.WriteFile(restoredFile, data, 0600); err != nil {
+		return err
+	}
+	if err := b.closeDB(); err != nil {
+		return err
+	}
+	if err := os.Rename(restoredFile, b.dbFile); err != nil {
+		return err
+	}
+	return b.openDB()
+}
+
+type restoreResult struct {
+	Restored string   `json:"restored,omitempty" yaml:"restored,omitempty"`
+	Backups  []string `json:"backups,omitempty" yaml:"backups,omitempty"`
+}
+
+func (r *restoreResult) String() string {
+	if len(r.Restored) != 0 {
+		return fmt.Sprintf("Database is restored from the backup %s", r.Restored)
+	}
+	if len(r.Backups) == 0 {
+		return "No backups"
+	}
+	return fmt.Sprintf("Backups:\n\t%s", strings.Join(r.Backups, "\n\t"))
+}
+
+// CmdRestore rolls the database back to a backup: "restore <timestamp>".
+// Without the timestamp the available backups are listed.
+func (a *app) CmdRestore(cmdStr string) (*restoreResult, error) {
+	b, err := a.boltStore()
+	if err != nil {
+		return nil, err
+	}
+	sSplitted := splitArgs(cmdStr)
+	switch len(sSplitted) {
+	case 1:
+		backups, err := b.listBackups()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the backups: %w", err)
+		}
+		return &restoreResult{Backups: backups}, nil
+	case 2:
+	default:
+		return nil, fmt.Errorf("expected at most 1 argument, got %d", len(sSplitted)-1)
+	}
+	timestamp := sSplitted[1]
+	if err := b.restore(timestamp); err != nil {
+		return nil, fmt.Errorf("failed to restore the database: %w", err)
+	}
+	teams, err := a.store.getTeams()
+	if err != nil {
+		return nil, err
+	}
+	if teams != nil {
+		a.config.Teams = teams
+	}
+	if err := a.store.appendEvent(fmt.Sprintf("restore: %s", timestamp)); err != nil {
+		return nil, err
+	}
+	return &restoreResult{Restored: timestamp}, nil
+}
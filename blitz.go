@@ -0,0 +1,224 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+const (
+	QuestionTypeNormal     = "normal"
+	QuestionTypeBlitz      = "blitz"
+	QuestionTypeSuperBlitz = "super-blitz"
+)
+
+// blitzSheetTitle is the sheet of the team spreadsheets where the blitz
+// sub-answers are entered, one row per blitz question.
+const blitzSheetTitle = "Blitz"
+
+// blitzSeparator joins the sub-answers in the answer cell of the answers
+// sheet.
+const blitzSeparator = " / "
+
+// subAnswersCount returns the number of answers of the round question: 2 for
+// a blitz, 3 for a super-blitz and 1 otherwise.
+func (c *Config) subAnswersCount(round int) int {
+	switch c.QuestionTypes[c.questionNumber(round)] {
+	case QuestionTypeBlitz:
+		return 2
+	case QuestionTypeSuperBlitz:
+		return 3
+	default:
+		return 1
+	}
+}
+
+// blitzQuestions returns the sorted rounds of the blitz and super-blitz
+// questions.
+func (c *Config) blitzQuestions() []int {
+	questions := make([]int, 0)
+	for number := range c.QuestionTypes {
+		if number == 0 || !c.hasQuestion(number) {
+			continue
+		}
+		round, err := c.roundOfQuestion(number)
+		if err != nil {
+			continue
+		}
+		if c.subAnswersCount(round) > 1 {
+			questions = append(questions, round)
+		}
+	}
+	sort.Ints(questions)
+	return questions
+}
+
+// blitzRow returns the row of the question sub-answers in the blitz sheet.
+func (c *Config) blitzRow(question int) (int, bool) {
+	for i, q := range c.blitzQuestions() {
+		if q == question {
+			return i + 2, true
+		}
+	}
+	return 0, false
+}
+
+// blitzRange returns the range of the question sub-answers in the blitz sheet.
+func (a *app) blitzRange(question int) (string, error) {
+	row, ok := a.config.blitzRow(question)
+	if !ok {
+		return "", fmt.Errorf("question %d is not a blitz", a.config.questionNumber(question))
+	}
+	return sheetRange(blitzSheetTitle, rangeName(1, row, a.config.subAnswersCount(question), row)), nil
+}
+
+// blitzGridRange returns the grid range of the question sub-answers in the
+// blitz sheet, or nil if the question is not a blitz.
+func (a *app) blitzGridRange(metadata *spreadsheetMetadata, question int) (*sheets.GridRange, error) {
+	row, ok := a.config.blitzRow(question)
+	if !ok {
+		return nil, nil
+	}
+	blitz, ok := metadata.sheetByTitle(blitzSheetTitle)
+	if !ok {
+		return nil, fmt.Errorf("the %s sheet is not found", blitzSheetTitle)
+	}
+	return &sheets.GridRange{
+		SheetId:          blitz.ID,
+		StartColumnIndex: 1,
+		EndColumnIndex:   int64(1 + a.config.subAnswersCount(question)),
+		StartRowIndex:    int64(row - 1),
+		EndRowIndex:      int64(row),
+	}, nil
+}
+
+// fillBlitzSheet adds the blitz sheet to the team spreadsheet. The answer cell
+// of a blitz question joins the sub-answers, so that the manager spreadsheet
+// shows them without a layout change.
+func (a *app) fillBlitzSheet(team *sheets.Spreadsheet, batch *setupBatch) error {
+	questions := a.config.blitzQuestions()
+	if len(questions) == 0 {
+		return nil
+	}
+	metadata, err := a.getSpreadsheetMetadata(a.commandContext(), team.SpreadsheetId)
+	if err != nil {
+		return err
+	}
+	if _, ok := metadata.sheetByTitle(blitzSheetTitle); !ok {
+		batch.addRequests(&sheets.Request{
+			AddSheet: &sheets.AddSheetRequest{
+				Properties: &sheets.SheetProperties{Title: blitzSheetTitle},
+			},
+		})
+	}
+	blitzValues := [][]interface{}{{tr("header.question"), tr("header.answer", 1), tr("header.answer", 2), tr("header.answer", 3)}}
+	data := make([]*sheets.ValueRange, 0, len(questions)+1)
+	for _, q := range questions {
+		blitzValues = append(blitzValues, []interface{}{a.config.questionNumber(q)})
+		column, row, err := a.getTeamRoundCellPosition(q)
+		if err != nil {
+			return err
+		}
+		subAnswers, err := a.blitzRange(q)
+		if err != nil {
+			return err
+		}
+		data = append(data, &sheets.ValueRange{
+			Range:  sheetRange(firstSheetTitle(team), cellName(column, row)),
+			Values: [][]interface{}{{fmt.Sprintf("=TEXTJOIN(\"%s\", TRUE, %s)", blitzSeparator, subAnswers)}},
+		})
+	}
+	data = append(data, &sheets.ValueRange{
+		Range:  sheetRange(blitzSheetTitle, rangeName(0, 1, 3, len(blitzValues))),
+		Values: blitzValues,
+	})
+	batch.addValues(data...)
+	return nil
+}
+
+// fetchBlitzSubResponses reads the question sub-answers from the blitz sheet of
+// every team spreadsheet.
+func (a *app) fetchBlitzSubResponses(question int) (map[string][]string, error) {
+	gameSheets, err := a.GetGameSpreadsheets()
+	if err != nil {
+		return nil, err
+	}
+	r, err := a.blitzRange(question)
+	if err != nil {
+		return nil, err
+	}
+	count := a.config.subAnswersCount(question)
+	valuesService := sheets.NewSpreadsheetsValuesService(a.service)
+	subResponses := make(map[string][]string, len(a.config.Teams))
+	for _, team := range a.config.Teams {
+		teamSheet, ok := gameSheets.teams[team]
+		if !ok {
+			return nil, fmt.Errorf("spreadsheet of the team %s is not found", team)
+		}
+		resp, err := valuesService.Get(teamSheet.ID, r).Context(a.commandContext()).Do()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the team %s blitz answers: %w", team, err)
+		}
+		sub := make([]string, count)
+		if len(resp.Values) != 0 {
+			for i, v := range resp.Values[0] {
+				if i < count {
+					sub[i] = fmt.Sprint(v)
+				}
+			}
+		}
+		subResponses[team] = sub
+	}
+	return subResponses, nil
+}
+
+// combineSubStatuses returns the status of a blitz answer: correct only if
+// every sub-answer is correct, undecided while a sub-answer is undecided and
+// incorrect otherwise.
+func combineSubStatuses(statuses []ResponseStatus) ResponseStatus {
+	allOK := true
+	for _, s := range statuses {
+		switch s {
+		case ResponseStatusNotChecked, ResponseStatusInQuestion:
+			return s
+		case ResponseStatusOK:
+		default:
+			allOK = false
+		}
+	}
+	if allOK {
+		return ResponseStatusOK
+	}
+	return ResponseStatusKO
+}
+
+func sameSubStatuses(a []ResponseStatus, b []ResponseStatus) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func checkQuestionTypes(c *Config) []string {
+	var problems []string
+	for q, t := range c.QuestionTypes {
+		switch t {
+		case QuestionTypeNormal, QuestionTypeBlitz, QuestionTypeSuperBlitz:
+		default:
+			problems = append(problems, fmt.Sprintf("question %d has unknown type %s, expected %s", q, t,
+				strings.Join([]string{QuestionTypeNormal, QuestionTypeBlitz, QuestionTypeSuperBlitz}, ", ")))
+		}
+		if q == 0 || !c.hasQuestion(q) {
+			problems = append(problems, fmt.Sprintf("QuestionTypes refers to question %d that is not in the game", q))
+		}
+	}
+	sort.Strings(problems)
+	return problems
+}
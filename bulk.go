@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// bulkConfirmFlag skips the confirmation of the bulk status changes, it is
+// required when they are run from the API.
+const bulkConfirmFlag = "--yes"
+
+type bulkStatusResult struct {
+	Round  int    `json:"round" yaml:"round"`
+	Status string `json:"status" yaml:"status"`
+	Teams  int    `json:"teams" yaml:"teams"`
+}
+
+func (r *bulkStatusResult) String() string {
+	return fmt.Sprintf("Round %d: the status of %d teams is set to %s", r.Round, r.Teams, r.Status)
+}
+
+// statusNames are the names of the statuses accepted by markAll along with the
+// check keys.
+var statusNames = map[string]ResponseStatus{
+	"ok":       ResponseStatusOK,
+	"ko":       ResponseStatusKO,
+	"question": ResponseStatusInQuestion,
+	"partial":  ResponseStatusPartial,
+	"noanswer": ResponseStatusNoAnswer,
+}
+
+func (a *app) parseStatus(s string) (ResponseStatus, error) {
+	if status, ok := statusNames[strings.ToLower(s)]; ok {
+		return status, nil
+	}
+	verdicts, err := a.config.CheckKeys.verdicts()
+	if err != nil {
+		return 0, err
+	}
+	if status, ok := verdicts[s]; ok && len(s) != 0 {
+		return status, nil
+	}
+	return 0, fmt.Errorf("unknown status %s, expected ok, ko, question, partial, noanswer or a check key", s)
+}
+
+// CmdMarkAll sets the status of every team response to the round, e.g. when
+// the question is thrown out: "markAll <round> <status> [--yes]".
+func (a *app) CmdMarkAll(cmdStr string) (*bulkStatusResult, error) {
+	args, confirmed := bulkArgs(cmdStr)
+	if len(args) != 3 {
+		return nil, fmt.Errorf("expected the round and the status, e.g. markAll 12 ok")
+	}
+	round, err := a.parseRound(args[1])
+	if err != nil {
+		return nil, err
+	}
+	status, err := a.parseStatus(args[2])
+	if err != nil {
+		return nil, err
+	}
+	return a.setRoundStatus(round, status, confirmed)
+}
+
+// CmdUncheck resets the statuses of the round responses to not checked, e.g.
+// after a bad fetch: "uncheck <round> [--yes]".
+func (a *app) CmdUncheck(cmdStr string) (*bulkStatusResult, error) {
+	args, confirmed := bulkArgs(cmdStr)
+	if len(args) != 2 {
+		return nil, fmt.Errorf("expected the round, e.g. uncheck 12")
+	}
+	round, err := a.parseRound(args[1])
+	if err != nil {
+		return nil, err
+	}
+	return a.setRoundStatus(round, ResponseStatusNotChecked, confirmed)
+}
+
+func bulkNeedsConfirmation(_ *app, cmdStr string) bool {
+	_, confirmed := bulkArgs(cmdStr)
+	return !confirmed
+}
+
+func bulkArgs(cmdStr string) ([]string, bool) {
+	args := make([]string, 0)
+	confirmed := false
+	for _, arg := range splitArgs(cmdStr) {
+		if arg == bulkConfirmFlag {
+			confirmed = true
+			continue
+		}
+		args = append(args, arg)
+	}
+	return args, confirmed
+}
+
+// setRoundStatus sets the status of all the round responses after the
+// confirmation, the check progress of the round is discarded.
+func (a *app) setRoundStatus(round int, status ResponseStatus, confirmed bool) (*bulkStatusResult, error) {
+	results, err := a.store.getRoundResults(round)
+	if err != nil {
+		return nil, err
+	}
+	if !confirmed {
+		fmt.Printf("Set the status %s for the %d responses of the round %d? [y/N] ", status, len(results.Results), a.config.questionNumber(round))
+		answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the confirmation: %w", err)
+		}
+		if reply := strings.ToLower(strings.TrimSpace(answer)); reply != "y" && reply != "yes" {
+			return nil, fmt.Errorf("the round %d statuses are not changed", a.config.questionNumber(round))
+		}
+	}
+	var subStatuses []ResponseStatus
+	if count := a.config.subAnswersCount(round); count > 1 && status != ResponseStatusNotChecked {
+		subStatuses = make([]ResponseStatus, count)
+		for i := range subStatuses {
+			subStatuses[i] = status
+		}
+	}
+	for _, resp := range results.Results {
+		resp.Status = status
+		resp.SubStatuses = subStatuses
+	}
+	stored, saveErr := a.store.saveVerdicts(results)
+	if stored == nil {
+		return nil, fmt.Errorf("failed to store round results: %w", saveErr)
+	}
+	if err := a.store.saveCheckProgress(round, nil); err != nil {
+		return nil, err
+	}
+	if err := a.store.appendEvent(fmt.Sprintf("set round %d statuses to %v", a.config.questionNumber(round), status)); err != nil {
+		return nil, err
+	}
+	a.publishResults(eventTypeStatuses, stored)
+	if status != ResponseStatusNotChecked {
+		a.notifyRound(webhookEventRoundChecked, stored)
+	}
+	if _, err := a.markStatuses(stored); err != nil {
+		if a.conn.isOnline() {
+			return nil, fmt.Errorf("failed to mark the statuses in the manager spreadsheet: %w", err)
+		}
+		a.offline.queueStatuses(round)
+		fmt.Printf("The Google API is unreachable, the round %d statuses are saved and will be written to the manager spreadsheet once it is reachable again\n", a.config.questionNumber(round))
+	}
+	if saveErr != nil {
+		return nil, saveErr
+	}
+	return &bulkStatusResult{Round: a.config.questionNumber(round), Status: status.String(), Teams: len(results.Results)}, nil
+}
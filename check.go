@@ -0,0 +1,407 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// checkGroup is a set of teams with the same normalized response, judged
+// with a single verdict.
+type checkGroup struct {
+	Teams      []string
+	Response   string
+	Normalized string
+}
+
+// checkResults asks for a verdict on every group of identical responses. The
+// verdicts are persisted as they are given, so that an interrupted check
+// resumes where it stopped.
+func (a *app) checkResults(results *roundResults) error {
+	verdicts, err := a.config.CheckKeys.verdicts()
+	if err != nil {
+		return err
+	}
+	reader, err := newVerdictReader(a.config.CheckSingleKeystroke)
+	if err != nil {
+		return err
+	}
+	defer reader.close()
+	subCount := a.config.subAnswersCount(results.Round)
+	// the blitz answers are judged part by part, so they are not graded
+	// automatically
+	graded := []string{}
+	if subCount == 1 {
+		graded, err = a.autoGrade(results)
+		if err != nil {
+			return err
+		}
+	}
+	autoGraded := make(map[string]bool, len(graded))
+	for _, team := range graded {
+		autoGraded[team] = true
+		fmt.Printf("Team %s response is graded automatically as correct\n", team)
+	}
+	groups, err := a.checkGroups(results, autoGraded)
+	if err != nil {
+		return err
+	}
+	progress, err := a.store.getCheckProgress(results.Round)
+	if err != nil {
+		return err
+	}
+	decided := make([]bool, len(groups))
+	if progress != nil {
+		resumed := 0
+		for i, g := range groups {
+			if applyCheckProgress(progress, results, g) {
+				decided[i] = true
+				resumed += len(g.Teams)
+			}
+		}
+		fmt.Printf("Resuming the interrupted check, %d teams are already judged\n", resumed)
+	} else {
+		progress = &checkProgress{
+			Verdicts:    make(map[string]ResponseStatus),
+			SubVerdicts: make(map[string][]ResponseStatus),
+			Versions:    make(map[string]int),
+		}
+	}
+	if progress.SubVerdicts == nil {
+		progress.SubVerdicts = make(map[string][]ResponseStatus)
+	}
+	total := 0
+	for _, g := range groups {
+		total += len(g.Teams)
+	}
+	a.startCheck(&checkSession{
+		round:    results.Round,
+		progress: progress,
+		reader:   reader,
+		judged:   judgedTeams(groups, decided),
+		total:    total,
+	})
+	defer a.finishCheck()
+	fmt.Print(tr("check.header", a.config.questionNumber(results.Round), a.config.CheckKeys.Back, a.config.CheckKeys.Skip))
+	i := firstUndecided(decided)
+	for i < len(groups) {
+		g := groups[i]
+		resp, _ := truncateAnswer(g.Response, displayWidth(g.Response))
+		teamsLabel := fmt.Sprintf("Team %s", g.Teams[0])
+		if len(g.Teams) > 1 {
+			teamsLabel = fmt.Sprintf("Teams %s (%d teams)", strings.Join(g.Teams, ", "), len(g.Teams))
+		}
+		fmt.Printf("[%d/%d teams] %s, response: %s, previous status: %v\n", judgedTeams(groups, decided), total, teamsLabel, resp, results.Results[g.Teams[0]].Status)
+		var subStatuses []ResponseStatus
+		var key string
+		if subCount > 1 {
+			subStatuses, key, err = a.readSubVerdicts(reader, verdicts, results.Results[g.Teams[0]].SubResponses, subCount)
+		} else {
+			key, err = reader.readKey()
+		}
+		if err != nil {
+			return fmt.Errorf("failed to scan the command: %w", err)
+		}
+		switch key {
+		case a.config.CheckKeys.Back:
+			if i == 0 {
+				fmt.Println(tr("check.firstResponse"))
+				continue
+			}
+			i--
+			continue
+		case a.config.CheckKeys.Skip:
+			i++
+			continue
+		}
+		status, ok := verdicts[key]
+		if subStatuses != nil {
+			status, ok = combineSubStatuses(subStatuses), true
+		}
+		if !ok {
+			fmt.Println(tr("check.unknownStatus"))
+			continue
+		}
+		err = a.updateCheck(func() int {
+			for _, team := range g.Teams {
+				results.Results[team].Status = status
+				results.Results[team].SubStatuses = subStatuses
+				progress.Verdicts[team] = status
+				progress.SubVerdicts[team] = subStatuses
+				progress.Versions[team] = results.Results[team].Version
+			}
+			decided[i] = true
+			return judgedTeams(groups, decided)
+		})
+		if err != nil {
+			return err
+		}
+		if status == ResponseStatusOK && subStatuses == nil {
+			if err := a.suggestSimilar(reader, results, groups, decided, i, progress); err != nil {
+				return err
+			}
+		}
+		i++
+	}
+	if skipped := total - judgedTeams(groups, decided); skipped != 0 {
+		fmt.Printf("%d teams are skipped and keep their previous status\n", skipped)
+	}
+	return nil
+}
+
+// checkGroups groups the teams with identical normalized responses, following
+// the check order.
+func (a *app) checkGroups(results *roundResults, autoGraded map[string]bool) ([]checkGroup, error) {
+	order, err := a.checkOrder(results)
+	if err != nil {
+		return nil, err
+	}
+	pipeline, err := newNormalizerPipeline(a.config.Normalizers)
+	if err != nil {
+		return nil, err
+	}
+	groups := make([]checkGroup, 0)
+	index := make(map[string]int)
+	for _, team := range order {
+		if autoGraded[team] {
+			continue
+		}
+		normalized := pipeline.normalize(results.Results[team].Response)
+		if i, ok := index[normalized]; ok {
+			groups[i].Teams = append(groups[i].Teams, team)
+			continue
+		}
+		index[normalized] = len(groups)
+		groups = append(groups, checkGroup{
+			Teams:      []string{team},
+			Response:   results.Results[team].Response,
+			Normalized: normalized,
+		})
+	}
+	return groups, nil
+}
+
+// suggestSimilar offers to accept the unchecked responses similar to the group
+// just judged correct, the responses within CheckSuggestDistance edits of its
+// normalized text. The Accept key marks them all correct, any other key keeps
+// them for the check.
+func (a *app) suggestSimilar(reader *verdictReader, results *roundResults, groups []checkGroup, decided []bool, judged int, progress *checkProgress) error {
+	distance := a.config.CheckSuggestDistance
+	if distance <= 0 {
+		return nil
+	}
+	similar := make([]int, 0)
+	for j, g := range groups {
+		if decided[j] || results.Results[g.Teams[0]].Status != ResponseStatusNotChecked {
+			continue
+		}
+		if levenshtein(groups[judged].Normalized, g.Normalized) <= distance {
+			similar = append(similar, j)
+		}
+	}
+	if len(similar) == 0 {
+		return nil
+	}
+	teams := 0
+	for _, j := range similar {
+		teams += len(groups[j].Teams)
+	}
+	fmt.Print(tr("check.suggestions", teams, a.config.CheckKeys.Accept))
+	for _, j := range similar {
+		resp, _ := truncateAnswer(groups[j].Response, displayWidth(groups[j].Response))
+		fmt.Printf("  %s: %s\n", strings.Join(groups[j].Teams, ", "), resp)
+	}
+	key, err := reader.readKey()
+	if err != nil {
+		return fmt.Errorf("failed to scan the command: %w", err)
+	}
+	if key != a.config.CheckKeys.Accept {
+		return nil
+	}
+	err = a.updateCheck(func() int {
+		for _, j := range similar {
+			for _, team := range groups[j].Teams {
+				results.Results[team].Status = ResponseStatusOK
+				results.Results[team].SubStatuses = nil
+				progress.Verdicts[team] = ResponseStatusOK
+				delete(progress.SubVerdicts, team)
+				progress.Versions[team] = results.Results[team].Version
+			}
+			decided[j] = true
+		}
+		return judgedTeams(groups, decided)
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Print(tr("check.suggestionsAccepted", teams))
+	return nil
+}
+
+// applyCheckProgress applies the persisted verdicts to the group, it reports
+// whether the whole group has been judged.
+func applyCheckProgress(progress *checkProgress, results *roundResults, g checkGroup) bool {
+	for _, team := range g.Teams {
+		if _, ok := progress.Verdicts[team]; !ok || progress.Versions[team] != results.Results[team].Version {
+			return false
+		}
+	}
+	for _, team := range g.Teams {
+		results.Results[team].Status = progress.Verdicts[team]
+		if sub, ok := progress.SubVerdicts[team]; ok {
+			results.Results[team].SubStatuses = sub
+		}
+	}
+	return true
+}
+
+// readSubVerdicts asks for a verdict on every part of a blitz answer. The back
+// and skip keys given on the first part apply to the whole answer and are
+// returned instead of the statuses.
+func (a *app) readSubVerdicts(reader *verdictReader, verdicts map[string]ResponseStatus, subResponses []string, count int) ([]ResponseStatus, string, error) {
+	statuses := make([]ResponseStatus, 0, count)
+	for len(statuses) < count {
+		part := ""
+		if len(statuses) < len(subResponses) {
+			part = subResponses[len(statuses)]
+		}
+		fmt.Print(tr("check.answerPart", len(statuses)+1, count, part))
+		key, err := reader.readKey()
+		if err != nil {
+			return nil, "", err
+		}
+		if len(statuses) == 0 && (key == a.config.CheckKeys.Back || key == a.config.CheckKeys.Skip) {
+			return nil, key, nil
+		}
+		status, ok := verdicts[key]
+		if !ok {
+			fmt.Println(tr("check.unknownStatus"))
+			continue
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, "", nil
+}
+
+func firstUndecided(decided []bool) int {
+	for i := range decided {
+		if !decided[i] {
+			return i
+		}
+	}
+	return len(decided)
+}
+
+func judgedTeams(groups []checkGroup, decided []bool) int {
+	judged := 0
+	for i, g := range groups {
+		if decided[i] {
+			judged += len(g.Teams)
+		}
+	}
+	return judged
+}
+
+// checkOrder returns the teams in the order their responses are checked:
+// sorted by the normalized response text, or grouped by similarity if
+// CheckClusterSimilar is set, so that identical answers are judged one after
+// another.
+func (a *app) checkOrder(results *roundResults) ([]string, error) {
+	pipeline, err := newNormalizerPipeline(a.config.Normalizers)
+	if err != nil {
+		return nil, err
+	}
+	order := make([]string, 0, len(results.Results))
+	if a.config.CheckClusterSimilar {
+		for _, c := range clusterResponses(results, pipeline) {
+			order = append(order, c.Teams...)
+		}
+		return order, nil
+	}
+	normalized := make(map[string]string, len(results.Results))
+	for team, resp := range results.Results {
+		order = append(order, team)
+		normalized[team] = pipeline.normalize(resp.Response)
+	}
+	sort.Slice(order, func(i, j int) bool {
+		if normalized[order[i]] != normalized[order[j]] {
+			return normalized[order[i]] < normalized[order[j]]
+		}
+		return order[i] < order[j]
+	})
+	return order, nil
+}
+
+// verdictReader reads the verdict keys either line by line or, in the single
+// keystroke mode, key by key with the terminal switched to the cbreak mode.
+type verdictReader struct {
+	reader       *bufio.Reader
+	singleKey    bool
+	termSettings string
+}
+
+func newVerdictReader(singleKey bool) (*verdictReader, error) {
+	r := &verdictReader{
+		reader: bufio.NewReader(os.Stdin),
+	}
+	if !singleKey || !isTerminal(os.Stdin) {
+		return r, nil
+	}
+	settings, err := stty("-g")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the terminal settings: %w", err)
+	}
+	if _, err := stty("cbreak", "-echo"); err != nil {
+		return nil, fmt.Errorf("failed to switch the terminal to the single keystroke mode: %w", err)
+	}
+	r.singleKey = true
+	r.termSettings = strings.TrimSpace(settings)
+	return r, nil
+}
+
+func (r *verdictReader) readKey() (string, error) {
+	if !r.singleKey {
+		key, err := r.reader.ReadString('\n')
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(key, "\r\n"), nil
+	}
+	key, _, err := r.reader.ReadRune()
+	if err != nil {
+		return "", err
+	}
+	if key == '\n' || key == '\r' {
+		fmt.Println()
+		return "", nil
+	}
+	fmt.Println(string(key))
+	return string(key), nil
+}
+
+func (r *verdictReader) close() {
+	if !r.singleKey {
+		return
+	}
+	if _, err := stty(r.termSettings); err != nil {
+		fmt.Printf("failed to restore the terminal settings: %v\n", err)
+	}
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	out, err := cmd.Output()
+	return string(out), err
+}
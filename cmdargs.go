@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// argKind is the type of a command argument.
+type argKind int
+
+const (
+	argString argKind = iota
+	argInt
+	// argChoice is one of the choices of the argument spec.
+	argChoice
+)
+
+func (k argKind) String() string {
+	switch k {
+	case argInt:
+		return "number"
+	case argChoice:
+		return "choice"
+	default:
+		return "text"
+	}
+}
+
+// argSpec describes a command argument. The optional arguments follow the
+// required ones, a variadic argument is the last one and takes the remaining
+// words, e.g. a team name with spaces given without quotes.
+type argSpec struct {
+	name     string
+	kind     argKind
+	choices  []string
+	optional bool
+	variadic bool
+}
+
+var (
+	noArgs    = []argSpec{}
+	roundArgs = []argSpec{{name: "round", kind: argInt}}
+	teamArgs  = []argSpec{{name: "team", kind: argString, variadic: true}}
+)
+
+type errorInvalidArguments struct {
+	cmd    string
+	usage  string
+	reason string
+}
+
+func (e *errorInvalidArguments) Error() string {
+	return tr("error.invalidArguments", e.cmd, e.reason, e.usage)
+}
+
+// checkArgs parses the command string and checks its arguments against the
+// command argument specs. The commands without specs are not checked.
+func (c *command) checkArgs(name string, cmdStr string) error {
+	if c.args == nil {
+		return nil
+	}
+	args, err := tokenizeArgs(cmdStr)
+	if err != nil {
+		return &errorInvalidArguments{cmd: name, usage: c.usage, reason: err.Error()}
+	}
+	if err := checkArgSpecs(c.args, args[1:]); err != nil {
+		return &errorInvalidArguments{cmd: name, usage: c.usage, reason: err.Error()}
+	}
+	return nil
+}
+
+func checkArgSpecs(specs []argSpec, args []string) error {
+	required := 0
+	variadic := false
+	for _, s := range specs {
+		if !s.optional {
+			required++
+		}
+		variadic = variadic || s.variadic
+	}
+	if len(args) < required {
+		return fmt.Errorf("expected at least %d argument(s), got %d", required, len(args))
+	}
+	if !variadic && len(args) > len(specs) {
+		return fmt.Errorf("expected at most %d argument(s), got %d", len(specs), len(args))
+	}
+	for i, arg := range args {
+		if i >= len(specs) {
+			// the remaining words of the variadic argument
+			break
+		}
+		s := specs[i]
+		switch s.kind {
+		case argInt:
+			if _, err := strconv.ParseInt(arg, 0, 0); err != nil {
+				return fmt.Errorf("%s must be a number, got %s", s.name, arg)
+			}
+		case argChoice:
+			found := false
+			for _, choice := range s.choices {
+				if arg == choice {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return fmt.Errorf("%s must be one of %s, got %s", s.name, strings.Join(s.choices, ", "), arg)
+			}
+		}
+	}
+	return nil
+}
+
+// tokenizeArgs splits the command string by whitespace, keeping together the
+// parts enclosed in double or single quotes, so that team names containing
+// spaces can be passed as arguments. A backslash escapes the next character.
+// The words are returned along with the error of an unterminated quote, the
+// quoted part extending to the end of the command.
+func tokenizeArgs(s string) ([]string, error) {
+	args := make([]string, 0)
+	var sb strings.Builder
+	var quote rune
+	inArg := false
+	escaped := false
+	for _, r := range s {
+		switch {
+		case escaped:
+			sb.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+			inArg = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+				continue
+			}
+			sb.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote = r
+			inArg = true
+		case unicode.IsSpace(r):
+			if inArg {
+				args = append(args, sb.String())
+				sb.Reset()
+				inArg = false
+			}
+		default:
+			sb.WriteRune(r)
+			inArg = true
+		}
+	}
+	if inArg {
+		args = append(args, sb.String())
+	}
+	if quote != 0 {
+		return args, fmt.Errorf("unterminated quote %c", quote)
+	}
+	if escaped {
+		return args, fmt.Errorf("nothing to escape at the end of the command")
+	}
+	return args, nil
+}
+
+// splitArgs is tokenizeArgs for the commands whose arguments are already
+// checked by the specs or parsed leniently.
+func splitArgs(s string) []string {
+	args, _ := tokenizeArgs(s)
+	return args
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// CollusionConfig tunes the detection of the identical wrong answers.
+type CollusionConfig struct {
+	// MaxDistance is the edit distance between two normalized answers under
+	// which they are reported. If zero, the distance depends on the answers
+	// length as for the similar command.
+	MaxDistance int
+	// MaxTeams is the number of teams sharing a wrong answer above which the
+	// answer is considered a common mistake rather than a suspicious one, 3
+	// by default.
+	MaxTeams int
+}
+
+func (c *CollusionConfig) maxTeams() int {
+	if c.MaxTeams <= 0 {
+		return 3
+	}
+	return c.MaxTeams
+}
+
+func (c *CollusionConfig) isSuspicious(a string, b string) (int, bool) {
+	distance := levenshtein(a, b)
+	if c.MaxDistance > 0 {
+		return distance, distance <= c.MaxDistance
+	}
+	length := len([]rune(a))
+	if l := len([]rune(b)); l < length {
+		length = l
+	}
+	return distance, distance <= similarityThreshold(length)
+}
+
+// collusionPair is a pair of teams with identical or near-identical wrong
+// answers.
+type collusionPair struct {
+	TeamA     string `json:"teamA" yaml:"teamA"`
+	TeamB     string `json:"teamB" yaml:"teamB"`
+	ResponseA string `json:"responseA" yaml:"responseA"`
+	ResponseB string `json:"responseB" yaml:"responseB"`
+	// Distance is the edit distance of the normalized answers.
+	Distance int `json:"distance" yaml:"distance"`
+	// Identical reports that the raw answers are the same, typos included.
+	Identical bool `json:"identical" yaml:"identical"`
+}
+
+type collusionResult struct {
+	Round int              `json:"round" yaml:"round"`
+	Pairs []*collusionPair `json:"pairs" yaml:"pairs"`
+}
+
+func (r *collusionResult) String() string {
+	var sb strings.Builder
+	if len(r.Pairs) == 0 {
+		return fmt.Sprintf("Round %d: no suspicious wrong answers\n", r.Round)
+	}
+	sb.WriteString(fmt.Sprintf("Round %d: %d pair(s) of suspicious wrong answers:\n", r.Round, len(r.Pairs)))
+	for _, p := range r.Pairs {
+		respA, _ := truncateAnswer(p.ResponseA, answerDisplayWidth)
+		respB, _ := truncateAnswer(p.ResponseB, answerDisplayWidth)
+		reason := fmt.Sprintf("distance %d", p.Distance)
+		if p.Identical {
+			reason = "identical"
+		} else if p.Distance == 0 {
+			reason = "identical once normalized"
+		}
+		sb.WriteString(fmt.Sprintf("\t teams %s and %s (%s): \"%s\" / \"%s\"\n", p.TeamA, p.TeamB, reason, respA, respB))
+	}
+	return sb.String()
+}
+
+// CmdCollusion reports the pairs of teams whose wrong answers to the round are
+// identical or nearly so: "collusion <round>". The wrong answers shared by more
+// than MaxTeams teams are common mistakes and are not reported. The answers
+// that are not checked yet are taken as wrong unless they match an accepted
+// answer.
+func (a *app) CmdCollusion(cmdStr string) (*collusionResult, error) {
+	round, err := a.getRoundNumber(cmdStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse collusion request: %w", err)
+	}
+	results, err := a.store.getRoundResults(round)
+	if err != nil {
+		return nil, err
+	}
+	pipeline, err := newNormalizerPipeline(a.config.Normalizers)
+	if err != nil {
+		return nil, err
+	}
+	accepted := make(map[string]bool)
+	for _, answer := range a.config.Answers[a.config.questionNumber(round)] {
+		accepted[pipeline.normalize(answer)] = true
+	}
+	teams := make([]string, 0, len(results.Results))
+	normalized := make(map[string]string, len(results.Results))
+	for team, resp := range results.Results {
+		n := pipeline.normalize(resp.Response)
+		if len(n) == 0 {
+			continue
+		}
+		switch resp.Status {
+		case ResponseStatusKO:
+		case ResponseStatusNotChecked:
+			if accepted[n] {
+				continue
+			}
+		default:
+			continue
+		}
+		teams = append(teams, team)
+		normalized[team] = n
+	}
+	sort.Strings(teams)
+	config := &a.config.Collusion
+	pairs := make([]*collusionPair, 0)
+	similar := make(map[string]int, len(teams))
+	for i, teamA := range teams {
+		for _, teamB := range teams[i+1:] {
+			distance, ok := config.isSuspicious(normalized[teamA], normalized[teamB])
+			if !ok {
+				continue
+			}
+			similar[teamA]++
+			similar[teamB]++
+			pairs = append(pairs, &collusionPair{
+				TeamA:     teamA,
+				TeamB:     teamB,
+				ResponseA: results.Results[teamA].Response,
+				ResponseB: results.Results[teamB].Response,
+				Distance:  distance,
+				Identical: results.Results[teamA].Response == results.Results[teamB].Response,
+			})
+		}
+	}
+	res := &collusionResult{Round: a.config.questionNumber(round), Pairs: make([]*collusionPair, 0, len(pairs))}
+	for _, p := range pairs {
+		// a team with a similar answer has the answer shared by
+		// similar+1 teams
+		if similar[p.TeamA] >= config.maxTeams() || similar[p.TeamB] >= config.maxTeams() {
+			continue
+		}
+		res.Pairs = append(res.Pairs, p)
+	}
+	sort.SliceStable(res.Pairs, func(i, j int) bool {
+		if res.Pairs[i].Identical != res.Pairs[j].Identical {
+			return res.Pairs[i].Identical
+		}
+		return res.Pairs[i].Distance < res.Pairs[j].Distance
+	})
+	return res, nil
+}
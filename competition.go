@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+)
+
+// standings returns the standings of the totals, the teams out of competition
+// follow the ranked teams without a place.
+func (a *app) standings(total map[string]float64) []*standing {
+	return computeOfficialStandings(total, a.config.OutOfCompetition)
+}
+
+// officialTeams returns the teams in competition.
+func (c *Config) officialTeams() []string {
+	teams := make([]string, 0, len(c.Teams))
+	for _, team := range c.Teams {
+		if !c.OutOfCompetition[team] {
+			teams = append(teams, team)
+		}
+	}
+	return teams
+}
+
+// checkOutOfCompetition returns the problems of the out of competition teams.
+func (c *Config) checkOutOfCompetition() []string {
+	known := make(map[string]bool, len(c.Teams))
+	for _, team := range c.Teams {
+		known[team] = true
+	}
+	var problems []string
+	for team := range c.OutOfCompetition {
+		if !known[team] {
+			problems = append(problems, fmt.Sprintf("OutOfCompetition: team %s is not in the game", team))
+		}
+	}
+	return problems
+}
@@ -4,19 +4,167 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 )
 
 type Config struct {
 	GameName          string
 	NumberOfQuestions int
 	HasWarmUpQuestion bool
-	Teams             []string
+	// FirstQuestionNumber is the number of the first question of the game, 1
+	// by default, e.g. 37 for the second session of a synchronous tournament.
+	// The commands, the sheet headers and the exports use this numbering.
+	FirstQuestionNumber int
+	Teams               []string
+	Tiebreak            TiebreakConfig
+	// Locale of the created spreadsheets, e.g. "ru_RU". The Google default
+	// is used if empty.
+	Locale string
+	// Language of the messages and of the default labels: en or ru, en by
+	// default. The --lang flag overrides it.
+	Language string
+	Labels   LabelsConfig
+	// CaptureSubmissionTime installs a script into the team spreadsheets that
+	// records when an answer was entered into a hidden sheet only the owner
+	// can edit. It requires the Apps Script API to be enabled for the
+	// credentials, the script trigger is installed on the owner's behalf, or
+	// by the owner if the tool cannot run the script.
+	CaptureSubmissionTime bool
+	// MaskAnswers links the team answers to the hidden and protected Raw sheet
+	// of the manager spreadsheet, the answers are copied to the manager sheet
+	// when the round is closed with the close command.
+	MaskAnswers bool
+	// ProgressiveDisclosure hides and protects the question columns of the
+	// team spreadsheets, the open command reveals them one at a time.
+	ProgressiveDisclosure bool
+	// TotalsFormulas adds the Totals sheet to the manager spreadsheet, with
+	// the totals and the standings computed by formulas from the statuses
+	// sheet, so that the statuses marked there by hand are counted live.
+	TotalsFormulas bool
+	// WriteManualAnswers writes the answers entered by the jury with the
+	// manual command into the team spreadsheets.
+	WriteManualAnswers bool
+	AudioCues          AudioCuesConfig
+	Timer              TimerConfig
+	CheckKeys          CheckKeysConfig
+	// CheckSingleKeystroke makes the interactive check accept a verdict key
+	// without pressing Enter.
+	CheckSingleKeystroke bool
+	// CheckClusterSimilar groups near-identical responses together in the
+	// interactive check instead of only sorting them by the normalized text.
+	CheckClusterSimilar bool
+	// CheckSuggestDistance is the maximum edit distance between the normalized
+	// responses for which the check suggests the verdict of a correct response
+	// to the other unchecked teams. 0 disables the suggestions.
+	CheckSuggestDistance int
+	// Normalizers is the list of named transforms applied to the answers
+	// before comparing them, e.g. ["lowercase", "yo-to-ye", "strip-hyphens"].
+	Normalizers []string
+	// Answers maps the question number, as numbered from FirstQuestionNumber,
+	// to its accepted answers. Responses matching them after normalization
+	// are graded automatically.
+	Answers     map[int][]string
+	FetchSettle FetchSettleConfig
+	Plugins     []PluginConfig
+	// JournalSize is the number of the latest mutations that can be undone,
+	// 20 by default.
+	JournalSize int
+	// Backups is the number of the database backups kept in the backups
+	// directory of the game, 10 by default. -1 disables the backups.
+	Backups int
+	// StaleAfterDays is the number of days without activity after which a
+	// game is considered finished and is suggested for archiving, 21 by
+	// default.
+	StaleAfterDays int
+	// MetadataCacheSeconds is the time the spreadsheets metadata is cached
+	// for, 300 by default.
+	MetadataCacheSeconds int
+	// RequestsPerMinute limits the rate of the Google API requests, the rate
+	// is not limited if zero. The requests of all the games run by the process
+	// share the limit.
+	RequestsPerMinute int
+	// QuotaPerMinute is the Google API quota of the requests per minute
+	// checked by selftest, 60 by default.
+	QuotaPerMinute int
+	// TeamIDs maps the teams to their IDs in the rating system, used by the
+	// rating export.
+	TeamIDs map[string]int
+	// OutOfCompetition marks the guest teams: they play and are checked as the
+	// other teams, but take no place in the standings and are listed after
+	// the ranked teams with the mark "в/к".
+	OutOfCompetition map[string]bool
+	// TeamDirectory is the path of the teams.json directory of the season
+	// shared by the games. The teams of the game are matched to it by their
+	// current or previous names.
+	TeamDirectory string
+	// TeamRefs are the stable IDs of the directory teams playing the game,
+	// their current names are added to Teams.
+	TeamRefs []string
+	// StableTeamIDs maps the teams to their stable IDs in the team directory.
+	// It is filled from the directory and is kept in the game archives, so
+	// that the tournament counts the games of a renamed team together.
+	StableTeamIDs map[string]string
+	// CallTimeoutSeconds bounds the duration of a Google API request, 60 by
+	// default. The longest timeout of the games run by the process is used.
+	CallTimeoutSeconds int
+	// FetchConcurrency is the number of the team spreadsheets read at once by
+	// fetchDirect and crosscheck, 8 by default.
+	FetchConcurrency int
+	// HealthCheckSeconds is the period of the check that the manager
+	// spreadsheet is readable, 300 by default. -1 disables the check.
+	HealthCheckSeconds int
+	Questions          QuestionsConfig
+	AnswerCells        AnswerCellsConfig
+	// Aliases maps the alternative command names, e.g. localized ones, to the
+	// commands. The target may include arguments, e.g. "итог": "total".
+	Aliases map[string]string
+	Drive   DriveConfig
+	// TemplateSpreadsheetID is the Drive ID of a spreadsheet copied for the
+	// manager and for each team instead of creating empty ones, so that its
+	// formatting and its extra sheets are kept. Only the answers grid, the
+	// question numbers and the links are written into the copies.
+	TemplateSpreadsheetID string
+	// Scopes are the OAuth scopes requested in addition to the ones required
+	// by the enabled features, e.g. "https://www.googleapis.com/auth/drive".
+	// The consent is requested again once the scopes change.
+	Scopes []string
+	// QuestionTypes maps the question numbers, as numbered from
+	// FirstQuestionNumber, to their types: normal, blitz (2 answers) or
+	// super-blitz (3 answers). The questions are normal by default.
+	QuestionTypes map[int]string
+	Jury          JuryConfig
+	Collusion     CollusionConfig
+	TeamSheet     TeamSheetConfig
+	Layout        LayoutConfig
+	Mail          MailConfig
+	Report        ReportConfig
+	Sharing       SharingConfig
+	// Webhooks are notified of the game events.
+	Webhooks []WebhookConfig
+	// APITokens are the bearer tokens accepted by the control API.
+	APITokens []string
 
 	OutputDir string `json:"-"`
 	NewGame   bool   `json:"-"`
-	CredsFile string `json:"-"`
+	// Force archives the existing game when a new one is created.
+	Force bool `json:"-"`
+	// ReadOnly runs an observer session next to the game instance: only the
+	// commands that read the results are allowed.
+	ReadOnly     bool   `json:"-"`
+	CredsFile    string `json:"-"`
+	OutputFormat string `json:"-"`
+	HTTPAddr     string `json:"-"`
+	APIAddr      string `json:"-"`
+	// Store is the store flag: bolt or sqlite:<path>.
+	Store string `json:"-"`
+	// ConfigFile is the path the configuration is read from by reload.
+	ConfigFile string `json:"-"`
 }
 
+// ParseJSONConfig reads the configuration and sets the defaults, the result
+// is checked with validate once the command line flags are applied. The
+// labels depend on the language and are set with the flags.
 func ParseJSONConfig(file string) (*Config, error) {
 	f, err := os.Open(file)
 	if err != nil {
@@ -27,8 +175,202 @@ func ParseJSONConfig(file string) (*Config, error) {
 	if err := json.NewDecoder(f).Decode(&c); err != nil {
 		return nil, err
 	}
-	if len(c.GameName) == 0 {
-		return nil, fmt.Errorf("game name cannot be empty")
+	if err := c.resolveTeamDirectory(); err != nil {
+		return nil, err
 	}
+	c.setDefaults()
 	return &c, nil
 }
+
+func (c *Config) setDefaults() {
+	if len(c.Tiebreak.Procedure) == 0 {
+		c.Tiebreak.Procedure = TiebreakProcedureRandom
+	}
+	if len(c.Layout.Orientation) == 0 {
+		c.Layout.Orientation = LayoutOrientationHorizontal
+	}
+	c.CheckKeys.setDefaults()
+}
+
+func (c *Config) fetchConcurrency() int {
+	if c.FetchConcurrency <= 0 {
+		return 8
+	}
+	return c.FetchConcurrency
+}
+
+const (
+	TiebreakProcedureRandom  = "random"
+	TiebreakProcedureClosest = "closest"
+)
+
+type TiebreakConfig struct {
+	// Procedure is either "random" (a random draw) or "closest" (the team
+	// whose answer is closest to the correct number wins).
+	Procedure string
+	// Seed of the random draw. If zero, the seed is derived from the current
+	// time and is recorded in the event log so that the draw can be reproduced.
+	Seed int64
+}
+
+// CheckKeysConfig maps the verdicts of the interactive check to keys. An empty
+// NotChecked key means that Enter resets the verdict. Back returns to the
+// previous answer and Skip leaves the answer unjudged.
+type CheckKeysConfig struct {
+	OK         string
+	KO         string
+	InQuestion string
+	Partial    string
+	NoAnswer   string
+	NotChecked string
+	Back       string
+	Skip       string
+	// Accept accepts the suggested verdicts of the similar responses.
+	Accept string
+}
+
+func (c *CheckKeysConfig) setDefaults() {
+	if len(c.OK) == 0 {
+		c.OK = "+"
+	}
+	if len(c.KO) == 0 {
+		c.KO = "-"
+	}
+	if len(c.InQuestion) == 0 {
+		c.InQuestion = "?"
+	}
+	if len(c.Partial) == 0 {
+		c.Partial = "~"
+	}
+	if len(c.NoAnswer) == 0 {
+		c.NoAnswer = "0"
+	}
+	if len(c.Back) == 0 {
+		c.Back = "b"
+	}
+	if len(c.Skip) == 0 {
+		c.Skip = "s"
+	}
+	if len(c.Accept) == 0 {
+		c.Accept = "a"
+	}
+}
+
+func (c *CheckKeysConfig) verdicts() (map[string]ResponseStatus, error) {
+	keys := []struct {
+		key    string
+		status ResponseStatus
+	}{
+		{c.OK, ResponseStatusOK},
+		{c.KO, ResponseStatusKO},
+		{c.InQuestion, ResponseStatusInQuestion},
+		{c.Partial, ResponseStatusPartial},
+		{c.NoAnswer, ResponseStatusNoAnswer},
+		{c.NotChecked, ResponseStatusNotChecked},
+	}
+	verdicts := make(map[string]ResponseStatus, len(keys))
+	for _, k := range keys {
+		if _, ok := verdicts[k.key]; ok {
+			return nil, fmt.Errorf("check key \"%s\" is assigned to several verdicts", k.key)
+		}
+		verdicts[k.key] = k.status
+	}
+	for _, navigation := range []string{c.Back, c.Skip, c.Accept} {
+		if _, ok := verdicts[navigation]; ok {
+			return nil, fmt.Errorf("check key \"%s\" is assigned to a verdict and to the navigation", navigation)
+		}
+	}
+	if len(c.Back) != 0 && c.Back == c.Skip {
+		return nil, fmt.Errorf("check key \"%s\" is assigned to both back and skip", c.Back)
+	}
+	return verdicts, nil
+}
+
+// FetchSettleConfig configures the fetch delays that work around the
+// IMPORTRANGE caching.
+type FetchSettleConfig struct {
+	// DelaySeconds is waited before the first fetch.
+	DelaySeconds int
+	// WindowSeconds is the time after the first fetch during which the round
+	// is re-fetched while the number of answers increases.
+	WindowSeconds int
+	// IntervalSeconds is the interval between the re-fetches, 5 by default.
+	IntervalSeconds int
+}
+
+// LabelsConfig holds the texts written into the created spreadsheets. In the
+// title templates {game} is replaced with the game name and {team} with the
+// team name.
+type LabelsConfig struct {
+	ManagerTitle   string
+	TeamTitle      string
+	ProjectorTitle string
+	ArchivedPrefix string
+	// SheetName renames the answers sheet of the created spreadsheets.
+	SheetName   string
+	TeamsHeader string
+}
+
+// setDefaults fills the empty labels with the texts of the language.
+func (l *LabelsConfig) setDefaults(lang string) {
+	if len(l.ManagerTitle) == 0 {
+		l.ManagerTitle = translate(lang, "label.managerTitle")
+	}
+	if len(l.TeamTitle) == 0 {
+		l.TeamTitle = translate(lang, "label.teamTitle")
+	}
+	if len(l.ProjectorTitle) == 0 {
+		l.ProjectorTitle = translate(lang, "label.projectorTitle")
+	}
+	if len(l.ArchivedPrefix) == 0 {
+		l.ArchivedPrefix = translate(lang, "label.archivedPrefix")
+	}
+	if len(l.TeamsHeader) == 0 {
+		l.TeamsHeader = translate(lang, "label.teamsHeader")
+	}
+}
+
+func (l *LabelsConfig) managerTitle(game string) string {
+	return strings.NewReplacer("{game}", game).Replace(l.ManagerTitle)
+}
+
+func (l *LabelsConfig) projectorTitle(game string) string {
+	return strings.NewReplacer("{game}", game).Replace(l.ProjectorTitle)
+}
+
+func (l *LabelsConfig) teamTitle(game string, team string) string {
+	return strings.NewReplacer("{game}", game, "{team}", team).Replace(l.TeamTitle)
+}
+
+func (c *Config) backupCount() int {
+	if c.Backups == 0 {
+		return 10
+	}
+	return c.Backups
+}
+
+func (c *Config) callTimeout() time.Duration {
+	seconds := c.CallTimeoutSeconds
+	if seconds <= 0 {
+		seconds = 60
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+func (c *Config) healthCheckInterval() time.Duration {
+	switch {
+	case c.HealthCheckSeconds < 0:
+		return 0
+	case c.HealthCheckSeconds == 0:
+		return 300 * time.Second
+	}
+	return time.Duration(c.HealthCheckSeconds) * time.Second
+}
+
+func (c *Config) staleAfter() time.Duration {
+	days := c.StaleAfterDays
+	if days <= 0 {
+		days = 21
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
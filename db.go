@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+type dbStatsResult struct {
+	FileSize int64         `json:"fileSize" yaml:"fileSize"`
+	Buckets  []bucketStats `json:"buckets" yaml:"buckets"`
+}
+
+func (r *dbStatsResult) String() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Database file size: %d bytes\n", r.FileSize))
+	for _, b := range r.Buckets {
+		sb.WriteString(fmt.Sprintf("\t bucket %s: %d keys, %d bytes\n", b.Name, b.Keys, b.Bytes))
+	}
+	return sb.String()
+}
+
+type dbCompactResult struct {
+	SizeBefore int64 `json:"sizeBefore" yaml:"sizeBefore"`
+	SizeAfter  int64 `json:"sizeAfter" yaml:"sizeAfter"`
+}
+
+func (r *dbCompactResult) String() string {
+	return fmt.Sprintf("Database is compacted: %d -> %d bytes", r.SizeBefore, r.SizeAfter)
+}
+
+func (a *app) CmdDB(cmdStr string) (fmt.Stringer, error) {
+	sSplitted := splitArgs(cmdStr)
+	if len(sSplitted) != 2 {
+		return nil, fmt.Errorf("expected 1 argument (stats or compact), got %d", len(sSplitted)-1)
+	}
+	switch sSplitted[1] {
+	case "stats":
+		return a.dbStats()
+	case "compact":
+		return a.dbCompact()
+	default:
+		return nil, fmt.Errorf("unknown db subcommand %s, expected stats or compact", sSplitted[1])
+	}
+}
+
+func (a *app) dbStats() (*dbStatsResult, error) {
+	b, err := a.boltStore()
+	if err != nil {
+		return nil, err
+	}
+	buckets, err := b.getBucketsStats()
+	if err != nil {
+		return nil, err
+	}
+	size, err := fileSize(b.dbFile)
+	if err != nil {
+		return nil, err
+	}
+	res := &dbStatsResult{
+		FileSize: size,
+		Buckets:  buckets,
+	}
+	return res, nil
+}
+
+func (a *app) dbCompact() (*dbCompactResult, error) {
+	b, err := a.boltStore()
+	if err != nil {
+		return nil, err
+	}
+	sizeBefore, err := fileSize(b.dbFile)
+	if err != nil {
+		return nil, err
+	}
+	if err := b.compact(); err != nil {
+		return nil, fmt.Errorf("failed to compact the database: %w", err)
+	}
+	sizeAfter, err := fileSize(b.dbFile)
+	if err != nil {
+		return nil, err
+	}
+	res := &dbCompactResult{
+		SizeBefore: sizeBefore,
+		SizeAfter:  sizeAfter,
+	}
+	return res, nil
+}
+
+func fileSize(file string) (int64, error) {
+	info, err := os.Stat(file)
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+func hiddenQuestionDescription(round int) string {
+	return fmt.Sprintf("hidden question %d", round)
+}
+
+// hideTeamQuestions hides the question columns (the rows in the vertical
+// layout) of the team spreadsheet and protects every answer cell, so that the
+// team sees and answers only the questions revealed by open.
+func (a *app) hideTeamQuestions(team *sheets.Spreadsheet, batch *setupBatch) {
+	if !a.config.ProgressiveDisclosure {
+		return
+	}
+	var sheetID int64
+	if len(team.Sheets) != 0 {
+		sheetID = team.Sheets[0].Properties.SheetId
+	}
+	requests := []*sheets.Request{
+		{
+			UpdateDimensionProperties: &sheets.UpdateDimensionPropertiesRequest{
+				Range:      a.hiddenQuestionsDimension(sheetID),
+				Properties: &sheets.DimensionProperties{HiddenByUser: true},
+				Fields:     "hiddenByUser",
+			},
+		},
+	}
+	for round := 0; round <= a.config.NumberOfQuestions; round++ {
+		column, row, err := a.getTeamRoundCellPosition(round)
+		if err != nil {
+			// no warm-up question
+			continue
+		}
+		requests = append(requests, &sheets.Request{
+			AddProtectedRange: &sheets.AddProtectedRangeRequest{
+				ProtectedRange: &sheets.ProtectedRange{
+					Description: hiddenQuestionDescription(round),
+					Range: &sheets.GridRange{
+						SheetId:          sheetID,
+						StartColumnIndex: int64(column),
+						EndColumnIndex:   int64(column + 1),
+						StartRowIndex:    int64(row - 1),
+						EndRowIndex:      int64(row),
+					},
+				},
+			},
+		})
+	}
+	batch.addRequests(requests...)
+}
+
+type openResult struct {
+	Round int      `json:"round" yaml:"round"`
+	Teams []string `json:"teams" yaml:"teams"`
+}
+
+func (r *openResult) String() string {
+	return fmt.Sprintf("Question %d is revealed for the teams: %s", r.Round, strings.Join(r.Teams, ", "))
+}
+
+// CmdOpen reveals the question in the team spreadsheets: "open <round>". The
+// question column (the row in the vertical layout) is shown and the protection
+// of the answer cell is removed. As the columns of the horizontal layout are
+// shared by the question groups, the other questions of the column become
+// visible but stay protected until they are opened.
+func (a *app) CmdOpen(cmdStr string) (*openResult, error) {
+	if !a.config.ProgressiveDisclosure {
+		return nil, fmt.Errorf("the questions are not hidden, enable ProgressiveDisclosure to open them one at a time")
+	}
+	round, err := a.getRoundNumber(cmdStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse open request: %w", err)
+	}
+	column, row, err := a.getTeamRoundCellPosition(round)
+	if err != nil {
+		return nil, err
+	}
+	gameSheets, err := a.GetGameSpreadsheets()
+	if err != nil {
+		return nil, err
+	}
+	spreadsheetsService := sheets.NewSpreadsheetsService(a.service)
+	res := &openResult{Round: a.config.questionNumber(round), Teams: make([]string, 0, len(a.config.Teams))}
+	for _, team := range a.config.Teams {
+		teamSheet, ok := gameSheets.teams[team]
+		if !ok {
+			return nil, fmt.Errorf("spreadsheet of the team %s is not found", team)
+		}
+		requests := []*sheets.Request{
+			{
+				UpdateDimensionProperties: &sheets.UpdateDimensionPropertiesRequest{
+					Range:      a.questionDimension(teamSheet.SheetID, column, row),
+					Properties: &sheets.DimensionProperties{HiddenByUser: false},
+					Fields:     "hiddenByUser",
+				},
+			},
+		}
+		metadata, err := a.getSpreadsheetMetadata(a.commandContext(), teamSheet.ID)
+		if err != nil {
+			return nil, err
+		}
+		id, protected := metadata.protectedRangeByDescription(hiddenQuestionDescription(round))
+		if protected {
+			requests = append(requests, &sheets.Request{
+				DeleteProtectedRange: &sheets.DeleteProtectedRangeRequest{ProtectedRangeId: id},
+			})
+		} else {
+			log.Printf("the question %d of the team %s is already open", a.config.questionNumber(round), team)
+		}
+		_, err = spreadsheetsService.BatchUpdate(teamSheet.ID, &sheets.BatchUpdateSpreadsheetRequest{
+			Requests: requests,
+		}).Context(a.commandContext()).Do()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open the question %d for the team %s: %w", a.config.questionNumber(round), team, err)
+		}
+		if protected {
+			a.metadata.removeProtectedRange(teamSheet.ID, id)
+		}
+		res.Teams = append(res.Teams, team)
+	}
+	if err := a.store.appendEvent(fmt.Sprintf("open: %d", round)); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
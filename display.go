@@ -0,0 +1,86 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/width"
+)
+
+const answerDisplayWidth = 40
+
+const (
+	bidiFirstStrongIsolate = '⁨'
+	bidiPopDirIsolate      = '⁩'
+)
+
+// runeWidth returns the number of terminal cells the rune occupies.
+func runeWidth(r rune) int {
+	if unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf) {
+		return 0
+	}
+	switch width.LookupRune(r).Kind() {
+	case width.EastAsianWide, width.EastAsianFullwidth:
+		return 2
+	}
+	return 1
+}
+
+func displayWidth(s string) int {
+	w := 0
+	for _, r := range s {
+		w += runeWidth(r)
+	}
+	return w
+}
+
+func hasRTL(s string) bool {
+	for _, r := range s {
+		if unicode.In(r, unicode.Hebrew, unicode.Arabic, unicode.Syriac, unicode.Thaana, unicode.Nko) {
+			return true
+		}
+	}
+	return false
+}
+
+// formatAnswer pads the truncated answer to maxWidth cells so that it can be
+// printed in a table column.
+func formatAnswer(s string, maxWidth int) string {
+	res, w := truncateAnswer(s, maxWidth)
+	return res + strings.Repeat(" ", maxWidth-w)
+}
+
+// truncateAnswer makes an answer safe to print in the terminal: control
+// characters are replaced, the string is truncated to maxWidth cells without
+// splitting combining sequences, and RTL text is isolated so that it does not
+// reorder the surrounding text. It returns the result and its width in cells.
+func truncateAnswer(s string, maxWidth int) (string, int) {
+	s = strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\t' || r == '\r' {
+			return ' '
+		}
+		return r
+	}, s)
+	var sb strings.Builder
+	w := 0
+	if displayWidth(s) > maxWidth {
+		for _, r := range s {
+			rw := runeWidth(r)
+			if w+rw > maxWidth-1 {
+				break
+			}
+			sb.WriteRune(r)
+			w += rw
+		}
+		sb.WriteRune('…')
+		w++
+	} else {
+		sb.WriteString(s)
+		w = displayWidth(s)
+	}
+	res := sb.String()
+	if hasRTL(res) {
+		res = string(bidiFirstStrongIsolate) + res + string(bidiPopDirIsolate)
+	}
+	return res, w
+}
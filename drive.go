@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/sheets/v4"
+)
+
+const driveFolderMimeType = "application/vnd.google-apps.folder"
+
+// DriveConfig configures the Drive folder the created spreadsheets are moved
+// to. The spreadsheets stay in the Drive root if neither field is set.
+type DriveConfig struct {
+	// Folder is the ID of an existing folder.
+	Folder string
+	// CreateFolder creates a folder named after the game if Folder is empty.
+	CreateFolder bool
+}
+
+func (c *DriveConfig) enabled() bool {
+	return len(c.Folder) != 0 || c.CreateFolder
+}
+
+// gameDriveFolder returns the ID of the game folder, creating it on the first
+// call if configured so. The created folder is stored to be reused.
+func (a *app) gameDriveFolder() (string, error) {
+	if len(a.config.Drive.Folder) != 0 {
+		return a.config.Drive.Folder, nil
+	}
+	folder, err := a.store.getDriveFolder()
+	if err != nil {
+		return "", err
+	}
+	if len(folder) != 0 {
+		return folder, nil
+	}
+	created, err := a.drive.Files.Create(&drive.File{
+		Name:     a.config.GameName,
+		MimeType: driveFolderMimeType,
+	}).Fields("id").Context(a.commandContext()).Do()
+	if err != nil {
+		return "", fmt.Errorf("failed to create the game Drive folder: %w", err)
+	}
+	if err := a.store.saveDriveFolder(created.Id); err != nil {
+		return "", err
+	}
+	log.Printf("created the game Drive folder %s", a.config.GameName)
+	return created.Id, nil
+}
+
+// copyTemplateSpreadsheet copies the template spreadsheet under the title. The
+// configured locale and sheet name are applied to the copy.
+func (a *app) copyTemplateSpreadsheet(title string) (*sheets.Spreadsheet, error) {
+	copied, err := a.drive.Files.Copy(a.config.TemplateSpreadsheetID, &drive.File{Name: title}).Fields("id").Context(a.commandContext()).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy the template spreadsheet %s: %w", a.config.TemplateSpreadsheetID, err)
+	}
+	spreadsheet, err := a.service.Spreadsheets.Get(copied.Id).Context(a.commandContext()).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the copy of the template spreadsheet: %w", err)
+	}
+	requests := make([]*sheets.Request, 0, 2)
+	if len(a.config.Locale) != 0 {
+		requests = append(requests, &sheets.Request{
+			UpdateSpreadsheetProperties: &sheets.UpdateSpreadsheetPropertiesRequest{
+				Properties: &sheets.SpreadsheetProperties{Locale: a.config.Locale},
+				Fields:     "locale",
+			},
+		})
+	}
+	first := spreadsheet.Sheets[0].Properties
+	if len(a.config.Labels.SheetName) != 0 && first.Title != a.config.Labels.SheetName {
+		requests = append(requests, &sheets.Request{
+			UpdateSheetProperties: &sheets.UpdateSheetPropertiesRequest{
+				Properties: &sheets.SheetProperties{SheetId: first.SheetId, Title: a.config.Labels.SheetName},
+				Fields:     "title",
+			},
+		})
+		first.Title = a.config.Labels.SheetName
+	}
+	if len(requests) != 0 {
+		_, err := a.service.Spreadsheets.BatchUpdate(spreadsheet.SpreadsheetId, &sheets.BatchUpdateSpreadsheetRequest{
+			Requests: requests,
+		}).Context(a.commandContext()).Do()
+		if err != nil {
+			return nil, fmt.Errorf("failed to update the copy of the template spreadsheet: %w", err)
+		}
+	}
+	return spreadsheet, nil
+}
+
+// moveToGameFolder moves the created spreadsheet from the Drive root to the
+// game folder. A failure is logged, the spreadsheet stays usable in the root.
+func (a *app) moveToGameFolder(spreadsheetID string) {
+	if !a.config.Drive.enabled() {
+		return
+	}
+	folder, err := a.gameDriveFolder()
+	if err != nil {
+		log.Printf("[ERR]: failed to move the spreadsheet %s to the game folder: %v", spreadsheetID, err)
+		return
+	}
+	file, err := a.drive.Files.Get(spreadsheetID).Fields("parents").Context(a.commandContext()).Do()
+	if err != nil {
+		log.Printf("[ERR]: failed to get the spreadsheet %s parents: %v", spreadsheetID, err)
+		return
+	}
+	update := a.drive.Files.Update(spreadsheetID, &drive.File{}).AddParents(folder)
+	for _, parent := range file.Parents {
+		if parent != folder {
+			update = update.RemoveParents(parent)
+		}
+	}
+	if _, err := update.Context(a.commandContext()).Do(); err != nil {
+		log.Printf("[ERR]: failed to move the spreadsheet %s to the game folder: %v", spreadsheetID, err)
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"google.golang.org/api/drive/v3"
+)
+
+const spreadsheetMimeType = "application/vnd.google-apps.spreadsheet"
+
+type errorGameExists struct {
+	// where is the output dir or the URLs of the spreadsheets found in Drive
+	where string
+}
+
+func (e *errorGameExists) Error() string {
+	return fmt.Sprintf("a game is already created in %s: run without --newGame to continue it, or add --force to archive it and create a new one", e.where)
+}
+
+// archiveExistingGame moves the game files of the output dir to the archived
+// games directory of the workspace, the OAuth token is kept.
+func archiveExistingGame(outputDir string) error {
+	dir, err := filepath.Abs(outputDir)
+	if err != nil {
+		return err
+	}
+	workspace, name := filepath.Split(dir)
+	archivedName := fmt.Sprintf("%s-%s", name, time.Now().Format("20060102-150405"))
+	if err := archiveGameDir(workspace, name); err != nil {
+		return err
+	}
+	if err := os.Rename(path.Join(workspace, archivedGamesDir, name), path.Join(workspace, archivedGamesDir, archivedName)); err != nil {
+		return fmt.Errorf("failed to archive the existing game: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create a new game directory %s: %w", outputDir, err)
+	}
+	tokFile := path.Join(workspace, archivedGamesDir, archivedName, "secret-token")
+	if _, err := os.Stat(tokFile); err == nil {
+		if err := os.Rename(tokFile, path.Join(dir, "secret-token")); err != nil {
+			return fmt.Errorf("failed to keep the OAuth token: %w", err)
+		}
+	}
+	return nil
+}
+
+// checkDuplicateGame looks for the spreadsheets of the game in Drive before
+// they are created. The found spreadsheets are renamed with the archived
+// prefix if the game is forced, otherwise it is an error. Drive is searched
+// only if the Drive folder is configured, as the Drive scope is required.
+func (a *app) checkDuplicateGame() error {
+	if a.drive == nil {
+		return nil
+	}
+	titles := []string{a.config.Labels.managerTitle(a.config.GameName)}
+	for _, team := range a.config.Teams {
+		titles = append(titles, a.config.Labels.teamTitle(a.config.GameName, team))
+	}
+	conditions := make([]string, len(titles))
+	for i, title := range titles {
+		conditions[i] = fmt.Sprintf("name = '%s'", escapeDriveQuery(title))
+	}
+	query := fmt.Sprintf("mimeType = '%s' and trashed = false and (%s)", spreadsheetMimeType, strings.Join(conditions, " or "))
+	found, err := a.drive.Files.List().Q(query).Fields("files(id,name,webViewLink)").Context(a.commandContext()).Do()
+	if err != nil {
+		return fmt.Errorf("failed to look for the existing game spreadsheets: %w", err)
+	}
+	if len(found.Files) == 0 {
+		return nil
+	}
+	if !a.config.Force {
+		links := make([]string, len(found.Files))
+		for i, f := range found.Files {
+			links[i] = f.WebViewLink
+		}
+		return &errorGameExists{where: "Drive: " + strings.Join(links, ", ")}
+	}
+	for _, f := range found.Files {
+		_, err := a.drive.Files.Update(f.Id, &drive.File{Name: a.config.Labels.ArchivedPrefix + f.Name}).Context(a.commandContext()).Do()
+		if err != nil {
+			return fmt.Errorf("failed to archive the existing spreadsheet %s: %w", f.Name, err)
+		}
+		log.Printf("archived the existing spreadsheet %s", f.Name)
+	}
+	return nil
+}
+
+func escapeDriveQuery(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s)
+}
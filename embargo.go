@@ -0,0 +1,199 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+const snapshotStandings = "standings"
+
+// standingsSnapshot is the standings frozen at a round. During the results
+// embargo the projector, the mails and the dashboard show the snapshot while
+// the jury still sees the live totals.
+type standingsSnapshot struct {
+	Round   int                `json:"round" yaml:"round"`
+	Time    time.Time          `json:"time" yaml:"time"`
+	Totals  map[string]float64 `json:"totals" yaml:"totals"`
+	Embargo bool               `json:"embargo" yaml:"embargo"`
+}
+
+type standingsSnapshotResult struct {
+	Round     int         `json:"round" yaml:"round"`
+	Embargo   bool        `json:"embargo" yaml:"embargo"`
+	Standings []*standing `json:"standings" yaml:"standings"`
+}
+
+func (r *standingsSnapshotResult) String() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Standings are frozen after the round %d", r.Round))
+	if r.Embargo {
+		sb.WriteString(", the embargo is on")
+	}
+	sb.WriteString("\n")
+	t := &table{header: []string{"Place", "Team", "Score"}}
+	for _, s := range r.Standings {
+		for _, team := range s.Teams {
+			t.addRow(plainCell(s.places()), plainCell(team), plainCell(formatPoints(s.Score)))
+		}
+	}
+	sb.WriteString(t.String())
+	return sb.String()
+}
+
+// CmdSnapshotStandings freezes the standings after the round: "snapshot
+// standings <round>". The rounds after it are not counted, the adjustments
+// made so far are. A new snapshot replaces the previous one and keeps its
+// embargo.
+func (a *app) CmdSnapshotStandings(cmdStr string) (*standingsSnapshotResult, error) {
+	sSplitted := splitArgs(cmdStr)
+	if len(sSplitted) != 3 {
+		return nil, fmt.Errorf("expected the round, e.g. snapshot standings 24")
+	}
+	round, err := a.parseRound(sSplitted[2])
+	if err != nil {
+		return nil, err
+	}
+	if round < 0 || round > a.config.NumberOfQuestions {
+		return nil, a.config.errRoundOutOfRange(round)
+	}
+	pluginTotals, err := a.pluginTotals()
+	if err != nil {
+		return nil, err
+	}
+	if pluginTotals != nil {
+		return nil, fmt.Errorf("the totals are computed by a plugin and cannot be frozen at a round")
+	}
+	allMeta, err := a.store.getAllRoundMeta()
+	if err != nil {
+		return nil, err
+	}
+	allResults, err := a.store.getAllRoundResults()
+	if err != nil {
+		return nil, err
+	}
+	resultsByRound := make(map[int]*roundResults, len(allResults))
+	for _, results := range allResults {
+		resultsByRound[results.Round] = results
+	}
+	rounds := make([]int, 0)
+	for _, r := range a.scoredRounds() {
+		if r <= round {
+			rounds = append(rounds, r)
+		}
+	}
+	totals := sumTotals(a.config.Teams, rounds, resultsByRound, allMeta)
+	if err := a.applyAdjustments(totals); err != nil {
+		return nil, err
+	}
+	snapshot := &standingsSnapshot{Round: round, Time: time.Now(), Totals: totals}
+	previous, err := a.store.getStandingsSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	if previous != nil {
+		snapshot.Embargo = previous.Embargo
+	}
+	if err := a.store.saveStandingsSnapshot(snapshot); err != nil {
+		return nil, fmt.Errorf("failed to store the standings snapshot: %w", err)
+	}
+	if err := a.store.appendEvent(fmt.Sprintf("snapshot standings: round %d", a.config.questionNumber(round))); err != nil {
+		return nil, err
+	}
+	return &standingsSnapshotResult{Round: a.config.questionNumber(round), Embargo: snapshot.Embargo, Standings: a.standings(totals)}, nil
+}
+
+type embargoResult struct {
+	Embargo bool `json:"embargo" yaml:"embargo"`
+	// Round is the round of the standings snapshot, -1 if none is taken.
+	Round int `json:"round" yaml:"round"`
+}
+
+func (r *embargoResult) String() string {
+	if !r.Embargo {
+		return "The results embargo is off, the live standings are published\n"
+	}
+	return fmt.Sprintf("The results embargo is on, the standings after the round %d are published\n", r.Round)
+}
+
+// CmdEmbargo turns the results embargo on or off: "embargo [on|off]". During
+// the embargo the projector and the mails show the standings snapshot, and
+// the dashboard does not receive the rounds after it. Without an argument the
+// embargo state is shown.
+func (a *app) CmdEmbargo(cmdStr string) (*embargoResult, error) {
+	sSplitted := splitArgs(cmdStr)
+	snapshot, err := a.store.getStandingsSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	res := &embargoResult{Round: -1}
+	if snapshot != nil {
+		res.Embargo, res.Round = snapshot.Embargo, a.config.questionNumber(snapshot.Round)
+	}
+	if len(sSplitted) == 1 {
+		return res, nil
+	}
+	switch sSplitted[1] {
+	case "on":
+		if snapshot == nil {
+			return nil, fmt.Errorf("no standings snapshot is taken, freeze the standings with snapshot standings <round> first")
+		}
+		snapshot.Embargo = true
+	case "off":
+		if snapshot == nil {
+			return res, nil
+		}
+		snapshot.Embargo = false
+	default:
+		return nil, fmt.Errorf("expected on or off, got %s", sSplitted[1])
+	}
+	if err := a.store.saveStandingsSnapshot(snapshot); err != nil {
+		return nil, fmt.Errorf("failed to store the embargo: %w", err)
+	}
+	if err := a.store.appendEvent(fmt.Sprintf("embargo %s", sSplitted[1])); err != nil {
+		return nil, err
+	}
+	res.Embargo = snapshot.Embargo
+	return res, nil
+}
+
+// embargoedRound returns the round of the standings snapshot if the embargo is
+// on: the rounds after it are not published.
+func (a *app) embargoedRound() (int, bool, error) {
+	snapshot, err := a.store.getStandingsSnapshot()
+	if err != nil {
+		return 0, false, err
+	}
+	if snapshot == nil || !snapshot.Embargo {
+		return 0, false, nil
+	}
+	return snapshot.Round, true, nil
+}
+
+// publicTotals returns the totals shown to the teams and the audience: the
+// standings snapshot during the embargo, the live totals otherwise.
+func (a *app) publicTotals() (map[string]float64, error) {
+	snapshot, err := a.store.getStandingsSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	if snapshot == nil || !snapshot.Embargo {
+		return a.computeTotals()
+	}
+	totals := make(map[string]float64, len(a.config.Teams))
+	for _, team := range a.config.Teams {
+		totals[team] = snapshot.Totals[team]
+	}
+	return totals, nil
+}
+
+// isEmbargoed reports whether the round results must not be published.
+func (a *app) isEmbargoed(round int) bool {
+	embargoed, ok, err := a.embargoedRound()
+	if err != nil {
+		log.Printf("[ERR]: failed to read the results embargo: %v", err)
+		return true
+	}
+	return ok && round > embargoed
+}
@@ -0,0 +1,539 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// command is an entry of the command set shared by the REPL and the API.
+type command struct {
+	// usage is the argument syntax of the command, description says what it
+	// does, both are shown by help.
+	usage       string
+	description string
+	// args are checked before the command is run, nil means that the command
+	// checks its arguments itself.
+	args []argSpec
+	run  func(a *app, cmdStr string) (fmt.Stringer, error)
+	// interactive reports whether the command reads from the standard input,
+	// such commands cannot be run through the API.
+	interactive func(a *app, cmdStr string) bool
+}
+
+func always(*app, string) bool { return true }
+
+var commands = map[string]*command{
+	"listURLs": {
+		usage:       "listURLs",
+		description: "print the URLs of the game spreadsheets",
+		args:        noArgs,
+		run:         func(a *app, _ string) (fmt.Stringer, error) { return a.CmdListURLs() },
+	},
+	"fetch": {
+		usage:       "fetch <round>",
+		description: "fetch the round answers from the manager spreadsheet",
+		args:        roundArgs,
+		run:         func(a *app, cmdStr string) (fmt.Stringer, error) { return a.CmdFetchResults(cmdStr) },
+	},
+	"get": {
+		usage:       "get <round>",
+		description: "print the stored round answers",
+		args:        roundArgs,
+		run:         func(a *app, cmdStr string) (fmt.Stringer, error) { return a.CmdGetResults(cmdStr) },
+	},
+	"check": {
+		usage:       "check <round>",
+		description: "check the round answers interactively",
+		args:        roundArgs,
+		run:         func(a *app, cmdStr string) (fmt.Stringer, error) { return nil, a.CmdCheckResults(cmdStr) },
+		interactive: always,
+	},
+	"fetchDirect": {
+		usage:       "fetchDirect <round>",
+		description: "fetch the round answers from the team spreadsheets and compare them with the manager spreadsheet",
+		args:        roundArgs,
+		run:         func(a *app, cmdStr string) (fmt.Stringer, error) { return a.CmdFetchDirect(cmdStr) },
+	},
+	"manual": {
+		usage:       "manual <round> <team> <answer>",
+		description: "record an answer the jury enters on behalf of a team",
+		args:        []argSpec{{name: "round", kind: argInt}, {name: "team", kind: argString}, {name: "answer", kind: argString, variadic: true}},
+		run:         func(a *app, cmdStr string) (fmt.Stringer, error) { return a.CmdManual(cmdStr) },
+	},
+	"history": {
+		usage:       "history <round> <team>",
+		description: "list the distinct responses of the team to the round seen by the fetches",
+		args:        []argSpec{{name: "round", kind: argInt}, {name: "team", kind: argString, variadic: true}},
+		run:         func(a *app, cmdStr string) (fmt.Stringer, error) { return a.CmdHistory(cmdStr) },
+	},
+	"crosscheck": {
+		usage:       "crosscheck <round>",
+		description: "compare the round answers of the manager and the team spreadsheets",
+		args:        roundArgs,
+		run:         func(a *app, cmdStr string) (fmt.Stringer, error) { return a.CmdCrossCheck(cmdStr) },
+	},
+	"addTeam": {
+		usage:       "addTeam <team>",
+		description: "add a team to the game",
+		args:        teamArgs,
+		run:         func(a *app, cmdStr string) (fmt.Stringer, error) { return nil, a.CmdAddTeam(cmdStr) },
+	},
+	"removeTeam": {
+		usage:       "removeTeam <team>",
+		description: "remove a team from the game and archive its spreadsheet",
+		args:        teamArgs,
+		run:         func(a *app, cmdStr string) (fmt.Stringer, error) { return nil, a.CmdRemoveTeam(cmdStr) },
+	},
+	"tiebreak": {
+		usage:       "tiebreak <team> <team>",
+		description: "break a tie between two teams",
+		args:        []argSpec{{name: "team", kind: argString}, {name: "team", kind: argString}},
+		run:         func(a *app, cmdStr string) (fmt.Stringer, error) { return a.CmdTiebreak(cmdStr) },
+		interactive: func(a *app, _ string) bool {
+			return a.config.Tiebreak.Procedure == TiebreakProcedureClosest
+		},
+	},
+	"shootout": {
+		usage:       "shootout start <team> <team> [team...] | shootout add | shootout fetch|check <question> | shootout result",
+		description: "play extra questions between the tied teams apart from the main totals",
+		args:        []argSpec{{name: "action", kind: argChoice, choices: []string{"start", "add", "fetch", "check", "result"}}, {name: "args", kind: argString, optional: true, variadic: true}},
+		run:         func(a *app, cmdStr string) (fmt.Stringer, error) { return a.CmdShootout(cmdStr) },
+		interactive: func(_ *app, cmdStr string) bool {
+			args := splitArgs(cmdStr)
+			return len(args) > 1 && args[1] == "check"
+		},
+	},
+	"mail": {
+		usage:       "mail results [team]",
+		description: "send the teams the summaries of their answers, score and place",
+		args:        []argSpec{{name: "action", kind: argChoice, choices: []string{"results"}}, {name: "team", kind: argString, optional: true, variadic: true}},
+		run:         func(a *app, cmdStr string) (fmt.Stringer, error) { return a.CmdMail(cmdStr) },
+	},
+	"snapshot": {
+		usage:       "snapshot <round> | snapshot standings <round>",
+		description: "render the round results to PNG and HTML, or freeze the standings after the round for the embargo",
+		args:        []argSpec{{name: "round", kind: argString}, {name: "round", kind: argInt, optional: true}},
+		run: func(a *app, cmdStr string) (fmt.Stringer, error) {
+			if args := splitArgs(cmdStr); len(args) > 1 && args[1] == snapshotStandings {
+				return a.CmdSnapshotStandings(cmdStr)
+			}
+			return a.CmdSnapshot(cmdStr)
+		},
+	},
+	"embargo": {
+		usage:       "embargo [on|off]",
+		description: "publish only the standings snapshot to the projector, the mails and the dashboard while the jury sees the live totals",
+		args:        []argSpec{{name: "state", kind: argChoice, choices: []string{"on", "off"}, optional: true}},
+		run:         func(a *app, cmdStr string) (fmt.Stringer, error) { return a.CmdEmbargo(cmdStr) },
+	},
+	"db": {
+		usage:       "db stats|compact",
+		description: "show the database statistics or compact it",
+		args:        []argSpec{{name: "action", kind: argChoice, choices: []string{"stats", "compact"}}},
+		run:         func(a *app, cmdStr string) (fmt.Stringer, error) { return a.CmdDB(cmdStr) },
+	},
+	"timer": {
+		usage:       "timer [seconds] [round] | timer stop",
+		description: "start or stop the countdown",
+		run:         func(a *app, cmdStr string) (fmt.Stringer, error) { return a.CmdTimer(cmdStr) },
+	},
+	"watch": {
+		usage:       "watch <round>",
+		description: "count the round answers until every team has answered or the time is up, then lock and fetch the round",
+		args:        roundArgs,
+		run:         func(a *app, cmdStr string) (fmt.Stringer, error) { return a.CmdWatch(cmdStr) },
+	},
+	"resumeSetup": {
+		usage:       "resumeSetup",
+		description: "complete an interrupted game setup",
+		args:        noArgs,
+		run:         func(a *app, _ string) (fmt.Stringer, error) { return a.CmdResumeSetup() },
+	},
+	"similar": {
+		usage:       "similar <round>",
+		description: "group the similar round answers",
+		args:        roundArgs,
+		run:         func(a *app, cmdStr string) (fmt.Stringer, error) { return a.CmdSimilar(cmdStr) },
+	},
+	"markStatuses": {
+		usage:       "markStatuses <round>",
+		description: "write the round statuses to the manager spreadsheet",
+		args:        roundArgs,
+		run:         func(a *app, cmdStr string) (fmt.Stringer, error) { return a.CmdMarkStatuses(cmdStr) },
+	},
+	"markAll": {
+		usage:       "markAll <round> <status> [--yes]",
+		description: "set the status of all the round responses, e.g. ok or noanswer when the question is thrown out",
+		args:        []argSpec{{name: "round", kind: argInt}, {name: "status", kind: argString}, {name: "yes", kind: argChoice, choices: []string{bulkConfirmFlag}, optional: true}},
+		run:         func(a *app, cmdStr string) (fmt.Stringer, error) { return a.CmdMarkAll(cmdStr) },
+		interactive: bulkNeedsConfirmation,
+	},
+	"uncheck": {
+		usage:       "uncheck <round> [--yes]",
+		description: "reset the statuses of the round responses to not checked",
+		args:        []argSpec{{name: "round", kind: argInt}, {name: "yes", kind: argChoice, choices: []string{bulkConfirmFlag}, optional: true}},
+		run:         func(a *app, cmdStr string) (fmt.Stringer, error) { return a.CmdUncheck(cmdStr) },
+		interactive: bulkNeedsConfirmation,
+	},
+	"announce": {
+		usage:       "announce [--step]",
+		description: "print the final standings reveal script",
+		args:        []argSpec{{name: "step", kind: argChoice, choices: []string{"--step"}, optional: true}},
+		run:         func(a *app, cmdStr string) (fmt.Stringer, error) { return a.CmdAnnounce(cmdStr) },
+		interactive: func(_ *app, cmdStr string) bool {
+			return strings.Contains(cmdStr, "--step")
+		},
+	},
+	"archive": {
+		usage:       "archive save|load <file>",
+		description: "save the game to an archive or load it",
+		args:        []argSpec{{name: "action", kind: argChoice, choices: []string{"save", "load"}}, {name: "file", kind: argString}},
+		run:         func(a *app, cmdStr string) (fmt.Stringer, error) { return a.CmdArchive(cmdStr) },
+	},
+	"lock": {
+		usage:       "lock <round>",
+		description: "protect the round answers in the team spreadsheets",
+		args:        roundArgs,
+		run:         func(a *app, cmdStr string) (fmt.Stringer, error) { return a.CmdLock(cmdStr) },
+	},
+	"unlock": {
+		usage:       "unlock <round>",
+		description: "remove the round answers protection",
+		args:        roundArgs,
+		run:         func(a *app, cmdStr string) (fmt.Stringer, error) { return a.CmdUnlock(cmdStr) },
+	},
+	"export": {
+		usage:       "export <plugin> [args...] | export rating <path>",
+		description: "run an exporter plugin or write the results for the rating system",
+		run:         func(a *app, cmdStr string) (fmt.Stringer, error) { return a.CmdExport(cmdStr) },
+	},
+	"import": {
+		usage:       "import pack <file|url>",
+		description: "import the questions and the answers of a db.chgk.info or 4s pack",
+		args:        []argSpec{{name: "what", kind: argChoice, choices: []string{"pack"}}, {name: "source", kind: argString}},
+		run:         func(a *app, cmdStr string) (fmt.Stringer, error) { return a.CmdImport(cmdStr) },
+	},
+	"where": {
+		usage:       "where <round>",
+		description: "print the cells holding the round answers",
+		args:        roundArgs,
+		run:         func(a *app, cmdStr string) (fmt.Stringer, error) { return a.CmdWhere(cmdStr) },
+	},
+	"undo": {
+		usage:       "undo",
+		description: "revert the latest change of the stored results",
+		args:        noArgs,
+		run:         func(a *app, _ string) (fmt.Stringer, error) { return a.CmdUndo() },
+	},
+	"games": {
+		usage:       "games list|gc [--apply] [workspace]",
+		description: "list the games of the workspace or archive the stale ones",
+		run:         func(a *app, cmdStr string) (fmt.Stringer, error) { return a.CmdGames(cmdStr) },
+	},
+	"attach": {
+		usage:       "attach manager=<id> team:<name>=<id>...",
+		description: "reconnect the game to existing spreadsheets",
+		run:         func(a *app, cmdStr string) (fmt.Stringer, error) { return a.CmdAttach(cmdStr) },
+	},
+	"missing": {
+		usage:       "missing <round> [--notify]",
+		description: "list the teams without a round answer",
+		args:        []argSpec{{name: "round", kind: argInt}, {name: "notify", kind: argChoice, choices: []string{"--notify"}, optional: true}},
+		run:         func(a *app, cmdStr string) (fmt.Stringer, error) { return a.CmdMissing(cmdStr) },
+	},
+	"finalize": {
+		usage:       "finalize",
+		description: "run the integrity checks and sign off the game",
+		args:        noArgs,
+		run:         func(a *app, _ string) (fmt.Stringer, error) { return a.CmdFinalize() },
+	},
+	"showQuestion": {
+		usage:       "showQuestion <n>",
+		description: "write the question text to the spreadsheets",
+		args:        []argSpec{{name: "question", kind: argInt}},
+		run:         func(a *app, cmdStr string) (fmt.Stringer, error) { return a.CmdShowQuestion(cmdStr) },
+	},
+	"hideQuestion": {
+		usage:       "hideQuestion <n>",
+		description: "clear the question text from the spreadsheets",
+		args:        []argSpec{{name: "question", kind: argInt}},
+		run:         func(a *app, cmdStr string) (fmt.Stringer, error) { return a.CmdHideQuestion(cmdStr) },
+	},
+	"restore": {
+		usage:       "restore [timestamp]",
+		description: "list the database backups or roll the database back to one",
+		args:        []argSpec{{name: "timestamp", kind: argString, optional: true}},
+		run:         func(a *app, cmdStr string) (fmt.Stringer, error) { return a.CmdRestore(cmdStr) },
+	},
+	"close": {
+		usage:       "close <round> [--check]",
+		description: "lock the round, show the masked answers, fetch them, report the missing ones and optionally check them",
+		args:        []argSpec{{name: "round", kind: argInt}, {name: "check", kind: argChoice, choices: []string{closeCheckFlag}, optional: true}},
+		run:         func(a *app, cmdStr string) (fmt.Stringer, error) { return a.CmdClose(cmdStr) },
+		interactive: closeNeedsCheck,
+	},
+	"projector": {
+		usage:       "projector [question]",
+		description: "show the question number and the top standings in the projector spreadsheet",
+		args:        []argSpec{{name: "question", kind: argInt, optional: true}},
+		run:         func(a *app, cmdStr string) (fmt.Stringer, error) { return a.CmdProjector(cmdStr) },
+	},
+	"vote": {
+		usage:       "vote <round> <team> [<juror> accept|reject]",
+		description: "cast a jury vote on a disputed response or show the tally",
+		args:        []argSpec{{name: "round", kind: argInt}, {name: "team", kind: argString}, {name: "juror", kind: argString, optional: true}, {name: "verdict", kind: argChoice, choices: []string{"accept", "reject"}, optional: true}},
+		run:         func(a *app, cmdStr string) (fmt.Stringer, error) { return a.CmdVote(cmdStr) },
+	},
+	"report": {
+		usage:       "report",
+		description: "write the end-of-game report in Markdown and HTML",
+		args:        noArgs,
+		run:         func(a *app, _ string) (fmt.Stringer, error) { return a.CmdReport() },
+	},
+	"refresh": {
+		usage:       "refresh",
+		description: "drop the cached spreadsheets metadata and values",
+		args:        noArgs,
+		run:         func(a *app, _ string) (fmt.Stringer, error) { return a.CmdRefresh() },
+	},
+	"relink": {
+		usage:       "relink [team]",
+		description: "rebuild the links of the team answers in the manager spreadsheet",
+		args:        []argSpec{{name: "team", kind: argString, optional: true}},
+		run:         func(a *app, cmdStr string) (fmt.Stringer, error) { return a.CmdRelink(cmdStr) },
+	},
+	"checkLinks": {
+		usage:       "checkLinks",
+		description: "report the broken links of the team answers",
+		args:        noArgs,
+		run:         func(a *app, _ string) (fmt.Stringer, error) { return a.CmdCheckLinks() },
+	},
+	"collusion": {
+		usage:       "collusion <round>",
+		description: "report the teams with identical or near-identical wrong answers",
+		args:        roundArgs,
+		run:         func(a *app, cmdStr string) (fmt.Stringer, error) { return a.CmdCollusion(cmdStr) },
+	},
+	"stats": {
+		usage:       "stats [--sheet]",
+		description: "print the per-question statistics and the team streaks, optionally write them to the Stats sheet",
+		args:        []argSpec{{name: "sheet", kind: argChoice, choices: []string{"--sheet"}, optional: true}},
+		run:         func(a *app, cmdStr string) (fmt.Stringer, error) { return a.CmdStats(cmdStr) },
+	},
+	"selftest": {
+		usage:       "selftest",
+		description: "check the token, the spreadsheets access, the team links, the store and the quota headroom",
+		args:        noArgs,
+		run:         func(a *app, _ string) (fmt.Stringer, error) { return a.CmdSelftest() },
+	},
+	"pause": {
+		usage:       "pause [HH:MM]",
+		description: "protect the team spreadsheets for a break, announcing the time the game is back at",
+		args:        []argSpec{{name: "time", kind: argString, optional: true}},
+		run:         func(a *app, cmdStr string) (fmt.Stringer, error) { return a.CmdPause(cmdStr) },
+	},
+	"resume": {
+		usage:       "resume",
+		description: "remove the protection of the team spreadsheets added by pause",
+		args:        noArgs,
+		run:         func(a *app, cmdStr string) (fmt.Stringer, error) { return a.CmdResume() },
+	},
+	"open": {
+		usage:       "open <round>",
+		description: "reveal the question column in the team spreadsheets",
+		args:        roundArgs,
+		run:         func(a *app, cmdStr string) (fmt.Stringer, error) { return a.CmdOpen(cmdStr) },
+	},
+	"note": {
+		usage:       "note <round> <text>",
+		description: "add a jury note to the round",
+		args:        []argSpec{{name: "round", kind: argInt}, {name: "text", kind: argString, variadic: true}},
+		run:         func(a *app, cmdStr string) (fmt.Stringer, error) { return a.CmdNote(cmdStr) },
+	},
+	"void": {
+		usage:       "void <round> [all|none|off]",
+		description: "remove the question, every team or no team gets the point",
+		args:        []argSpec{{name: "round", kind: argInt}, {name: "points", kind: argChoice, choices: []string{roundVoidAll, roundVoidNone, "off"}, optional: true}},
+		run:         func(a *app, cmdStr string) (fmt.Stringer, error) { return a.CmdVoid(cmdStr) },
+	},
+	"adjust": {
+		usage:       "adjust <team> <+/-points> <reason>",
+		description: "add points to the team score or take them away, e.g. a penalty",
+		args:        []argSpec{{name: "team", kind: argString}, {name: "points", kind: argString}, {name: "reason", kind: argString, variadic: true}},
+		run:         func(a *app, cmdStr string) (fmt.Stringer, error) { return a.CmdAdjust(cmdStr) },
+	},
+	"share": {
+		usage:       "share [team]",
+		description: "share the team spreadsheets with the configured accounts again and protect the answers so that only the captains can edit them",
+		args:        []argSpec{{name: "team", kind: argString, optional: true, variadic: true}},
+		run:         func(a *app, cmdStr string) (fmt.Stringer, error) { return a.CmdShare(cmdStr) },
+	},
+	"audit": {
+		usage:       "audit [round|team]",
+		description: "show the executed commands with their time, user and changes, only the ones concerning the round or the team if given",
+		args:        []argSpec{{name: "round|team", kind: argString, optional: true, variadic: true}},
+		run:         func(a *app, cmdStr string) (fmt.Stringer, error) { return a.CmdAudit(cmdStr) },
+	},
+	"adjustments": {
+		usage:       "adjustments",
+		description: "list the score adjustments with their time and reason",
+		args:        noArgs,
+		run:         func(a *app, _ string) (fmt.Stringer, error) { return a.CmdAdjustments() },
+	},
+	"status": {
+		usage:       "status",
+		description: "show the progress of the game",
+		args:        noArgs,
+		run:         func(a *app, _ string) (fmt.Stringer, error) { return a.CmdStatus() },
+	},
+	"total": {
+		usage:       "total",
+		description: "print the teams totals",
+		args:        noArgs,
+		run:         func(a *app, _ string) (fmt.Stringer, error) { return a.CmdGetTotal() },
+	},
+}
+
+type errorUnknownCommand struct {
+	cmd string
+}
+
+func (e *errorUnknownCommand) Error() string {
+	if len(e.cmd) == 0 {
+		return tr("error.emptyCommand")
+	}
+	return tr("error.unknownCommand", e.cmd)
+}
+
+type errorInteractiveCommand struct {
+	cmd string
+}
+
+func (e *errorInteractiveCommand) Error() string {
+	return tr("error.interactiveCommand", e.cmd)
+}
+
+// errorBusy refuses a command that changes the game while an interactive
+// command runs in the REPL.
+type errorBusy struct {
+	cmd         string
+	interactive string
+}
+
+func (e *errorBusy) Error() string {
+	return tr("error.busy", e.cmd, e.interactive)
+}
+
+func (a *app) setInteractiveCommand(cmd string) {
+	a.engineMu.Lock()
+	defer a.engineMu.Unlock()
+	a.interactiveCmd = cmd
+}
+
+func init() {
+	// help and reload are registered here as they refer to the commands map
+	commands["help"] = &command{
+		usage:       "help [command]",
+		description: "list the commands, their arguments and aliases",
+		args:        []argSpec{{name: "command", kind: argString, optional: true}},
+		run:         func(a *app, cmdStr string) (fmt.Stringer, error) { return a.CmdHelp(cmdStr) },
+	}
+	commands["reload"] = &command{
+		usage:       "reload",
+		description: "parse the configuration file again and apply the changes that are safe during the game, e.g. the labels, the accepted answers or the aliases",
+		args:        noArgs,
+		run:         func(a *app, _ string) (fmt.Stringer, error) { return a.CmdReload() },
+	}
+}
+
+// resolveAlias replaces the alias the command starts with by its target.
+func (a *app) resolveAlias(cmdStr string) string {
+	cmd := getCommand(cmdStr)
+	target, ok := a.config.Aliases[cmd]
+	if !ok {
+		return cmdStr
+	}
+	return target + strings.TrimPrefix(cmdStr, cmd)
+}
+
+// lookupCommand returns the command and checks its arguments.
+func (a *app) lookupCommand(cmdStr string) (*command, error) {
+	cmd := getCommand(cmdStr)
+	c, ok := commands[cmd]
+	if !ok {
+		return nil, &errorUnknownCommand{cmd: cmd}
+	}
+	if a.config.ReadOnly && !readOnlyCommands[cmd] {
+		return nil, &errorReadOnly{cmd: cmd}
+	}
+	if err := c.checkArgs(cmd, cmdStr); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// isInteractive reports whether the command reads from the standard input.
+func (a *app) isInteractive(cmdStr string) bool {
+	cmdStr = a.resolveAlias(cmdStr)
+	c, err := a.lookupCommand(cmdStr)
+	if err != nil {
+		return false
+	}
+	return c.interactive != nil && c.interactive(a, cmdStr)
+}
+
+// execute runs the command. The non-interactive commands are serialized, as
+// they can be run concurrently from the REPL and the API.
+func (a *app) execute(cmdStr string) (fmt.Stringer, error) {
+	return a.executeContext(context.Background(), cmdStr)
+}
+
+// executeContext runs the command, the API calls of a non-interactive command
+// are cancelled with ctx.
+func (a *app) executeContext(ctx context.Context, cmdStr string) (fmt.Stringer, error) {
+	return a.executeFrom(ctx, cmdStr, auditOriginREPL)
+}
+
+// executeFrom runs the command and records it in the audit log along with its
+// origin, the REPL or the API.
+func (a *app) executeFrom(ctx context.Context, cmdStr string, origin string) (res fmt.Stringer, err error) {
+	cmdStr = a.resolveAlias(cmdStr)
+	c, err := a.lookupCommand(cmdStr)
+	if err != nil {
+		return nil, err
+	}
+	a.recordCommand(cmdStr)
+	if c.interactive != nil && c.interactive(a, cmdStr) {
+		// the interactive command waits for the running API command, the
+		// API commands that change the game are refused until it ends
+		a.setInteractiveCommand(getCommand(cmdStr))
+		defer a.setInteractiveCommand("")
+		entry, snapshot := a.startAudit(cmdStr, origin)
+		defer func() { a.finishAudit(entry, snapshot, err) }()
+		return c.run(a, cmdStr)
+	}
+	a.engineMu.Lock()
+	defer a.engineMu.Unlock()
+	if len(a.interactiveCmd) != 0 && !readOnlyCommands[getCommand(cmdStr)] {
+		return nil, &errorBusy{cmd: getCommand(cmdStr), interactive: a.interactiveCmd}
+	}
+	entry, snapshot := a.startAudit(cmdStr, origin)
+	defer func() { a.finishAudit(entry, snapshot, err) }()
+	a.setCommandContext(ctx)
+	defer a.setCommandContext(nil)
+	return c.run(a, cmdStr)
+}
+
+// executeNonInteractive runs the command unless it is interactive.
+func (a *app) executeNonInteractive(cmdStr string) (fmt.Stringer, error) {
+	cmdStr = a.resolveAlias(cmdStr)
+	c, err := a.lookupCommand(cmdStr)
+	if err != nil {
+		return nil, err
+	}
+	if c.interactive != nil && c.interactive(a, cmdStr) {
+		return nil, &errorInteractiveCommand{cmd: getCommand(cmdStr)}
+	}
+	return a.executeFrom(context.Background(), cmdStr, auditOriginAPI)
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"os"
+
+	"google.golang.org/api/googleapi"
+)
+
+var (
+	errRoundNotFound error = &errorMessage{key: "error.roundNotFound"}
+	errQuotaExceeded error = &errorMessage{key: "error.quotaExceeded"}
+	errNotAuthorized error = &errorMessage{key: "error.notAuthorized"}
+)
+
+// The process exit codes, so that the scripts running the tool can tell the
+// failures apart.
+const (
+	exitCodeError         = 1
+	exitCodeInvalidConfig = 2
+	exitCodeNotAuthorized = 3
+	exitCodeQuotaExceeded = 4
+	exitCodeRoundNotFound = 5
+)
+
+type errorRoundNotFound struct {
+	round int
+}
+
+func (e *errorRoundNotFound) Error() string {
+	return tr("error.roundNumberNotFound", e.round)
+}
+
+func (e *errorRoundNotFound) Is(target error) bool {
+	return target == errRoundNotFound
+}
+
+// errorClass returns the class of the error: one of the sentinel errors or
+// nil. The Google API errors are classified by their HTTP status.
+func errorClass(err error) error {
+	for _, class := range []error{errRoundNotFound, errQuotaExceeded, errNotAuthorized} {
+		if errors.Is(err, class) {
+			return class
+		}
+	}
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		switch apiErr.Code {
+		case http.StatusTooManyRequests:
+			return errQuotaExceeded
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return errNotAuthorized
+		}
+	}
+	return nil
+}
+
+func exitCode(err error) int {
+	var configErr *errorInvalidConfig
+	if errors.As(err, &configErr) {
+		return exitCodeInvalidConfig
+	}
+	switch errorClass(err) {
+	case errNotAuthorized:
+		return exitCodeNotAuthorized
+	case errQuotaExceeded:
+		return exitCodeQuotaExceeded
+	case errRoundNotFound:
+		return exitCodeRoundNotFound
+	default:
+		return exitCodeError
+	}
+}
+
+// exit logs the error and terminates the process with the exit code of the
+// error class.
+func exit(err error) {
+	log.Printf("[ERR]: %v", err)
+	os.Exit(exitCode(err))
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+const (
+	eventTypeAnswers  = "answers"
+	eventTypeStatuses = "statuses"
+)
+
+// eventBufferSize is the number of events a slow subscriber may lag behind,
+// the events beyond it are dropped for the subscriber.
+const eventBufferSize = 64
+
+// dashboardEvent is pushed to the dashboard when the round answers are
+// fetched or their statuses change.
+type dashboardEvent struct {
+	Type    string                       `json:"type"`
+	Game    string                       `json:"game"`
+	Round   int                          `json:"round"`
+	Time    time.Time                    `json:"time"`
+	Results map[string]roundResponseView `json:"results"`
+}
+
+// eventHub fans the game events out to the subscribers.
+type eventHub struct {
+	mu          sync.Mutex
+	subscribers map[chan *dashboardEvent]struct{}
+}
+
+func newEventHub() *eventHub {
+	return &eventHub{subscribers: make(map[chan *dashboardEvent]struct{})}
+}
+
+// subscribe returns the channel of the events and the function that stops the
+// subscription.
+func (h *eventHub) subscribe() (<-chan *dashboardEvent, func()) {
+	ch := make(chan *dashboardEvent, eventBufferSize)
+	h.mu.Lock()
+	h.subscribers[ch] = struct{}{}
+	h.mu.Unlock()
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if _, ok := h.subscribers[ch]; ok {
+			delete(h.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+func (h *eventHub) publish(e *dashboardEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subscribers {
+		select {
+		case ch <- e:
+		default:
+			log.Printf("[ERR]: a dashboard subscriber lags behind, the round %d %s event is dropped for it", e.Round, e.Type)
+		}
+	}
+}
+
+// publishResults notifies the dashboard of the stored round results.
+func (a *app) publishResults(eventType string, results *roundResults) {
+	if a.isEmbargoed(results.Round) {
+		return
+	}
+	view := a.numberResults(results).outputView().(*roundResultsView)
+	a.events.publish(&dashboardEvent{
+		Type:    eventType,
+		Game:    a.config.GameName,
+		Round:   results.question(),
+		Time:    time.Now(),
+		Results: view.Results,
+	})
+}
+
+// handleEvents streams the game events to a WebSocket client as JSON
+// messages.
+func (a *app) handleEvents() http.Handler {
+	return websocket.Handler(func(ws *websocket.Conn) {
+		defer ws.Close()
+		events, cancel := a.events.subscribe()
+		defer cancel()
+		// the client sends nothing, a failed read means it is gone
+		go func() {
+			var msg string
+			for websocket.Message.Receive(ws, &msg) == nil {
+			}
+			cancel()
+		}()
+		for e := range events {
+			if err := websocket.JSON.Send(ws, e); err != nil {
+				return
+			}
+		}
+	})
+}
+
+func (a *app) handleDashboard(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(dashboardPage))
+}
+
+// dashboardPage shows the latest answers and statuses of every round, it is
+// updated by the events of the /events WebSocket.
+const dashboardPage = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Jury dashboard</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+td, th { border: 1px solid #ccc; padding: 2px 8px; }
+</style>
+</head>
+<body>
+<div id="rounds"></div>
+<script>
+var rounds = {};
+function render() {
+  var html = "";
+  Object.keys(rounds).sort(function(a, b) { return b - a; }).forEach(function(round) {
+    html += "<h3>Round " + round + "</h3><table><tr><th>Team</th><th>Answer</th><th>Status</th></tr>";
+    var results = rounds[round];
+    Object.keys(results).sort().forEach(function(team) {
+      var div = document.createElement("div");
+      div.textContent = results[team].response;
+      var t = document.createElement("div");
+      t.textContent = team;
+      html += "<tr><td>" + t.innerHTML + "</td><td>" + div.innerHTML + "</td><td>" + results[team].status + "</td></tr>";
+    });
+    html += "</table>";
+  });
+  document.getElementById("rounds").innerHTML = html;
+}
+function connect() {
+  var ws = new WebSocket((location.protocol === "https:" ? "wss://" : "ws://") + location.host + "/events");
+  ws.onmessage = function(msg) {
+    var e = JSON.parse(msg.data);
+    rounds[e.round] = e.results;
+    render();
+  };
+  ws.onclose = function() { setTimeout(connect, 2000); };
+}
+connect();
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+type fetchDirectResult struct {
+	Results *roundResults `json:"results" yaml:"results"`
+	// Discrepancies are the teams whose answer in the manager spreadsheet
+	// differs from the one in their spreadsheet.
+	Discrepancies []crossCheckMismatch `json:"discrepancies" yaml:"discrepancies"`
+}
+
+func (r *fetchDirectResult) outputView() interface{} {
+	return &struct {
+		Results       interface{}          `json:"results" yaml:"results"`
+		Discrepancies []crossCheckMismatch `json:"discrepancies" yaml:"discrepancies"`
+	}{
+		Results:       r.Results.outputView(),
+		Discrepancies: r.Discrepancies,
+	}
+}
+
+func (r *fetchDirectResult) String() string {
+	var sb strings.Builder
+	sb.WriteString(r.Results.String())
+	if len(r.Discrepancies) == 0 {
+		sb.WriteString("The manager spreadsheet is in sync with the teams spreadsheets\n")
+		return sb.String()
+	}
+	sb.WriteString(fmt.Sprintf("%d discrepancy(ies) with the manager spreadsheet, check them:\n", len(r.Discrepancies)))
+	sb.WriteString((&crossCheckResult{Round: r.Results.question(), Mismatches: r.Discrepancies}).String())
+	return sb.String()
+}
+
+// CmdFetchDirect fetches the round answers from the team spreadsheets instead
+// of the manager spreadsheet, as IMPORTRANGE may lag or fail silently. The
+// manager spreadsheet is read at the same time and the answers that differ
+// are reported: "fetchDirect <round>".
+func (a *app) CmdFetchDirect(cmdStr string) (*fetchDirectResult, error) {
+	round, err := a.getRoundNumber(cmdStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse fetchDirect request: %w", err)
+	}
+	if err := a.checkRoundClosed(round); err != nil {
+		return nil, err
+	}
+	var wg sync.WaitGroup
+	var managerResults map[string]string
+	var managerErr error
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		managerResults, _, managerErr = a.fetchRoundResults(round)
+	}()
+	teamsResults, quarantined, err := a.fetchTeamsRoundResults(round)
+	wg.Wait()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch round results from the teams spreadsheets: %w", err)
+	}
+	if managerErr != nil {
+		return nil, fmt.Errorf("failed to fetch round results from the manager spreadsheet: %w", managerErr)
+	}
+	res := &fetchDirectResult{Discrepancies: make([]crossCheckMismatch, 0)}
+	for _, team := range a.config.Teams {
+		teamResp, ok := teamsResults[team]
+		if !ok {
+			// quarantined
+			continue
+		}
+		if managerResults[team] != teamResp {
+			res.Discrepancies = append(res.Discrepancies, crossCheckMismatch{
+				Team:            team,
+				ManagerResponse: managerResults[team],
+				TeamResponse:    teamResp,
+			})
+		}
+	}
+	if res.Results, err = a.storeFetchedResults(round, teamsResults, quarantined); err != nil {
+		return nil, err
+	}
+	if len(res.Discrepancies) != 0 {
+		teams := make([]string, len(res.Discrepancies))
+		for i, d := range res.Discrepancies {
+			teams[i] = d.Team
+		}
+		if err := a.store.appendEvent(fmt.Sprintf("fetchDirect %d: manager spreadsheet differs for %s", round, strings.Join(teams, ", "))); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
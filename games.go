@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const archivedGamesDir = "archived-games"
+
+type gameInfo struct {
+	Dir          string    `json:"dir" yaml:"dir"`
+	LastActivity time.Time `json:"lastActivity" yaml:"lastActivity"`
+	Stale        bool      `json:"stale" yaml:"stale"`
+	Archived     bool      `json:"archived,omitempty" yaml:"archived,omitempty"`
+}
+
+type gamesResult struct {
+	Workspace string     `json:"workspace" yaml:"workspace"`
+	Games     []gameInfo `json:"games" yaml:"games"`
+}
+
+func (r *gamesResult) String() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Games in %s:\n", r.Workspace))
+	for _, g := range r.Games {
+		status := "active"
+		if g.Archived {
+			status = "archived"
+		} else if g.Stale {
+			status = "stale"
+		}
+		sb.WriteString(fmt.Sprintf("\t%s: last activity %s, %s\n", g.Dir, g.LastActivity.Format("2006-01-02 15:04"), status))
+	}
+	return sb.String()
+}
+
+// CmdGames manages the games of the workspace, the directory that contains the
+// output dir of the current game: "games list [workspace]" and
+// "games gc [--apply] [workspace]". The stale games, the ones without activity
+// for StaleAfterDays, are moved to the archived-games directory of the
+// workspace by gc with --apply, otherwise they are only listed.
+func (a *app) CmdGames(cmdStr string) (*gamesResult, error) {
+	sSplitted := splitArgs(cmdStr)
+	if len(sSplitted) < 2 {
+		return nil, fmt.Errorf("expected a subcommand, list or gc")
+	}
+	subcommand := sSplitted[1]
+	apply := false
+	workspace := path.Dir(path.Clean(a.config.OutputDir))
+	for _, arg := range sSplitted[2:] {
+		if arg == "--apply" && subcommand == "gc" {
+			apply = true
+			continue
+		}
+		workspace = arg
+	}
+	games, err := a.listWorkspaceGames(workspace)
+	if err != nil {
+		return nil, err
+	}
+	switch subcommand {
+	case "list":
+	case "gc":
+		if !apply {
+			break
+		}
+		for i := range games {
+			if !games[i].Stale {
+				continue
+			}
+			if err := archiveGameDir(workspace, games[i].Dir); err != nil {
+				return nil, err
+			}
+			games[i].Archived = true
+		}
+	default:
+		return nil, fmt.Errorf("unknown games subcommand %s, expected list or gc", subcommand)
+	}
+	return &gamesResult{Workspace: workspace, Games: games}, nil
+}
+
+// listWorkspaceGames returns the games found in the subdirectories of the
+// workspace, the current game is never reported as stale.
+func (a *app) listWorkspaceGames(workspace string) ([]gameInfo, error) {
+	entries, err := ioutil.ReadDir(workspace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the workspace %s: %w", workspace, err)
+	}
+	current, err := filepath.Abs(a.config.OutputDir)
+	if err != nil {
+		return nil, err
+	}
+	games := make([]gameInfo, 0)
+	for _, entry := range entries {
+		if !entry.IsDir() || entry.Name() == archivedGamesDir {
+			continue
+		}
+		dir := path.Join(workspace, entry.Name())
+		dbFile := path.Join(dir, "bolt-db")
+		if _, err := os.Stat(dbFile); err != nil {
+			continue
+		}
+		last, err := (&boltManager{dbFile: dbFile}).lastActivity()
+		if err != nil {
+			log.Printf("[ERR]: failed to read the game in %s: %v", dir, err)
+			continue
+		}
+		absDir, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, err
+		}
+		games = append(games, gameInfo{
+			Dir:          entry.Name(),
+			LastActivity: last,
+			Stale:        absDir != current && time.Since(last) > a.config.staleAfter(),
+		})
+	}
+	sort.Slice(games, func(i, j int) bool {
+		return games[i].LastActivity.Before(games[j].LastActivity)
+	})
+	return games, nil
+}
+
+func archiveGameDir(workspace string, dir string) error {
+	archivedDir := path.Join(workspace, archivedGamesDir)
+	if err := os.MkdirAll(archivedDir, 0700); err != nil {
+		return fmt.Errorf("failed to create the archived games directory: %w", err)
+	}
+	if err := os.Rename(path.Join(workspace, dir), path.Join(archivedDir, dir)); err != nil {
+		return fmt.Errorf("failed to archive the game %s: %w", dir, err)
+	}
+	log.Printf("archived the game %s to %s", dir, archivedDir)
+	return nil
+}
+
+// warnIfStale suggests archiving the current game if it has not been active
+// for StaleAfterDays.
+func (a *app) warnIfStale() {
+	b, err := a.boltStore()
+	if err != nil {
+		// the activity of the other stores is not tracked
+		return
+	}
+	last, err := b.lastActivity()
+	if err != nil {
+		log.Printf("[ERR]: failed to check the last game activity: %v", err)
+		return
+	}
+	if time.Since(last) <= a.config.staleAfter() {
+		return
+	}
+	fmt.Printf("The game has not been active since %s, it has probably finished long ago.\n", last.Format("2006-01-02"))
+	fmt.Println("Consider saving it with \"archive save <file>\" and tidying up the workspace with \"games gc\".")
+}
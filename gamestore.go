@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"path"
+	"strings"
+	"time"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+// gameStore persists the game state. The bolt store is the default one, the
+// SQL store keeps the results in tables suitable for later analysis.
+type gameStore interface {
+	saveSpreadsheets(req *storeGameSpreadsheets) error
+	saveTeamsSpreadsheets(req *storeGameSpreadsheets) error
+	archiveTeamSpreadsheet(team string) error
+	getSpreadsheets() (*storeGameSpreadsheets, error)
+	saveTeams(teams []string) error
+	getTeams() ([]string, error)
+	saveDriveFolder(folder string) error
+	getDriveFolder() (string, error)
+	saveProjectorSpreadsheet(projector *sheets.Spreadsheet) error
+	getProjectorSpreadsheet() (*storeSpreadsheet, error)
+	saveRoundResults(req *roundResults) error
+	getRoundResults(round int) (*roundResults, error)
+	getAllRoundResults() ([]*roundResults, error)
+	saveVerdicts(checked *roundResults) (*roundResults, error)
+	markSetupStep(step string) error
+	getSetupSteps() (map[string]bool, error)
+	saveRoundLocks(round int, locks map[string]int64) error
+	getRoundLocks(round int) (map[string]int64, error)
+	saveClosedRound(round int, closedAt time.Time) error
+	getClosedRounds() (map[int]time.Time, error)
+	saveCheckProgress(round int, progress *checkProgress) error
+	getCheckProgress(round int) (*checkProgress, error)
+	saveVotes(round int, team string, votes *roundVotes) error
+	getVotes(round int, team string) (*roundVotes, error)
+	saveRoundMeta(round int, meta *roundMeta) error
+	getRoundMeta(round int) (*roundMeta, error)
+	getAllRoundMeta() (map[int]*roundMeta, error)
+	saveResponseRevisions(round int, responses map[string]string, fetchedAt time.Time) error
+	getResponseHistory(round int, team string) ([]responseRevision, error)
+	saveShootout(shootout *shootoutState) error
+	getShootout() (*shootoutState, error)
+	saveStandingsSnapshot(snapshot *standingsSnapshot) error
+	getStandingsSnapshot() (*standingsSnapshot, error)
+	saveAdjustment(adj scoreAdjustment) error
+	getAdjustments() ([]scoreAdjustment, error)
+	saveAuditEntry(entry *auditEntry) error
+	getAuditEntries() ([]*auditEntry, error)
+	appendEvent(message string) error
+	cacheStore
+	// reset deletes all the game data.
+	reset() error
+	close() error
+}
+
+const (
+	storeKindBolt   = "bolt"
+	storeKindSQLite = "sqlite"
+)
+
+// parseStoreFlag parses the store flag: "bolt" or "sqlite:<path>".
+func parseStoreFlag(store string) (kind string, dsn string, err error) {
+	if len(store) == 0 || store == storeKindBolt {
+		return storeKindBolt, "", nil
+	}
+	sSplitted := strings.SplitN(store, ":", 2)
+	if sSplitted[0] != storeKindSQLite || len(sSplitted) != 2 || len(sSplitted[1]) == 0 {
+		return "", "", fmt.Errorf("unknown store %s, expected %s or %s:<path>", store, storeKindBolt, storeKindSQLite)
+	}
+	return storeKindSQLite, sSplitted[1], nil
+}
+
+// boltStore returns the bolt store for the commands that maintain the bolt
+// database itself.
+func (a *app) boltStore() (*boltManager, error) {
+	b, ok := a.store.(*boltManager)
+	if !ok {
+		return nil, fmt.Errorf("the command needs the bolt store, the game uses %s", a.config.Store)
+	}
+	return b, nil
+}
+
+// newGameStore opens the store selected by the store flag.
+func newGameStore(config *Config) (gameStore, error) {
+	kind, dsn, err := parseStoreFlag(config.Store)
+	if err != nil {
+		return nil, err
+	}
+	if kind == storeKindSQLite {
+		s, err := newSQLStore(sqliteDriver, dsn, config.ReadOnly)
+		if err != nil {
+			return nil, err
+		}
+		s.numbering = config.questionNumber
+		return s, nil
+	}
+	b := &boltManager{
+		dbFile:      path.Join(config.OutputDir, "bolt-db"),
+		journalSize: config.JournalSize,
+		backupDir:   path.Join(config.OutputDir, "backups"),
+		backupCount: config.backupCount(),
+		readOnly:    config.ReadOnly,
+		numbering:   config.questionNumber,
+	}
+	if err := b.open(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// close closes the game store.
+func (a *app) close() {
+	if err := a.store.close(); err != nil {
+		log.Printf("[ERR]: failed to close the game store: %v", err)
+	}
+}
@@ -3,7 +3,13 @@ module github.com/SergeyShpak/chgk-google-sheets
 go 1.14
 
 require (
+	github.com/mattn/go-sqlite3 v1.14.6
 	go.etcd.io/bbolt v1.3.4
+	golang.org/x/crypto v0.23.0
+	golang.org/x/image v0.18.0
+	golang.org/x/net v0.25.0
 	golang.org/x/oauth2 v0.0.0-20200107190931-bf48bf16ab8d
+	golang.org/x/text v0.16.0
 	google.golang.org/api v0.21.0
+	gopkg.in/yaml.v2 v2.4.0
 )
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+const (
+	// tokenRefreshAhead is how long before its expiry the access token is
+	// refreshed by the background refresh.
+	tokenRefreshAhead = 10 * time.Minute
+	// tokenRefreshInterval is the period of the background token refresh.
+	tokenRefreshInterval = time.Minute
+)
+
+// tokenKeeper is the token source of the API clients. Unlike the default
+// source it refreshes the access token before it expires, saves the refreshed
+// token into the game dir and remembers the last refresh error, so that an
+// authorization problem is reported before a fetch fails.
+type tokenKeeper struct {
+	mu     sync.Mutex
+	config *oauth2.Config
+	token  *oauth2.Token
+	// dir is the directory of the saved token.
+	dir string
+	err error
+}
+
+func newTokenKeeper(config *oauth2.Config, token *oauth2.Token, dir string) *tokenKeeper {
+	return &tokenKeeper{config: config, token: token, dir: dir}
+}
+
+func (k *tokenKeeper) Token() (*oauth2.Token, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.token.Valid() {
+		return k.token, nil
+	}
+	return k.refreshLocked()
+}
+
+func (k *tokenKeeper) refreshLocked() (*oauth2.Token, error) {
+	expired := &oauth2.Token{RefreshToken: k.token.RefreshToken}
+	tok, err := k.config.TokenSource(context.Background(), expired).Token()
+	if err != nil {
+		k.err = err
+		return nil, fmt.Errorf("failed to refresh the authorization: %w", err)
+	}
+	if len(tok.RefreshToken) == 0 {
+		tok.RefreshToken = k.token.RefreshToken
+	}
+	if k.err != nil {
+		log.Printf("the authorization is refreshed")
+	}
+	k.token, k.err = tok, nil
+	if err := saveGameToken(k.dir, &gameToken{Token: *tok, Scopes: k.config.Scopes}); err != nil {
+		log.Printf("[ERR]: failed to save the refreshed token: %v", err)
+	}
+	return tok, nil
+}
+
+// refreshAhead refreshes the access token if it expires soon.
+func (k *tokenKeeper) refreshAhead() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if !k.token.Expiry.IsZero() && time.Until(k.token.Expiry) > tokenRefreshAhead && k.err == nil {
+		return
+	}
+	if _, err := k.refreshLocked(); err != nil {
+		log.Printf("[ERR]: %v, the Google API requests will fail: run the game again to renew the authorization", err)
+	}
+}
+
+// keepFresh refreshes the access token in the background for the sessions
+// that outlive it.
+func (k *tokenKeeper) keepFresh() {
+	ticker := time.NewTicker(tokenRefreshInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		k.refreshAhead()
+	}
+}
+
+// status returns the expiry of the access token and the last refresh error.
+func (k *tokenKeeper) status() (time.Time, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.token.Expiry, k.err
+}
+
+// healthState is the result of the latest health check of the game.
+type healthState struct {
+	mu      sync.Mutex
+	checked time.Time
+	err     error
+}
+
+func (h *healthState) set(err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checked = time.Now()
+	h.err = err
+}
+
+func (h *healthState) get() (time.Time, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.checked, h.err
+}
+
+// watchHealth checks on a timer that the manager spreadsheet is readable, so
+// that the jury is warned before the next fetch breaks.
+func (a *app) watchHealth() {
+	interval := a.config.healthCheckInterval()
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		a.checkHealth()
+	}
+}
+
+func (a *app) checkHealth() {
+	gameSheets, err := a.GetGameSpreadsheets()
+	if err != nil || gameSheets.manager == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), a.config.callTimeout())
+	defer cancel()
+	_, err = a.service.Spreadsheets.Get(gameSheets.manager.ID).Fields("spreadsheetId").Context(ctx).Do()
+	_, previous := a.health.get()
+	a.health.set(err)
+	switch {
+	case err != nil && previous == nil:
+		fmt.Printf("\n[WARN]: the health check failed, the next fetch may fail: %v\n", err)
+	case err == nil && previous != nil:
+		fmt.Printf("\nThe health check passed again\n")
+	}
+}
+
+// healthIndicator is shown in the prompt: the offline mode, the authorization
+// errors, the access token about to expire and the failed health check.
+func (a *app) healthIndicator() string {
+	indicator := a.conn.indicator()
+	if a.tokens != nil {
+		expiry, err := a.tokens.status()
+		switch {
+		case err != nil:
+			indicator += " [auth error]"
+		case !expiry.IsZero() && time.Until(expiry) <= 0:
+			indicator += " [token expired]"
+		case !expiry.IsZero() && time.Until(expiry) < tokenRefreshAhead/2:
+			indicator += fmt.Sprintf(" [token expires in %s]", time.Until(expiry).Round(time.Minute))
+		}
+	}
+	if _, err := a.health.get(); err != nil && a.conn.isOnline() {
+		indicator += " [health check failed]"
+	}
+	return indicator
+}
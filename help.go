@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type commandHelp struct {
+	Name        string    `json:"name" yaml:"name"`
+	Usage       string    `json:"usage" yaml:"usage"`
+	Description string    `json:"description" yaml:"description"`
+	Aliases     []string  `json:"aliases,omitempty" yaml:"aliases,omitempty"`
+	Arguments   []argHelp `json:"arguments,omitempty" yaml:"arguments,omitempty"`
+}
+
+type argHelp struct {
+	Name     string   `json:"name" yaml:"name"`
+	Type     string   `json:"type" yaml:"type"`
+	Choices  []string `json:"choices,omitempty" yaml:"choices,omitempty"`
+	Optional bool     `json:"optional,omitempty" yaml:"optional,omitempty"`
+}
+
+func (h argHelp) String() string {
+	s := fmt.Sprintf("%s: %s", h.Name, h.Type)
+	if len(h.Choices) != 0 {
+		s += " " + strings.Join(h.Choices, "|")
+	}
+	if h.Optional {
+		s += tr("help.optional")
+	}
+	return s
+}
+
+type helpResult struct {
+	Commands []commandHelp `json:"commands" yaml:"commands"`
+	// single is set when the help on one command is asked, its arguments
+	// are listed then.
+	single bool
+}
+
+func (r *helpResult) String() string {
+	var sb strings.Builder
+	if r.single {
+		c := r.Commands[0]
+		sb.WriteString(fmt.Sprintf("%s\n\t%s", c.Usage, c.Description))
+		if len(c.Aliases) != 0 {
+			sb.WriteString(tr("help.aliases", strings.Join(c.Aliases, ", ")))
+		}
+		sb.WriteString("\n")
+		for _, arg := range c.Arguments {
+			sb.WriteString(fmt.Sprintf("\t\t%s\n", arg))
+		}
+		return sb.String()
+	}
+	sb.WriteString(tr("help.commands") + "\n")
+	for _, c := range r.Commands {
+		sb.WriteString(fmt.Sprintf("\t%s\n\t\t%s", c.Usage, c.Description))
+		if len(c.Aliases) != 0 {
+			sb.WriteString(tr("help.aliases", strings.Join(c.Aliases, ", ")))
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString(fmt.Sprintf("\texit\n\t\t%s\n", tr("help.exit")))
+	return sb.String()
+}
+
+// CmdHelp lists the commands: "help [command]". With the command, its
+// arguments are described as well.
+func (a *app) CmdHelp(cmdStr string) (*helpResult, error) {
+	aliases := make(map[string][]string)
+	for alias, target := range a.config.Aliases {
+		cmd := getCommand(target)
+		aliases[cmd] = append(aliases[cmd], alias)
+	}
+	if args := splitArgs(cmdStr); len(args) == 2 {
+		name := args[1]
+		if target, ok := a.config.Aliases[name]; ok {
+			name = getCommand(target)
+		}
+		c, ok := commands[name]
+		if !ok {
+			return nil, &errorUnknownCommand{cmd: name}
+		}
+		sort.Strings(aliases[name])
+		h := commandHelp{
+			Name:        name,
+			Usage:       c.usage,
+			Description: commandDescription(name, c),
+			Aliases:     aliases[name],
+		}
+		for _, spec := range c.args {
+			h.Arguments = append(h.Arguments, argHelp{
+				Name:     spec.name,
+				Type:     spec.kind.String(),
+				Choices:  spec.choices,
+				Optional: spec.optional,
+			})
+		}
+		return &helpResult{Commands: []commandHelp{h}, single: true}, nil
+	}
+	res := &helpResult{Commands: make([]commandHelp, 0, len(commands))}
+	for name, c := range commands {
+		sort.Strings(aliases[name])
+		res.Commands = append(res.Commands, commandHelp{
+			Name:        name,
+			Usage:       c.usage,
+			Description: commandDescription(name, c),
+			Aliases:     aliases[name],
+		})
+	}
+	sort.Slice(res.Commands, func(i, j int) bool {
+		return res.Commands[i].Name < res.Commands[j].Name
+	})
+	return res, nil
+}
+
+// checkAliases checks that the aliases do not shadow the commands and refer
+// to existing commands.
+func checkAliases(aliases map[string]string) []string {
+	var problems []string
+	for alias, target := range aliases {
+		if len(strings.TrimSpace(alias)) == 0 || strings.Contains(alias, " ") {
+			problems = append(problems, fmt.Sprintf("alias \"%s\" must be a single word", alias))
+			continue
+		}
+		if _, ok := commands[alias]; ok || alias == "exit" {
+			problems = append(problems, fmt.Sprintf("alias %s shadows the command with the same name", alias))
+		}
+		if _, ok := commands[getCommand(target)]; !ok {
+			problems = append(problems, fmt.Sprintf("alias %s refers to the unknown command %s", alias, getCommand(target)))
+		}
+	}
+	sort.Strings(problems)
+	return problems
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+const historySheetTitle = "History"
+
+// updateHistory writes the round answers into the history sheet of the
+// manager spreadsheet: one row per team and one column per question, the
+// answers are colored by their status.
+func (a *app) updateHistory(results *roundResults) error {
+	gameSheets, err := a.GetGameSpreadsheets()
+	if err != nil {
+		return err
+	}
+	if gameSheets.manager == nil {
+		return errManagerSpreadsheetNotFound
+	}
+	sheetID, err := a.ensureHistorySheet(gameSheets.manager.ID)
+	if err != nil {
+		return err
+	}
+	column := int64(results.Round + 1)
+	if a.config.HasWarmUpQuestion {
+		column++
+	}
+	rows := make([]*sheets.RowData, 0, len(a.config.Teams)+1)
+	rows = append(rows, &sheets.RowData{Values: []*sheets.CellData{stringCell(fmt.Sprint(a.config.questionNumber(results.Round)))}})
+	for _, team := range a.config.Teams {
+		cell := stringCell("")
+		if resp, ok := results.Results[team]; ok {
+			cell = stringCell(resp.Response)
+			cell.UserEnteredFormat = &sheets.CellFormat{BackgroundColor: statusSheetsColor(resp.Status)}
+		}
+		rows = append(rows, &sheets.RowData{Values: []*sheets.CellData{cell}})
+	}
+	spreadsheetsService := sheets.NewSpreadsheetsService(a.service)
+	_, err = spreadsheetsService.BatchUpdate(gameSheets.manager.ID, &sheets.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheets.Request{
+			a.historyGridRequest(sheetID),
+			a.historyTeamsRequest(sheetID),
+			{
+				UpdateCells: &sheets.UpdateCellsRequest{
+					Range: &sheets.GridRange{
+						SheetId:          sheetID,
+						StartColumnIndex: column - 1,
+						EndColumnIndex:   column,
+						StartRowIndex:    0,
+						EndRowIndex:      int64(len(rows)),
+					},
+					Rows:   rows,
+					Fields: "userEnteredValue,userEnteredFormat.backgroundColor",
+				},
+			},
+		},
+	}).Context(a.commandContext()).Do()
+	if err != nil {
+		return fmt.Errorf("failed to update the history sheet: %w", err)
+	}
+	return nil
+}
+
+// historyGridRequest resizes the history sheet to the current roster.
+func (a *app) historyGridRequest(sheetID int64) *sheets.Request {
+	return &sheets.Request{
+		UpdateSheetProperties: &sheets.UpdateSheetPropertiesRequest{
+			Properties: &sheets.SheetProperties{
+				SheetId: sheetID,
+				GridProperties: &sheets.GridProperties{
+					RowCount:    int64(len(a.config.Teams) + 1),
+					ColumnCount: int64(a.config.NumberOfQuestions + 2),
+				},
+			},
+			Fields: "gridProperties(rowCount,columnCount)",
+		},
+	}
+}
+
+// historyTeamsRequest writes the teams column of the history sheet.
+func (a *app) historyTeamsRequest(sheetID int64) *sheets.Request {
+	rows := make([]*sheets.RowData, 0, len(a.config.Teams)+1)
+	rows = append(rows, &sheets.RowData{Values: []*sheets.CellData{stringCell(a.config.Labels.TeamsHeader)}})
+	for _, team := range a.config.Teams {
+		rows = append(rows, &sheets.RowData{Values: []*sheets.CellData{stringCell(team)}})
+	}
+	return &sheets.Request{
+		UpdateCells: &sheets.UpdateCellsRequest{
+			Range: &sheets.GridRange{
+				SheetId:          sheetID,
+				StartColumnIndex: 0,
+				EndColumnIndex:   1,
+				StartRowIndex:    0,
+				EndRowIndex:      int64(len(rows)),
+			},
+			Rows:   rows,
+			Fields: "userEnteredValue",
+		},
+	}
+}
+
+// ensureHistorySheet returns the ID of the history sheet, adding it to the
+// manager spreadsheet if needed.
+func (a *app) ensureHistorySheet(managerID string) (int64, error) {
+	manager, err := a.getSpreadsheetMetadata(a.commandContext(), managerID)
+	if err != nil {
+		return 0, err
+	}
+	if sheet, ok := manager.sheetByTitle(historySheetTitle); ok {
+		return sheet.ID, nil
+	}
+	spreadsheetsService := sheets.NewSpreadsheetsService(a.service)
+	resp, err := spreadsheetsService.BatchUpdate(managerID, &sheets.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheets.Request{
+			{
+				AddSheet: &sheets.AddSheetRequest{
+					Properties: &sheets.SheetProperties{
+						Title: historySheetTitle,
+						GridProperties: &sheets.GridProperties{
+							RowCount:       int64(len(a.config.Teams) + 1),
+							ColumnCount:    int64(a.config.NumberOfQuestions + 2),
+							FrozenRowCount: 1,
+						},
+					},
+				},
+			},
+		},
+	}).Context(a.commandContext()).Do()
+	if err != nil {
+		return 0, fmt.Errorf("failed to add the history sheet: %w", err)
+	}
+	a.metadata.invalidate(managerID)
+	if err := a.store.markSetupStep(setupStepHistorySheet); err != nil {
+		return 0, err
+	}
+	return resp.Replies[0].AddSheet.Properties.SheetId, nil
+}
+
+// rewriteHistory clears the history sheet, if it exists, and fills it again
+// from the stored results, e.g. when the teams roster changes.
+func (a *app) rewriteHistory(managerID string) error {
+	steps, err := a.store.getSetupSteps()
+	if err != nil {
+		return err
+	}
+	if !steps[setupStepHistorySheet] {
+		return nil
+	}
+	valuesService := sheets.NewSpreadsheetsValuesService(a.service)
+	_, err = valuesService.Clear(managerID, historySheetTitle, &sheets.ClearValuesRequest{}).Context(a.commandContext()).Do()
+	if err != nil {
+		return fmt.Errorf("failed to clear the history sheet: %w", err)
+	}
+	allResults, err := a.store.getAllRoundResults()
+	if err != nil {
+		return err
+	}
+	for _, results := range allResults {
+		if err := a.updateHistory(results); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// logHistoryError reports a failed history update, the history sheet is a
+// convenience and does not fail the command that updates it.
+func logHistoryError(question int, err error) {
+	if err != nil {
+		log.Printf("[ERR]: failed to update the round %d history: %v", question, err)
+	}
+}
+
+func stringCell(s string) *sheets.CellData {
+	return &sheets.CellData{UserEnteredValue: &sheets.ExtendedValue{StringValue: s}}
+}
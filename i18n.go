@@ -0,0 +1,247 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	languageEN = "en"
+	languageRU = "ru"
+)
+
+// messages maps the languages to their message catalogs. The English catalog
+// is complete, the messages missing from the other catalogs are shown in
+// English. The command descriptions are looked up as "cmd.<command>", the
+// description of the commands map is the English one.
+var messages = map[string]map[string]string{
+	languageEN: {
+		"prompt.enterCommand":       "Enter command%s: ",
+		"prompt.interrupted":        "Command \"%s\" is interrupted: %v\n",
+		"help.commands":             "Commands:",
+		"help.aliases":              " (aliases: %s)",
+		"help.exit":                 "quit the program",
+		"help.optional":             ", optional",
+		"check.header":              "Checking results for the round %d (%s back, %s skip)\n",
+		"check.firstResponse":       "This is the first response",
+		"check.unknownStatus":       "Unknown status, try again",
+		"check.answerPart":          "  answer %d/%d: %s\n",
+		"check.suggestions":         "%d similar unchecked responses, %s to mark them all correct, any other key to continue:\n",
+		"check.suggestionsAccepted": "%d responses are marked correct\n",
+		"shutdown.signal":           "\nReceived %v, saving the game and shutting down\n",
+		"shutdown.check":            "Game %s: the check of the round %d is stopped at %d of %d teams, run \"check %d\" to resume\n",
+		"shutdown.lastCommand":      "Game %s: the last command was \"%s\"\n",
+		"label.managerTitle":        "{game}-manager",
+		"label.teamTitle":           "{game}: team {team}",
+		"label.projectorTitle":      "{game}-projector",
+		"label.archivedPrefix":      "[archived] ",
+		"label.teamsHeader":         "Teams",
+		"header.place":              "Place",
+		"header.team":               "Team",
+		"header.score":              "Score",
+		"header.question":           "Question",
+		"header.answer":             "Answer %d",
+		"header.response":           "Answer",
+		"header.status":             "Status",
+		"header.submitted":          "Submitted",
+		"header.fetched":            "Fetched",
+		"answerCell.maxLength":      "The answer is at most %d characters long",
+		"pause.banner":              "BREAK — back at %s",
+		"wizard.intro":              "The configuration file %s does not exist, let's create the game.\n",
+		"wizard.gameName":           "Game name: ",
+		"wizard.questions":          "Number of questions: ",
+		"wizard.warmUp":             "Is there a warm-up question? (y/n): ",
+		"wizard.teams":              "Team names, one per line, a pasted list works too. Finish with an empty line:",
+		"wizard.notPositive":        "Not a positive number, try again",
+		"wizard.notYesNo":           "Answer y or n",
+		"wizard.retry":              "Let's fix the settings, please enter them again.",
+		"wizard.written":            "The configuration is written to %s, creating the game spreadsheets.\n",
+		"error.emptyCommand":        "got an empty command",
+		"error.unknownCommand":      "unknown command: %s",
+		"error.invalidArguments":    "invalid arguments of %s: %s, usage: %s",
+		"error.interactiveCommand":  "command %s is interactive and can be run only from the REPL",
+		"error.busy":                "command %s is refused while %s is running in the REPL, retry once it ends",
+		"error.readOnly":            "the session is read-only",
+		"error.readOnlyCommand":     "command %s is not allowed in a read-only session",
+		"error.roundNotFound":       "round results are not found",
+		"error.roundNumberNotFound": "round %d results are not found",
+		"error.quotaExceeded":       "the Google API quota is exceeded",
+		"error.notAuthorized":       "the Google API access is not authorized",
+	},
+	languageRU: {
+		"prompt.enterCommand":       "Введите команду%s: ",
+		"prompt.interrupted":        "Команда \"%s\" прервана: %v\n",
+		"help.commands":             "Команды:",
+		"help.aliases":              " (синонимы: %s)",
+		"help.exit":                 "выйти из программы",
+		"help.optional":             ", необязательный",
+		"check.header":              "Проверка ответов на вопрос %d (%s назад, %s пропустить)\n",
+		"check.firstResponse":       "Это первый ответ",
+		"check.unknownStatus":       "Неизвестный статус, попробуйте ещё раз",
+		"check.answerPart":          "  ответ %d/%d: %s\n",
+		"check.suggestions":         "Похожих непроверенных ответов: %d, %s — засчитать все, любая другая клавиша — продолжить:\n",
+		"check.suggestionsAccepted": "Засчитано ответов: %d\n",
+		"shutdown.signal":           "\nПолучен сигнал %v, игра сохраняется, программа завершается\n",
+		"shutdown.check":            "Игра %s: проверка вопроса %d остановлена, проверено команд: %d из %d, продолжите командой \"check %d\"\n",
+		"shutdown.lastCommand":      "Игра %s: последняя команда — \"%s\"\n",
+		"label.managerTitle":        "{game}-ведущий",
+		"label.teamTitle":           "{game}: команда {team}",
+		"label.projectorTitle":      "{game}-проектор",
+		"label.archivedPrefix":      "[архив] ",
+		"label.teamsHeader":         "Команды",
+		"header.place":              "Место",
+		"header.team":               "Команда",
+		"header.score":              "Очки",
+		"header.question":           "Вопрос",
+		"header.answer":             "Ответ %d",
+		"header.response":           "Ответ",
+		"header.status":             "Статус",
+		"header.submitted":          "Сдан",
+		"header.fetched":            "Загружен",
+		"answerCell.maxLength":      "Ответ не длиннее %d символов",
+		"pause.banner":              "ПЕРЕРЫВ — продолжим в %s",
+		"wizard.intro":              "Файл конфигурации %s не найден, давайте создадим игру.\n",
+		"wizard.gameName":           "Название игры: ",
+		"wizard.questions":          "Количество вопросов: ",
+		"wizard.warmUp":             "Есть ли разминочный вопрос? (д/н): ",
+		"wizard.teams":              "Названия команд, по одному в строке, можно вставить готовый список. Завершите пустой строкой:",
+		"wizard.notPositive":        "Нужно положительное число, попробуйте ещё раз",
+		"wizard.notYesNo":           "Ответьте д или н",
+		"wizard.retry":              "Исправим настройки, введите их ещё раз.",
+		"wizard.written":            "Конфигурация записана в %s, создаём таблицы игры.\n",
+		"error.emptyCommand":        "пустая команда",
+		"error.unknownCommand":      "неизвестная команда: %s",
+		"error.invalidArguments":    "неверные аргументы команды %s: %s, использование: %s",
+		"error.interactiveCommand":  "команда %s интерактивная и может быть запущена только из командной строки",
+		"error.busy":                "команда %s отклонена, пока в командной строке выполняется %s, повторите после её завершения",
+		"error.readOnly":            "сеанс только для чтения",
+		"error.readOnlyCommand":     "команда %s недоступна в сеансе только для чтения",
+		"error.roundNotFound":       "ответы на вопрос не найдены",
+		"error.roundNumberNotFound": "ответы на вопрос %d не найдены",
+		"error.quotaExceeded":       "превышена квота Google API",
+		"error.notAuthorized":       "нет доступа к Google API",
+
+		"cmd.listURLs":     "показать ссылки на таблицы игры",
+		"cmd.fetch":        "загрузить ответы на вопрос из таблицы ведущего",
+		"cmd.get":          "показать сохранённые ответы на вопрос",
+		"cmd.check":        "проверить ответы на вопрос",
+		"cmd.crosscheck":   "сравнить ответы в таблицах ведущего и команд",
+		"cmd.manual":       "записать ответ, который жюри вводит за команду",
+		"cmd.history":      "показать все ответы команды на вопрос, полученные при загрузках",
+		"cmd.fetchDirect":  "загрузить ответы на вопрос из таблиц команд и сравнить их с таблицей ведущего",
+		"cmd.addTeam":      "добавить команду в игру",
+		"cmd.removeTeam":   "удалить команду из игры и отправить её таблицу в архив",
+		"cmd.tiebreak":     "определить победителя среди двух команд с равным счётом",
+		"cmd.shootout":     "сыграть дополнительные вопросы между командами с равным счётом отдельно от основных итогов",
+		"cmd.mail":         "отправить командам их ответы, очки и место",
+		"cmd.snapshot":     "сохранить ответы на вопрос в PNG и HTML или зафиксировать положение команд после тура для эмбарго",
+		"cmd.embargo":      "показывать на проекторе, в письмах и на дашборде только зафиксированное положение команд, пока жюри видит текущие итоги",
+		"cmd.db":           "показать статистику базы данных или сжать её",
+		"cmd.timer":        "запустить или остановить отсчёт времени",
+		"cmd.watch":        "следить за ответами на вопрос, пока не ответят все команды или не выйдет время, затем защитить и загрузить их",
+		"cmd.resumeSetup":  "завершить прерванную подготовку игры",
+		"cmd.similar":      "сгруппировать похожие ответы на вопрос",
+		"cmd.markStatuses": "записать статусы ответов в таблицу ведущего",
+		"cmd.markAll":      "задать статус всех ответов на вопрос, например ok или noanswer, если вопрос снят",
+		"cmd.uncheck":      "сбросить статусы ответов на вопрос на «не проверен»",
+		"cmd.announce":     "показать сценарий объявления итогов",
+		"cmd.archive":      "сохранить игру в архив или загрузить её",
+		"cmd.lock":         "защитить ответы на вопрос в таблицах команд",
+		"cmd.unlock":       "снять защиту с ответов на вопрос",
+		"cmd.export":       "запустить плагин экспорта или выгрузить результаты для рейтинга",
+		"cmd.import":       "загрузить вопросы и ответы из пакета db.chgk.info или 4s",
+		"cmd.where":        "показать ячейки с ответами на вопрос",
+		"cmd.undo":         "отменить последнее изменение результатов",
+		"cmd.games":        "показать игры рабочего каталога или отправить старые в архив",
+		"cmd.attach":       "подключить игру к существующим таблицам",
+		"cmd.missing":      "показать команды без ответа на вопрос",
+		"cmd.finalize":     "проверить целостность результатов и завершить игру",
+		"cmd.showQuestion": "записать текст вопроса в таблицы",
+		"cmd.hideQuestion": "стереть текст вопроса из таблиц",
+		"cmd.restore":      "показать резервные копии базы данных или восстановить одну из них",
+		"cmd.close":        "закрыть вопрос: заблокировать ответы, показать скрытые, загрузить, сообщить о недостающих и по желанию проверить",
+		"cmd.projector":    "показать номер вопроса и лидеров в таблице для проектора",
+		"cmd.vote":         "проголосовать за спорный ответ или показать итог голосования",
+		"cmd.report":       "записать итоговый отчёт об игре в Markdown и HTML",
+		"cmd.refresh":      "сбросить кэш метаданных и значений таблиц",
+		"cmd.relink":       "пересоздать ссылки на ответы команд в таблице ведущего",
+		"cmd.checkLinks":   "показать неработающие ссылки на ответы команд",
+		"cmd.collusion":    "показать команды с одинаковыми или почти одинаковыми неверными ответами",
+		"cmd.stats":        "показать статистику по вопросам и серии верных ответов команд",
+		"cmd.selftest":     "проверить токен, доступ к таблицам, ссылки на таблицы команд, хранилище и запас квоты",
+		"cmd.open":         "открыть столбец вопроса в таблицах команд",
+		"cmd.pause":        "защитить таблицы команд на время перерыва",
+		"cmd.resume":       "снять защиту таблиц команд после перерыва",
+		"cmd.note":         "добавить заметку жюри к вопросу",
+		"cmd.void":         "снять вопрос: балл получают все команды или никто",
+		"cmd.adjust":       "начислить команде очки или снять их, например штраф",
+		"cmd.share":        "снова открыть доступ к таблицам команд для указанных аккаунтов и защитить ответы, чтобы их могли менять только капитаны",
+		"cmd.reload":       "снова прочитать файл конфигурации и применить изменения, безопасные во время игры, например подписи, принимаемые ответы или псевдонимы",
+		"cmd.audit":        "показать выполненные команды со временем, пользователем и изменениями, только касающиеся тура или команды, если они указаны",
+		"cmd.adjustments":  "показать поправки к очкам команд со временем и причиной",
+		"cmd.status":       "показать ход игры",
+		"cmd.total":        "показать итоговые очки команд",
+		"cmd.help":         "показать команды, их аргументы и синонимы",
+	},
+}
+
+// language is the language of the messages, it is set once the configuration
+// is read.
+var language = languageEN
+
+func setLanguage(lang string) {
+	if len(lang) == 0 {
+		lang = languageEN
+	}
+	language = lang
+}
+
+func checkLanguage(lang string) error {
+	if _, ok := messages[lang]; ok || len(lang) == 0 {
+		return nil
+	}
+	langs := make([]string, 0, len(messages))
+	for l := range messages {
+		langs = append(langs, l)
+	}
+	sort.Strings(langs)
+	return fmt.Errorf("unknown language %s, expected one of: %s", lang, strings.Join(langs, ", "))
+}
+
+// translate returns the message of the language formatted with the args.
+func translate(lang string, key string, args ...interface{}) string {
+	msg, ok := messages[lang][key]
+	if !ok {
+		msg = messages[languageEN][key]
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+// tr returns the message in the current language.
+func tr(key string, args ...interface{}) string {
+	return translate(language, key, args...)
+}
+
+// commandDescription returns the description of the command in the current
+// language.
+func commandDescription(name string, c *command) string {
+	if msg, ok := messages[language]["cmd."+name]; ok {
+		return msg
+	}
+	return c.description
+}
+
+// errorMessage is an error whose text is looked up in the current language,
+// so that the sentinel errors declared before the configuration is read are
+// shown in the configured language.
+type errorMessage struct {
+	key string
+}
+
+func (e *errorMessage) Error() string {
+	return tr(e.key)
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/user"
+	"path"
+	"strings"
+	"time"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+const integrityReportFile = "integrity-report.txt"
+
+type integrityCheck struct {
+	Name     string   `json:"name" yaml:"name"`
+	Passed   bool     `json:"passed" yaml:"passed"`
+	Skipped  bool     `json:"skipped,omitempty" yaml:"skipped,omitempty"`
+	Problems []string `json:"problems,omitempty" yaml:"problems,omitempty"`
+}
+
+type integrityReport struct {
+	Checks []integrityCheck `json:"checks" yaml:"checks"`
+	// Fingerprint is the SHA-256 of the stored results the report is about.
+	Fingerprint string    `json:"fingerprint" yaml:"fingerprint"`
+	SignedOffBy string    `json:"signedOffBy,omitempty" yaml:"signedOffBy,omitempty"`
+	SignedOffAt time.Time `json:"signedOffAt,omitempty" yaml:"signedOffAt,omitempty"`
+}
+
+func (r *integrityReport) passed() bool {
+	for _, c := range r.Checks {
+		if !c.Passed {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *integrityReport) String() string {
+	var sb strings.Builder
+	sb.WriteString("Integrity report:\n")
+	for _, c := range r.Checks {
+		verdict := "OK"
+		if c.Skipped {
+			verdict = "skipped"
+		} else if !c.Passed {
+			verdict = "FAILED"
+		}
+		sb.WriteString(fmt.Sprintf("\t%s: %s\n", c.Name, verdict))
+		for _, p := range c.Problems {
+			sb.WriteString(fmt.Sprintf("\t\t- %s\n", p))
+		}
+	}
+	sb.WriteString(fmt.Sprintf("Results fingerprint: %s\n", r.Fingerprint))
+	if len(r.SignedOffBy) != 0 {
+		sb.WriteString(fmt.Sprintf("Signed off by %s at %s\n", r.SignedOffBy, r.SignedOffAt.Format(time.RFC3339)))
+	} else {
+		sb.WriteString("Not signed off, the game is not finalized\n")
+	}
+	return sb.String()
+}
+
+// CmdFinalize runs the integrity checks of the game. If they all pass, the
+// report is signed off, written to the output dir and the game is marked as
+// finalized in the event log.
+func (a *app) CmdFinalize() (*integrityReport, error) {
+	report, err := a.checkIntegrity()
+	if err != nil {
+		return nil, err
+	}
+	if !report.passed() {
+		return report, nil
+	}
+	report.SignedOffBy = currentUserName()
+	report.SignedOffAt = time.Now()
+	reportFile := path.Join(a.config.OutputDir, integrityReportFile)
+	if err := ioutil.WriteFile(reportFile, []byte(report.String()), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write the integrity report: %w", err)
+	}
+	if err := a.store.appendEvent(fmt.Sprintf("game finalized by %s, results fingerprint %s", report.SignedOffBy, report.Fingerprint)); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func (a *app) checkIntegrity() (*integrityReport, error) {
+	allResults, err := a.store.getAllRoundResults()
+	if err != nil {
+		return nil, err
+	}
+	resultsBytes, err := json.Marshal(allResults)
+	if err != nil {
+		return nil, err
+	}
+	fingerprint := sha256.Sum256(resultsBytes)
+	report := &integrityReport{Fingerprint: hex.EncodeToString(fingerprint[:])}
+	report.Checks = append(report.Checks, a.checkStatusesComplete(allResults))
+	spreadsheetsCheck, err := a.checkSpreadsheetsExist()
+	if err != nil {
+		return nil, err
+	}
+	report.Checks = append(report.Checks, spreadsheetsCheck)
+	publishedCheck, err := a.checkPublishedStatuses(allResults)
+	if err != nil {
+		return nil, err
+	}
+	report.Checks = append(report.Checks, publishedCheck)
+	return report, nil
+}
+
+// checkStatusesComplete checks that every team has a final status for every
+// scored question.
+func (a *app) checkStatusesComplete(allResults []*roundResults) integrityCheck {
+	check := integrityCheck{Name: "every team has a final status for every question"}
+	byRound := make(map[int]*roundResults, len(allResults))
+	for _, results := range allResults {
+		byRound[results.Round] = results
+	}
+	for _, round := range a.scoredRounds() {
+		results, ok := byRound[round]
+		if !ok {
+			check.Problems = append(check.Problems, fmt.Sprintf("round %d is not fetched", a.config.questionNumber(round)))
+			continue
+		}
+		for _, team := range a.config.Teams {
+			resp, ok := results.Results[team]
+			switch {
+			case !ok:
+				check.Problems = append(check.Problems, fmt.Sprintf("round %d: team %s has no answer", a.config.questionNumber(round), team))
+			case resp.Status == ResponseStatusNotChecked:
+				check.Problems = append(check.Problems, fmt.Sprintf("round %d: team %s is not checked", a.config.questionNumber(round), team))
+			case resp.Status == ResponseStatusInQuestion:
+				check.Problems = append(check.Problems, fmt.Sprintf("round %d: team %s is still in question", a.config.questionNumber(round), team))
+			}
+		}
+	}
+	check.Passed = len(check.Problems) == 0
+	return check
+}
+
+func (a *app) checkSpreadsheetsExist() (integrityCheck, error) {
+	check := integrityCheck{Name: "stored spreadsheets exist"}
+	gameSheets, err := a.GetGameSpreadsheets()
+	if err != nil {
+		return check, err
+	}
+	ids := make(map[string]string)
+	if gameSheets.manager == nil {
+		check.Problems = append(check.Problems, "the manager spreadsheet is not stored")
+	} else {
+		ids["the manager spreadsheet"] = gameSheets.manager.ID
+	}
+	for _, team := range a.config.Teams {
+		teamSheet, ok := gameSheets.teams[team]
+		if !ok {
+			check.Problems = append(check.Problems, fmt.Sprintf("the team %s spreadsheet is not stored", team))
+			continue
+		}
+		ids[fmt.Sprintf("the team %s spreadsheet", team)] = teamSheet.ID
+	}
+	for name, id := range ids {
+		if _, err := a.service.Spreadsheets.Get(id).Fields("spreadsheetId").Context(a.commandContext()).Do(); err != nil {
+			check.Problems = append(check.Problems, fmt.Sprintf("%s %s cannot be read: %v", name, id, err))
+		}
+	}
+	check.Passed = len(check.Problems) == 0
+	return check, nil
+}
+
+// checkPublishedStatuses checks that the statuses sheet of the manager
+// spreadsheet gives the same totals as the stored statuses.
+func (a *app) checkPublishedStatuses(allResults []*roundResults) (integrityCheck, error) {
+	check := integrityCheck{Name: "published statuses match the stored totals"}
+	steps, err := a.store.getSetupSteps()
+	if err != nil {
+		return check, err
+	}
+	gameSheets, err := a.GetGameSpreadsheets()
+	if err != nil {
+		return check, err
+	}
+	if !steps[setupStepStatusesSheet] || gameSheets.manager == nil || len(allResults) == 0 {
+		check.Passed = true
+		check.Skipped = true
+		return check, nil
+	}
+	scored := make(map[int]bool)
+	for _, round := range a.scoredRounds() {
+		scored[round] = true
+	}
+	ranges := make([]string, 0, len(allResults))
+	stored := make(map[string]float64, len(a.config.Teams))
+	for _, results := range allResults {
+		if !scored[results.Round] {
+			continue
+		}
+		roundRange, err := a.getRoundRange(results.Round)
+		if err != nil {
+			return check, err
+		}
+		column := int(roundRange.StartColumnIndex)
+		ranges = append(ranges, sheetRange(statusesSheetTitle,
+			rangeName(column, int(roundRange.StartRowIndex)+1, column, int(roundRange.EndRowIndex))))
+		for team, resp := range results.Results {
+			stored[team] += resp.Status.points()
+		}
+	}
+	valuesService := sheets.NewSpreadsheetsValuesService(a.service)
+	resp, err := valuesService.BatchGet(gameSheets.manager.ID).Ranges(ranges...).MajorDimension("COLUMNS").Context(a.commandContext()).Do()
+	if err != nil {
+		return check, fmt.Errorf("failed to read the statuses sheet: %w", err)
+	}
+	statusesByString := make(map[string]ResponseStatus)
+	for _, s := range []ResponseStatus{ResponseStatusOK, ResponseStatusKO, ResponseStatusInQuestion, ResponseStatusPartial, ResponseStatusNoAnswer} {
+		statusesByString[s.String()] = s
+	}
+	published := make(map[string]float64, len(a.config.Teams))
+	for _, vr := range resp.ValueRanges {
+		if len(vr.Values) == 0 {
+			continue
+		}
+		for i, v := range vr.Values[0] {
+			if i < len(a.config.Teams) {
+				published[a.config.Teams[i]] += statusesByString[fmt.Sprint(v)].points()
+			}
+		}
+	}
+	for _, team := range a.config.Teams {
+		if stored[team] != published[team] {
+			check.Problems = append(check.Problems, fmt.Sprintf("team %s: %g stored, %g published", team, stored[team], published[team]))
+		}
+	}
+	check.Passed = len(check.Problems) == 0
+	return check, nil
+}
+
+// scoredRounds returns the rounds that count towards the totals.
+func (a *app) scoredRounds() []int {
+	return a.config.scoredRounds()
+}
+
+// scoredRounds returns the rounds of the game: the questions 1 to
+// NumberOfQuestions, preceded by the round 0 if there is a warm-up question.
+func (c *Config) scoredRounds() []int {
+	rounds := make([]int, 0, c.NumberOfQuestions+1)
+	if c.HasWarmUpQuestion {
+		rounds = append(rounds, 0)
+	}
+	for i := 1; i <= c.NumberOfQuestions; i++ {
+		rounds = append(rounds, i)
+	}
+	return rounds
+}
+
+func currentUserName() string {
+	if u, err := user.Current(); err == nil && len(u.Username) != 0 {
+		return u.Username
+	}
+	if name := os.Getenv("USER"); len(name) != 0 {
+		return name
+	}
+	return "unknown"
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"time"
+)
+
+// interruptContext returns a context cancelled by Ctrl-C, stop restores the
+// default Ctrl-C handling, which terminates the process.
+func interruptContext() (ctx context.Context, stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+	atomic.AddInt32(&cancellableCommands, 1)
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-signals:
+			cancel()
+		case <-done:
+		}
+	}()
+	return ctx, func() {
+		signal.Stop(signals)
+		atomic.AddInt32(&cancellableCommands, -1)
+		close(done)
+		cancel()
+	}
+}
+
+// commandContext returns the context of the running command, the API calls
+// made by the command are cancelled with it. The calls made outside of a
+// command use the background context. The goroutines that outlive a command,
+// e.g. the timer, must not use it, as it would be the context of whichever
+// command runs at the time: they pass their own context instead.
+func (a *app) commandContext() context.Context {
+	a.ctxMu.Lock()
+	defer a.ctxMu.Unlock()
+	if a.cmdCtx == nil {
+		return context.Background()
+	}
+	return a.cmdCtx
+}
+
+// sleepContext waits for d, it returns the context error if ctx is cancelled
+// first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (a *app) setCommandContext(ctx context.Context) {
+	a.ctxMu.Lock()
+	defer a.ctxMu.Unlock()
+	a.cmdCtx = ctx
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"google.golang.org/api/sheets/v4"
+)
+
+const (
+	LayoutOrientationHorizontal = "horizontal"
+	LayoutOrientationVertical   = "vertical"
+)
+
+// questionsGroupLength is the number of the questions in a group of the
+// horizontal layout, one column each.
+const questionsGroupLength = 12
+
+// LayoutConfig is the layout of the answers grid of the team spreadsheets.
+type LayoutConfig struct {
+	// Orientation is either "horizontal" (the questions in groups of 12
+	// columns, the answers in the row below the question numbers) or
+	// "vertical" (one question per row, the number in column A and the answer
+	// in column B). Horizontal by default.
+	Orientation string
+}
+
+func (c *LayoutConfig) vertical() bool {
+	return c.Orientation == LayoutOrientationVertical
+}
+
+// verticalQuestionRow returns the one-based row of the question in the
+// vertical layout, the warm-up question takes the first row.
+func (a *app) verticalQuestionRow(round int) int {
+	if a.config.HasWarmUpQuestion {
+		return round + 1
+	}
+	return round
+}
+
+// verticalTeamAnswerGroup returns the question numbers of a group of the
+// vertical layout, one per row of column A.
+func (a *app) verticalTeamAnswerGroup(length int, currQuestionIndex int) *sheets.ValueRange {
+	startRow := a.verticalQuestionRow(currQuestionIndex + 1)
+	values := make([][]interface{}, length)
+	for j := 0; j < length; j++ {
+		values[j] = []interface{}{a.config.questionNumber(currQuestionIndex + j + 1)}
+	}
+	return &sheets.ValueRange{
+		MajorDimension: "ROWS",
+		Range:          rangeName(0, startRow, 0, startRow+length-1),
+		Values:         values,
+	}
+}
+
+// verticalTeamAnswerGridRanges returns the bordered ranges of the vertical
+// layout: the warm-up question and the list of the questions.
+func (a *app) verticalTeamAnswerGridRanges() []*sheets.GridRange {
+	ranges := make([]*sheets.GridRange, 0, 2)
+	if a.config.HasWarmUpQuestion {
+		ranges = append(ranges, &sheets.GridRange{
+			StartColumnIndex: 0,
+			EndColumnIndex:   2,
+			StartRowIndex:    0,
+			EndRowIndex:      1,
+		})
+	}
+	if a.config.NumberOfQuestions > 0 {
+		startRow := a.verticalQuestionRow(1) - 1
+		ranges = append(ranges, &sheets.GridRange{
+			StartColumnIndex: 0,
+			EndColumnIndex:   2,
+			StartRowIndex:    int64(startRow),
+			EndRowIndex:      int64(startRow + a.config.NumberOfQuestions),
+		})
+	}
+	return ranges
+}
+
+// hiddenQuestionsDimension returns the dimension hidden by the progressive
+// disclosure: the question columns of the horizontal layout or the question
+// rows of the vertical one.
+func (a *app) hiddenQuestionsDimension(sheetID int64) *sheets.DimensionRange {
+	if a.config.Layout.vertical() {
+		return &sheets.DimensionRange{
+			SheetId:    sheetID,
+			Dimension:  "ROWS",
+			StartIndex: 0,
+			EndIndex:   int64(a.verticalQuestionRow(a.config.NumberOfQuestions)),
+		}
+	}
+	return &sheets.DimensionRange{
+		SheetId:    sheetID,
+		Dimension:  "COLUMNS",
+		StartIndex: 0,
+		EndIndex:   questionsGroupLength,
+	}
+}
+
+// questionDimension returns the column or the row of the team spreadsheet
+// revealed when the question is opened.
+func (a *app) questionDimension(sheetID int64, column int, row int) *sheets.DimensionRange {
+	if a.config.Layout.vertical() {
+		return &sheets.DimensionRange{
+			SheetId:    sheetID,
+			Dimension:  "ROWS",
+			StartIndex: int64(row - 1),
+			EndIndex:   int64(row),
+		}
+	}
+	return &sheets.DimensionRange{
+		SheetId:    sheetID,
+		Dimension:  "COLUMNS",
+		StartIndex: int64(column),
+		EndIndex:   int64(column + 1),
+	}
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+type lockResult struct {
+	Round  int      `json:"round" yaml:"round"`
+	Locked bool     `json:"locked" yaml:"locked"`
+	Teams  []string `json:"teams" yaml:"teams"`
+}
+
+func (r *lockResult) String() string {
+	action := "unlocked"
+	if r.Locked {
+		action = "locked"
+	}
+	return fmt.Sprintf("Round %d answers are %s for the teams: %s", r.Round, action, strings.Join(r.Teams, ", "))
+}
+
+func (a *app) CmdLock(cmdStr string) (*lockResult, error) {
+	round, err := a.getRoundNumber(cmdStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse lock request: %w", err)
+	}
+	return a.lockRound(a.commandContext(), round)
+}
+
+func (a *app) CmdUnlock(cmdStr string) (*lockResult, error) {
+	round, err := a.getRoundNumber(cmdStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse unlock request: %w", err)
+	}
+	return a.unlockRound(round)
+}
+
+// lockRound protects the round answer cell in every team spreadsheet, so that
+// only the spreadsheets owner can edit it. The sub-answers of a blitz round
+// in the blitz sheet are protected as well. The protected range IDs of the
+// answer cells are stored to be able to unlock the round, the blitz
+// protections are found by their description. A protection of the round that
+// already exists in the spreadsheet, e.g. if the stored ID was lost, is
+// reused. The API calls are made with ctx, the timer locks the round outside
+// of any command.
+func (a *app) lockRound(ctx context.Context, round int) (*lockResult, error) {
+	gameSheets, err := a.GetGameSpreadsheets()
+	if err != nil {
+		return nil, err
+	}
+	column, row, err := a.getTeamRoundCellPosition(round)
+	if err != nil {
+		return nil, err
+	}
+	locks, err := a.store.getRoundLocks(round)
+	if err != nil {
+		return nil, err
+	}
+	spreadsheetsService := sheets.NewSpreadsheetsService(a.service)
+	res := &lockResult{Round: a.config.questionNumber(round), Locked: true, Teams: make([]string, 0)}
+	for _, team := range a.config.Teams {
+		if _, ok := locks[team]; ok {
+			continue
+		}
+		teamSheet, ok := gameSheets.teams[team]
+		if !ok {
+			return nil, fmt.Errorf("spreadsheet of the team %s is not found", team)
+		}
+		description := fmt.Sprintf("round %d", round)
+		metadata, err := a.getSpreadsheetMetadata(ctx, teamSheet.ID)
+		if err != nil {
+			return nil, err
+		}
+		requests := make([]*sheets.Request, 0, 2)
+		id, ok := metadata.protectedRangeByDescription(description)
+		if !ok {
+			requests = append(requests, &sheets.Request{
+				AddProtectedRange: &sheets.AddProtectedRangeRequest{
+					ProtectedRange: &sheets.ProtectedRange{
+						Description: description,
+						Range: &sheets.GridRange{
+							SheetId:          teamSheet.SheetID,
+							StartColumnIndex: int64(column),
+							EndColumnIndex:   int64(column + 1),
+							StartRowIndex:    int64(row - 1),
+							EndRowIndex:      int64(row),
+						},
+					},
+				},
+			})
+		}
+		blitz, err := a.blitzGridRange(metadata, round)
+		if err != nil {
+			return nil, fmt.Errorf("failed to lock the round %d blitz answers of the team %s: %w", a.config.questionNumber(round), team, err)
+		}
+		if _, ok := metadata.protectedRangeByDescription(blitzLockDescription(round)); blitz != nil && !ok {
+			requests = append(requests, &sheets.Request{
+				AddProtectedRange: &sheets.AddProtectedRangeRequest{
+					ProtectedRange: &sheets.ProtectedRange{
+						Description: blitzLockDescription(round),
+						Range:       blitz,
+					},
+				},
+			})
+		}
+		if len(requests) != 0 {
+			resp, err := spreadsheetsService.BatchUpdate(teamSheet.ID, &sheets.BatchUpdateSpreadsheetRequest{
+				Requests: requests,
+			}).Context(ctx).Do()
+			if err != nil {
+				return nil, fmt.Errorf("failed to lock the round %d answer of the team %s: %w", a.config.questionNumber(round), team, err)
+			}
+			for _, reply := range resp.Replies {
+				added := reply.AddProtectedRange.ProtectedRange
+				a.metadata.addProtectedRange(teamSheet.ID, added.ProtectedRangeId, added.Description)
+				if added.Description == description {
+					id = added.ProtectedRangeId
+				}
+			}
+		}
+		locks[team] = id
+		if err := a.store.saveRoundLocks(round, locks); err != nil {
+			return nil, err
+		}
+		res.Teams = append(res.Teams, team)
+	}
+	log.Printf("locked the round %d answers", a.config.questionNumber(round))
+	return res, nil
+}
+
+// blitzLockDescription is the description of the protection of the round
+// sub-answers in the blitz sheet.
+func blitzLockDescription(round int) string {
+	return fmt.Sprintf("round %d blitz", round)
+}
+
+func (a *app) unlockRound(round int) (*lockResult, error) {
+	gameSheets, err := a.GetGameSpreadsheets()
+	if err != nil {
+		return nil, err
+	}
+	locks, err := a.store.getRoundLocks(round)
+	if err != nil {
+		return nil, err
+	}
+	teams := make([]string, 0, len(locks))
+	for team := range locks {
+		teams = append(teams, team)
+	}
+	sort.Strings(teams)
+	spreadsheetsService := sheets.NewSpreadsheetsService(a.service)
+	res := &lockResult{Round: a.config.questionNumber(round), Locked: false, Teams: make([]string, 0)}
+	for _, team := range teams {
+		if teamSheet, ok := gameSheets.teams[team]; ok {
+			metadata, err := a.getSpreadsheetMetadata(a.commandContext(), teamSheet.ID)
+			if err != nil {
+				return nil, err
+			}
+			ids := make([]int64, 0, 2)
+			if _, ok := metadata.protectedRanges[locks[team]]; ok {
+				ids = append(ids, locks[team])
+			} else {
+				log.Printf("the round %d protection of the team %s is already removed", a.config.questionNumber(round), team)
+			}
+			if id, ok := metadata.protectedRangeByDescription(blitzLockDescription(round)); ok {
+				ids = append(ids, id)
+			}
+			if len(ids) != 0 {
+				requests := make([]*sheets.Request, 0, len(ids))
+				for _, id := range ids {
+					requests = append(requests, &sheets.Request{
+						DeleteProtectedRange: &sheets.DeleteProtectedRangeRequest{ProtectedRangeId: id},
+					})
+				}
+				_, err = spreadsheetsService.BatchUpdate(teamSheet.ID, &sheets.BatchUpdateSpreadsheetRequest{
+					Requests: requests,
+				}).Context(a.commandContext()).Do()
+				if err != nil {
+					return nil, fmt.Errorf("failed to unlock the round %d answer of the team %s: %w", a.config.questionNumber(round), team, err)
+				}
+				for _, id := range ids {
+					a.metadata.removeProtectedRange(teamSheet.ID, id)
+				}
+			}
+		}
+		delete(locks, team)
+		if err := a.store.saveRoundLocks(round, locks); err != nil {
+			return nil, err
+		}
+		res.Teams = append(res.Teams, team)
+	}
+	// the unlocked answers may change, so the close command locks and fetches
+	// the round again
+	if err := a.resetCloseSteps(round); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -0,0 +1,350 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	htmltemplate "html/template"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"google.golang.org/api/gmail/v1"
+)
+
+// MailConfig configures the delivery of the result summaries to the teams
+// with the mail command. The messages are sent through the SMTP server if it
+// is set, through the Gmail API with the game authorization otherwise.
+type MailConfig struct {
+	// Teams maps the teams to their email addresses.
+	Teams map[string]string
+	// From is the sender address, required by SMTP. Gmail sends on behalf
+	// of the authorized account if it is empty.
+	From string
+	// Subject is a template of the subject, with the fields of the summary:
+	// {{.Game}}, {{.Team}}, {{.Score}}, {{.Place}}.
+	Subject string
+	// TextTemplate and HTMLTemplate are the files of the text/template and
+	// html/template of the message body, the built-in text template is used
+	// if both are empty.
+	TextTemplate string
+	HTMLTemplate string
+	SMTP         SMTPConfig
+}
+
+type SMTPConfig struct {
+	// Addr is the address of the server, e.g. "smtp.example.com:587".
+	Addr     string
+	Username string
+	Password string
+}
+
+func (c *MailConfig) enabled() bool {
+	return len(c.Teams) != 0
+}
+
+func (c *MailConfig) useGmail() bool {
+	return c.enabled() && len(c.SMTP.Addr) == 0
+}
+
+// check parses the addresses and the templates.
+func (c *MailConfig) check() []string {
+	var problems []string
+	for team, address := range c.Teams {
+		if _, err := mail.ParseAddress(address); err != nil {
+			problems = append(problems, fmt.Sprintf("Mail: address %s of the team %s is invalid: %v", address, team, err))
+		}
+	}
+	if !c.enabled() {
+		return problems
+	}
+	if len(c.From) != 0 {
+		if _, err := mail.ParseAddress(c.From); err != nil {
+			problems = append(problems, fmt.Sprintf("Mail: From address %s is invalid: %v", c.From, err))
+		}
+	} else if len(c.SMTP.Addr) != 0 {
+		problems = append(problems, "Mail: From is required to send through SMTP")
+	}
+	if _, err := c.templates(); err != nil {
+		problems = append(problems, fmt.Sprintf("Mail: %v", err))
+	}
+	return problems
+}
+
+// defaultMailSubject and defaultMailText are used unless the templates are
+// configured.
+const defaultMailSubject = "{{.Game}}: results of the team {{.Team}}"
+
+const defaultMailText = `Hello, {{.Team}}!
+
+Thank you for playing {{.Game}}. Your score is {{.Score}}, {{if .OutOfCompetition}}out of competition{{else}}place {{.Place}} of {{.Teams}}{{end}}.
+
+Your answers:
+{{range .Answers}}{{.Round}}. {{if .Response}}{{.Response}}{{else}}(no answer){{end}} {{.Status}}
+{{end}}`
+
+type mailTemplates struct {
+	subject *template.Template
+	text    *template.Template
+	html    *htmltemplate.Template
+}
+
+func (c *MailConfig) templates() (*mailTemplates, error) {
+	subject := c.Subject
+	if len(subject) == 0 {
+		subject = defaultMailSubject
+	}
+	var err error
+	t := &mailTemplates{}
+	if t.subject, err = template.New("subject").Parse(subject); err != nil {
+		return nil, fmt.Errorf("the subject is not a valid template: %w", err)
+	}
+	if len(c.TextTemplate) == 0 && len(c.HTMLTemplate) == 0 {
+		t.text = template.Must(template.New("text").Parse(defaultMailText))
+		return t, nil
+	}
+	if len(c.TextTemplate) != 0 {
+		text, err := ioutil.ReadFile(c.TextTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the text template: %w", err)
+		}
+		if t.text, err = template.New("text").Parse(string(text)); err != nil {
+			return nil, fmt.Errorf("%s is not a valid template: %w", c.TextTemplate, err)
+		}
+	}
+	if len(c.HTMLTemplate) != 0 {
+		html, err := ioutil.ReadFile(c.HTMLTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the HTML template: %w", err)
+		}
+		if t.html, err = htmltemplate.New("html").Parse(string(html)); err != nil {
+			return nil, fmt.Errorf("%s is not a valid template: %w", c.HTMLTemplate, err)
+		}
+	}
+	return t, nil
+}
+
+// mailSummary is the data of the message templates.
+type mailSummary struct {
+	Game  string
+	Team  string
+	Score string
+	// Place is the place of the team, e.g. "3" or "3-4" if it is shared.
+	Place string
+	// Teams is the number of the teams in competition.
+	Teams int
+	// OutOfCompetition is set for a guest team, its Place is "в/к".
+	OutOfCompetition bool
+	Answers          []mailAnswer
+}
+
+type mailAnswer struct {
+	Round    int
+	Response string
+	Status   string
+}
+
+type mailResult struct {
+	Sent []string `json:"sent" yaml:"sent"`
+	// NoAddress are the teams without an address in the configuration.
+	NoAddress []string `json:"noAddress,omitempty" yaml:"noAddress,omitempty"`
+}
+
+func (r *mailResult) String() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("The results are sent to %d team(s): %s\n", len(r.Sent), strings.Join(r.Sent, ", ")))
+	if len(r.NoAddress) != 0 {
+		sb.WriteString(fmt.Sprintf("The teams without an address are skipped: %s\n", strings.Join(r.NoAddress, ", ")))
+	}
+	return sb.String()
+}
+
+// CmdMail sends the teams the summaries of their results: their answers, the
+// verdicts, the score and the place: "mail results [team]".
+func (a *app) CmdMail(cmdStr string) (*mailResult, error) {
+	sSplitted := splitArgs(cmdStr)
+	if len(sSplitted) < 2 || sSplitted[1] != "results" {
+		return nil, fmt.Errorf("failed to parse mail request: expected mail results [team]")
+	}
+	if !a.config.Mail.enabled() {
+		return nil, fmt.Errorf("no team address is configured in Mail.Teams")
+	}
+	teams := a.config.Teams
+	if len(sSplitted) > 2 {
+		team := strings.Join(sSplitted[2:], " ")
+		found := false
+		for _, t := range a.config.Teams {
+			if t == team {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("team %s is unknown", team)
+		}
+		teams = []string{team}
+	}
+	templates, err := a.config.Mail.templates()
+	if err != nil {
+		return nil, err
+	}
+	summaries, err := a.mailSummaries()
+	if err != nil {
+		return nil, err
+	}
+	res := &mailResult{Sent: make([]string, 0, len(teams))}
+	for _, team := range teams {
+		to, ok := a.config.Mail.Teams[team]
+		if !ok {
+			res.NoAddress = append(res.NoAddress, team)
+			continue
+		}
+		msg, err := buildMailMessage(a.config.Mail.From, to, templates, summaries[team])
+		if err != nil {
+			return nil, fmt.Errorf("failed to render the message of the team %s: %w", team, err)
+		}
+		if err := a.sendMail(to, msg); err != nil {
+			return nil, fmt.Errorf("failed to send the results to the team %s (sent to %s so far): %w", team, strings.Join(res.Sent, ", "), err)
+		}
+		res.Sent = append(res.Sent, team)
+	}
+	if err := a.store.appendEvent(fmt.Sprintf("mail results: %s", strings.Join(res.Sent, ", "))); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (a *app) mailSummaries() (map[string]*mailSummary, error) {
+	total, err := a.publicTotals()
+	if err != nil {
+		return nil, err
+	}
+	allResults, err := a.store.getAllRoundResults()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(allResults, func(i, j int) bool { return allResults[i].Round < allResults[j].Round })
+	summaries := make(map[string]*mailSummary, len(a.config.Teams))
+	official := len(a.config.officialTeams())
+	for _, s := range a.standings(total) {
+		for _, team := range s.Teams {
+			summaries[team] = &mailSummary{
+				Game:             a.config.GameName,
+				Team:             team,
+				Score:            formatPoints(s.Score),
+				Place:            s.places(),
+				Teams:            official,
+				OutOfCompetition: s.OutOfCompetition,
+			}
+		}
+	}
+	for _, results := range allResults {
+		if a.isEmbargoed(results.Round) {
+			continue
+		}
+		for team, resp := range results.Results {
+			summary, ok := summaries[team]
+			if !ok {
+				continue
+			}
+			summary.Answers = append(summary.Answers, mailAnswer{
+				Round:    a.config.questionNumber(results.Round),
+				Response: resp.Response,
+				Status:   resp.Status.String(),
+			})
+		}
+	}
+	return summaries, nil
+}
+
+// buildMailMessage renders the message, a multipart one if both the text and
+// the HTML templates are set.
+func buildMailMessage(from string, to string, templates *mailTemplates, summary *mailSummary) ([]byte, error) {
+	var subject strings.Builder
+	if err := templates.subject.Execute(&subject, summary); err != nil {
+		return nil, err
+	}
+	var body bytes.Buffer
+	contentType := "text/plain; charset=utf-8"
+	switch {
+	case templates.text != nil && templates.html != nil:
+		w := multipart.NewWriter(&body)
+		contentType = fmt.Sprintf("multipart/alternative; boundary=%s", w.Boundary())
+		for _, part := range []struct {
+			contentType string
+			execute     func(*bytes.Buffer) error
+		}{
+			{"text/plain; charset=utf-8", func(b *bytes.Buffer) error { return templates.text.Execute(b, summary) }},
+			{"text/html; charset=utf-8", func(b *bytes.Buffer) error { return templates.html.Execute(b, summary) }},
+		} {
+			var content bytes.Buffer
+			if err := part.execute(&content); err != nil {
+				return nil, err
+			}
+			pw, err := w.CreatePart(textproto.MIMEHeader{"Content-Type": {part.contentType}})
+			if err != nil {
+				return nil, err
+			}
+			if _, err := pw.Write(content.Bytes()); err != nil {
+				return nil, err
+			}
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+	case templates.html != nil:
+		contentType = "text/html; charset=utf-8"
+		if err := templates.html.Execute(&body, summary); err != nil {
+			return nil, err
+		}
+	default:
+		if err := templates.text.Execute(&body, summary); err != nil {
+			return nil, err
+		}
+	}
+	var msg bytes.Buffer
+	if len(from) != 0 {
+		fmt.Fprintf(&msg, "From: %s\r\n", from)
+	}
+	fmt.Fprintf(&msg, "To: %s\r\n", to)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject.String()))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&msg, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: %s\r\n\r\n", contentType)
+	msg.Write(body.Bytes())
+	return msg.Bytes(), nil
+}
+
+func (a *app) sendMail(to string, msg []byte) error {
+	if !a.config.Mail.useGmail() {
+		smtpConfig := a.config.Mail.SMTP
+		var auth smtp.Auth
+		if len(smtpConfig.Username) != 0 {
+			host := strings.Split(smtpConfig.Addr, ":")[0]
+			auth = smtp.PlainAuth("", smtpConfig.Username, smtpConfig.Password, host)
+		}
+		from, err := mail.ParseAddress(a.config.Mail.From)
+		if err != nil {
+			return err
+		}
+		recipient, err := mail.ParseAddress(to)
+		if err != nil {
+			return err
+		}
+		return smtp.SendMail(smtpConfig.Addr, auth, from.Address, []string{recipient.Address}, msg)
+	}
+	if a.gmail == nil {
+		return fmt.Errorf("the Gmail API client is not initialized")
+	}
+	_, err := a.gmail.Users.Messages.Send("me", &gmail.Message{
+		Raw: base64.URLEncoding.EncodeToString(msg),
+	}).Context(a.commandContext()).Do()
+	return err
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
 )
 
 func main() {
@@ -12,18 +13,87 @@ func main() {
 	if err != nil {
 		log.Printf("[ERR]: %v", err)
 		flag.PrintDefaults()
-		os.Exit(1)
+		os.Exit(exitCodeError)
+	}
+	setColors(!parsedFlags.noColor)
+	setSecretsEncryption(!parsedFlags.noEncrypt)
+	if len(parsedFlags.encryptFile) != 0 {
+		if err := encryptFileInPlace(parsedFlags.encryptFile); err != nil {
+			exit(err)
+		}
+		fmt.Printf("%s is encrypted\n", parsedFlags.encryptFile)
+		return
+	}
+	if len(parsedFlags.tournament) != 0 {
+		setLanguage(parsedFlags.lang)
+		if err := runTournament(parsedFlags); err != nil {
+			exit(err)
+		}
+		return
+	}
+	if parsedFlags.newGame && !strings.Contains(parsedFlags.configFile, ",") {
+		if _, err := os.Stat(parsedFlags.configFile); os.IsNotExist(err) {
+			setLanguage(parsedFlags.lang)
+			if err := runWizard(parsedFlags.configFile, os.Stdin); err != nil {
+				exit(err)
+			}
+		}
+	}
+	configFiles := strings.Split(parsedFlags.configFile, ",")
+	if len(configFiles) > 1 {
+		runMultiGame(parsedFlags, configFiles)
+		return
 	}
 	conf, err := getConfiguration(parsedFlags)
 	if err != nil {
-		log.Fatalf("[ERR]: %v", err)
+		exit(err)
 	}
+	setLanguage(conf.Language)
 	app, err := newApp(conf)
 	if err != nil {
-		log.Fatalf("[ERR]: %v", err)
+		exit(err)
+	}
+	handleShutdown(app)
+	err = app.Run()
+	app.close()
+	if err != nil {
+		exit(fmt.Errorf("error during app run: %w", err))
+	}
+}
+
+// runMultiGame runs the games of several configuration files, each game is
+// stored in its own subdirectory of the output dir. The web server and the
+// control API serve the first game.
+func runMultiGame(fl *parsedFlags, configFiles []string) {
+	configs := make([]*Config, 0, len(configFiles))
+	for i, configFile := range configFiles {
+		gameFlags := *fl
+		gameFlags.configFile = configFile
+		if i != 0 {
+			gameFlags.httpAddr = ""
+			gameFlags.apiAddr = ""
+		}
+		conf, err := getConfiguration(&gameFlags)
+		if err != nil {
+			exit(fmt.Errorf("configuration %s: %w", configFile, err))
+		}
+		if kind, _, _ := parseStoreFlag(conf.Store); kind != storeKindBolt {
+			exit(fmt.Errorf("the %s store supports a single game, use the bolt store to run several games", kind))
+		}
+		conf.OutputDir = gameOutputDir(fl.outputDir, conf.GameName)
+		configs = append(configs, conf)
+	}
+	// the games share the REPL, it speaks the language of the first game
+	setLanguage(configs[0].Language)
+	games, err := newMultiGame(configs, fl.credsFile, fl.outputDir)
+	if err != nil {
+		exit(err)
 	}
-	if err := app.Run(); err != nil {
-		log.Fatalf("[ERR]: error during app run: %v", err)
+	handleShutdown(games.list()...)
+	err = games.Run()
+	games.close()
+	if err != nil {
+		exit(fmt.Errorf("error during app run: %w", err))
 	}
 }
 
@@ -42,31 +112,84 @@ func getConfiguration(fl *parsedFlags) (*Config, error) {
 	}
 	config.OutputDir = fl.outputDir
 	config.NewGame = fl.newGame
+	config.Force = fl.force
+	config.ReadOnly = fl.readOnly
 	config.CredsFile = fl.credsFile
+	config.OutputFormat = fl.outputFormat
+	config.HTTPAddr = fl.httpAddr
+	config.APIAddr = fl.apiAddr
+	config.Store = fl.store
+	config.ConfigFile = fl.configFile
+	if len(fl.lang) != 0 {
+		config.Language = fl.lang
+	}
+	config.Labels.setDefaults(config.Language)
+	if _, _, err := parseStoreFlag(config.Store); err != nil {
+		return nil, err
+	}
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
 	return config, nil
 }
 
 type parsedFlags struct {
-	configFile string
-	outputDir  string
-	newGame    bool
-	credsFile  string
+	configFile   string
+	outputDir    string
+	newGame      bool
+	force        bool
+	readOnly     bool
+	credsFile    string
+	outputFormat string
+	httpAddr     string
+	apiAddr      string
+	store        string
+	lang         string
+	tournament   string
+	noColor      bool
+	noEncrypt    bool
+	encryptFile  string
 }
 
 func parseFlags() (*parsedFlags, error) {
-	configFile := flag.String("config", "config.json", "configuration file path")
+	configFile := flag.String("config", "config.json", "configuration file path, several comma-separated files run several games")
 	outputDir := flag.String("out", "", "output dir")
 	newGame := flag.Bool("newGame", false, "indicates a new game creation`")
+	force := flag.Bool("force", false, "with --newGame, archive the game already created in the output dir or in Drive")
+	readOnly := flag.Bool("read-only", false, "observe a game run by another instance from the same output dir, only the commands reading the results are allowed")
 	credentials := flag.String("creds", "", "file that contains credentails for Google sheets API")
+	outputFormat := flag.String("output", outputFormatTable, "commands output format: table, json or yaml")
+	httpAddr := flag.String("http", "", "address of the optional web server, e.g. localhost:8080")
+	apiAddr := flag.String("api", "", "address of the optional control API, e.g. :9090")
+	store := flag.String("store", storeKindBolt, "game store: bolt or sqlite:<path>, the sqlite store needs a build with the sqlite tag")
+	lang := flag.String("lang", "", "language of the messages and of the spreadsheet labels: en or ru, overrides the configuration")
+	tournament := flag.String("tournament", "", "directory of the game archives of a tournament, prints the cumulative standings instead of running a game")
+	noColor := flag.Bool("no-color", false, "disable the colors of the terminal output")
+	noEncrypt := flag.Bool("no-encrypt", false, "keep the OAuth token in plain text in the output dir instead of encrypting it with a passphrase")
+	encryptFile := flag.String("encrypt-file", "", "encrypt a secret file in place, e.g. the credentials file, and exit")
 	flag.Parse()
-	if len(*outputDir) == 0 {
+	if len(*outputDir) == 0 && len(*tournament) == 0 && len(*encryptFile) == 0 {
 		return nil, fmt.Errorf("flag --o must be set")
 	}
+	if err := checkOutputFormat(*outputFormat); err != nil {
+		return nil, err
+	}
 	f := &parsedFlags{
-		configFile: *configFile,
-		outputDir:  *outputDir,
-		newGame:    *newGame,
-		credsFile:  *credentials,
+		configFile:   *configFile,
+		outputDir:    *outputDir,
+		newGame:      *newGame,
+		force:        *force,
+		readOnly:     *readOnly,
+		credsFile:    *credentials,
+		outputFormat: *outputFormat,
+		httpAddr:     *httpAddr,
+		apiAddr:      *apiAddr,
+		store:        *store,
+		lang:         *lang,
+		tournament:   *tournament,
+		noColor:      *noColor,
+		noEncrypt:    *noEncrypt,
+		encryptFile:  *encryptFile,
 	}
 	return f, nil
 }
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+// responseSourceManual marks the responses entered by the jury on behalf of
+// the teams, e.g. dictated by phone. The fetches keep them.
+const responseSourceManual = "manual"
+
+type manualResult struct {
+	Round    int    `json:"round" yaml:"round"`
+	Team     string `json:"team" yaml:"team"`
+	Response string `json:"response" yaml:"response"`
+	// Written is set if the answer is written into the team spreadsheet.
+	Written bool `json:"written" yaml:"written"`
+}
+
+func (r *manualResult) String() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Round %d answer of the team %s is recorded: %s\n", r.Round, r.Team, r.Response))
+	if r.Written {
+		sb.WriteString("The answer is written into the team spreadsheet\n")
+	}
+	return sb.String()
+}
+
+// CmdManual records an answer the jury enters on behalf of a team, e.g. when
+// the team has lost the connection and dictates it by phone: "manual <round>
+// <team> <answer>". A team name with spaces is quoted. The answer is marked
+// as manual, so that it is not replaced by the answers fetched later. With
+// WriteManualAnswers it is also written into the team spreadsheet.
+func (a *app) CmdManual(cmdStr string) (*manualResult, error) {
+	sSplitted := splitArgs(cmdStr)
+	if len(sSplitted) < 4 {
+		return nil, fmt.Errorf("expected the round, the team and the answer")
+	}
+	round, err := a.parseRound(sSplitted[1])
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := a.getTeamRoundCellPosition(round); err != nil {
+		return nil, err
+	}
+	team := sSplitted[2]
+	found := false
+	for _, t := range a.config.Teams {
+		if t == team {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("team %s is unknown", team)
+	}
+	answer := strings.TrimSpace(strings.Join(sSplitted[3:], " "))
+	results, err := a.store.getRoundResults(round)
+	if err != nil {
+		if !errors.Is(err, errRoundNotFound) {
+			return nil, err
+		}
+		results = &roundResults{}
+	}
+	results.Round = round
+	if results.Results == nil {
+		results.Results = make(map[string]*roundResponse)
+	}
+	version := 0
+	if previous, ok := results.Results[team]; ok {
+		version = previous.Version + 1
+	}
+	now := time.Now()
+	results.Results[team] = &roundResponse{
+		Response:    answer,
+		Status:      ResponseStatusNotChecked,
+		SubmittedAt: now,
+		Version:     version,
+		Source:      responseSourceManual,
+	}
+	if err := a.store.saveResponseRevisions(round, map[string]string{team: answer}, now); err != nil {
+		return nil, fmt.Errorf("failed to store the response history: %w", err)
+	}
+	if err := a.store.saveRoundResults(results); err != nil {
+		return nil, fmt.Errorf("failed to store round results: %w", err)
+	}
+	a.publishResults(eventTypeAnswers, results)
+	if err := a.store.appendEvent(fmt.Sprintf("manual: round %d, team %s answered %s", a.config.questionNumber(round), team, answer)); err != nil {
+		return nil, err
+	}
+	res := &manualResult{Round: a.config.questionNumber(round), Team: team, Response: answer}
+	if a.config.WriteManualAnswers {
+		if err := a.writeManualAnswer(round, team, answer); err != nil {
+			return nil, fmt.Errorf("the answer is recorded but not written into the team spreadsheet: %w", err)
+		}
+		res.Written = true
+	}
+	return res, nil
+}
+
+// writeManualAnswer writes the answer into the round cell of the team
+// spreadsheet, the manager spreadsheet then shows it as well.
+func (a *app) writeManualAnswer(round int, team string, answer string) error {
+	gameSheets, err := a.GetGameSpreadsheets()
+	if err != nil {
+		return err
+	}
+	teamSheet, ok := gameSheets.teams[team]
+	if !ok {
+		return fmt.Errorf("spreadsheet of the team %s is not found", team)
+	}
+	column, row, err := a.getTeamRoundCellPosition(round)
+	if err != nil {
+		return err
+	}
+	cell := sheetRange(teamSheet.toSpreadsheet().Sheets[0].Properties.Title, cellName(column, row))
+	_, err = sheets.NewSpreadsheetsValuesService(a.service).Update(teamSheet.ID, cell, &sheets.ValueRange{
+		Values: [][]interface{}{{answer}},
+	}).ValueInputOption("RAW").Context(a.commandContext()).Do()
+	if err != nil {
+		return err
+	}
+	log.Printf("wrote the manual round %d answer of the team %s into its spreadsheet", a.config.questionNumber(round), team)
+	return nil
+}
+
+// keepManualResponse returns the stored manual response that the fetched one
+// must not replace, or nil. A fetched answer that differs from the manual one
+// is reported.
+func keepManualResponse(question int, team string, previous *roundResponse, fetched string) *roundResponse {
+	if previous == nil || previous.Source != responseSourceManual {
+		return nil
+	}
+	if len(strings.TrimSpace(fetched)) != 0 && fetched != previous.Response {
+		log.Printf("the fetched round %d answer of the team %s \"%s\" differs from the manual one \"%s\", the manual answer is kept", question, team, fetched, previous.Response)
+	}
+	return previous
+}
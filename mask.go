@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+// rawSheetTitle is the hidden sheet of the manager spreadsheet holding the
+// team answers links when the answers are masked.
+const rawSheetTitle = "Raw"
+
+// ensureRawSheet returns the ID of the raw sheet, adding it hidden and
+// protected to the manager spreadsheet if needed.
+func (a *app) ensureRawSheet(managerID string) (int64, error) {
+	manager, err := a.getSpreadsheetMetadata(a.commandContext(), managerID)
+	if err != nil {
+		return 0, err
+	}
+	if sheet, ok := manager.sheetByTitle(rawSheetTitle); ok {
+		return sheet.ID, nil
+	}
+	spreadsheetsService := sheets.NewSpreadsheetsService(a.service)
+	resp, err := spreadsheetsService.BatchUpdate(managerID, &sheets.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheets.Request{
+			{
+				AddSheet: &sheets.AddSheetRequest{
+					Properties: &sheets.SheetProperties{
+						Title:  rawSheetTitle,
+						Hidden: true,
+					},
+				},
+			},
+		},
+	}).Context(a.commandContext()).Do()
+	if err != nil {
+		return 0, fmt.Errorf("failed to add the raw sheet: %w", err)
+	}
+	sheetID := resp.Replies[0].AddSheet.Properties.SheetId
+	a.metadata.invalidate(managerID)
+	_, err = spreadsheetsService.BatchUpdate(managerID, &sheets.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheets.Request{
+			{
+				AddProtectedRange: &sheets.AddProtectedRangeRequest{
+					ProtectedRange: &sheets.ProtectedRange{
+						Description: "masked answers",
+						Range:       &sheets.GridRange{SheetId: sheetID},
+						Editors:     &sheets.Editors{},
+					},
+				},
+			},
+		},
+	}).Context(a.commandContext()).Do()
+	if err != nil {
+		return 0, fmt.Errorf("failed to protect the raw sheet: %w", err)
+	}
+	return sheetID, nil
+}
+
+// maskLinks moves the team answers links to the raw sheet.
+func (a *app) maskLinks(managerID string, groups []*sheets.ValueRange) error {
+	if _, err := a.ensureRawSheet(managerID); err != nil {
+		return err
+	}
+	for _, g := range groups {
+		g.Range = sheetRange(rawSheetTitle, g.Range)
+	}
+	return nil
+}
+
+// getRoundA1Range returns the A1 notation of the round answers column.
+func (a *app) getRoundA1Range(round int) (string, error) {
+	gr, err := a.getRoundRange(round)
+	if err != nil {
+		return "", err
+	}
+	return rangeName(int(gr.StartColumnIndex), int(gr.StartRowIndex)+1, int(gr.EndColumnIndex)-1, int(gr.EndRowIndex)), nil
+}
+
+// closeMaskedRound copies the masked round answers to the manager sheet and
+// records the round as closed.
+func (a *app) closeMaskedRound(round int) error {
+	if err := a.revealRound(round); err != nil {
+		return err
+	}
+	if err := a.store.saveClosedRound(round, time.Now()); err != nil {
+		return err
+	}
+	return a.store.appendEvent(fmt.Sprintf("close: round %d", a.config.questionNumber(round)))
+}
+
+// revealRound copies the values of the round answers from the raw sheet to
+// the manager sheet.
+func (a *app) revealRound(round int) error {
+	gameSheets, err := a.GetGameSpreadsheets()
+	if err != nil {
+		return err
+	}
+	if gameSheets.manager == nil {
+		return errManagerSpreadsheetNotFound
+	}
+	r, err := a.getRoundA1Range(round)
+	if err != nil {
+		return err
+	}
+	valuesService := sheets.NewSpreadsheetsValuesService(a.service)
+	resp, err := valuesService.Get(gameSheets.manager.ID, sheetRange(rawSheetTitle, r)).Context(a.commandContext()).Do()
+	if err != nil {
+		return fmt.Errorf("failed to read the masked round %d answers: %w", a.config.questionNumber(round), err)
+	}
+	values := resp.Values
+	if values == nil {
+		values = [][]interface{}{}
+	}
+	_, err = valuesService.Update(gameSheets.manager.ID, sheetRange(gameSheets.manager.SheetTitle, r), &sheets.ValueRange{
+		Values: values,
+	}).ValueInputOption("RAW").Context(a.commandContext()).Do()
+	if err != nil {
+		return fmt.Errorf("failed to reveal the round %d answers: %w", a.config.questionNumber(round), err)
+	}
+	return nil
+}
+
+// checkRoundClosed returns an error if the answers are masked and the round
+// is not closed, as the manager sheet has no answers to fetch yet.
+func (a *app) checkRoundClosed(round int) error {
+	if !a.config.MaskAnswers {
+		return nil
+	}
+	closed, err := a.store.getClosedRounds()
+	if err != nil {
+		return err
+	}
+	if _, ok := closed[round]; !ok {
+		return fmt.Errorf("round %d answers are masked, close the round first", a.config.questionNumber(round))
+	}
+	return nil
+}
+
+// revealClosedRounds shows again the answers of the closed rounds, after the
+// manager spreadsheet layout changes.
+func (a *app) revealClosedRounds() error {
+	closed, err := a.store.getClosedRounds()
+	if err != nil {
+		return err
+	}
+	for round := range closed {
+		if err := a.revealRound(round); err != nil {
+			return err
+		}
+	}
+	return nil
+}
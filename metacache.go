@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// spreadsheetMetadata holds the parts of the spreadsheet metadata the tool
+// needs repeatedly: the sheets and the protected ranges.
+type spreadsheetMetadata struct {
+	fetchedAt time.Time
+	sheets    []sheetMetadata
+	// protectedRanges maps the protected range IDs to their descriptions
+	protectedRanges map[int64]string
+}
+
+type sheetMetadata struct {
+	ID    int64
+	Title string
+}
+
+// storedMetadata is the representation of the metadata in the game store.
+type storedMetadata struct {
+	FetchedAt       time.Time
+	Sheets          []sheetMetadata
+	ProtectedRanges map[int64]string
+}
+
+func (m *spreadsheetMetadata) sheetByTitle(title string) (sheetMetadata, bool) {
+	for _, s := range m.sheets {
+		if s.Title == title {
+			return s, true
+		}
+	}
+	return sheetMetadata{}, false
+}
+
+// protectedRangeByDescription returns the ID of the protected range with the
+// description, if any.
+func (m *spreadsheetMetadata) protectedRangeByDescription(description string) (int64, bool) {
+	for id, d := range m.protectedRanges {
+		if d == description {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+// metadataCache is a read-through cache of the spreadsheets metadata. An entry
+// is fetched again once it is older than ttl. The entries are kept in the game
+// store too, so that they survive a restart and are shared with the observer
+// sessions.
+type metadataCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]*spreadsheetMetadata
+	// store is nil until the game store is opened
+	store cacheStore
+}
+
+// cacheStore keeps the cache entries, the failures to write them are logged
+// only, as the cache is not required for the game.
+type cacheStore interface {
+	saveCacheEntry(key string, value []byte) error
+	getCacheEntry(key string) ([]byte, error)
+	deleteCacheEntries(prefix string) error
+}
+
+const metadataCachePrefix = "metadata/"
+
+func metadataCacheKey(spreadsheetID string) string {
+	return metadataCachePrefix + spreadsheetID
+}
+
+func newMetadataCache(ttlSeconds int) *metadataCache {
+	if ttlSeconds <= 0 {
+		ttlSeconds = 300
+	}
+	return &metadataCache{
+		ttl:     time.Duration(ttlSeconds) * time.Second,
+		entries: make(map[string]*spreadsheetMetadata),
+	}
+}
+
+func (c *metadataCache) get(spreadsheetID string) (*spreadsheetMetadata, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	m, ok := c.entries[spreadsheetID]
+	if !ok {
+		m, ok = c.load(spreadsheetID)
+	}
+	if !ok || time.Since(m.fetchedAt) > c.ttl {
+		return nil, false
+	}
+	return m, true
+}
+
+// load reads the entry from the store into the memory.
+func (c *metadataCache) load(spreadsheetID string) (*spreadsheetMetadata, bool) {
+	if c.store == nil {
+		return nil, false
+	}
+	value, err := c.store.getCacheEntry(metadataCacheKey(spreadsheetID))
+	if err != nil || value == nil {
+		return nil, false
+	}
+	var stored storedMetadata
+	if err := json.Unmarshal(value, &stored); err != nil {
+		return nil, false
+	}
+	m := &spreadsheetMetadata{
+		fetchedAt:       stored.FetchedAt,
+		sheets:          stored.Sheets,
+		protectedRanges: stored.ProtectedRanges,
+	}
+	if m.protectedRanges == nil {
+		m.protectedRanges = make(map[int64]string)
+	}
+	c.entries[spreadsheetID] = m
+	return m, true
+}
+
+// save writes the entry to the store, the lock must be held.
+func (c *metadataCache) save(spreadsheetID string, m *spreadsheetMetadata) {
+	if c.store == nil {
+		return
+	}
+	value, err := json.Marshal(&storedMetadata{
+		FetchedAt:       m.fetchedAt,
+		Sheets:          m.sheets,
+		ProtectedRanges: m.protectedRanges,
+	})
+	if err == nil {
+		err = c.store.saveCacheEntry(metadataCacheKey(spreadsheetID), value)
+	}
+	if err != nil {
+		log.Printf("failed to store the spreadsheet %s metadata: %v", spreadsheetID, err)
+	}
+}
+
+func (c *metadataCache) put(spreadsheetID string, m *spreadsheetMetadata) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[spreadsheetID] = m
+	c.save(spreadsheetID, m)
+}
+
+func (c *metadataCache) invalidate(spreadsheetID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, spreadsheetID)
+	if c.store == nil {
+		return
+	}
+	if err := c.store.deleteCacheEntries(metadataCacheKey(spreadsheetID)); err != nil {
+		log.Printf("failed to remove the spreadsheet %s metadata from the store: %v", spreadsheetID, err)
+	}
+}
+
+// invalidateAll drops all the entries.
+func (c *metadataCache) invalidateAll() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string]*spreadsheetMetadata)
+	if c.store == nil {
+		return nil
+	}
+	return c.store.deleteCacheEntries(metadataCachePrefix)
+}
+
+// addProtectedRange and removeProtectedRange keep a cached entry in sync with
+// the changes made by the tool, so that it is not fetched again.
+func (c *metadataCache) addProtectedRange(spreadsheetID string, id int64, description string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if m, ok := c.entries[spreadsheetID]; ok {
+		m.protectedRanges[id] = description
+		c.save(spreadsheetID, m)
+	}
+}
+
+func (c *metadataCache) removeProtectedRange(spreadsheetID string, id int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if m, ok := c.entries[spreadsheetID]; ok {
+		delete(m.protectedRanges, id)
+		c.save(spreadsheetID, m)
+	}
+}
+
+// getSpreadsheetMetadata returns the cached metadata of the spreadsheet,
+// fetching it with ctx if it is not cached or expired.
+func (a *app) getSpreadsheetMetadata(ctx context.Context, spreadsheetID string) (*spreadsheetMetadata, error) {
+	if m, ok := a.metadata.get(spreadsheetID); ok {
+		return m, nil
+	}
+	spreadsheet, err := a.service.Spreadsheets.Get(spreadsheetID).
+		Fields("sheets(properties(sheetId,title),protectedRanges(protectedRangeId,description))").Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the spreadsheet %s metadata: %w", spreadsheetID, err)
+	}
+	m := &spreadsheetMetadata{
+		fetchedAt:       time.Now(),
+		sheets:          make([]sheetMetadata, 0, len(spreadsheet.Sheets)),
+		protectedRanges: make(map[int64]string),
+	}
+	for _, sheet := range spreadsheet.Sheets {
+		if sheet.Properties != nil {
+			m.sheets = append(m.sheets, sheetMetadata{ID: sheet.Properties.SheetId, Title: sheet.Properties.Title})
+		}
+		for _, pr := range sheet.ProtectedRanges {
+			m.protectedRanges[pr.ProtectedRangeId] = pr.Description
+		}
+	}
+	a.metadata.put(spreadsheetID, m)
+	return m, nil
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var fetchLatencyBuckets = []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+type histogram struct {
+	mu      sync.Mutex
+	buckets []float64
+	counts  []uint64
+	sum     float64
+	count   uint64
+}
+
+func newHistogram(buckets []float64) *histogram {
+	return &histogram{
+		buckets: buckets,
+		counts:  make([]uint64, len(buckets)),
+	}
+}
+
+func (h *histogram) observe(v float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, b := range h.buckets {
+		if v <= b {
+			h.counts[i]++
+		}
+	}
+	h.sum += v
+	h.count++
+}
+
+func (h *histogram) write(w io.Writer, name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, b := range h.buckets {
+		fmt.Fprintf(w, "%s_bucket{le=\"%g\"} %d\n", name, b, h.counts[i])
+	}
+	fmt.Fprintf(w, "%s_bucket{le=\"+Inf\"} %d\n", name, h.count)
+	fmt.Fprintf(w, "%s_sum %g\n", name, h.sum)
+	fmt.Fprintf(w, "%s_count %d\n", name, h.count)
+}
+
+type metrics struct {
+	apiCalls     uint64
+	apiErrors    uint64
+	apiRetries   uint64
+	fetchLatency *histogram
+	// callTimes are the times of the API calls of the last minute
+	callsMu   sync.Mutex
+	callTimes []time.Time
+}
+
+func newMetrics() *metrics {
+	return &metrics{
+		fetchLatency: newHistogram(fetchLatencyBuckets),
+	}
+}
+
+// metricsTransport counts the Google API requests passing through it.
+type metricsTransport struct {
+	base    http.RoundTripper
+	metrics *metrics
+}
+
+func (t *metricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	atomic.AddUint64(&t.metrics.apiCalls, 1)
+	t.metrics.recordCall(time.Now())
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || resp.StatusCode >= 400 {
+		atomic.AddUint64(&t.metrics.apiErrors, 1)
+	}
+	return resp, err
+}
+
+func (a *app) serveHTTP() {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", a.handleMetrics)
+	mux.Handle("/events", a.handleEvents())
+	mux.HandleFunc("/", a.handleDashboard)
+	log.Printf("serving HTTP on %s", a.config.HTTPAddr)
+	if err := a.listenAndServe(&http.Server{Addr: a.config.HTTPAddr, Handler: mux}); err != nil {
+		log.Printf("[ERR]: HTTP server stopped: %v", err)
+	}
+}
+
+func (a *app) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	checked, unchecked, err := a.countCheckedRounds()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	m := a.metrics
+	fmt.Fprintln(w, "# HELP chgk_sheets_api_calls_total Google API calls.")
+	fmt.Fprintln(w, "# TYPE chgk_sheets_api_calls_total counter")
+	fmt.Fprintf(w, "chgk_sheets_api_calls_total %d\n", atomic.LoadUint64(&m.apiCalls))
+	fmt.Fprintln(w, "# HELP chgk_sheets_api_errors_total Failed Google API calls.")
+	fmt.Fprintln(w, "# TYPE chgk_sheets_api_errors_total counter")
+	fmt.Fprintf(w, "chgk_sheets_api_errors_total %d\n", atomic.LoadUint64(&m.apiErrors))
+	fmt.Fprintln(w, "# HELP chgk_sheets_api_retries_total Retried Google API calls.")
+	fmt.Fprintln(w, "# TYPE chgk_sheets_api_retries_total counter")
+	fmt.Fprintf(w, "chgk_sheets_api_retries_total %d\n", atomic.LoadUint64(&m.apiRetries))
+	fmt.Fprintln(w, "# HELP chgk_fetch_latency_seconds Round fetch latency.")
+	fmt.Fprintln(w, "# TYPE chgk_fetch_latency_seconds histogram")
+	m.fetchLatency.write(w, "chgk_fetch_latency_seconds")
+	fmt.Fprintln(w, "# HELP chgk_rounds_checked Fetched rounds with all responses checked.")
+	fmt.Fprintln(w, "# TYPE chgk_rounds_checked gauge")
+	fmt.Fprintf(w, "chgk_rounds_checked{game=%q} %d\n", a.config.GameName, checked)
+	fmt.Fprintln(w, "# HELP chgk_rounds_unchecked Fetched rounds with unchecked responses.")
+	fmt.Fprintln(w, "# TYPE chgk_rounds_unchecked gauge")
+	fmt.Fprintf(w, "chgk_rounds_unchecked{game=%q} %d\n", a.config.GameName, unchecked)
+}
+
+func (a *app) countCheckedRounds() (int, int, error) {
+	allResults, err := a.store.getAllRoundResults()
+	if err != nil {
+		return 0, 0, err
+	}
+	checked, unchecked := 0, 0
+	for _, results := range allResults {
+		isChecked := true
+		for _, resp := range results.Results {
+			if resp.Status == ResponseStatusNotChecked {
+				isChecked = false
+				break
+			}
+		}
+		if isChecked {
+			checked++
+		} else {
+			unchecked++
+		}
+	}
+	return checked, unchecked, nil
+}
+
+func (m *metrics) recordCall(t time.Time) {
+	m.callsMu.Lock()
+	defer m.callsMu.Unlock()
+	m.callTimes = append(dropBefore(m.callTimes, t.Add(-time.Minute)), t)
+}
+
+// recentCalls returns the number of the API calls in the last period, at most
+// a minute.
+func (m *metrics) recentCalls(period time.Duration) int {
+	m.callsMu.Lock()
+	defer m.callsMu.Unlock()
+	m.callTimes = dropBefore(m.callTimes, time.Now().Add(-time.Minute))
+	return len(dropBefore(m.callTimes, time.Now().Add(-period)))
+}
+
+func dropBefore(times []time.Time, t time.Time) []time.Time {
+	i := 0
+	for i < len(times) && times[i].Before(t) {
+		i++
+	}
+	return times[i:]
+}
+
+func (m *metrics) observeFetch(start time.Time) {
+	m.fetchLatency.observe(time.Since(start).Seconds())
+}
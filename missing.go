@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+type missingResult struct {
+	Round    int      `json:"round" yaml:"round"`
+	Teams    []string `json:"teams" yaml:"teams"`
+	Notified bool     `json:"notified" yaml:"notified"`
+}
+
+func (r *missingResult) String() string {
+	if len(r.Teams) == 0 {
+		return fmt.Sprintf("All the teams have answered the round %d", r.Round)
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Round %d answers are missing for the teams: %s", r.Round, strings.Join(r.Teams, ", ")))
+	if r.Notified {
+		sb.WriteString("\nThe teams are notified in their spreadsheets")
+	}
+	return sb.String()
+}
+
+// CmdMissing lists the teams whose fetched round answer is empty:
+// "missing <round> [--notify]". With --notify the answer cell of those teams
+// is highlighted and gets a "no answer received" note, which is removed from
+// the cells of the teams that have answered.
+func (a *app) CmdMissing(cmdStr string) (*missingResult, error) {
+	notify := false
+	sSplitted := splitArgs(cmdStr)
+	if len(sSplitted) == 3 && sSplitted[2] == "--notify" {
+		notify = true
+		cmdStr = strings.Join(sSplitted[:2], " ")
+	}
+	round, err := a.getRoundNumber(cmdStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse missing request: %w", err)
+	}
+	return a.roundMissing(round, notify)
+}
+
+// roundMissing lists the teams without the round answer and notifies them if
+// asked.
+func (a *app) roundMissing(round int, notify bool) (*missingResult, error) {
+	results, err := a.store.getRoundResults(round)
+	if err != nil {
+		return nil, fmt.Errorf("%w, fetch the round first", err)
+	}
+	res := &missingResult{Round: a.config.questionNumber(round), Teams: make([]string, 0)}
+	missing := make(map[string]bool)
+	for _, team := range a.config.Teams {
+		resp, ok := results.Results[team]
+		if ok && len(strings.TrimSpace(resp.Response)) != 0 {
+			continue
+		}
+		missing[team] = true
+		res.Teams = append(res.Teams, team)
+	}
+	if notify {
+		if err := a.notifyMissing(round, missing); err != nil {
+			return nil, err
+		}
+		res.Notified = true
+	}
+	return res, nil
+}
+
+func (a *app) notifyMissing(round int, missing map[string]bool) error {
+	gameSheets, err := a.GetGameSpreadsheets()
+	if err != nil {
+		return err
+	}
+	column, row, err := a.getTeamRoundCellPosition(round)
+	if err != nil {
+		return err
+	}
+	spreadsheetsService := sheets.NewSpreadsheetsService(a.service)
+	for _, team := range a.config.Teams {
+		teamSheet, ok := gameSheets.teams[team]
+		if !ok {
+			return fmt.Errorf("spreadsheet of the team %s is not found", team)
+		}
+		cell := &sheets.CellData{}
+		if missing[team] {
+			cell.Note = "no answer received"
+			cell.UserEnteredFormat = &sheets.CellFormat{
+				BackgroundColor: &sheets.Color{Red: 1, Green: 0.9, Blue: 0.6},
+			}
+		}
+		_, err := spreadsheetsService.BatchUpdate(teamSheet.ID, &sheets.BatchUpdateSpreadsheetRequest{
+			Requests: []*sheets.Request{
+				{
+					UpdateCells: &sheets.UpdateCellsRequest{
+						Range: &sheets.GridRange{
+							SheetId:          teamSheet.SheetID,
+							StartColumnIndex: int64(column),
+							EndColumnIndex:   int64(column + 1),
+							StartRowIndex:    int64(row - 1),
+							EndRowIndex:      int64(row),
+						},
+						Rows:   []*sheets.RowData{{Values: []*sheets.CellData{cell}}},
+						Fields: "note,userEnteredFormat.backgroundColor",
+					},
+				},
+			},
+		}).Context(a.commandContext()).Do()
+		if err != nil {
+			return fmt.Errorf("failed to notify the team %s: %w", team, err)
+		}
+	}
+	log.Printf("notified %d teams about their missing round %d answer", len(missing), a.config.questionNumber(round))
+	return nil
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+)
+
+// multiGame runs several games from one process. The commands are addressed
+// to the current game unless prefixed with "game <name>".
+type multiGame struct {
+	apps    map[string]*app
+	names   []string
+	current string
+}
+
+// gameOutputDir is the output dir of a game run along with other games, each
+// game keeps its own bolt database in it.
+func gameOutputDir(outputDir string, gameName string) string {
+	return path.Join(outputDir, strings.NewReplacer("/", "_", "\\", "_").Replace(gameName))
+}
+
+func newMultiGame(configs []*Config, credsFile string, outputDir string) (*multiGame, error) {
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create the output directory %s: %w", outputDir, err)
+	}
+	m := &multiGame{
+		apps:  make(map[string]*app, len(configs)),
+		names: make([]string, 0, len(configs)),
+	}
+	for _, config := range configs {
+		if _, ok := m.apps[config.GameName]; ok {
+			return nil, fmt.Errorf("game %s is configured several times", config.GameName)
+		}
+		if err := checkOutputDir(config.NewGame, config.Force, config.OutputDir); err != nil {
+			return nil, err
+		}
+		m.apps[config.GameName] = nil
+	}
+	clients, err := newAPIClients(configs, credsFile, outputDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, config := range configs {
+		a, err := newAppWithClients(config, clients)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set up the game %s: %w", config.GameName, err)
+		}
+		m.apps[config.GameName] = a
+		m.names = append(m.names, config.GameName)
+	}
+	m.current = m.names[0]
+	return m, nil
+}
+
+func (m *multiGame) Run() error {
+	for _, name := range m.names {
+		if err := m.apps[name].start(); err != nil {
+			return fmt.Errorf("failed to start the game %s: %w", name, err)
+		}
+	}
+	fmt.Printf("Running the games: %s. The current game is %s, switch with \"game <name>\".\n", strings.Join(m.names, ", "), m.current)
+	return runREPL(m.apps[m.current].config.OutputFormat, m.apps[m.current].healthIndicator, m.resolve)
+}
+
+// list returns the games in the order of the configurations.
+func (m *multiGame) list() []*app {
+	apps := make([]*app, 0, len(m.names))
+	for _, name := range m.names {
+		apps = append(apps, m.apps[name])
+	}
+	return apps
+}
+
+func (m *multiGame) close() {
+	for _, name := range m.names {
+		m.apps[name].close()
+	}
+}
+
+// resolve handles "game", which lists the games, and "game <name>", which
+// switches the current game. "game <name> <command>" runs the command in the
+// game without switching.
+func (m *multiGame) resolve(cmdStr string) (*app, string, error) {
+	if getCommand(cmdStr) != "game" {
+		return m.apps[m.current], cmdStr, nil
+	}
+	rest := strings.TrimSpace(strings.TrimPrefix(cmdStr, "game"))
+	if len(rest) == 0 {
+		for _, name := range m.names {
+			marker := " "
+			if name == m.current {
+				marker = "*"
+			}
+			fmt.Printf("%s %s\n", marker, name)
+		}
+		return nil, "", nil
+	}
+	// the longest matching name wins, as game names may contain spaces
+	game := ""
+	for _, name := range m.names {
+		if (rest == name || strings.HasPrefix(rest, name+" ")) && len(name) > len(game) {
+			game = name
+		}
+	}
+	if len(game) == 0 {
+		return nil, "", fmt.Errorf("unknown game in \"%s\", the games are: %s", cmdStr, strings.Join(m.names, ", "))
+	}
+	gameCmd := strings.TrimSpace(strings.TrimPrefix(rest, game))
+	if len(gameCmd) == 0 {
+		m.current = game
+		fmt.Printf("The current game is %s\n", game)
+		return nil, "", nil
+	}
+	return m.apps[game], gameCmd, nil
+}
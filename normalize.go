@@ -0,0 +1,280 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// normalizerFunc transforms an answer before the answers are compared.
+type normalizerFunc func(string) string
+
+// defaultNormalizers are the normalizers of an empty configuration.
+var defaultNormalizers = []string{"trim", "lowercase", "collapse-spaces"}
+
+// normalizers are the named transforms the answer normalization pipeline can
+// be configured with.
+var normalizers = map[string]normalizerFunc{
+	"trim":      strings.TrimSpace,
+	"lowercase": strings.ToLower,
+	"collapse-spaces": func(s string) string {
+		return strings.Join(strings.Fields(s), " ")
+	},
+	"yo-to-ye": func(s string) string {
+		return strings.NewReplacer("ё", "е", "Ё", "Е").Replace(s)
+	},
+	"strip-hyphens": func(s string) string {
+		return strings.Map(func(r rune) rune {
+			if unicode.Is(unicode.Pd, r) {
+				return ' '
+			}
+			return r
+		}, s)
+	},
+	"strip-punctuation": func(s string) string {
+		return strings.Map(func(r rune) rune {
+			if unicode.IsPunct(r) && !unicode.Is(unicode.Pd, r) {
+				return -1
+			}
+			return r
+		}, s)
+	},
+	"strip-quotes": func(s string) string {
+		return strings.Map(func(r rune) rune {
+			if unicode.In(r, unicode.Pi, unicode.Pf) || r == '"' || r == '\'' {
+				return -1
+			}
+			return r
+		}, s)
+	},
+	"translit": transliterate,
+}
+
+var translitTable = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e",
+	'ж': "zh", 'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m",
+	'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u",
+	'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch",
+	'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+}
+
+// transliterate converts the Cyrillic letters to Latin, so that an answer
+// written in either script normalizes to the same text.
+func transliterate(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		lower := unicode.ToLower(r)
+		t, ok := translitTable[lower]
+		if !ok {
+			sb.WriteRune(r)
+			continue
+		}
+		if lower != r && len(t) != 0 {
+			t = strings.ToUpper(t[:1]) + t[1:]
+		}
+		sb.WriteString(t)
+	}
+	return sb.String()
+}
+
+// normalizerPipeline applies the normalizers in order.
+type normalizerPipeline []normalizerFunc
+
+// newNormalizerPipeline returns the pipeline of the named normalizers, the
+// default ones if no name is given.
+func newNormalizerPipeline(names []string) (normalizerPipeline, error) {
+	if len(names) == 0 {
+		names = defaultNormalizers
+	}
+	pipeline := make(normalizerPipeline, 0, len(names))
+	for _, name := range names {
+		fn, ok := normalizers[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown answer normalizer %s", name)
+		}
+		pipeline = append(pipeline, fn)
+	}
+	return pipeline, nil
+}
+
+// Normalize returns the normalized answer.
+func (p normalizerPipeline) normalize(s string) string {
+	for _, fn := range p {
+		s = fn(s)
+	}
+	return s
+}
+
+// similarityThreshold returns the maximum edit distance between two
+// normalized answers of the given length that are considered near-identical.
+func similarityThreshold(length int) int {
+	switch {
+	case length <= 3:
+		return 0
+	case length <= 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// levenshtein returns the edit distance between the strings in runes.
+func levenshtein(a string, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min3(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+func min3(a int, b int, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
+// isSimilar reports whether the normalized answers are near-identical.
+func isSimilar(a string, b string) bool {
+	if a == b {
+		return true
+	}
+	length := len([]rune(a))
+	if l := len([]rune(b)); l < length {
+		length = l
+	}
+	return levenshtein(a, b) <= similarityThreshold(length)
+}
+
+type answerCluster struct {
+	Normalized string   `json:"normalized" yaml:"normalized"`
+	Teams      []string `json:"teams" yaml:"teams"`
+	Responses  []string `json:"responses" yaml:"responses"`
+}
+
+// clusterResponses groups the teams whose normalized responses are
+// near-identical. The clusters are sorted by their normalized text.
+func clusterResponses(results *roundResults, pipeline normalizerPipeline) []*answerCluster {
+	teams := make([]string, 0, len(results.Results))
+	for team := range results.Results {
+		teams = append(teams, team)
+	}
+	sort.Strings(teams)
+	clusters := make([]*answerCluster, 0)
+	for _, team := range teams {
+		resp := results.Results[team].Response
+		normalized := pipeline.normalize(resp)
+		var cluster *answerCluster
+		for _, c := range clusters {
+			if isSimilar(c.Normalized, normalized) {
+				cluster = c
+				break
+			}
+		}
+		if cluster == nil {
+			cluster = &answerCluster{Normalized: normalized}
+			clusters = append(clusters, cluster)
+		}
+		cluster.Teams = append(cluster.Teams, team)
+		cluster.Responses = append(cluster.Responses, resp)
+	}
+	sort.SliceStable(clusters, func(i, j int) bool {
+		return clusters[i].Normalized < clusters[j].Normalized
+	})
+	return clusters
+}
+
+type similarResult struct {
+	Round    int              `json:"round" yaml:"round"`
+	Clusters []*answerCluster `json:"clusters" yaml:"clusters"`
+}
+
+func (r *similarResult) String() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Round %d answers grouped by similarity:\n", r.Round))
+	for _, c := range r.Clusters {
+		normalized, _ := truncateAnswer(c.Normalized, answerDisplayWidth)
+		sb.WriteString(fmt.Sprintf("\t %s (%d):\n", normalized, len(c.Teams)))
+		for i, team := range c.Teams {
+			resp, _ := truncateAnswer(c.Responses[i], answerDisplayWidth)
+			sb.WriteString(fmt.Sprintf("\t\t team %s: %s\n", team, resp))
+		}
+	}
+	return sb.String()
+}
+
+func (a *app) CmdSimilar(cmdStr string) (*similarResult, error) {
+	round, err := a.getRoundNumber(cmdStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse similar request: %w", err)
+	}
+	results, err := a.store.getRoundResults(round)
+	if err != nil {
+		return nil, err
+	}
+	pipeline, err := newNormalizerPipeline(a.config.Normalizers)
+	if err != nil {
+		return nil, err
+	}
+	res := &similarResult{
+		Round:    a.config.questionNumber(round),
+		Clusters: clusterResponses(results, pipeline),
+	}
+	return res, nil
+}
+
+// autoGrade marks as correct the unchecked responses that normalize to one of
+// the accepted answers of the round, the configured ones or the ones of the
+// questions file. It returns the auto-graded teams.
+func (a *app) autoGrade(results *roundResults) ([]string, error) {
+	accepted := a.config.Answers[a.config.questionNumber(results.Round)]
+	if len(accepted) == 0 && len(a.config.Questions.File) != 0 {
+		questions, err := a.config.Questions.load()
+		if err != nil {
+			return nil, err
+		}
+		if q, ok := questions[a.config.questionNumber(results.Round)]; ok {
+			accepted = q.Answers
+		}
+	}
+	if len(accepted) == 0 {
+		return nil, nil
+	}
+	pipeline, err := newNormalizerPipeline(a.config.Normalizers)
+	if err != nil {
+		return nil, err
+	}
+	normalizedAccepted := make(map[string]bool, len(accepted))
+	for _, answer := range accepted {
+		normalizedAccepted[pipeline.normalize(answer)] = true
+	}
+	graded := make([]string, 0)
+	for team, resp := range results.Results {
+		if resp.Status != ResponseStatusNotChecked {
+			continue
+		}
+		if normalizedAccepted[pipeline.normalize(resp.Response)] {
+			resp.Status = ResponseStatusOK
+			graded = append(graded, team)
+		}
+	}
+	sort.Strings(graded)
+	return graded, nil
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+)
+
+func (c *Config) firstQuestionNumber() int {
+	if c.FirstQuestionNumber <= 0 {
+		return 1
+	}
+	return c.FirstQuestionNumber
+}
+
+// questionNumber returns the number the question of the round has in the
+// tournament, e.g. 37 for the first round of a game started at the question
+// 37. The warm-up question keeps the number 0.
+func (c *Config) questionNumber(round int) int {
+	if round == 0 {
+		return 0
+	}
+	return round + c.firstQuestionNumber() - 1
+}
+
+// roundNumbering numbers the rounds in the messages of the stores as the
+// questions of the tournament. Without it the rounds keep their own numbers.
+type roundNumbering func(round int) int
+
+func (n roundNumbering) question(round int) int {
+	if n == nil {
+		return round
+	}
+	return n(round)
+}
+
+// roundOfQuestion returns the round of the question number given by the user.
+func (c *Config) roundOfQuestion(number int) (int, error) {
+	if number == 0 {
+		return 0, nil
+	}
+	if number < c.firstQuestionNumber() {
+		return 0, fmt.Errorf("question %d is before the first question of the game %d", number, c.firstQuestionNumber())
+	}
+	return number - c.firstQuestionNumber() + 1, nil
+}
+
+// hasQuestion tells if the question number given by the user is a question
+// of the game, the warm-up question 0 included.
+func (c *Config) hasQuestion(number int) bool {
+	if number == 0 {
+		return c.HasWarmUpQuestion
+	}
+	return number >= c.firstQuestionNumber() && number < c.firstQuestionNumber()+c.NumberOfQuestions
+}
+
+// errRoundOutOfRange reports a round that is not a round of the game by its
+// question number.
+func (c *Config) errRoundOutOfRange(round int) error {
+	return fmt.Errorf("round %d is out of range [%d; %d]", c.questionNumber(round), c.firstQuestionNumber(), c.questionNumber(c.NumberOfQuestions))
+}
+
+// numberResults sets the number of the round question in the tournament on
+// the results shown to the user.
+func (a *app) numberResults(results *roundResults) *roundResults {
+	results.Question = a.config.questionNumber(results.Round)
+	return results
+}
+
+// parseRound parses the question number of a command argument as a round.
+func (a *app) parseRound(s string) (int, error) {
+	number, err := strconv.ParseInt(s, 0, 0)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse argument %s as a round number: %w", s, err)
+	}
+	return a.config.roundOfQuestion(int(number))
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+const connectivityProbeInterval = 15 * time.Second
+
+// connectivity tracks whether the Google API is reachable, it is updated by
+// every request passing through connectivityTransport.
+type connectivity struct {
+	mu     sync.Mutex
+	online bool
+}
+
+func newConnectivity() *connectivity {
+	return &connectivity{online: true}
+}
+
+func (c *connectivity) isOnline() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.online
+}
+
+func (c *connectivity) set(online bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.online != online {
+		if online {
+			log.Printf("the Google API is reachable again")
+		} else {
+			log.Printf("the Google API is unreachable, switching to the offline mode")
+		}
+	}
+	c.online = online
+}
+
+func (c *connectivity) indicator() string {
+	if c.isOnline() {
+		return ""
+	}
+	return " [offline]"
+}
+
+type connectivityTransport struct {
+	base http.RoundTripper
+	conn *connectivity
+}
+
+func (t *connectivityTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		if req.Context().Err() == nil && err != context.Canceled {
+			t.conn.set(false)
+		}
+		return resp, err
+	}
+	t.conn.set(true)
+	return resp, nil
+}
+
+// offlineQueue holds the operations that need the API and are postponed until
+// it is reachable again.
+type offlineQueue struct {
+	mu       sync.Mutex
+	fetches  map[int]bool
+	statuses map[int]bool
+}
+
+func (q *offlineQueue) add(queue *map[int]bool, round int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if *queue == nil {
+		*queue = make(map[int]bool)
+	}
+	(*queue)[round] = true
+}
+
+func (q *offlineQueue) queueFetch(round int) {
+	q.add(&q.fetches, round)
+}
+
+func (q *offlineQueue) queueStatuses(round int) {
+	q.add(&q.statuses, round)
+}
+
+// take empties the queue and returns the queued rounds in order.
+func (q *offlineQueue) take() ([]int, []int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	sorted := func(m map[int]bool) []int {
+		rounds := make([]int, 0, len(m))
+		for r := range m {
+			rounds = append(rounds, r)
+		}
+		sort.Ints(rounds)
+		return rounds
+	}
+	fetches, statuses := sorted(q.fetches), sorted(q.statuses)
+	q.fetches, q.statuses = nil, nil
+	return fetches, statuses
+}
+
+// watchConnectivity probes the API while it is unreachable and replays the
+// queued operations once it is reachable again.
+func (a *app) watchConnectivity() {
+	ticker := time.NewTicker(connectivityProbeInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if !a.conn.isOnline() {
+			a.probeAPI()
+		}
+		if a.conn.isOnline() {
+			a.replayOfflineQueue()
+		}
+	}
+}
+
+func (a *app) probeAPI() {
+	gameSheets, err := a.GetGameSpreadsheets()
+	if err != nil || gameSheets.manager == nil {
+		return
+	}
+	a.service.Spreadsheets.Get(gameSheets.manager.ID).Fields("spreadsheetId").Do()
+}
+
+func (a *app) replayOfflineQueue() {
+	a.engineMu.Lock()
+	defer a.engineMu.Unlock()
+	// the queue waits for the end of the interactive command
+	if len(a.interactiveCmd) != 0 {
+		return
+	}
+	fetches, statuses := a.offline.take()
+	if len(fetches) == 0 && len(statuses) == 0 {
+		return
+	}
+	for _, round := range fetches {
+		res, err := a.fetchRound(round)
+		if err != nil {
+			log.Printf("[ERR]: failed to fetch the queued round %d: %v", a.config.questionNumber(round), err)
+			continue
+		}
+		if res != nil {
+			fmt.Printf("\nThe queued round %d is fetched:\n%s\n", a.config.questionNumber(round), res)
+		}
+	}
+	for _, round := range statuses {
+		results, err := a.store.getRoundResults(round)
+		if err != nil {
+			log.Printf("[ERR]: failed to mark the queued round %d statuses: %v", a.config.questionNumber(round), err)
+			continue
+		}
+		if _, err := a.markStatuses(results); err != nil {
+			if !a.conn.isOnline() {
+				a.offline.queueStatuses(round)
+			}
+			log.Printf("[ERR]: failed to mark the queued round %d statuses: %v", a.config.questionNumber(round), err)
+			continue
+		}
+		fmt.Printf("\nThe queued round %d statuses are written to the manager spreadsheet\n", a.config.questionNumber(round))
+	}
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	outputFormatTable = "table"
+	outputFormatJSON  = "json"
+	outputFormatYAML  = "yaml"
+)
+
+func checkOutputFormat(format string) error {
+	switch format {
+	case outputFormatTable, outputFormatJSON, outputFormatYAML:
+		return nil
+	default:
+		return fmt.Errorf("unknown output format %s, expected one of: %s, %s, %s", format, outputFormatTable, outputFormatJSON, outputFormatYAML)
+	}
+}
+
+// extractOutputFormat strips a per-command output format suffix (--json,
+// --yaml, --table) from the command string. If there is no such suffix,
+// defaultFormat is returned.
+func extractOutputFormat(cmdStr string, defaultFormat string) (string, string) {
+	format := defaultFormat
+	sSplitted := strings.Split(cmdStr, " ")
+	args := make([]string, 0, len(sSplitted))
+	for _, arg := range sSplitted {
+		if strings.HasPrefix(arg, "--") {
+			if err := checkOutputFormat(arg[2:]); err == nil {
+				format = arg[2:]
+				continue
+			}
+		}
+		args = append(args, arg)
+	}
+	return strings.Join(args, " "), format
+}
+
+// outputViewer is implemented by the results whose serialized representation
+// differs from their Go structure.
+type outputViewer interface {
+	outputView() interface{}
+}
+
+func printResult(res fmt.Stringer, format string) error {
+	// the commands return typed nil pointers when there is nothing to print
+	if v := reflect.ValueOf(res); v.Kind() == reflect.Ptr && v.IsNil() {
+		return nil
+	}
+	var view interface{} = res
+	if v, ok := res.(outputViewer); ok {
+		view = v.outputView()
+	}
+	switch format {
+	case outputFormatJSON:
+		b, err := json.MarshalIndent(view, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal the result to JSON: %w", err)
+		}
+		fmt.Println(string(b))
+	case outputFormatYAML:
+		b, err := yaml.Marshal(view)
+		if err != nil {
+			return fmt.Errorf("failed to marshal the result to YAML: %w", err)
+		}
+		fmt.Print(string(b))
+	default:
+		fmt.Println(res)
+	}
+	return nil
+}
+
+type listURLsResult struct {
+	Manager string            `json:"manager" yaml:"manager"`
+	Teams   map[string]string `json:"teams" yaml:"teams"`
+}
+
+func newListURLsResult(s *storeGameSpreadsheets) *listURLsResult {
+	res := &listURLsResult{
+		Teams: make(map[string]string, len(s.teams)),
+	}
+	if s.manager != nil {
+		res.Manager = s.manager.URL
+	}
+	for team, sheet := range s.teams {
+		res.Teams[team] = sheet.URL
+	}
+	return res
+}
+
+func (r *listURLsResult) String() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("manager: %s\n", r.Manager))
+	for team, url := range r.Teams {
+		sb.WriteString(fmt.Sprintf("team %s: %s\n", team, url))
+	}
+	return sb.String()
+}
+
+type teamTotal struct {
+	Team             string  `json:"team" yaml:"team"`
+	Score            float64 `json:"score" yaml:"score"`
+	OutOfCompetition bool    `json:"outOfCompetition,omitempty" yaml:"outOfCompetition,omitempty"`
+}
+
+type totalResult struct {
+	Totals []teamTotal `json:"totals" yaml:"totals"`
+}
+
+func (r *totalResult) String() string {
+	totals := make([]teamTotal, len(r.Totals))
+	copy(totals, r.Totals)
+	sort.Slice(totals, func(i, j int) bool {
+		if totals[i].OutOfCompetition != totals[j].OutOfCompetition {
+			return !totals[i].OutOfCompetition
+		}
+		return totals[i].Team < totals[j].Team
+	})
+	t := &table{header: []string{tr("header.team"), tr("header.score")}}
+	for _, total := range totals {
+		team := total.Team
+		if total.OutOfCompetition {
+			team = fmt.Sprintf("%s (%s)", team, outOfCompetitionMark)
+		}
+		t.addRow(plainCell(team), plainCell(formatPoints(total.Score)))
+	}
+	return t.String()
+}
+
+type crossCheckMismatch struct {
+	Team            string `json:"team" yaml:"team"`
+	ManagerResponse string `json:"managerResponse" yaml:"managerResponse"`
+	TeamResponse    string `json:"teamResponse" yaml:"teamResponse"`
+}
+
+type crossCheckResult struct {
+	Round      int                  `json:"round" yaml:"round"`
+	Mismatches []crossCheckMismatch `json:"mismatches" yaml:"mismatches"`
+}
+
+func (r *crossCheckResult) String() string {
+	var sb strings.Builder
+	for _, m := range r.Mismatches {
+		managerResp, _ := truncateAnswer(m.ManagerResponse, answerDisplayWidth)
+		teamResp, _ := truncateAnswer(m.TeamResponse, answerDisplayWidth)
+		sb.WriteString(fmt.Sprintf("Team %s: manager shows \"%s\", team spreadsheet has \"%s\"\n", m.Team, managerResp, teamResp))
+	}
+	if len(r.Mismatches) == 0 {
+		sb.WriteString(fmt.Sprintf("Round %d: manager and teams spreadsheets are in sync\n", r.Round))
+		return sb.String()
+	}
+	sb.WriteString(fmt.Sprintf("Round %d: %d mismatch(es) found, the manager spreadsheet may show stale data\n", r.Round, len(r.Mismatches)))
+	return sb.String()
+}
+
+type tiebreakResult struct {
+	TeamA     string `json:"teamA" yaml:"teamA"`
+	TeamB     string `json:"teamB" yaml:"teamB"`
+	Procedure string `json:"procedure" yaml:"procedure"`
+	Details   string `json:"details" yaml:"details"`
+	Winner    string `json:"winner" yaml:"winner"`
+}
+
+func (r *tiebreakResult) String() string {
+	return fmt.Sprintf("Tiebreak winner: %s", r.Winner)
+}
+
+type roundResponseView struct {
+	Response    string     `json:"response" yaml:"response"`
+	Status      string     `json:"status" yaml:"status"`
+	SubmittedAt *time.Time `json:"submittedAt,omitempty" yaml:"submittedAt,omitempty"`
+	Source      string     `json:"source,omitempty" yaml:"source,omitempty"`
+}
+
+type roundResultsView struct {
+	Round   int                          `json:"round" yaml:"round"`
+	Results map[string]roundResponseView `json:"results" yaml:"results"`
+	Notes   []string                     `json:"notes,omitempty" yaml:"notes,omitempty"`
+	Void    string                       `json:"void,omitempty" yaml:"void,omitempty"`
+}
+
+func (r *roundResults) outputView() interface{} {
+	view := &roundResultsView{
+		Round:   r.question(),
+		Results: make(map[string]roundResponseView, len(r.Results)),
+	}
+	if r.Meta != nil {
+		view.Notes = r.Meta.Notes
+		view.Void = r.Meta.Void
+	}
+	for team, resp := range r.Results {
+		respView := roundResponseView{
+			Response: resp.Response,
+			Status:   resp.Status.String(),
+			Source:   resp.Source,
+		}
+		if !resp.SubmittedAt.IsZero() {
+			submittedAt := resp.SubmittedAt
+			respView.SubmittedAt = &submittedAt
+		}
+		view.Results[team] = respView
+	}
+	return view
+}
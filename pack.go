@@ -0,0 +1,368 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// packQuestion is a question of the imported pack. The number is the one of
+// the pack, zero if it is not given.
+type packQuestion struct {
+	Number  int
+	Text    string
+	Answer  string
+	Zachet  string
+	Comment string
+	Source  string
+}
+
+// importResult reports the imported pack.
+type importResult struct {
+	Source    string `json:"source" yaml:"source"`
+	File      string `json:"file" yaml:"file"`
+	Questions int    `json:"questions" yaml:"questions"`
+	// Warnings are e.g. the difference with the configured number of
+	// questions.
+	Warnings []string `json:"warnings,omitempty" yaml:"warnings,omitempty"`
+}
+
+func (r *importResult) String() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%d questions of %s are written to %s\n", r.Questions, r.Source, r.File))
+	for _, w := range r.Warnings {
+		sb.WriteString(fmt.Sprintf("Warning: %s\n", w))
+	}
+	return sb.String()
+}
+
+// CmdImport imports a question pack into the questions file: "import pack
+// <file|url>". The pack is a db.chgk.info text or XML export or a 4s
+// (chgksuite) file, the accepted answers of the pack are used by the
+// auto-grading. The pack replaces the questions file, which is
+// <output>/questions.json unless Questions.File is configured.
+func (a *app) CmdImport(cmdStr string) (*importResult, error) {
+	sSplitted := splitArgs(cmdStr)
+	if len(sSplitted) != 3 || sSplitted[1] != "pack" {
+		return nil, fmt.Errorf("failed to parse import request: expected import pack <file|url>")
+	}
+	source := sSplitted[2]
+	content, err := a.readPack(source)
+	if err != nil {
+		return nil, err
+	}
+	if !utf8.Valid(content) {
+		return nil, fmt.Errorf("the pack %s is not UTF-8 encoded", source)
+	}
+	parsed, err := parsePack(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the pack %s: %w", source, err)
+	}
+	if len(parsed) == 0 {
+		return nil, fmt.Errorf("no question is found in the pack %s", source)
+	}
+	questions := packQuestions(parsed, a.config.firstQuestionNumber())
+	b, err := json.MarshalIndent(questions, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	file := a.config.Questions.File
+	if len(file) == 0 {
+		file = path.Join(a.config.OutputDir, "questions.json")
+	}
+	if err := ioutil.WriteFile(file, b, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write the questions file: %w", err)
+	}
+	res := &importResult{Source: source, File: file, Questions: len(questions)}
+	if len(a.config.Questions.File) == 0 {
+		a.config.Questions.File = file
+		res.Warnings = append(res.Warnings, fmt.Sprintf("Questions.File is not configured, set it to %s to use the questions after a restart", file))
+	}
+	if a.config.NumberOfQuestions != 0 && len(questions) != a.config.NumberOfQuestions {
+		res.Warnings = append(res.Warnings, fmt.Sprintf("the pack has %d questions, the game has %d", len(questions), a.config.NumberOfQuestions))
+	}
+	if err := a.store.appendEvent(fmt.Sprintf("import pack %s: %d questions", source, len(questions))); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// readPack reads the pack file or downloads it. A db.chgk.info tour page is
+// downloaded in the XML form.
+func (a *app) readPack(source string) ([]byte, error) {
+	u, err := url.Parse(source)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		b, err := ioutil.ReadFile(source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the pack: %w", err)
+		}
+		return b, nil
+	}
+	if strings.HasSuffix(u.Host, "db.chgk.info") && strings.HasPrefix(u.Path, "/tour/") &&
+		!strings.HasSuffix(u.Path, "/xml") && !strings.HasSuffix(u.Path, ".txt") {
+		u.Path = strings.TrimSuffix(u.Path, "/") + "/xml"
+	}
+	req, err := http.NewRequestWithContext(a.commandContext(), http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download the pack: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download the pack %s: %s", u, resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+// parsePack detects the format of the pack and parses it.
+func parsePack(content []byte) ([]*packQuestion, error) {
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(content, []byte("\xef\xbb\xbf")))
+	if bytes.HasPrefix(trimmed, []byte("<")) {
+		return parseXMLPack(trimmed)
+	}
+	if dbQuestionHeader.Match(trimmed) {
+		return parseDBPack(trimmed)
+	}
+	return parse4sPack(trimmed)
+}
+
+// dbQuestionHeader matches the question header of the db.chgk.info text
+// format, e.g. "Вопрос 12:".
+var dbQuestionHeader = regexp.MustCompile(`(?m)^Вопрос\s+(\d+)\s*:`)
+
+// dbFieldHeader matches the other field headers of the db.chgk.info text
+// format, the fields that are not imported are read and dropped.
+var dbFieldHeader = regexp.MustCompile(`^(Ответ|Зач[её]т|Незач[её]т|Комментари[йи]|Источник|Источники|Автор|Авторы|Чемпионат|Тур|Дата|Редактор|Редакторы|Инфо|Вид|URL|Обработан|Копирайт)\s*:\s*(.*)$`)
+
+func parseDBPack(content []byte) ([]*packQuestion, error) {
+	var questions []*packQuestion
+	var q *packQuestion
+	var field *string
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if m := dbQuestionHeader.FindStringSubmatch(line); m != nil {
+			number, _ := strconv.Atoi(m[1])
+			q = &packQuestion{Number: number}
+			questions = append(questions, q)
+			field = &q.Text
+			appendPackLine(field, line[len(m[0]):])
+			continue
+		}
+		if m := dbFieldHeader.FindStringSubmatch(line); m != nil {
+			field = nil
+			if q != nil {
+				field = q.field(m[1])
+			}
+			appendPackLine(field, m[2])
+			continue
+		}
+		appendPackLine(field, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return questions, nil
+}
+
+// field returns the imported field of the header, nil for the other ones.
+func (q *packQuestion) field(header string) *string {
+	switch {
+	case header == "Ответ":
+		return &q.Answer
+	case strings.HasPrefix(header, "Зач"):
+		return &q.Zachet
+	case strings.HasPrefix(header, "Комментари"):
+		return &q.Comment
+	case strings.HasPrefix(header, "Источник"):
+		return &q.Source
+	default:
+		return nil
+	}
+}
+
+// parse4sPack parses the 4s format of chgksuite: every field starts with its
+// marker, e.g. "? " for the question and "! " for the answer, and continues
+// up to the next marker.
+func parse4sPack(content []byte) ([]*packQuestion, error) {
+	var questions []*packQuestion
+	var q *packQuestion
+	var field *string
+	number := 0
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		marker, rest := line, ""
+		if i := strings.IndexAny(line, " \t"); i >= 0 {
+			marker, rest = line[:i], line[i+1:]
+		}
+		switch {
+		case marker == "№":
+			number, _ = strconv.Atoi(strings.TrimSpace(rest))
+			field = nil
+		case marker == "?":
+			q = &packQuestion{Number: number}
+			number = 0
+			questions = append(questions, q)
+			field = &q.Text
+			appendPackLine(field, rest)
+		case q != nil && marker == "!":
+			field = &q.Answer
+			appendPackLine(field, rest)
+		case q != nil && marker == "=":
+			field = &q.Zachet
+			appendPackLine(field, rest)
+		case q != nil && marker == "/":
+			field = &q.Comment
+			appendPackLine(field, rest)
+		case q != nil && marker == "^":
+			field = &q.Source
+			appendPackLine(field, rest)
+		case marker == "!=" || marker == "@" || strings.HasPrefix(marker, "#"):
+			// the rejected answers, the authors and the headings are not
+			// imported
+			field = nil
+		default:
+			appendPackLine(field, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(questions) == 0 {
+		return nil, fmt.Errorf("neither the db.chgk.info nor the 4s format is recognized")
+	}
+	return questions, nil
+}
+
+// xmlPackQuestion is a question of the db.chgk.info XML export.
+type xmlPackQuestion struct {
+	Number       string `xml:"Number"`
+	Question     string `xml:"Question"`
+	Answer       string `xml:"Answer"`
+	PassCriteria string `xml:"PassCriteria"`
+	Comments     string `xml:"Comments"`
+	Sources      string `xml:"Sources"`
+}
+
+// parseXMLPack reads the questions of the db.chgk.info XML export of a
+// tournament or a tour, at any depth of the document.
+func parseXMLPack(content []byte) ([]*packQuestion, error) {
+	var questions []*packQuestion
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "question" {
+			continue
+		}
+		var x xmlPackQuestion
+		if err := decoder.DecodeElement(&x, &start); err != nil {
+			return nil, err
+		}
+		number, _ := strconv.Atoi(strings.TrimSpace(x.Number))
+		questions = append(questions, &packQuestion{
+			Number:  number,
+			Text:    strings.TrimSpace(x.Question),
+			Answer:  strings.TrimSpace(x.Answer),
+			Zachet:  strings.TrimSpace(x.PassCriteria),
+			Comment: strings.TrimSpace(x.Comments),
+			Source:  strings.TrimSpace(x.Sources),
+		})
+	}
+	return questions, nil
+}
+
+func appendPackLine(field *string, line string) {
+	if field == nil {
+		return
+	}
+	line = strings.TrimSpace(line)
+	if len(line) == 0 {
+		return
+	}
+	if len(*field) != 0 {
+		*field += "\n"
+	}
+	*field += line
+}
+
+// packQuestions numbers the questions of the pack. The numbers of the pack
+// are kept if every question has a distinct one, the questions are numbered
+// from the first question number of the game in the pack order otherwise,
+// e.g. when every tour starts from 1.
+func packQuestions(parsed []*packQuestion, first int) map[int]*question {
+	numbered := make(map[int]bool, len(parsed))
+	keepNumbers := true
+	for _, q := range parsed {
+		if q.Number <= 0 || numbered[q.Number] {
+			keepNumbers = false
+			break
+		}
+		numbered[q.Number] = true
+	}
+	questions := make(map[int]*question, len(parsed))
+	for i, q := range parsed {
+		number := first + i
+		if keepNumbers {
+			number = q.Number
+		}
+		questions[number] = &question{
+			Text:    q.Text,
+			Answers: acceptedAnswers(q.Answer, q.Zachet),
+			Comment: q.Comment,
+			Source:  q.Source,
+		}
+	}
+	return questions
+}
+
+// optionalAnswerPart matches the optional part of an answer, e.g. "[Александр
+// Сергеевич] Пушкин".
+var optionalAnswerPart = regexp.MustCompile(`\s*\[[^\]]*\]\s*`)
+
+// acceptedAnswers returns the answer, with and without its optional parts,
+// and the accepted alternatives separated by semicolons.
+func acceptedAnswers(answer string, zachet string) []string {
+	var accepted []string
+	seen := make(map[string]bool)
+	add := func(s string) {
+		s = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(s), "."))
+		if len(s) == 0 || seen[s] {
+			return
+		}
+		seen[s] = true
+		accepted = append(accepted, s)
+	}
+	answer = strings.ReplaceAll(answer, "\n", " ")
+	add(strings.NewReplacer("[", "", "]", "").Replace(answer))
+	add(optionalAnswerPart.ReplaceAllString(answer, " "))
+	for _, alt := range strings.Split(strings.ReplaceAll(zachet, "\n", " "), ";") {
+		add(alt)
+	}
+	return accepted
+}
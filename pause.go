@@ -0,0 +1,181 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+// pausedDescriptionPrefix starts the descriptions of the protections added by
+// pause, one per sheet of the team spreadsheets.
+const pausedDescriptionPrefix = "game paused"
+
+func pausedDescription(sheetTitle string) string {
+	return fmt.Sprintf("%s: %s", pausedDescriptionPrefix, sheetTitle)
+}
+
+type pauseResult struct {
+	Paused bool     `json:"paused" yaml:"paused"`
+	BackAt string   `json:"backAt,omitempty" yaml:"backAt,omitempty"`
+	Teams  []string `json:"teams" yaml:"teams"`
+}
+
+func (r *pauseResult) String() string {
+	if !r.Paused {
+		return fmt.Sprintf("The game is resumed for the teams: %s", strings.Join(r.Teams, ", "))
+	}
+	if len(r.BackAt) != 0 {
+		return fmt.Sprintf("The game is paused until %s for the teams: %s", r.BackAt, strings.Join(r.Teams, ", "))
+	}
+	return fmt.Sprintf("The game is paused for the teams: %s", strings.Join(r.Teams, ", "))
+}
+
+// CmdPause protects every sheet of the team spreadsheets for a break, so that
+// the answers cannot be edited until resume: "pause [HH:MM]". If the time the
+// game is back at is given, it is announced in the questions cell.
+func (a *app) CmdPause(cmdStr string) (*pauseResult, error) {
+	args := strings.Fields(cmdStr)
+	if len(args) > 2 {
+		return nil, fmt.Errorf("failed to parse pause request: expected at most 1 argument, the time the game is back at")
+	}
+	res := &pauseResult{Paused: true, Teams: make([]string, 0, len(a.config.Teams))}
+	if len(args) == 2 {
+		if _, err := time.Parse("15:04", args[1]); err != nil {
+			return nil, fmt.Errorf("failed to parse pause request: expected the time as HH:MM, got %s", args[1])
+		}
+		res.BackAt = args[1]
+	}
+	gameSheets, err := a.GetGameSpreadsheets()
+	if err != nil {
+		return nil, err
+	}
+	spreadsheetsService := sheets.NewSpreadsheetsService(a.service)
+	for _, team := range a.config.Teams {
+		teamSheet, ok := gameSheets.teams[team]
+		if !ok {
+			return nil, fmt.Errorf("spreadsheet of the team %s is not found", team)
+		}
+		metadata, err := a.getSpreadsheetMetadata(a.commandContext(), teamSheet.ID)
+		if err != nil {
+			return nil, err
+		}
+		requests := make([]*sheets.Request, 0, len(metadata.sheets))
+		for _, sheet := range metadata.sheets {
+			if _, ok := metadata.protectedRangeByDescription(pausedDescription(sheet.Title)); ok {
+				continue
+			}
+			requests = append(requests, &sheets.Request{
+				AddProtectedRange: &sheets.AddProtectedRangeRequest{
+					ProtectedRange: &sheets.ProtectedRange{
+						Description: pausedDescription(sheet.Title),
+						Range:       &sheets.GridRange{SheetId: sheet.ID},
+					},
+				},
+			})
+		}
+		if len(requests) == 0 {
+			log.Printf("the team %s spreadsheet is already paused", team)
+			res.Teams = append(res.Teams, team)
+			continue
+		}
+		resp, err := spreadsheetsService.BatchUpdate(teamSheet.ID, &sheets.BatchUpdateSpreadsheetRequest{
+			Requests: requests,
+		}).Context(a.commandContext()).Do()
+		if err != nil {
+			return nil, fmt.Errorf("failed to pause the team %s spreadsheet: %w", team, err)
+		}
+		for _, reply := range resp.Replies {
+			pr := reply.AddProtectedRange.ProtectedRange
+			a.metadata.addProtectedRange(teamSheet.ID, pr.ProtectedRangeId, pr.Description)
+		}
+		res.Teams = append(res.Teams, team)
+	}
+	if len(res.BackAt) != 0 {
+		if err := a.writeQuestionCell(tr("pause.banner", res.BackAt)); err != nil {
+			return nil, err
+		}
+	}
+	if err := a.store.appendEvent(fmt.Sprintf("pause: %s", res.BackAt)); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// CmdResume removes the protections added by pause and clears the break
+// announcement: "resume".
+func (a *app) CmdResume() (*pauseResult, error) {
+	gameSheets, err := a.GetGameSpreadsheets()
+	if err != nil {
+		return nil, err
+	}
+	spreadsheetsService := sheets.NewSpreadsheetsService(a.service)
+	res := &pauseResult{Paused: false, Teams: make([]string, 0, len(a.config.Teams))}
+	for _, team := range a.config.Teams {
+		teamSheet, ok := gameSheets.teams[team]
+		if !ok {
+			return nil, fmt.Errorf("spreadsheet of the team %s is not found", team)
+		}
+		metadata, err := a.getSpreadsheetMetadata(a.commandContext(), teamSheet.ID)
+		if err != nil {
+			return nil, err
+		}
+		ids := make([]int64, 0)
+		for id, description := range metadata.protectedRanges {
+			if strings.HasPrefix(description, pausedDescriptionPrefix) {
+				ids = append(ids, id)
+			}
+		}
+		res.Teams = append(res.Teams, team)
+		if len(ids) == 0 {
+			log.Printf("the team %s spreadsheet is not paused", team)
+			continue
+		}
+		requests := make([]*sheets.Request, len(ids))
+		for i, id := range ids {
+			requests[i] = &sheets.Request{
+				DeleteProtectedRange: &sheets.DeleteProtectedRangeRequest{ProtectedRangeId: id},
+			}
+		}
+		_, err = spreadsheetsService.BatchUpdate(teamSheet.ID, &sheets.BatchUpdateSpreadsheetRequest{
+			Requests: requests,
+		}).Context(a.commandContext()).Do()
+		if err != nil {
+			return nil, fmt.Errorf("failed to resume the team %s spreadsheet: %w", team, err)
+		}
+		for _, id := range ids {
+			a.metadata.removeProtectedRange(teamSheet.ID, id)
+		}
+	}
+	if gameSheets.manager != nil {
+		announced, err := a.breakAnnounced(gameSheets.manager)
+		if err != nil {
+			return nil, err
+		}
+		if announced {
+			if err := a.writeQuestionCell(""); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := a.store.appendEvent("resume"); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// breakAnnounced reports whether the questions cell holds the break
+// announcement, so that a question shown during the break is not cleared.
+func (a *app) breakAnnounced(manager *storeSpreadsheet) (bool, error) {
+	cell := sheetRange(manager.SheetTitle, a.config.Questions.cell())
+	values, err := a.getCachedValues(manager.ID, cell)
+	if err != nil {
+		return false, fmt.Errorf("failed to read the questions cell: %w", err)
+	}
+	if len(values) == 0 || len(values[0]) == 0 {
+		return false, nil
+	}
+	return strings.HasPrefix(fmt.Sprint(values[0][0]), tr("pause.banner", "")), nil
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os/exec"
+	"strings"
+)
+
+const (
+	pluginKindScoring   = "scoring"
+	pluginKindExporter  = "exporter"
+	pluginKindValidator = "validator"
+)
+
+// PluginConfig describes a plugin: an executable that receives a single JSON
+// request on its standard input and writes a single JSON response to its
+// standard output.
+type PluginConfig struct {
+	Name    string
+	Kind    string
+	Command []string
+}
+
+type pluginRequest struct {
+	Type    string              `json:"type"`
+	Game    string              `json:"game"`
+	Teams   []string            `json:"teams"`
+	Round   int                 `json:"round,omitempty"`
+	Answers map[string]string   `json:"answers,omitempty"`
+	Results []*roundResultsView `json:"results,omitempty"`
+	// Adjustments are the score adjustments of the referees, sent to the
+	// exporter plugins.
+	Adjustments []scoreAdjustment `json:"adjustments,omitempty"`
+	Args        []string          `json:"args,omitempty"`
+}
+
+type pluginResponse struct {
+	// Totals is the response of the scoring plugins.
+	Totals map[string]float64 `json:"totals,omitempty"`
+	// Invalid maps the teams to the reasons their answers are invalid, it is
+	// the response of the validator plugins.
+	Invalid map[string]string `json:"invalid,omitempty"`
+	// Message is the response of the exporter plugins.
+	Message string `json:"message,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+func checkPluginsConfig(plugins []PluginConfig) error {
+	names := make(map[string]bool, len(plugins))
+	scoring := 0
+	for _, p := range plugins {
+		if len(p.Name) == 0 {
+			return fmt.Errorf("plugin name cannot be empty")
+		}
+		if names[p.Name] {
+			return fmt.Errorf("plugin %s is defined several times", p.Name)
+		}
+		names[p.Name] = true
+		if len(p.Command) == 0 {
+			return fmt.Errorf("plugin %s command cannot be empty", p.Name)
+		}
+		switch p.Kind {
+		case pluginKindScoring:
+			scoring++
+		case pluginKindExporter, pluginKindValidator:
+		default:
+			return fmt.Errorf("plugin %s has unknown kind %s", p.Name, p.Kind)
+		}
+	}
+	if scoring > 1 {
+		return fmt.Errorf("only one scoring plugin can be configured")
+	}
+	return nil
+}
+
+func runPlugin(p *PluginConfig, req *pluginRequest) (*pluginResponse, error) {
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.Command(p.Command[0], p.Command[1:]...)
+	cmd.Stdin = bytes.NewReader(reqBytes)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s failed: %w: %s", p.Name, err, strings.TrimSpace(stderr.String()))
+	}
+	var resp pluginResponse
+	if err := json.Unmarshal(out, &resp); err != nil {
+		return nil, fmt.Errorf("plugin %s response could not be parsed: %w", p.Name, err)
+	}
+	if len(resp.Error) != 0 {
+		return nil, fmt.Errorf("plugin %s returned an error: %s", p.Name, resp.Error)
+	}
+	return &resp, nil
+}
+
+func (a *app) findPlugins(kind string) []*PluginConfig {
+	plugins := make([]*PluginConfig, 0)
+	for i := range a.config.Plugins {
+		if a.config.Plugins[i].Kind == kind {
+			plugins = append(plugins, &a.config.Plugins[i])
+		}
+	}
+	return plugins
+}
+
+func (a *app) newPluginRequest(reqType string) *pluginRequest {
+	return &pluginRequest{
+		Type:  reqType,
+		Game:  a.config.GameName,
+		Teams: a.config.Teams,
+	}
+}
+
+func (a *app) allResultsViews() ([]*roundResultsView, error) {
+	allResults, err := a.store.getAllRoundResults()
+	if err != nil {
+		return nil, err
+	}
+	allMeta, err := a.store.getAllRoundMeta()
+	if err != nil {
+		return nil, err
+	}
+	views := make([]*roundResultsView, len(allResults))
+	for i, r := range allResults {
+		r.Meta = allMeta[r.Round]
+		views[i] = a.numberResults(r).outputView().(*roundResultsView)
+	}
+	return views, nil
+}
+
+// pluginTotals returns the totals computed by the scoring plugin, or nil if
+// no scoring plugin is configured.
+func (a *app) pluginTotals() (map[string]float64, error) {
+	plugins := a.findPlugins(pluginKindScoring)
+	if len(plugins) == 0 {
+		return nil, nil
+	}
+	req := a.newPluginRequest("score")
+	var err error
+	if req.Results, err = a.allResultsViews(); err != nil {
+		return nil, err
+	}
+	resp, err := runPlugin(plugins[0], req)
+	if err != nil {
+		return nil, err
+	}
+	totals := make(map[string]float64, len(a.config.Teams))
+	for _, team := range a.config.Teams {
+		totals[team] = resp.Totals[team]
+	}
+	return totals, nil
+}
+
+// validateAnswers runs the validator plugins on the fetched answers and
+// reports the invalid ones.
+func (a *app) validateAnswers(round int, answers map[string]string) {
+	for _, p := range a.findPlugins(pluginKindValidator) {
+		req := a.newPluginRequest("validate")
+		req.Round = a.config.questionNumber(round)
+		req.Answers = answers
+		resp, err := runPlugin(p, req)
+		if err != nil {
+			log.Printf("[ERR]: %v", err)
+			continue
+		}
+		for team, reason := range resp.Invalid {
+			fmt.Printf("Validator %s: team %s answer is invalid: %s\n", p.Name, team, reason)
+		}
+	}
+}
+
+type exportResult struct {
+	Plugin  string `json:"plugin" yaml:"plugin"`
+	Message string `json:"message" yaml:"message"`
+}
+
+func (r *exportResult) String() string {
+	return fmt.Sprintf("Exporter %s: %s", r.Plugin, r.Message)
+}
+
+// CmdExport runs an exporter plugin: "export <plugin> [args...]". The
+// built-in rating exporter writes the results for the rating system: "export
+// rating <path>".
+func (a *app) CmdExport(cmdStr string) (*exportResult, error) {
+	sSplitted := splitArgs(cmdStr)
+	if len(sSplitted) < 2 {
+		return nil, fmt.Errorf("expected the exporter plugin name")
+	}
+	if sSplitted[1] == ratingExporter {
+		return a.exportRating(sSplitted[2:])
+	}
+	var plugin *PluginConfig
+	for _, p := range a.findPlugins(pluginKindExporter) {
+		if p.Name == sSplitted[1] {
+			plugin = p
+		}
+	}
+	if plugin == nil {
+		return nil, fmt.Errorf("exporter plugin %s is not configured", sSplitted[1])
+	}
+	req := a.newPluginRequest("export")
+	req.Args = sSplitted[2:]
+	var err error
+	if req.Results, err = a.allResultsViews(); err != nil {
+		return nil, err
+	}
+	if req.Adjustments, err = a.store.getAdjustments(); err != nil {
+		return nil, err
+	}
+	resp, err := runPlugin(plugin, req)
+	if err != nil {
+		return nil, err
+	}
+	return &exportResult{Plugin: plugin.Name, Message: resp.Message}, nil
+}
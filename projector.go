@@ -0,0 +1,181 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+// The sheets of the projector spreadsheet, their IDs are set on creation.
+const (
+	projectorQuestionSheetTitle  = "Question"
+	projectorStandingsSheetTitle = "Standings"
+	projectorQuestionSheetID     = 0
+	projectorStandingsSheetID    = 1
+	projectorStandingsLength     = 10
+)
+
+type projectorResult struct {
+	URL      string `json:"url" yaml:"url"`
+	Question int    `json:"question" yaml:"question"`
+}
+
+func (r *projectorResult) String() string {
+	return fmt.Sprintf("Projector spreadsheet shows the question %d: %s", r.Question, r.URL)
+}
+
+// CmdProjector creates or updates the projector spreadsheet, which shows the
+// current question number and the top standings in big fonts: "projector
+// [question]". Without the question the latest fetched round is shown.
+func (a *app) CmdProjector(cmdStr string) (*projectorResult, error) {
+	question := -1
+	if len(strings.Fields(cmdStr)) > 1 {
+		var err error
+		question, err = a.getRoundNumber(cmdStr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse projector request: %w", err)
+		}
+	} else {
+		allResults, err := a.store.getAllRoundResults()
+		if err != nil {
+			return nil, err
+		}
+		for _, results := range allResults {
+			if results.Round > question {
+				question = results.Round
+			}
+		}
+		if question < 0 {
+			return nil, fmt.Errorf("no round is fetched yet, pass the question number")
+		}
+	}
+	projector, err := a.ensureProjectorSpreadsheet()
+	if err != nil {
+		return nil, err
+	}
+	total, err := a.publicTotals()
+	if err != nil {
+		return nil, err
+	}
+	rows := [][]interface{}{{tr("header.place"), tr("header.team"), tr("header.score")}}
+	for _, s := range a.standings(total) {
+		for _, team := range s.Teams {
+			if len(rows) > projectorStandingsLength {
+				break
+			}
+			rows = append(rows, []interface{}{s.places(), team, formatPoints(s.Score)})
+		}
+	}
+	valuesService := sheets.NewSpreadsheetsValuesService(a.service)
+	_, err = valuesService.Clear(projector.SpreadsheetId, projectorStandingsSheetTitle, &sheets.ClearValuesRequest{}).Context(a.commandContext()).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to clear the projector standings: %w", err)
+	}
+	_, err = valuesService.BatchUpdate(projector.SpreadsheetId, &sheets.BatchUpdateValuesRequest{
+		ValueInputOption: "RAW",
+		Data: []*sheets.ValueRange{
+			{
+				Range:  sheetRange(projectorQuestionSheetTitle, "A1"),
+				Values: [][]interface{}{{a.config.questionNumber(question)}},
+			},
+			{
+				Range:  sheetRange(projectorStandingsSheetTitle, rangeName(0, 1, 2, len(rows))),
+				Values: rows,
+			},
+		},
+	}).Context(a.commandContext()).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to update the projector spreadsheet: %w", err)
+	}
+	a.notifyWebhooks(&webhookPayload{Event: webhookEventTotalsPublished, Round: a.config.questionNumber(question), Standings: a.standings(total)})
+	return &projectorResult{URL: projector.SpreadsheetUrl, Question: a.config.questionNumber(question)}, nil
+}
+
+// ensureProjectorSpreadsheet returns the projector spreadsheet, creating and
+// formatting it on the first use.
+func (a *app) ensureProjectorSpreadsheet() (*sheets.Spreadsheet, error) {
+	stored, err := a.store.getProjectorSpreadsheet()
+	if err != nil {
+		return nil, err
+	}
+	if stored != nil {
+		return stored.toSpreadsheet(), nil
+	}
+	projector, err := a.service.Spreadsheets.Create(&sheets.Spreadsheet{
+		Properties: &sheets.SpreadsheetProperties{
+			Title:  a.config.Labels.projectorTitle(a.config.GameName),
+			Locale: a.config.Locale,
+		},
+		Sheets: []*sheets.Sheet{
+			{Properties: &sheets.SheetProperties{SheetId: projectorQuestionSheetID, Title: projectorQuestionSheetTitle}},
+			{Properties: &sheets.SheetProperties{SheetId: projectorStandingsSheetID, Title: projectorStandingsSheetTitle}},
+		},
+	}).Context(a.commandContext()).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the projector spreadsheet: %w", err)
+	}
+	log.Printf("created the projector spreadsheet: %s", projector.SpreadsheetUrl)
+	a.moveToGameFolder(projector.SpreadsheetId)
+	spreadsheetsService := sheets.NewSpreadsheetsService(a.service)
+	_, err = spreadsheetsService.BatchUpdate(projector.SpreadsheetId, &sheets.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheets.Request{
+			projectorTextFormat(projectorQuestionSheetID, 1, 1, 200),
+			projectorColumnWidth(projectorQuestionSheetID, 0, 1, 600),
+			projectorTextFormat(projectorStandingsSheetID, projectorStandingsLength+1, 3, 36),
+			projectorColumnWidth(projectorStandingsSheetID, 0, 1, 150),
+			projectorColumnWidth(projectorStandingsSheetID, 1, 2, 700),
+			projectorColumnWidth(projectorStandingsSheetID, 2, 3, 150),
+		},
+	}).Context(a.commandContext()).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to format the projector spreadsheet: %w", err)
+	}
+	if err := a.store.saveProjectorSpreadsheet(projector); err != nil {
+		return nil, err
+	}
+	return projector, nil
+}
+
+// projectorTextFormat sets the bold centered text of the font size for the
+// top-left cells of the sheet.
+func projectorTextFormat(sheetID int64, rows int64, columns int64, fontSize int64) *sheets.Request {
+	return &sheets.Request{
+		RepeatCell: &sheets.RepeatCellRequest{
+			Range: &sheets.GridRange{
+				SheetId:          sheetID,
+				StartRowIndex:    0,
+				EndRowIndex:      rows,
+				StartColumnIndex: 0,
+				EndColumnIndex:   columns,
+			},
+			Cell: &sheets.CellData{
+				UserEnteredFormat: &sheets.CellFormat{
+					HorizontalAlignment: "CENTER",
+					VerticalAlignment:   "MIDDLE",
+					TextFormat: &sheets.TextFormat{
+						Bold:     true,
+						FontSize: fontSize,
+					},
+				},
+			},
+			Fields: "userEnteredFormat(horizontalAlignment,verticalAlignment,textFormat)",
+		},
+	}
+}
+
+func projectorColumnWidth(sheetID int64, start int64, end int64, width int64) *sheets.Request {
+	return &sheets.Request{
+		UpdateDimensionProperties: &sheets.UpdateDimensionPropertiesRequest{
+			Range: &sheets.DimensionRange{
+				SheetId:    sheetID,
+				Dimension:  "COLUMNS",
+				StartIndex: start,
+				EndIndex:   end,
+			},
+			Properties: &sheets.DimensionProperties{PixelSize: width},
+			Fields:     "pixelSize",
+		},
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"log"
+)
+
+// quarantinedEntry is a fetched value that could not be taken as a team
+// answer. It is reported to the jury instead of failing the whole fetch.
+type quarantinedEntry struct {
+	// Team is empty if the value is outside of the teams rows.
+	Team   string
+	Value  string
+	Reason string
+}
+
+func (e quarantinedEntry) String() string {
+	team := e.Team
+	if len(team) == 0 {
+		team = "<no team>"
+	}
+	return fmt.Sprintf("%s: %q (%s)", team, e.Value, e.Reason)
+}
+
+func quarantine(team string, value interface{}, reason string) quarantinedEntry {
+	e := quarantinedEntry{
+		Team:   team,
+		Value:  fmt.Sprint(value),
+		Reason: reason,
+	}
+	log.Printf("quarantined the fetched value %s", e)
+	return e
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"strings"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+// QuestionsConfig configures the distribution of the question texts to the
+// spreadsheets for the remote games.
+type QuestionsConfig struct {
+	// File is a JSON object mapping the question numbers, as numbered from
+	// FirstQuestionNumber, to their texts, e.g. {"1": "..."}. A multiple-choice question is an object with the
+	// text and the choices, e.g. {"2": {"Text": "...", "Choices": ["A", "B"]}},
+	// the team answer cell then accepts only the choices. An object may also
+	// have the accepted Answers, used by the auto-grading of the questions
+	// without Answers in the configuration, the Comment and the Source. The
+	// file is written by the pack import.
+	File string
+	// Cell of the spreadsheets where the question is written, "N2" by default.
+	Cell string
+}
+
+func (c *QuestionsConfig) cell() string {
+	if len(c.Cell) == 0 {
+		return "N2"
+	}
+	return c.Cell
+}
+
+// question is an entry of the questions file.
+type question struct {
+	Text    string
+	Choices []string `json:",omitempty"`
+	Answers []string `json:",omitempty"`
+	Comment string   `json:",omitempty"`
+	Source  string   `json:",omitempty"`
+}
+
+// UnmarshalJSON accepts either the question text or an object with the text
+// and the choices.
+func (q *question) UnmarshalJSON(b []byte) error {
+	var text string
+	if err := json.Unmarshal(b, &text); err == nil {
+		q.Text = text
+		return nil
+	}
+	type plainQuestion question
+	var p plainQuestion
+	if err := json.Unmarshal(b, &p); err != nil {
+		return err
+	}
+	*q = question(p)
+	return nil
+}
+
+func (c *QuestionsConfig) load() (map[int]*question, error) {
+	if len(c.File) == 0 {
+		return nil, fmt.Errorf("the questions file is not configured")
+	}
+	b, err := ioutil.ReadFile(c.File)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the questions file %s: %w", c.File, err)
+	}
+	var questions map[int]*question
+	if err := json.Unmarshal(b, &questions); err != nil {
+		return nil, fmt.Errorf("failed to parse the questions file %s: %w", c.File, err)
+	}
+	for number, q := range questions {
+		if q == nil {
+			return nil, fmt.Errorf("question %d of the questions file %s is empty", number, c.File)
+		}
+	}
+	return questions, nil
+}
+
+type questionResult struct {
+	Question int    `json:"question" yaml:"question"`
+	Shown    bool   `json:"shown" yaml:"shown"`
+	Text     string `json:"text,omitempty" yaml:"text,omitempty"`
+}
+
+func (r *questionResult) String() string {
+	if !r.Shown {
+		return fmt.Sprintf("Question %d is hidden", r.Question)
+	}
+	return fmt.Sprintf("Question %d is shown: %s", r.Question, r.Text)
+}
+
+// CmdShowQuestion writes the question text into the questions cell of every
+// team spreadsheet and of the manager spreadsheet.
+func (a *app) CmdShowQuestion(cmdStr string) (*questionResult, error) {
+	question, err := a.getRoundNumber(cmdStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse showQuestion request: %w", err)
+	}
+	questions, err := a.config.Questions.load()
+	if err != nil {
+		return nil, err
+	}
+	number := a.config.questionNumber(question)
+	q, ok := questions[number]
+	if !ok {
+		return nil, fmt.Errorf("question %d is not found in %s", number, a.config.Questions.File)
+	}
+	text := q.Text
+	if err := a.writeQuestionCell(fmt.Sprintf("%d. %s", number, text)); err != nil {
+		return nil, err
+	}
+	return &questionResult{Question: number, Shown: true, Text: text}, nil
+}
+
+// CmdHideQuestion clears the questions cell.
+func (a *app) CmdHideQuestion(cmdStr string) (*questionResult, error) {
+	question, err := a.getRoundNumber(cmdStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse hideQuestion request: %w", err)
+	}
+	if err := a.writeQuestionCell(""); err != nil {
+		return nil, err
+	}
+	return &questionResult{Question: a.config.questionNumber(question), Shown: false}, nil
+}
+
+func (a *app) writeQuestionCell(value string) error {
+	gameSheets, err := a.GetGameSpreadsheets()
+	if err != nil {
+		return err
+	}
+	targets := make(map[string]*storeSpreadsheet, len(gameSheets.teams)+1)
+	for team, teamSheet := range gameSheets.teams {
+		targets[fmt.Sprintf("team %s", team)] = teamSheet
+	}
+	if gameSheets.manager != nil {
+		targets["manager"] = gameSheets.manager
+	}
+	valuesService := sheets.NewSpreadsheetsValuesService(a.service)
+	failed := make([]string, 0)
+	for name, spreadsheet := range targets {
+		cell := sheetRange(spreadsheet.toSpreadsheet().Sheets[0].Properties.Title, a.config.Questions.cell())
+		_, err := valuesService.Update(spreadsheet.ID, cell, &sheets.ValueRange{
+			Values: [][]interface{}{{value}},
+		}).ValueInputOption("RAW").Context(a.commandContext()).Do()
+		if err != nil {
+			log.Printf("[ERR]: failed to write the question to the %s spreadsheet: %v", name, err)
+			failed = append(failed, name)
+			continue
+		}
+		a.values.put(spreadsheet.ID, cell, [][]interface{}{{value}})
+	}
+	if len(failed) != 0 {
+		return fmt.Errorf("failed to write the question to the spreadsheets: %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"net/http"
+	"time"
+)
+
+// rateLimitedTransport spaces the requests passing through it evenly so that
+// at most requestsPerMinute requests are sent per minute.
+type rateLimitedTransport struct {
+	base  http.RoundTripper
+	slots chan struct{}
+}
+
+func newRateLimitedTransport(base http.RoundTripper, requestsPerMinute int) http.RoundTripper {
+	if requestsPerMinute <= 0 {
+		return base
+	}
+	t := &rateLimitedTransport{
+		base:  base,
+		slots: make(chan struct{}, 1),
+	}
+	go func() {
+		ticker := time.NewTicker(time.Minute / time.Duration(requestsPerMinute))
+		defer ticker.Stop()
+		for range ticker.C {
+			select {
+			case t.slots <- struct{}{}:
+			default:
+			}
+		}
+	}()
+	t.slots <- struct{}{}
+	return t
+}
+
+func (t *rateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	select {
+	case <-t.slots:
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ratingExporter is the name of the built-in exporter writing the results in
+// the format of the rating.chgk.info tournament results upload.
+const ratingExporter = "rating"
+
+// exportRating writes the per-question results of every team to a CSV file:
+// the team rating ID, the team name, 1 or 0 per question and the total. The
+// total includes the score adjustments, the question marks do not. The teams
+// out of competition follow the ranked ones, marked "в/к", and may have no
+// rating ID. The teams of the team directory without an ID in TeamIDs get
+// the RatingID of the directory when the configuration is read.
+func (a *app) exportRating(args []string) (*exportResult, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("expected the path of the results file")
+	}
+	missing := make([]string, 0)
+	for _, team := range a.config.officialTeams() {
+		if _, ok := a.config.TeamIDs[team]; !ok {
+			missing = append(missing, team)
+		}
+	}
+	if len(missing) != 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("neither TeamIDs nor the team directory has a rating ID for the teams %s", strings.Join(missing, ", "))
+	}
+	rounds := a.scoredRounds()
+	header := []string{"Team ID", "Team"}
+	for _, round := range rounds {
+		header = append(header, strconv.Itoa(a.config.questionNumber(round)))
+	}
+	header = append(header, "Total")
+	masks := make(map[string][]string, len(a.config.Teams))
+	totals := make(map[string]int, len(a.config.Teams))
+	for _, team := range a.config.Teams {
+		masks[team] = make([]string, len(rounds))
+		for i := range rounds {
+			masks[team][i] = "0"
+		}
+	}
+	allMeta, err := a.store.getAllRoundMeta()
+	if err != nil {
+		return nil, err
+	}
+	for i, round := range rounds {
+		meta := allMeta[round]
+		if meta != nil && meta.Void == roundVoidAll {
+			for team := range masks {
+				masks[team][i] = "1"
+				totals[team]++
+			}
+			continue
+		}
+		results, err := a.store.getRoundResults(round)
+		if err != nil {
+			if errors.Is(err, errRoundNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		for team, res := range results.Results {
+			if _, ok := masks[team]; !ok {
+				// the team has been removed from the game
+				continue
+			}
+			if meta.points(res.Status) == 1 {
+				masks[team][i] = "1"
+				totals[team]++
+			}
+		}
+	}
+	storedAdjustments, err := a.store.getAdjustments()
+	if err != nil {
+		return nil, err
+	}
+	adjustments := sumAdjustments(storedAdjustments)
+	teams := make([]string, len(a.config.Teams))
+	copy(teams, a.config.Teams)
+	// the teams out of competition are listed after the ranked ones
+	sort.SliceStable(teams, func(i, j int) bool {
+		if guest := a.config.OutOfCompetition; guest[teams[i]] != guest[teams[j]] {
+			return !guest[teams[i]]
+		}
+		return a.config.TeamIDs[teams[i]] < a.config.TeamIDs[teams[j]]
+	})
+	f, err := os.Create(args[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the results file: %w", err)
+	}
+	defer f.Close()
+	w := csv.NewWriter(f)
+	if err := w.Write(header); err != nil {
+		return nil, err
+	}
+	for _, team := range teams {
+		id, name := "", team
+		if teamID, ok := a.config.TeamIDs[team]; ok {
+			id = strconv.Itoa(teamID)
+		}
+		if a.config.OutOfCompetition[team] {
+			name = fmt.Sprintf("%s (%s)", team, outOfCompetitionMark)
+		}
+		record := []string{id, name}
+		record = append(record, masks[team]...)
+		record = append(record, formatPoints(float64(totals[team])+adjustments[team]))
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, fmt.Errorf("failed to write the results file: %w", err)
+	}
+	return &exportResult{
+		Plugin:  ratingExporter,
+		Message: fmt.Sprintf("results of %d teams are written to %s", len(teams), args[0]),
+	}, nil
+}
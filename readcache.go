@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+// valuesCache is a read-through cache of the value ranges that change only
+// when the tool writes them, such as the questions cell. The team answers are
+// always read from the API. Like the metadata, the entries expire after the
+// metadata cache time and are kept in the game store.
+type valuesCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]*storedValues
+	// store is nil until the game store is opened
+	store cacheStore
+}
+
+type storedValues struct {
+	FetchedAt time.Time
+	Values    [][]interface{}
+}
+
+func newValuesCache(ttlSeconds int) *valuesCache {
+	if ttlSeconds <= 0 {
+		ttlSeconds = 300
+	}
+	return &valuesCache{
+		ttl:     time.Duration(ttlSeconds) * time.Second,
+		entries: make(map[string]*storedValues),
+	}
+}
+
+const valuesCachePrefix = "values/"
+
+func valuesCacheKey(spreadsheetID string, r string) string {
+	return valuesCachePrefix + spreadsheetID + "/" + r
+}
+
+func (c *valuesCache) get(spreadsheetID string, r string) ([][]interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := valuesCacheKey(spreadsheetID, r)
+	v, ok := c.entries[key]
+	if !ok && c.store != nil {
+		if value, err := c.store.getCacheEntry(key); err == nil && value != nil {
+			v = &storedValues{}
+			ok = json.Unmarshal(value, v) == nil
+			if ok {
+				c.entries[key] = v
+			}
+		}
+	}
+	if !ok || time.Since(v.FetchedAt) > c.ttl {
+		return nil, false
+	}
+	return v.Values, true
+}
+
+func (c *valuesCache) put(spreadsheetID string, r string, values [][]interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := valuesCacheKey(spreadsheetID, r)
+	v := &storedValues{FetchedAt: time.Now(), Values: values}
+	c.entries[key] = v
+	if c.store == nil {
+		return
+	}
+	value, err := json.Marshal(v)
+	if err == nil {
+		err = c.store.saveCacheEntry(key, value)
+	}
+	if err != nil {
+		log.Printf("failed to store the cached range %s: %v", r, err)
+	}
+}
+
+// invalidateAll drops all the entries.
+func (c *valuesCache) invalidateAll() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string]*storedValues)
+	if c.store == nil {
+		return nil
+	}
+	return c.store.deleteCacheEntries(valuesCachePrefix)
+}
+
+// getCachedValues returns the values of the range, reading them from the API
+// if they are not cached or expired.
+func (a *app) getCachedValues(spreadsheetID string, r string) ([][]interface{}, error) {
+	if values, ok := a.values.get(spreadsheetID, r); ok {
+		return values, nil
+	}
+	resp, err := sheets.NewSpreadsheetsValuesService(a.service).Get(spreadsheetID, r).Context(a.commandContext()).Do()
+	if err != nil {
+		return nil, err
+	}
+	a.values.put(spreadsheetID, r, resp.Values)
+	return resp.Values, nil
+}
+
+type refreshResult struct{}
+
+func (r *refreshResult) String() string {
+	return "The cached spreadsheets metadata and values are dropped, they are read again on the next use"
+}
+
+// CmdRefresh drops the cached metadata and values, e.g. after the
+// spreadsheets are edited by hand: "refresh".
+func (a *app) CmdRefresh() (*refreshResult, error) {
+	if err := a.metadata.invalidateAll(); err != nil {
+		return nil, err
+	}
+	if err := a.values.invalidateAll(); err != nil {
+		return nil, err
+	}
+	return &refreshResult{}, nil
+}
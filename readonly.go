@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// readOnlyCommands are the commands of the observer sessions started with
+// --read-only: they neither write to the spreadsheets nor change the store.
+var readOnlyCommands = map[string]bool{
+	"get":         true,
+	"total":       true,
+	"adjustments": true,
+	"audit":       true,
+	"stats":       true,
+	"listURLs":    true,
+	"status":      true,
+	"help":        true,
+}
+
+type errorReadOnly struct {
+	cmd string
+}
+
+func (e *errorReadOnly) Error() string {
+	if len(e.cmd) == 0 {
+		return tr("error.readOnly")
+	}
+	return tr("error.readOnlyCommand", e.cmd)
+}
+
+// readOnlyTransport refuses the requests that may change the spreadsheets, the
+// Sheets API reads are GET requests.
+type readOnlyTransport struct {
+	base http.RoundTripper
+}
+
+func (t *readOnlyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return nil, fmt.Errorf("%s %s: %w", req.Method, req.URL.Path, &errorReadOnly{})
+	}
+	return t.base.RoundTrip(req)
+}
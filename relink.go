@@ -0,0 +1,206 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+// linkHealth is the state of the links of a team answers in the manager
+// spreadsheet.
+type linkHealth struct {
+	Team string `json:"team" yaml:"team"`
+	// Broken are the cells showing an error, e.g. #REF! when the team
+	// spreadsheet is not accessible.
+	Broken []string `json:"broken,omitempty" yaml:"broken,omitempty"`
+	// Loading are the cells that are not resolved yet.
+	Loading []string `json:"loading,omitempty" yaml:"loading,omitempty"`
+	Error   string   `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+func (h *linkHealth) healthy() bool {
+	return len(h.Broken) == 0 && len(h.Loading) == 0
+}
+
+type checkLinksResult struct {
+	Teams []*linkHealth `json:"teams" yaml:"teams"`
+}
+
+func (r *checkLinksResult) String() string {
+	var sb strings.Builder
+	for _, h := range r.Teams {
+		switch {
+		case h.healthy():
+			sb.WriteString(fmt.Sprintf("\t team %s: ok\n", h.Team))
+		case len(h.Broken) != 0:
+			sb.WriteString(fmt.Sprintf("\t team %s: %d broken links (%s), e.g. %s, run relink\n", h.Team, len(h.Broken), h.Error, h.Broken[0]))
+		default:
+			sb.WriteString(fmt.Sprintf("\t team %s: %d links are loading\n", h.Team, len(h.Loading)))
+		}
+	}
+	return sb.String()
+}
+
+type relinkResult struct {
+	Teams  []string          `json:"teams" yaml:"teams"`
+	Health *checkLinksResult `json:"health" yaml:"health"`
+}
+
+func (r *relinkResult) String() string {
+	return fmt.Sprintf("Links of the teams %s are rebuilt\n%s", strings.Join(r.Teams, ", "), r.Health)
+}
+
+// CmdRelink rebuilds the IMPORTRANGE links of the team answers in the manager
+// spreadsheet, for one team or for all teams: "relink [team]".
+func (a *app) CmdRelink(cmdStr string) (*relinkResult, error) {
+	args := splitArgs(cmdStr)
+	if len(args) > 2 {
+		return nil, fmt.Errorf("expected at most 1 argument, the team name")
+	}
+	storeSheets, err := a.GetGameSpreadsheets()
+	if err != nil {
+		return nil, err
+	}
+	if storeSheets.manager == nil {
+		return nil, errManagerSpreadsheetNotFound
+	}
+	gameSheets := newGameSpreadsheets(storeSheets)
+	teams := a.config.Teams
+	if len(args) == 2 {
+		teams = []string{args[1]}
+	}
+	for _, team := range teams {
+		if _, ok := gameSheets.teams[team]; !ok {
+			return nil, fmt.Errorf("spreadsheet of the team %s is not found", team)
+		}
+	}
+	if len(args) == 1 {
+		if err := a.linkManagerTeams(gameSheets); err != nil {
+			return nil, fmt.Errorf("failed to relink the teams: %w", err)
+		}
+	} else if err := a.relinkTeam(gameSheets, teams[0]); err != nil {
+		return nil, err
+	}
+	if err := a.store.appendEvent(fmt.Sprintf("relink: %s", strings.Join(teams, ", "))); err != nil {
+		return nil, err
+	}
+	health, err := a.checkLinks(teams)
+	if err != nil {
+		return nil, err
+	}
+	return &relinkResult{Teams: teams, Health: health}, nil
+}
+
+// relinkTeam rewrites the links of the team row in every answer group.
+func (a *app) relinkTeam(gameSheets *gameSpreadsheets, team string) error {
+	teamIndex := -1
+	for i, t := range a.config.Teams {
+		if t == team {
+			teamIndex = i
+		}
+	}
+	if teamIndex < 0 {
+		return fmt.Errorf("team %s is not in the game", team)
+	}
+	groups, err := a.createLinkManagerTeamsGroups(gameSheets)
+	if err != nil {
+		return err
+	}
+	teamGroups := make([]*sheets.ValueRange, 0, len(groups))
+	for offset, g := range groups {
+		row := offset*(len(a.config.Teams)+2) + 2 + teamIndex
+		values := make([]interface{}, len(g.Values))
+		for i, column := range g.Values {
+			values[i] = column[teamIndex]
+		}
+		teamGroups = append(teamGroups, &sheets.ValueRange{
+			MajorDimension: "ROWS",
+			Range:          rangeName(1, row, len(values), row),
+			Values:         [][]interface{}{values},
+		})
+	}
+	if a.config.MaskAnswers {
+		if err := a.maskLinks(gameSheets.manager.SpreadsheetId, teamGroups); err != nil {
+			return err
+		}
+	}
+	valuesService := sheets.NewSpreadsheetsValuesService(a.service)
+	_, err = valuesService.BatchUpdate(gameSheets.manager.SpreadsheetId, &sheets.BatchUpdateValuesRequest{
+		ValueInputOption: "USER_ENTERED",
+		Data:             teamGroups,
+	}).Context(a.commandContext()).Do()
+	if err != nil {
+		return fmt.Errorf("failed to relink the team %s: %w", team, err)
+	}
+	return nil
+}
+
+// CmdCheckLinks reports the teams whose links in the manager spreadsheet do
+// not resolve: "checkLinks".
+func (a *app) CmdCheckLinks() (*checkLinksResult, error) {
+	return a.checkLinks(a.config.Teams)
+}
+
+func (a *app) checkLinks(teams []string) (*checkLinksResult, error) {
+	storeSheets, err := a.GetGameSpreadsheets()
+	if err != nil {
+		return nil, err
+	}
+	if storeSheets.manager == nil {
+		return nil, errManagerSpreadsheetNotFound
+	}
+	sheetTitle := storeSheets.manager.SheetTitle
+	if a.config.MaskAnswers {
+		sheetTitle = rawSheetTitle
+	}
+	ranges := make([]string, 0)
+	_, err = a.createGroups(func(length int, _ int, groups []*sheets.ValueRange) ([]*sheets.ValueRange, error) {
+		ranges = append(ranges, sheetRange(sheetTitle, a.getLinkRange(len(groups), length)))
+		return append(groups, nil), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	valuesService := sheets.NewSpreadsheetsValuesService(a.service)
+	resp, err := valuesService.BatchGet(storeSheets.manager.ID).Ranges(ranges...).
+		MajorDimension("ROWS").ValueRenderOption("FORMATTED_VALUE").Context(a.commandContext()).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the links: %w", err)
+	}
+	health := make(map[string]*linkHealth, len(teams))
+	for _, team := range teams {
+		health[team] = &linkHealth{Team: team}
+	}
+	for offset, vr := range resp.ValueRanges {
+		for j, row := range vr.Values {
+			if j >= len(a.config.Teams) {
+				break
+			}
+			h, ok := health[a.config.Teams[j]]
+			if !ok {
+				continue
+			}
+			for i, v := range row {
+				value := fmt.Sprint(v)
+				cell := cellName(1+i, offset*(len(a.config.Teams)+2)+2+j)
+				switch {
+				case strings.HasPrefix(value, "#"):
+					h.Broken = append(h.Broken, cell)
+					h.Error = value
+				case strings.HasPrefix(value, "Loading"):
+					h.Loading = append(h.Loading, cell)
+				}
+			}
+		}
+	}
+	res := &checkLinksResult{Teams: make([]*linkHealth, 0, len(health))}
+	for _, h := range health {
+		res.Teams = append(res.Teams, h)
+	}
+	sort.Slice(res.Teams, func(i, j int) bool {
+		return res.Teams[i].Team < res.Teams[j].Team
+	})
+	return res, nil
+}
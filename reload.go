@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// reloadableFields are the configuration fields that can change during the
+// game: they are read when a command runs. The other fields shape the
+// spreadsheets, the stored game or the clients created at the start.
+var reloadableFields = map[string]bool{
+	"Labels":               true,
+	"Tiebreak":             true,
+	"AudioCues":            true,
+	"CheckKeys":            true,
+	"CheckSingleKeystroke": true,
+	"CheckClusterSimilar":  true,
+	"CheckSuggestDistance": true,
+	"Normalizers":          true,
+	"Answers":              true,
+	"FetchSettle":          true,
+	"JournalSize":          true,
+	"Backups":              true,
+	"StaleAfterDays":       true,
+	"TeamIDs":              true,
+	"OutOfCompetition":     true,
+	"StableTeamIDs":        true,
+	"FetchConcurrency":     true,
+	"Questions":            true,
+	"Aliases":              true,
+	"Jury":                 true,
+	"Collusion":            true,
+	"Mail":                 true,
+	"Report":               true,
+	"Webhooks":             true,
+	"WriteManualAnswers":   true,
+}
+
+type reloadResult struct {
+	Changed []string `json:"changed" yaml:"changed"`
+}
+
+func (r *reloadResult) String() string {
+	if len(r.Changed) == 0 {
+		return "The configuration is reloaded, nothing has changed\n"
+	}
+	return fmt.Sprintf("The configuration is reloaded, changed: %s\n", strings.Join(r.Changed, ", "))
+}
+
+// CmdReload parses the configuration file again and applies it if only the
+// reloadable fields have changed. The language and the command line settings
+// are kept.
+func (a *app) CmdReload() (*reloadResult, error) {
+	if len(a.config.ConfigFile) == 0 {
+		return nil, fmt.Errorf("the game is not started from a configuration file")
+	}
+	next, err := ParseJSONConfig(a.config.ConfigFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the configuration %s: %w", a.config.ConfigFile, err)
+	}
+	next.Language = a.config.Language
+	next.Labels.setDefaults(next.Language)
+	copyRuntimeFields(next, a.config)
+	if err := next.validate(); err != nil {
+		return nil, err
+	}
+	changed, rejected := diffConfigs(a.config, next)
+	if len(rejected) != 0 {
+		return nil, fmt.Errorf("the configuration is not reloaded, %s cannot change during the game", strings.Join(rejected, ", "))
+	}
+	if err := a.checkReloadedTeams(next); err != nil {
+		return nil, err
+	}
+	*a.config = *next
+	res := &reloadResult{Changed: changed}
+	if err := a.store.appendEvent(fmt.Sprintf("reload: %s", strings.Join(changed, ", "))); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// checkReloadedTeams validates the teams mentioned by the new configuration
+// against the teams of the stored game.
+func (a *app) checkReloadedTeams(next *Config) error {
+	teams, err := a.store.getTeams()
+	if err != nil {
+		return err
+	}
+	if len(teams) == 0 {
+		return nil
+	}
+	known := make(map[string]bool, len(teams))
+	for _, team := range teams {
+		known[team] = true
+	}
+	for team := range next.TeamIDs {
+		if !known[team] {
+			return fmt.Errorf("TeamIDs: team %s is not in the game", team)
+		}
+	}
+	for team := range next.StableTeamIDs {
+		if !known[team] {
+			return fmt.Errorf("StableTeamIDs: team %s is not in the game", team)
+		}
+	}
+	return nil
+}
+
+// copyRuntimeFields copies the fields set from the command line, they are not
+// in the configuration file.
+func copyRuntimeFields(dst *Config, src *Config) {
+	t := reflect.TypeOf(*src)
+	d, s := reflect.ValueOf(dst).Elem(), reflect.ValueOf(src).Elem()
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Tag.Get("json") == "-" {
+			d.Field(i).Set(s.Field(i))
+		}
+	}
+}
+
+// diffConfigs returns the changed reloadable fields and the changed fields
+// that cannot be reloaded.
+func diffConfigs(current *Config, next *Config) (changed []string, rejected []string) {
+	t := reflect.TypeOf(*current)
+	c, n := reflect.ValueOf(current).Elem(), reflect.ValueOf(next).Elem()
+	changed = make([]string, 0)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Tag.Get("json") == "-" || reflect.DeepEqual(c.Field(i).Interface(), n.Field(i).Interface()) {
+			continue
+		}
+		if reloadableFields[field.Name] {
+			changed = append(changed, field.Name)
+		} else {
+			rejected = append(rejected, field.Name)
+		}
+	}
+	return changed, rejected
+}
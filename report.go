@@ -0,0 +1,392 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"google.golang.org/api/drive/v3"
+)
+
+// ReportConfig configures the end-of-game report of the report command.
+type ReportConfig struct {
+	// MarkdownTemplate and HTMLTemplate are the files of the text/template
+	// and html/template of the report, the built-in ones are used if they
+	// are empty. The templates get the reportData fields, e.g. {{.Game}} and
+	// {{range .Standings}}.
+	MarkdownTemplate string
+	HTMLTemplate     string
+	// Upload uploads the report files to the game Drive folder, or to the
+	// Drive root if no folder is configured.
+	Upload bool
+}
+
+// check parses the templates.
+func (c *ReportConfig) check() []string {
+	if _, _, err := c.templates(); err != nil {
+		return []string{fmt.Sprintf("Report: %v", err)}
+	}
+	return nil
+}
+
+var reportFuncs = map[string]interface{}{
+	"ints": joinInts,
+	// md escapes the text of a Markdown table cell
+	"md": func(s string) string {
+		return strings.NewReplacer("|", `\|`, "\r", "", "\n", " ").Replace(s)
+	},
+	"points":     formatPoints,
+	"adjustment": formatAdjustment,
+}
+
+const defaultReportMarkdown = `# {{.Game}}
+
+Generated {{.GeneratedAt.Format "2006-01-02 15:04"}}
+
+## Standings
+
+| Place | Team | Score |
+|---|---|---|
+{{range .Standings}}{{$s := .}}{{range .Teams}}| {{$s.Places}} | {{md .}} | {{$s.Score}} |
+{{end}}{{end}}
+## Questions
+
+| Question | Correct | % |
+|---|---|---|
+{{range .Questions}}| {{.Question}} | {{.Correct}}/{{.Teams}}{{if .Voided}} (voided){{end}} | {{printf "%.0f" .Percent}} |
+{{end}}
+Hardest questions: {{ints .Hardest}}
+
+Easiest questions: {{ints .Easiest}}
+
+## Appeals
+{{if .Appeals}}
+| Question | Team | Answer | Accept | Reject | Outcome |
+|---|---|---|---|---|---|
+{{range .Appeals}}| {{.Round}} | {{md .Team}} | {{md .Response}} | {{points .Accept}} | {{points .Reject}} | {{.Outcome}} |
+{{end}}{{else}}
+No appeal.
+{{end}}
+## Notable answers
+{{if .Notable}}
+{{range .Notable}}- Question {{.Round}}: {{.Team}}, the only correct answer "{{.Response}}"
+{{end}}{{else}}
+No question is answered by a single team.
+{{end}}{{if .Adjustments}}
+## Score adjustments
+
+| Team | Points | Reason |
+|---|---|---|
+{{range .Adjustments}}| {{md .Team}} | {{adjustment .Points}} | {{md .Reason}} |
+{{end}}{{end}}{{if .Notes}}
+## Jury notes
+
+{{range .Notes}}{{$n := .}}{{range .Notes}}- Question {{$n.Round}}: {{.}}
+{{end}}{{end}}{{end}}`
+
+const defaultReportHTML = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Game}}</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; }
+td, th { border: 1px solid #ccc; padding: 4px 12px; }
+</style>
+</head>
+<body>
+<h1>{{.Game}}</h1>
+<p>Generated {{.GeneratedAt.Format "2006-01-02 15:04"}}</p>
+<h2>Standings</h2>
+<table>
+<tr><th>Place</th><th>Team</th><th>Score</th></tr>
+{{range .Standings}}{{$s := .}}{{range .Teams}}<tr><td>{{$s.Places}}</td><td>{{.}}</td><td>{{$s.Score}}</td></tr>
+{{end}}{{end}}</table>
+<h2>Questions</h2>
+<table>
+<tr><th>Question</th><th>Correct</th><th>%</th></tr>
+{{range .Questions}}<tr><td>{{.Question}}</td><td>{{.Correct}}/{{.Teams}}{{if .Voided}} (voided){{end}}</td><td>{{printf "%.0f" .Percent}}</td></tr>
+{{end}}</table>
+<p>Hardest questions: {{ints .Hardest}}</p>
+<p>Easiest questions: {{ints .Easiest}}</p>
+<h2>Appeals</h2>
+{{if .Appeals}}<table>
+<tr><th>Question</th><th>Team</th><th>Answer</th><th>Accept</th><th>Reject</th><th>Outcome</th></tr>
+{{range .Appeals}}<tr><td>{{.Round}}</td><td>{{.Team}}</td><td>{{.Response}}</td><td>{{points .Accept}}</td><td>{{points .Reject}}</td><td>{{.Outcome}}</td></tr>
+{{end}}</table>{{else}}<p>No appeal.</p>{{end}}
+<h2>Notable answers</h2>
+{{if .Notable}}<ul>
+{{range .Notable}}<li>Question {{.Round}}: {{.Team}}, the only correct answer "{{.Response}}"</li>
+{{end}}</ul>{{else}}<p>No question is answered by a single team.</p>{{end}}
+{{if .Adjustments}}<h2>Score adjustments</h2>
+<table>
+<tr><th>Team</th><th>Points</th><th>Reason</th></tr>
+{{range .Adjustments}}<tr><td>{{.Team}}</td><td>{{adjustment .Points}}</td><td>{{.Reason}}</td></tr>
+{{end}}</table>{{end}}
+{{if .Notes}}<h2>Jury notes</h2>
+<ul>
+{{range .Notes}}{{$n := .}}{{range .Notes}}<li>Question {{$n.Round}}: {{.}}</li>
+{{end}}{{end}}</ul>{{end}}
+</body>
+</html>
+`
+
+func (c *ReportConfig) templates() (*template.Template, *htmltemplate.Template, error) {
+	markdown, html := defaultReportMarkdown, defaultReportHTML
+	if len(c.MarkdownTemplate) != 0 {
+		b, err := ioutil.ReadFile(c.MarkdownTemplate)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read the Markdown template: %w", err)
+		}
+		markdown = string(b)
+	}
+	if len(c.HTMLTemplate) != 0 {
+		b, err := ioutil.ReadFile(c.HTMLTemplate)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read the HTML template: %w", err)
+		}
+		html = string(b)
+	}
+	mdTemplate, err := template.New("markdown").Funcs(reportFuncs).Parse(markdown)
+	if err != nil {
+		return nil, nil, fmt.Errorf("the Markdown template is not valid: %w", err)
+	}
+	htmlTemplate, err := htmltemplate.New("html").Funcs(reportFuncs).Parse(html)
+	if err != nil {
+		return nil, nil, fmt.Errorf("the HTML template is not valid: %w", err)
+	}
+	return mdTemplate, htmlTemplate, nil
+}
+
+// reportData is the data of the report templates.
+type reportData struct {
+	Game        string
+	GeneratedAt time.Time
+	Standings   []reportStanding
+	Questions   []*questionStats
+	Hardest     []int
+	Easiest     []int
+	Appeals     []reportAppeal
+	// Notable are the correct answers of the questions answered by a single
+	// team.
+	Notable     []reportAnswer
+	Adjustments []scoreAdjustment
+	Notes       []reportNotes
+}
+
+type reportStanding struct {
+	Places string
+	Score  string
+	Teams  []string
+}
+
+// reportAppeal is a disputed response the jury voted on.
+type reportAppeal struct {
+	Round    int
+	Team     string
+	Response string
+	Accept   float64
+	Reject   float64
+	// Outcome is the status of the response, "pending" while it is
+	// disputed.
+	Outcome string
+}
+
+type reportAnswer struct {
+	Round    int
+	Team     string
+	Response string
+}
+
+type reportNotes struct {
+	Round int
+	Notes []string
+}
+
+type reportResult struct {
+	MarkdownFile string `json:"markdownFile" yaml:"markdownFile"`
+	HTMLFile     string `json:"htmlFile" yaml:"htmlFile"`
+	// URLs are the links of the uploaded files.
+	URLs []string `json:"urls,omitempty" yaml:"urls,omitempty"`
+}
+
+func (r *reportResult) String() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("The report is written to %s and %s\n", r.MarkdownFile, r.HTMLFile))
+	for _, u := range r.URLs {
+		sb.WriteString(fmt.Sprintf("Uploaded: %s\n", u))
+	}
+	return sb.String()
+}
+
+// CmdReport writes the end-of-game report, in Markdown and HTML, to the
+// output directory: the standings, the per-question statistics, the appeals,
+// the notable answers, the score adjustments and the jury notes. The files
+// are uploaded to Drive if Report.Upload is set.
+func (a *app) CmdReport() (*reportResult, error) {
+	mdTemplate, htmlTemplate, err := a.config.Report.templates()
+	if err != nil {
+		return nil, err
+	}
+	data, err := a.reportData()
+	if err != nil {
+		return nil, err
+	}
+	var markdown, html bytes.Buffer
+	if err := mdTemplate.Execute(&markdown, data); err != nil {
+		return nil, fmt.Errorf("failed to render the Markdown report: %w", err)
+	}
+	if err := htmlTemplate.Execute(&html, data); err != nil {
+		return nil, fmt.Errorf("failed to render the HTML report: %w", err)
+	}
+	res := &reportResult{
+		MarkdownFile: path.Join(a.config.OutputDir, "report.md"),
+		HTMLFile:     path.Join(a.config.OutputDir, "report.html"),
+	}
+	if err := ioutil.WriteFile(res.MarkdownFile, markdown.Bytes(), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write the report: %w", err)
+	}
+	if err := ioutil.WriteFile(res.HTMLFile, html.Bytes(), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write the report: %w", err)
+	}
+	if a.config.Report.Upload {
+		for _, file := range []struct {
+			name     string
+			mimeType string
+		}{
+			{res.MarkdownFile, "text/markdown"},
+			{res.HTMLFile, "text/html"},
+		} {
+			u, err := a.uploadReportFile(file.name, file.mimeType)
+			if err != nil {
+				return nil, err
+			}
+			res.URLs = append(res.URLs, u)
+		}
+	}
+	return res, nil
+}
+
+func (a *app) reportData() (*reportData, error) {
+	total, err := a.computeTotals()
+	if err != nil {
+		return nil, err
+	}
+	stats, err := a.computeStats()
+	if err != nil {
+		return nil, err
+	}
+	data := &reportData{
+		Game:        a.config.GameName,
+		GeneratedAt: time.Now(),
+		Questions:   stats.Questions,
+		Hardest:     stats.Hardest,
+		Easiest:     stats.Easiest,
+	}
+	for _, s := range a.standings(total) {
+		data.Standings = append(data.Standings, reportStanding{Places: s.places(), Score: formatPoints(s.Score), Teams: s.Teams})
+	}
+	if data.Adjustments, err = a.store.getAdjustments(); err != nil {
+		return nil, err
+	}
+	allMeta, err := a.store.getAllRoundMeta()
+	if err != nil {
+		return nil, err
+	}
+	allResults, err := a.store.getAllRoundResults()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(allResults, func(i, j int) bool { return allResults[i].Round < allResults[j].Round })
+	for _, results := range allResults {
+		meta := allMeta[results.Round]
+		teams := make([]string, 0, len(results.Results))
+		for team := range results.Results {
+			teams = append(teams, team)
+		}
+		sort.Strings(teams)
+		voided := meta != nil && len(meta.Void) != 0
+		correct := make([]string, 0)
+		for _, team := range teams {
+			resp := results.Results[team]
+			if !voided && resp.Status == ResponseStatusOK {
+				correct = append(correct, team)
+			}
+			votes, err := a.store.getVotes(results.Round, team)
+			if err != nil {
+				return nil, err
+			}
+			if votes == nil || len(votes.Votes) == 0 {
+				continue
+			}
+			tally := a.tallyVotes(results.Round, team, votes, resp)
+			outcome := resp.Status.String()
+			if resp.Status == ResponseStatusInQuestion {
+				outcome = "pending"
+			}
+			data.Appeals = append(data.Appeals, reportAppeal{
+				Round:    a.config.questionNumber(results.Round),
+				Team:     team,
+				Response: resp.Response,
+				Accept:   tally.Accept,
+				Reject:   tally.Reject,
+				Outcome:  outcome,
+			})
+		}
+		if len(correct) == 1 {
+			data.Notable = append(data.Notable, reportAnswer{
+				Round:    a.config.questionNumber(results.Round),
+				Team:     correct[0],
+				Response: results.Results[correct[0]].Response,
+			})
+		}
+	}
+	rounds := make([]int, 0, len(allMeta))
+	for round, meta := range allMeta {
+		if meta != nil && len(meta.Notes) != 0 {
+			rounds = append(rounds, round)
+		}
+	}
+	sort.Ints(rounds)
+	for _, round := range rounds {
+		data.Notes = append(data.Notes, reportNotes{Round: a.config.questionNumber(round), Notes: allMeta[round].Notes})
+	}
+	return data, nil
+}
+
+// uploadReportFile uploads the report file to the game Drive folder and
+// returns its link.
+func (a *app) uploadReportFile(file string, mimeType string) (string, error) {
+	if a.drive == nil {
+		return "", fmt.Errorf("the Drive API client is not initialized")
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	driveFile := &drive.File{
+		Name:     fmt.Sprintf("%s - %s", a.config.GameName, path.Base(file)),
+		MimeType: mimeType,
+	}
+	if a.config.Drive.enabled() {
+		folder, err := a.gameDriveFolder()
+		if err != nil {
+			return "", err
+		}
+		driveFile.Parents = []string{folder}
+	}
+	created, err := a.drive.Files.Create(driveFile).Media(f).Fields("id", "webViewLink").Context(a.commandContext()).Do()
+	if err != nil {
+		return "", fmt.Errorf("failed to upload the report %s: %w", file, err)
+	}
+	return created.WebViewLink, nil
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// responseRevision is a distinct response of a team to a round, with the time
+// it was first fetched.
+type responseRevision struct {
+	Response  string    `json:"response" yaml:"response"`
+	FetchedAt time.Time `json:"fetchedAt" yaml:"fetchedAt"`
+}
+
+type responseHistoryResult struct {
+	Round     int                `json:"round" yaml:"round"`
+	Team      string             `json:"team" yaml:"team"`
+	Revisions []responseRevision `json:"revisions" yaml:"revisions"`
+}
+
+func (r *responseHistoryResult) String() string {
+	if len(r.Revisions) == 0 {
+		return fmt.Sprintf("No response of the team %s to the round %d has been fetched\n", r.Team, r.Round)
+	}
+	t := &table{header: []string{tr("header.fetched"), tr("header.response")}, indent: "\t"}
+	for _, rev := range r.Revisions {
+		response, _ := truncateAnswer(rev.Response, answerDisplayWidth)
+		t.addRow(plainCell(rev.FetchedAt.Local().Format("2006-01-02 15:04:05")), plainCell(response))
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Responses of the team %s to the round %d:\n", r.Team, r.Round))
+	sb.WriteString(t.String())
+	return sb.String()
+}
+
+// CmdHistory lists the distinct responses of the team to the round seen by
+// the fetches: "history <round> <team>".
+func (a *app) CmdHistory(cmdStr string) (*responseHistoryResult, error) {
+	sSplitted := splitArgs(cmdStr)
+	if len(sSplitted) < 3 {
+		return nil, fmt.Errorf("expected the round number and the team name")
+	}
+	round, err := a.getRoundNumber(strings.Join(sSplitted[:2], " "))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse history request: %w", err)
+	}
+	team := strings.Join(sSplitted[2:], " ")
+	revisions, err := a.store.getResponseHistory(round, team)
+	if err != nil {
+		return nil, err
+	}
+	return &responseHistoryResult{Round: a.config.questionNumber(round), Team: team, Revisions: revisions}, nil
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// The steps of the close command in their order. The completed lock, reveal
+// and fetch steps are stored in the round metadata and are not repeated until
+// the round is unlocked, the missing answers report and the check run every
+// time.
+const (
+	closeStepLock    = "lock"
+	closeStepReveal  = "reveal"
+	closeStepFetch   = "fetch"
+	closeStepMissing = "missing"
+	closeStepCheck   = "check"
+)
+
+// closeCheckFlag makes the close command open the check of the round once the
+// answers are fetched.
+const closeCheckFlag = "--check"
+
+const (
+	closeStatusDone    = "done"
+	closeStatusResumed = "done earlier"
+	closeStatusSkipped = "skipped"
+)
+
+type closeStepResult struct {
+	Step    string `json:"step" yaml:"step"`
+	Status  string `json:"status" yaml:"status"`
+	Details string `json:"details,omitempty" yaml:"details,omitempty"`
+}
+
+type closeResult struct {
+	// Round is the question number of the round.
+	Round int                `json:"round" yaml:"round"`
+	Steps []*closeStepResult `json:"steps" yaml:"steps"`
+}
+
+func (r *closeResult) String() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Round %d is closed:\n", r.Round))
+	for _, s := range r.Steps {
+		sb.WriteString(fmt.Sprintf("\t%s: %s", s.Step, s.Status))
+		if len(s.Details) != 0 {
+			sb.WriteString(", " + s.Details)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// errorCloseStep is the failure of a close step, running the command again
+// resumes from the failed step.
+type errorCloseStep struct {
+	round int
+	step  string
+	done  []*closeStepResult
+	err   error
+}
+
+func (e *errorCloseStep) Error() string {
+	done := make([]string, 0, len(e.done))
+	for _, s := range e.done {
+		done = append(done, s.Step)
+	}
+	completed := "no step is completed"
+	if len(done) != 0 {
+		completed = "completed: " + strings.Join(done, ", ")
+	}
+	return fmt.Sprintf("round %d closing has stopped at the %s step (%s): %v, run \"close %d\" to resume", e.round, e.step, completed, e.err, e.round)
+}
+
+func (e *errorCloseStep) Unwrap() error {
+	return e.err
+}
+
+func closeNeedsCheck(_ *app, cmdStr string) bool {
+	args := splitArgs(cmdStr)
+	return len(args) == 3 && args[2] == closeCheckFlag
+}
+
+// CmdClose runs the round closing sequence: "close <round> [--check]". It
+// locks the round answers, shows the masked answers in the manager sheet,
+// fetches the answers, reports the teams without an answer and, with
+// --check, opens the check of the round.
+func (a *app) CmdClose(cmdStr string) (*closeResult, error) {
+	check := closeNeedsCheck(a, cmdStr)
+	if check {
+		cmdStr = strings.Join(splitArgs(cmdStr)[:2], " ")
+	}
+	round, err := a.getRoundNumber(cmdStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse close request: %w", err)
+	}
+	meta, err := a.getRoundMeta(round)
+	if err != nil {
+		return nil, err
+	}
+	completed := make(map[string]bool, len(meta.Closed))
+	for _, step := range meta.Closed {
+		completed[step] = true
+	}
+	res := &closeResult{Round: a.config.questionNumber(round), Steps: make([]*closeStepResult, 0)}
+	steps := []struct {
+		name string
+		run  func() (*closeStepResult, error)
+	}{
+		{closeStepLock, func() (*closeStepResult, error) {
+			locked, err := a.lockRound(a.commandContext(), round)
+			if err != nil {
+				return nil, err
+			}
+			return &closeStepResult{Status: closeStatusDone, Details: fmt.Sprintf("%d teams locked", len(locked.Teams))}, nil
+		}},
+		{closeStepReveal, func() (*closeStepResult, error) {
+			if !a.config.MaskAnswers {
+				return &closeStepResult{Status: closeStatusSkipped, Details: "the answers are not masked"}, nil
+			}
+			if err := a.closeMaskedRound(round); err != nil {
+				return nil, err
+			}
+			return &closeStepResult{Status: closeStatusDone, Details: "the answers are shown in the manager spreadsheet"}, nil
+		}},
+		{closeStepFetch, func() (*closeStepResult, error) {
+			results, err := a.fetchRound(round)
+			if err != nil {
+				return nil, err
+			}
+			if results == nil {
+				return nil, errors.New("the fetch is queued until the Google API is reachable")
+			}
+			return &closeStepResult{Status: closeStatusDone, Details: fmt.Sprintf("%d answers fetched", len(results.Results))}, nil
+		}},
+	}
+	for _, step := range steps {
+		if completed[step.name] {
+			res.Steps = append(res.Steps, &closeStepResult{Step: step.name, Status: closeStatusResumed})
+			continue
+		}
+		s, err := step.run()
+		if err != nil {
+			return nil, &errorCloseStep{round: res.Round, step: step.name, done: res.Steps, err: err}
+		}
+		s.Step = step.name
+		res.Steps = append(res.Steps, s)
+		if err := a.completeCloseStep(round, step.name); err != nil {
+			return nil, err
+		}
+	}
+	missing, err := a.roundMissing(round, false)
+	if err != nil {
+		return nil, &errorCloseStep{round: res.Round, step: closeStepMissing, done: res.Steps, err: err}
+	}
+	details := "all the teams have answered"
+	if len(missing.Teams) != 0 {
+		details = "no answer from " + strings.Join(missing.Teams, ", ")
+	}
+	res.Steps = append(res.Steps, &closeStepResult{Step: closeStepMissing, Status: closeStatusDone, Details: details})
+	if !check {
+		return res, nil
+	}
+	// the steps so far are shown before the check starts
+	fmt.Println(res)
+	if err := a.checkRound(round); err != nil {
+		return nil, &errorCloseStep{round: res.Round, step: closeStepCheck, done: res.Steps, err: err}
+	}
+	res.Steps = append(res.Steps, &closeStepResult{Step: closeStepCheck, Status: closeStatusDone})
+	return res, nil
+}
+
+// completeCloseStep stores the completed close step in the round metadata.
+func (a *app) completeCloseStep(round int, step string) error {
+	meta, err := a.getRoundMeta(round)
+	if err != nil {
+		return err
+	}
+	meta.Closed = append(meta.Closed, step)
+	if err := a.store.saveRoundMeta(round, meta); err != nil {
+		return fmt.Errorf("failed to save the round %d close progress: %w", a.config.questionNumber(round), err)
+	}
+	return nil
+}
+
+// resetCloseSteps forgets the completed close steps of the round.
+func (a *app) resetCloseSteps(round int) error {
+	meta, err := a.getRoundMeta(round)
+	if err != nil {
+		return err
+	}
+	if len(meta.Closed) == 0 {
+		return nil
+	}
+	meta.Closed = nil
+	if err := a.store.saveRoundMeta(round, meta); err != nil {
+		return fmt.Errorf("failed to reset the round %d close progress: %w", a.config.questionNumber(round), err)
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// roundVoidAll gives the point of a removed question to every team.
+	roundVoidAll = "all"
+	// roundVoidNone gives the point of a removed question to no team.
+	roundVoidNone = "none"
+)
+
+// roundMeta holds the jury notes on a round and whether the question has been
+// removed. Void is roundVoidAll, roundVoidNone or empty if the round is scored
+// as usual.
+type roundMeta struct {
+	Notes []string `json:"notes,omitempty" yaml:"notes,omitempty"`
+	Void  string   `json:"void,omitempty" yaml:"void,omitempty"`
+	// Closed are the completed steps of the close command, they are skipped
+	// when the command is run again.
+	Closed []string `json:"closed,omitempty" yaml:"closed,omitempty"`
+}
+
+// points returns the points of a response in the round, a voided round
+// overrides the status of the response.
+func (m *roundMeta) points(status ResponseStatus) float64 {
+	if m == nil {
+		return status.points()
+	}
+	switch m.Void {
+	case roundVoidAll:
+		return 1
+	case roundVoidNone:
+		return 0
+	default:
+		return status.points()
+	}
+}
+
+type roundMetaResult struct {
+	Round int        `json:"round" yaml:"round"`
+	Meta  *roundMeta `json:"meta" yaml:"meta"`
+}
+
+func (r *roundMetaResult) String() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Round %d:\n", r.Round))
+	switch r.Meta.Void {
+	case roundVoidAll:
+		sb.WriteString("\t voided, every team gets the point\n")
+	case roundVoidNone:
+		sb.WriteString("\t voided, no team gets the point\n")
+	}
+	for _, note := range r.Meta.Notes {
+		sb.WriteString(fmt.Sprintf("\t note: %s\n", note))
+	}
+	return sb.String()
+}
+
+// CmdNote adds a jury note to the round: "note <round> <text>".
+func (a *app) CmdNote(cmdStr string) (*roundMetaResult, error) {
+	sSplitted := splitArgs(cmdStr)
+	if len(sSplitted) < 3 || len(strings.TrimSpace(strings.Join(sSplitted[2:], " "))) == 0 {
+		return nil, fmt.Errorf("expected the round number and the note text")
+	}
+	round, err := a.parseRound(sSplitted[1])
+	if err != nil {
+		return nil, err
+	}
+	meta, err := a.getRoundMeta(round)
+	if err != nil {
+		return nil, err
+	}
+	note := strings.TrimSpace(strings.Join(sSplitted[2:], " "))
+	meta.Notes = append(meta.Notes, note)
+	if err := a.store.saveRoundMeta(round, meta); err != nil {
+		return nil, err
+	}
+	if err := a.store.appendEvent(fmt.Sprintf("note %d: %s", round, note)); err != nil {
+		return nil, err
+	}
+	return &roundMetaResult{Round: a.config.questionNumber(round), Meta: meta}, nil
+}
+
+// CmdVoid removes the question of the round from the scoring: "void <round>
+// [all|none|off]". With all, the default, every team gets the point, with
+// none no team gets it, off scores the round as usual again.
+func (a *app) CmdVoid(cmdStr string) (*roundMetaResult, error) {
+	sSplitted := splitArgs(cmdStr)
+	if len(sSplitted) != 2 && len(sSplitted) != 3 {
+		return nil, fmt.Errorf("expected the round number and optionally all, none or off")
+	}
+	round, err := a.parseRound(sSplitted[1])
+	if err != nil {
+		return nil, err
+	}
+	void := roundVoidAll
+	if len(sSplitted) == 3 {
+		void = sSplitted[2]
+	}
+	meta, err := a.getRoundMeta(round)
+	if err != nil {
+		return nil, err
+	}
+	switch void {
+	case roundVoidAll, roundVoidNone:
+		meta.Void = void
+	case "off":
+		meta.Void = ""
+	default:
+		return nil, fmt.Errorf("unknown void mode %s, expected all, none or off", void)
+	}
+	if err := a.store.saveRoundMeta(round, meta); err != nil {
+		return nil, err
+	}
+	if err := a.store.appendEvent(fmt.Sprintf("void %d: %s", round, void)); err != nil {
+		return nil, err
+	}
+	return &roundMetaResult{Round: a.config.questionNumber(round), Meta: meta}, nil
+}
+
+// getRoundMeta returns the stored round metadata, or an empty one.
+func (a *app) getRoundMeta(round int) (*roundMeta, error) {
+	meta, err := a.store.getRoundMeta(round)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the round %d metadata: %w", a.config.questionNumber(round), err)
+	}
+	if meta == nil {
+		meta = &roundMeta{}
+	}
+	return meta, nil
+}
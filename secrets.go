@@ -0,0 +1,271 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// secretsEncryptionEnabled makes the secret files written to the game dir
+// encrypted at rest, the --no-encrypt flag disables it.
+var secretsEncryptionEnabled = true
+
+func setSecretsEncryption(enabled bool) {
+	secretsEncryptionEnabled = enabled
+}
+
+const (
+	encryptedFileVersion = 1
+	// passphraseEnv is the environment variable of the passphrase, e.g. for
+	// the unattended runs.
+	passphraseEnv = "CHGK_PASSPHRASE"
+	// keychainService is the name of the passphrase entry in the OS
+	// keychain.
+	keychainService     = "chgk-google-sheets"
+	secretKeyIterations = 200000
+)
+
+// encryptedFile is the content of an encrypted secret file: the data sealed
+// with AES-GCM under a key derived from the passphrase and the salt.
+type encryptedFile struct {
+	Encrypted int    `json:"encrypted"`
+	Salt      []byte `json:"salt"`
+	Nonce     []byte `json:"nonce"`
+	Data      []byte `json:"data"`
+}
+
+var errWrongPassphrase = errors.New("the passphrase is wrong or the file is damaged")
+
+// sessionPassphrase is asked once per process and reused for every secret
+// file.
+var sessionPassphrase struct {
+	mu    sync.Mutex
+	value string
+}
+
+// readSecretFile reads the file, decrypting it if it is encrypted. plain
+// reports that the file is not encrypted.
+func readSecretFile(file string) (data []byte, plain bool, err error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, false, err
+	}
+	enc := &encryptedFile{}
+	if err := json.Unmarshal(b, enc); err != nil || enc.Encrypted == 0 {
+		return b, true, nil
+	}
+	if enc.Encrypted != encryptedFileVersion {
+		return nil, false, fmt.Errorf("unsupported encryption version %d of %s", enc.Encrypted, file)
+	}
+	passphrase, err := getPassphrase(false)
+	if err != nil {
+		return nil, false, err
+	}
+	data, err = decryptSecret(enc, passphrase)
+	if err != nil {
+		forgetPassphrase()
+		return nil, false, fmt.Errorf("failed to decrypt %s: %w", file, err)
+	}
+	return data, false, nil
+}
+
+// writeSecretFile writes the data readable by the owner only, encrypted
+// unless the encryption is disabled.
+func writeSecretFile(file string, data []byte) error {
+	if secretsEncryptionEnabled {
+		passphrase, err := getPassphrase(true)
+		if err != nil {
+			return err
+		}
+		enc, err := encryptSecret(data, passphrase)
+		if err != nil {
+			return err
+		}
+		if data, err = json.Marshal(enc); err != nil {
+			return err
+		}
+	}
+	return ioutil.WriteFile(file, data, 0600)
+}
+
+// encryptFileInPlace encrypts a plain text secret file, e.g. the credentials
+// file given to --creds.
+func encryptFileInPlace(file string) error {
+	data, plain, err := readSecretFile(file)
+	if err != nil {
+		return err
+	}
+	if !plain {
+		return fmt.Errorf("%s is already encrypted", file)
+	}
+	secretsEncryptionEnabled = true
+	return writeSecretFile(file, data)
+}
+
+func encryptSecret(data []byte, passphrase string) (*encryptedFile, error) {
+	enc := &encryptedFile{Encrypted: encryptedFileVersion, Salt: make([]byte, 16)}
+	if _, err := rand.Read(enc.Salt); err != nil {
+		return nil, err
+	}
+	gcm, err := newSecretCipher(passphrase, enc.Salt)
+	if err != nil {
+		return nil, err
+	}
+	enc.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(enc.Nonce); err != nil {
+		return nil, err
+	}
+	enc.Data = gcm.Seal(nil, enc.Nonce, data, nil)
+	return enc, nil
+}
+
+func decryptSecret(enc *encryptedFile, passphrase string) ([]byte, error) {
+	gcm, err := newSecretCipher(passphrase, enc.Salt)
+	if err != nil {
+		return nil, err
+	}
+	if len(enc.Nonce) != gcm.NonceSize() {
+		return nil, errWrongPassphrase
+	}
+	data, err := gcm.Open(nil, enc.Nonce, enc.Data, nil)
+	if err != nil {
+		return nil, errWrongPassphrase
+	}
+	return data, nil
+}
+
+func newSecretCipher(passphrase string, salt []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(pbkdf2.Key([]byte(passphrase), salt, secretKeyIterations, 32, sha256.New))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// getPassphrase returns the passphrase of the session: the one of the
+// environment, of the OS keychain or typed in the terminal. A new passphrase
+// is typed twice and may be remembered in the OS keychain.
+func getPassphrase(isNew bool) (string, error) {
+	sessionPassphrase.mu.Lock()
+	defer sessionPassphrase.mu.Unlock()
+	if len(sessionPassphrase.value) != 0 {
+		return sessionPassphrase.value, nil
+	}
+	if passphrase := os.Getenv(passphraseEnv); len(passphrase) != 0 {
+		sessionPassphrase.value = passphrase
+		return passphrase, nil
+	}
+	if passphrase, err := keychainPassphrase(); err == nil && len(passphrase) != 0 {
+		sessionPassphrase.value = passphrase
+		return passphrase, nil
+	}
+	if !isTerminal(os.Stdin) {
+		return "", fmt.Errorf("the secrets are encrypted: set the passphrase in %s, or run with --no-encrypt", passphraseEnv)
+	}
+	passphrase, err := readPassphrase("Passphrase of the game secrets: ")
+	if err != nil {
+		return "", err
+	}
+	if len(passphrase) == 0 {
+		return "", fmt.Errorf("the passphrase cannot be empty")
+	}
+	if isNew {
+		confirmed, err := readPassphrase("Repeat the passphrase: ")
+		if err != nil {
+			return "", err
+		}
+		if confirmed != passphrase {
+			return "", fmt.Errorf("the passphrases do not match")
+		}
+		offerKeychain(passphrase)
+	}
+	sessionPassphrase.value = passphrase
+	return passphrase, nil
+}
+
+func forgetPassphrase() {
+	sessionPassphrase.mu.Lock()
+	defer sessionPassphrase.mu.Unlock()
+	sessionPassphrase.value = ""
+}
+
+// readPassphrase reads a line of the terminal with the echo turned off.
+func readPassphrase(prompt string) (string, error) {
+	fmt.Print(prompt)
+	settings, err := stty("-g")
+	if err == nil {
+		if _, err := stty("-echo"); err == nil {
+			defer stty(strings.TrimSpace(settings))
+		}
+	}
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	fmt.Println()
+	if err != nil {
+		return "", fmt.Errorf("failed to read the passphrase: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// keychainPassphrase reads the passphrase from the macOS keychain or from the
+// Secret Service on Linux.
+func keychainPassphrase() (string, error) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("security", "find-generic-password", "-s", keychainService, "-a", currentUserName(), "-w")
+	case "linux":
+		cmd = exec.Command("secret-tool", "lookup", "service", keychainService)
+	default:
+		return "", fmt.Errorf("the OS keychain is not supported on %s", runtime.GOOS)
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(out), "\r\n"), nil
+}
+
+// offerKeychain stores the new passphrase in the OS keychain if the user
+// agrees, so that it is not asked again. The passphrase is written to the
+// standard input of the keychain tool, as its arguments are seen by the
+// other users of the machine.
+func offerKeychain(passphrase string) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		// security reads the password from its interactive prompt, which
+		// is answered on the standard input, when -w is the last option
+		cmd = exec.Command("security", "add-generic-password", "-U", "-s", keychainService, "-a", currentUserName(), "-w")
+		cmd.Stdin = bytes.NewBufferString(passphrase + "\n" + passphrase + "\n")
+	case "linux":
+		cmd = exec.Command("secret-tool", "store", "--label", keychainService, "service", keychainService)
+		cmd.Stdin = bytes.NewBufferString(passphrase)
+	default:
+		return
+	}
+	if _, err := exec.LookPath(cmd.Path); err != nil {
+		return
+	}
+	fmt.Print("Remember the passphrase in the OS keychain? (y/n): ")
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil || strings.TrimSpace(strings.ToLower(answer)) != "y" {
+		return
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		fmt.Printf("failed to store the passphrase in the keychain: %v %s\n", err, strings.TrimSpace(string(out)))
+	}
+}
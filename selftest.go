@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+// defaultQuotaPerMinute is the Google Sheets API limit of the requests per
+// minute per user.
+const defaultQuotaPerMinute = 60
+
+// selftestProbeColumn is the manager sheet column written and cleared by the
+// write access check, it is far to the right of the answers.
+const selftestProbeColumn = 40
+
+type selftestCheck struct {
+	Name   string `json:"name" yaml:"name"`
+	Passed bool   `json:"passed" yaml:"passed"`
+	Detail string `json:"detail" yaml:"detail"`
+}
+
+type selftestResult struct {
+	Checks []*selftestCheck `json:"checks" yaml:"checks"`
+	Passed bool             `json:"passed" yaml:"passed"`
+}
+
+func (r *selftestResult) String() string {
+	t := &table{header: []string{"Check", "Result", "Detail"}}
+	for _, c := range r.Checks {
+		result := tableCell{text: "pass", color: colorGreen}
+		if !c.Passed {
+			result = tableCell{text: "FAIL", color: colorRed}
+		}
+		t.addRow(plainCell(c.Name), result, plainCell(c.Detail))
+	}
+	var sb strings.Builder
+	sb.WriteString(t.String())
+	if r.Passed {
+		sb.WriteString("All checks passed\n")
+	} else {
+		sb.WriteString("Some checks failed, fix them before the game starts\n")
+	}
+	return sb.String()
+}
+
+func (r *selftestResult) add(name string, err error, detail string) {
+	c := &selftestCheck{Name: name, Passed: err == nil, Detail: detail}
+	if err != nil {
+		c.Detail = err.Error()
+	}
+	r.Checks = append(r.Checks, c)
+}
+
+// CmdSelftest checks that the game can run: the OAuth token, the write access
+// to the manager spreadsheet, the links to the team spreadsheets, the store
+// and the API quota headroom.
+func (a *app) CmdSelftest() (*selftestResult, error) {
+	res := &selftestResult{}
+	storeSheets, err := a.GetGameSpreadsheets()
+	if err == nil && storeSheets.manager == nil {
+		err = errManagerSpreadsheetNotFound
+	}
+	if err != nil {
+		res.add("manager spreadsheet", err, "")
+		res.Passed = false
+		return res, nil
+	}
+	managerID := storeSheets.manager.ID
+	_, err = a.service.Spreadsheets.Get(managerID).Fields("spreadsheetId").Context(a.commandContext()).Do()
+	res.add("OAuth token", err, "the token is valid")
+	res.add("manager write access", a.probeManagerWrite(storeSheets.manager), "a probe cell is written and cleared")
+	health, err := a.checkLinks(a.config.Teams)
+	if err != nil {
+		res.add("team links", err, "")
+	} else {
+		for _, h := range health.Teams {
+			var linkErr error
+			switch {
+			case len(h.Broken) != 0:
+				linkErr = fmt.Errorf("%d broken links (%s), run relink", len(h.Broken), h.Error)
+			case len(h.Loading) != 0:
+				linkErr = fmt.Errorf("%d links are still loading, run selftest again", len(h.Loading))
+			}
+			res.add(fmt.Sprintf("team %s links", h.Team), linkErr, "IMPORTRANGE resolves")
+		}
+	}
+	res.add("store", a.store.appendEvent("selftest"), "the store is writable")
+	res.Checks = append(res.Checks, a.checkQuotaHeadroom())
+	res.Passed = true
+	for _, c := range res.Checks {
+		res.Passed = res.Passed && c.Passed
+	}
+	return res, nil
+}
+
+func (a *app) probeManagerWrite(manager *storeSpreadsheet) error {
+	probe := sheetRange(manager.SheetTitle, cellName(selftestProbeColumn, 1))
+	valuesService := sheets.NewSpreadsheetsValuesService(a.service)
+	_, err := valuesService.Update(manager.ID, probe, &sheets.ValueRange{
+		Values: [][]interface{}{{fmt.Sprintf("selftest %s", time.Now().Format(time.RFC3339))}},
+	}).ValueInputOption("RAW").Context(a.commandContext()).Do()
+	if err != nil {
+		return err
+	}
+	_, err = valuesService.Clear(manager.ID, probe, &sheets.ClearValuesRequest{}).Context(a.commandContext()).Do()
+	return err
+}
+
+// checkQuotaHeadroom compares the requests sent in the last minute and the
+// configured rate limit with the API quota.
+func (a *app) checkQuotaHeadroom() *selftestCheck {
+	c := &selftestCheck{Name: "quota headroom"}
+	quota := a.config.QuotaPerMinute
+	if quota <= 0 {
+		quota = defaultQuotaPerMinute
+	}
+	recent := a.metrics.recentCalls(time.Minute)
+	c.Detail = fmt.Sprintf("%d of %d requests per minute are used", recent, quota)
+	switch {
+	case a.config.RequestsPerMinute > quota:
+		c.Detail = fmt.Sprintf("RequestsPerMinute %d exceeds the quota of %d requests per minute", a.config.RequestsPerMinute, quota)
+	case recent*5 > quota*4:
+		// less than a fifth of the quota is left
+	default:
+		c.Passed = true
+	}
+	return c
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+// setupBatch collects the writes of a spreadsheet setup, so that they are sent
+// in one spreadsheet BatchUpdate followed by one values BatchUpdate instead of
+// a round trip per step.
+type setupBatch struct {
+	requests []*sheets.Request
+	values   []*sheets.ValueRange
+}
+
+func (b *setupBatch) addRequests(requests ...*sheets.Request) {
+	b.requests = append(b.requests, requests...)
+}
+
+func (b *setupBatch) addValues(values ...*sheets.ValueRange) {
+	b.values = append(b.values, values...)
+}
+
+// flush sends the collected writes. The requests go first, as the values may
+// be written to the sheets they add.
+func (b *setupBatch) flush(a *app, spreadsheetID string) error {
+	if len(b.requests) != 0 {
+		_, err := sheets.NewSpreadsheetsService(a.service).BatchUpdate(spreadsheetID, &sheets.BatchUpdateSpreadsheetRequest{
+			Requests: b.requests,
+		}).Context(a.commandContext()).Do()
+		a.metadata.invalidate(spreadsheetID)
+		if err != nil {
+			return fmt.Errorf("failed to format the spreadsheet: %w", err)
+		}
+	}
+	if len(b.values) != 0 {
+		_, err := sheets.NewSpreadsheetsValuesService(a.service).BatchUpdate(spreadsheetID, &sheets.BatchUpdateValuesRequest{
+			ValueInputOption: "USER_ENTERED",
+			Data:             b.values,
+		}).Context(a.commandContext()).Do()
+		if err != nil {
+			return fmt.Errorf("failed to fill the spreadsheet: %w", err)
+		}
+	}
+	b.requests, b.values = nil, nil
+	return nil
+}
+
+// forEachTeam runs fn for the teams concurrently, at most FetchConcurrency at
+// a time. It returns the error of the first failed team in the teams order.
+func (a *app) forEachTeam(teams []string, fn func(team string) error) error {
+	errs := make([]error, len(teams))
+	slots := make(chan struct{}, a.config.fetchConcurrency())
+	var wg sync.WaitGroup
+	for i, team := range teams {
+		wg.Add(1)
+		go func(i int, team string) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			errs[i] = fn(team)
+		}(i, team)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// apiCalls returns the number of the API requests made so far.
+func (a *app) apiCalls() uint64 {
+	if a.metrics == nil {
+		return 0
+	}
+	return atomic.LoadUint64(&a.metrics.apiCalls)
+}
+
+// logSetupCost reports how long the setup took and how many API requests it
+// made.
+func (a *app) logSetupCost(start time.Time, calls uint64) {
+	if a.metrics == nil {
+		return
+	}
+	log.Printf("the game setup took %s and %d API requests", time.Since(start).Round(time.Millisecond), a.apiCalls()-calls)
+}
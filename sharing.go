@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/sheets/v4"
+)
+
+// captainsProtectionDescription is the description of the protected ranges of
+// the answers editable only by the captains.
+const captainsProtectionDescription = "captains answers"
+
+// SharingConfig shares the team spreadsheets with the team accounts. The
+// answer cells are protected so that only the captains can edit them, the
+// members can view and comment the spreadsheet.
+type SharingConfig struct {
+	// Captains maps the teams to the accounts that can edit the answers.
+	Captains map[string][]string
+	// Members maps the teams to the accounts that can view and comment the
+	// team spreadsheet.
+	Members map[string][]string
+	// Notify sends the Drive sharing notification emails.
+	Notify bool
+}
+
+func (c *SharingConfig) enabled() bool {
+	return len(c.Captains) != 0 || len(c.Members) != 0
+}
+
+// check returns the problems of the sharing configuration.
+func (c *SharingConfig) check(teams []string) []string {
+	known := make(map[string]bool, len(teams))
+	for _, team := range teams {
+		known[team] = true
+	}
+	var problems []string
+	for _, accounts := range []struct {
+		name     string
+		accounts map[string][]string
+	}{{"Captains", c.Captains}, {"Members", c.Members}} {
+		for team, emails := range accounts.accounts {
+			if !known[team] {
+				problems = append(problems, fmt.Sprintf("Sharing: %s are given for the team %s that is not in the game", accounts.name, team))
+			}
+			for _, email := range emails {
+				if !strings.Contains(email, "@") {
+					problems = append(problems, fmt.Sprintf("Sharing: %s of the team %s: %s is not an email address", accounts.name, team, email))
+				}
+			}
+		}
+	}
+	return problems
+}
+
+type shareResult struct {
+	Teams []string `json:"teams" yaml:"teams"`
+}
+
+func (r *shareResult) String() string {
+	return fmt.Sprintf("Spreadsheets are shared and the answers are protected for the teams: %s", strings.Join(r.Teams, ", "))
+}
+
+// CmdShare shares the team spreadsheets again and updates the protection of
+// their answers, e.g. after the captains are changed: "share [team]".
+func (a *app) CmdShare(cmdStr string) (*shareResult, error) {
+	if !a.config.Sharing.enabled() {
+		return nil, fmt.Errorf("no team account is configured in Sharing")
+	}
+	teams := a.config.Teams
+	if team := strings.TrimSpace(strings.Join(splitArgs(cmdStr)[1:], " ")); len(team) != 0 {
+		found := false
+		for _, t := range a.config.Teams {
+			if t == team {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("team %s is unknown", team)
+		}
+		teams = []string{team}
+	}
+	gameSheets, err := a.GetGameSpreadsheets()
+	if err != nil {
+		return nil, err
+	}
+	res := &shareResult{Teams: make([]string, 0, len(teams))}
+	for _, team := range teams {
+		teamSheet, ok := gameSheets.teams[team]
+		if !ok {
+			return nil, fmt.Errorf("spreadsheet of the team %s is not found", team)
+		}
+		if err := a.shareTeamSpreadsheet(team, teamSheet.ID, teamSheet.SheetID); err != nil {
+			return nil, err
+		}
+		res.Teams = append(res.Teams, team)
+	}
+	if err := a.store.appendEvent(fmt.Sprintf("share: %s", strings.Join(res.Teams, ", "))); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// shareTeamSpreadsheet gives the captains the edit access and the members the
+// comment access to the team spreadsheet, then protects the answers grid and
+// the blitz sheet so that only the captains can edit them.
+func (a *app) shareTeamSpreadsheet(team string, spreadsheetID string, sheetID int64) error {
+	if !a.config.Sharing.enabled() {
+		return nil
+	}
+	captains := a.config.Sharing.Captains[team]
+	for _, share := range []struct {
+		role   string
+		emails []string
+	}{{"writer", captains}, {"commenter", a.config.Sharing.Members[team]}} {
+		for _, email := range share.emails {
+			_, err := a.drive.Permissions.Create(spreadsheetID, &drive.Permission{
+				Type:         "user",
+				Role:         share.role,
+				EmailAddress: email,
+			}).SendNotificationEmail(a.config.Sharing.Notify).Context(a.commandContext()).Do()
+			if err != nil {
+				return fmt.Errorf("failed to share the team %s spreadsheet with %s: %w", team, email, err)
+			}
+		}
+	}
+	ranges, err := a.getTeamAnswerGridRanges()
+	if err != nil {
+		return err
+	}
+	for _, r := range ranges {
+		r.SheetId = sheetID
+	}
+	metadata, err := a.getSpreadsheetMetadata(a.commandContext(), spreadsheetID)
+	if err != nil {
+		return err
+	}
+	if blitz, ok := metadata.sheetByTitle(blitzSheetTitle); ok {
+		ranges = append(ranges, &sheets.GridRange{SheetId: blitz.ID})
+	}
+	requests := make([]*sheets.Request, 0, len(ranges))
+	// the protection is replaced, as the captains may have changed
+	for id, description := range metadata.protectedRanges {
+		if description == captainsProtectionDescription {
+			requests = append(requests, &sheets.Request{
+				DeleteProtectedRange: &sheets.DeleteProtectedRangeRequest{ProtectedRangeId: id},
+			})
+		}
+	}
+	for _, r := range ranges {
+		requests = append(requests, &sheets.Request{
+			AddProtectedRange: &sheets.AddProtectedRangeRequest{
+				ProtectedRange: &sheets.ProtectedRange{
+					Description: captainsProtectionDescription,
+					Range:       r,
+					Editors:     &sheets.Editors{Users: captains},
+				},
+			},
+		})
+	}
+	_, err = sheets.NewSpreadsheetsService(a.service).BatchUpdate(spreadsheetID, &sheets.BatchUpdateSpreadsheetRequest{
+		Requests: requests,
+	}).Context(a.commandContext()).Do()
+	a.metadata.invalidate(spreadsheetID)
+	if err != nil {
+		return fmt.Errorf("failed to protect the team %s answers: %w", team, err)
+	}
+	log.Printf("shared the team %s spreadsheet with %d captain(s) and %d member(s)", team, len(captains), len(a.config.Sharing.Members[team]))
+	return nil
+}
@@ -0,0 +1,437 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+// shootoutSheetTitle is the sheet added to the spreadsheets of the tied teams
+// and to the manager spreadsheet for the shootout questions.
+const shootoutSheetTitle = "Shootout"
+
+// shootoutState is the tie-break played after the main game. Its questions
+// and responses are kept apart from the rounds, so that the main totals are
+// not affected.
+type shootoutState struct {
+	Teams     []string
+	Questions int
+	// Responses maps the shootout questions to the responses of the teams.
+	Responses map[int]map[string]*roundResponse
+}
+
+// scores counts a point for a correct answer and half a point for a partial
+// one, as the main totals do.
+func (s *shootoutState) scores() map[string]float64 {
+	scores := make(map[string]float64, len(s.Teams))
+	for _, team := range s.Teams {
+		scores[team] = 0
+	}
+	for _, responses := range s.Responses {
+		for team, resp := range responses {
+			switch resp.Status {
+			case ResponseStatusOK:
+				scores[team]++
+			case ResponseStatusPartial:
+				scores[team] += 0.5
+			}
+		}
+	}
+	return scores
+}
+
+type shootoutResult struct {
+	Teams     []string `json:"teams" yaml:"teams"`
+	Questions int      `json:"questions" yaml:"questions"`
+	// Question and Responses are set by fetch and check.
+	Question  int                       `json:"question,omitempty" yaml:"question,omitempty"`
+	Responses map[string]*roundResponse `json:"responses,omitempty" yaml:"responses,omitempty"`
+	Standings []*standing               `json:"standings" yaml:"standings"`
+	// Winner is empty while the leaders are still tied.
+	Winner string `json:"winner,omitempty" yaml:"winner,omitempty"`
+}
+
+func (r *shootoutResult) outputView() interface{} {
+	view := &struct {
+		Teams     []string                     `json:"teams" yaml:"teams"`
+		Questions int                          `json:"questions" yaml:"questions"`
+		Question  int                          `json:"question,omitempty" yaml:"question,omitempty"`
+		Responses map[string]roundResponseView `json:"responses,omitempty" yaml:"responses,omitempty"`
+		Standings []*standing                  `json:"standings" yaml:"standings"`
+		Winner    string                       `json:"winner,omitempty" yaml:"winner,omitempty"`
+	}{
+		Teams:     r.Teams,
+		Questions: r.Questions,
+		Question:  r.Question,
+		Standings: r.Standings,
+		Winner:    r.Winner,
+	}
+	if r.Responses != nil {
+		view.Responses = make(map[string]roundResponseView, len(r.Responses))
+		for team, resp := range r.Responses {
+			view.Responses[team] = roundResponseView{Response: resp.Response, Status: resp.Status.String()}
+		}
+	}
+	return view
+}
+
+func (r *shootoutResult) String() string {
+	var sb strings.Builder
+	if r.Responses != nil {
+		t := &table{header: []string{tr("header.team"), tr("header.response"), tr("header.status")}, indent: "\t"}
+		for _, team := range r.Teams {
+			resp, ok := r.Responses[team]
+			if !ok {
+				continue
+			}
+			response, _ := truncateAnswer(resp.Response, answerDisplayWidth)
+			t.addRow(plainCell(team), plainCell(response), plainCell(resp.Status.String()))
+		}
+		sb.WriteString(fmt.Sprintf("Shootout question %d:\n", r.Question))
+		sb.WriteString(t.String())
+	}
+	sb.WriteString(fmt.Sprintf("Shootout of %s, %d question(s):\n", strings.Join(r.Teams, ", "), r.Questions))
+	t := &table{header: []string{tr("header.place"), tr("header.team"), tr("header.score")}, indent: "\t"}
+	for _, s := range r.Standings {
+		for _, team := range s.Teams {
+			t.addRow(plainCell(s.places()), plainCell(team), plainCell(formatPoints(s.Score)))
+		}
+	}
+	sb.WriteString(t.String())
+	if len(r.Winner) != 0 {
+		sb.WriteString(fmt.Sprintf("The shootout is won by the team %s\n", r.Winner))
+	} else if len(r.Standings) != 0 {
+		sb.WriteString(fmt.Sprintf("The teams %s are still tied, add a question\n", strings.Join(r.Standings[0].Teams, ", ")))
+	}
+	return sb.String()
+}
+
+// CmdShootout runs a tie-break after the main game: "shootout start <team>
+// <team> [...]" adds the shootout sheet to the spreadsheets of the teams and
+// to the manager spreadsheet with the first question, "shootout add" appends
+// a question, "shootout fetch <question>" and "shootout check <question>"
+// fetch and judge the answers, "shootout result" reports the winner.
+func (a *app) CmdShootout(cmdStr string) (*shootoutResult, error) {
+	sSplitted := splitArgs(cmdStr)
+	if len(sSplitted) < 2 {
+		return nil, fmt.Errorf("failed to parse shootout request: expected a subcommand")
+	}
+	if sSplitted[1] == "start" {
+		return a.shootoutStart(sSplitted[2:])
+	}
+	shootout, err := a.store.getShootout()
+	if err != nil {
+		return nil, err
+	}
+	if shootout == nil {
+		return nil, fmt.Errorf("no shootout is started, start it with shootout start <team> <team>")
+	}
+	switch sSplitted[1] {
+	case "add":
+		if len(sSplitted) != 2 {
+			return nil, fmt.Errorf("failed to parse shootout request: add expects no arguments")
+		}
+		return a.shootoutAdd(shootout)
+	case "fetch", "check":
+		if len(sSplitted) != 3 {
+			return nil, fmt.Errorf("failed to parse shootout request: %s expects the question number", sSplitted[1])
+		}
+		question, err := strconv.Atoi(sSplitted[2])
+		if err != nil || question < 1 || question > shootout.Questions {
+			return nil, fmt.Errorf("failed to parse shootout request: expected a question number in [1; %d], got %s", shootout.Questions, sSplitted[2])
+		}
+		if sSplitted[1] == "fetch" {
+			return a.shootoutFetch(shootout, question)
+		}
+		return a.shootoutCheck(shootout, question)
+	case "result":
+		if len(sSplitted) != 2 {
+			return nil, fmt.Errorf("failed to parse shootout request: result expects no arguments")
+		}
+		res := newShootoutResult(shootout)
+		event := fmt.Sprintf("shootout result: %s", strings.Join(shootout.Teams, ", "))
+		if len(res.Winner) != 0 {
+			event = fmt.Sprintf("%s, winner: %s", event, res.Winner)
+		}
+		if err := a.store.appendEvent(event); err != nil {
+			return nil, err
+		}
+		return res, nil
+	default:
+		return nil, fmt.Errorf("unknown shootout subcommand %s, expected start, add, fetch, check or result", sSplitted[1])
+	}
+}
+
+func newShootoutResult(shootout *shootoutState) *shootoutResult {
+	res := &shootoutResult{
+		Teams:     shootout.Teams,
+		Questions: shootout.Questions,
+		Standings: computeStandings(shootout.scores()),
+	}
+	if len(res.Standings) != 0 && len(res.Standings[0].Teams) == 1 {
+		res.Winner = res.Standings[0].Teams[0]
+	}
+	return res
+}
+
+func (a *app) shootoutStart(teams []string) (*shootoutResult, error) {
+	if len(teams) < 2 {
+		return nil, fmt.Errorf("failed to parse shootout request: expected at least 2 teams, got %d", len(teams))
+	}
+	known := make(map[string]bool, len(a.config.Teams))
+	for _, team := range a.config.Teams {
+		known[team] = true
+	}
+	seen := make(map[string]bool, len(teams))
+	for _, team := range teams {
+		if !known[team] {
+			return nil, fmt.Errorf("team %s is unknown", team)
+		}
+		if seen[team] {
+			return nil, fmt.Errorf("team %s is listed several times", team)
+		}
+		seen[team] = true
+	}
+	gameSheets, err := a.GetGameSpreadsheets()
+	if err != nil {
+		return nil, err
+	}
+	if gameSheets.manager == nil {
+		return nil, fmt.Errorf("manager spreadsheet is not found")
+	}
+	previous, err := a.store.getShootout()
+	if err != nil {
+		return nil, err
+	}
+	// the sheets of a previous shootout are cleared, so that its answers are
+	// not taken for the answers of this one
+	staleTeams := make([]string, 0)
+	if previous != nil {
+		for _, team := range previous.Teams {
+			if !seen[team] {
+				staleTeams = append(staleTeams, team)
+			}
+		}
+	}
+	valuesService := sheets.NewSpreadsheetsValuesService(a.service)
+	for _, team := range staleTeams {
+		teamSheet, ok := gameSheets.teams[team]
+		if !ok {
+			continue
+		}
+		_, err := valuesService.Clear(teamSheet.ID, shootoutSheetTitle, &sheets.ClearValuesRequest{}).Context(a.commandContext()).Do()
+		if err != nil {
+			return nil, fmt.Errorf("failed to clear the shootout sheet of the team %s: %w", team, err)
+		}
+	}
+	for _, team := range teams {
+		teamSheet, ok := gameSheets.teams[team]
+		if !ok {
+			return nil, fmt.Errorf("spreadsheet of the team %s is not found", team)
+		}
+		if err := a.ensureShootoutSheet(teamSheet.ID); err != nil {
+			return nil, fmt.Errorf("failed to prepare the shootout sheet of the team %s: %w", team, err)
+		}
+		_, err := valuesService.Update(teamSheet.ID, sheetRange(shootoutSheetTitle, "A1:A2"), &sheets.ValueRange{
+			Values: [][]interface{}{{tr("header.question")}, {tr("header.response")}},
+		}).ValueInputOption("RAW").Context(a.commandContext()).Do()
+		if err != nil {
+			return nil, fmt.Errorf("failed to write the shootout headers of the team %s: %w", team, err)
+		}
+	}
+	if err := a.ensureShootoutSheet(gameSheets.manager.ID); err != nil {
+		return nil, fmt.Errorf("failed to prepare the shootout sheet of the manager spreadsheet: %w", err)
+	}
+	values := [][]interface{}{{tr("header.team")}}
+	for _, team := range teams {
+		values = append(values, []interface{}{team})
+	}
+	_, err = valuesService.Update(gameSheets.manager.ID, sheetRange(shootoutSheetTitle, rangeName(0, 1, 0, len(values))), &sheets.ValueRange{
+		Values: values,
+	}).ValueInputOption("RAW").Context(a.commandContext()).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to write the shootout teams into the manager spreadsheet: %w", err)
+	}
+	shootout := &shootoutState{
+		Teams:     teams,
+		Responses: make(map[int]map[string]*roundResponse),
+	}
+	if err := a.store.appendEvent(fmt.Sprintf("shootout start: %s", strings.Join(teams, ", "))); err != nil {
+		return nil, err
+	}
+	return a.shootoutAdd(shootout)
+}
+
+// ensureShootoutSheet adds the shootout sheet to the spreadsheet, or clears it
+// if it is already there.
+func (a *app) ensureShootoutSheet(spreadsheetID string) error {
+	metadata, err := a.getSpreadsheetMetadata(a.commandContext(), spreadsheetID)
+	if err != nil {
+		return err
+	}
+	if _, ok := metadata.sheetByTitle(shootoutSheetTitle); ok {
+		_, err := sheets.NewSpreadsheetsValuesService(a.service).Clear(spreadsheetID, shootoutSheetTitle, &sheets.ClearValuesRequest{}).Context(a.commandContext()).Do()
+		return err
+	}
+	_, err = sheets.NewSpreadsheetsService(a.service).BatchUpdate(spreadsheetID, &sheets.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheets.Request{
+			{
+				AddSheet: &sheets.AddSheetRequest{
+					Properties: &sheets.SheetProperties{Title: shootoutSheetTitle},
+				},
+			},
+		},
+	}).Context(a.commandContext()).Do()
+	if err != nil {
+		return err
+	}
+	a.metadata.invalidate(spreadsheetID)
+	return nil
+}
+
+// shootoutAdd appends the next question column to the shootout sheets: the
+// question number in the team spreadsheets, and the links to the answers of
+// the teams in the manager spreadsheet.
+func (a *app) shootoutAdd(shootout *shootoutState) (*shootoutResult, error) {
+	gameSheets, err := a.GetGameSpreadsheets()
+	if err != nil {
+		return nil, err
+	}
+	if gameSheets.manager == nil {
+		return nil, fmt.Errorf("manager spreadsheet is not found")
+	}
+	question := shootout.Questions + 1
+	column := question
+	valuesService := sheets.NewSpreadsheetsValuesService(a.service)
+	managerValues := [][]interface{}{{question}}
+	for _, team := range shootout.Teams {
+		teamSheet, ok := gameSheets.teams[team]
+		if !ok {
+			return nil, fmt.Errorf("spreadsheet of the team %s is not found", team)
+		}
+		_, err := valuesService.Update(teamSheet.ID, sheetRange(shootoutSheetTitle, cellName(column, 1)), &sheets.ValueRange{
+			Values: [][]interface{}{{question}},
+		}).ValueInputOption("RAW").Context(a.commandContext()).Do()
+		if err != nil {
+			return nil, fmt.Errorf("failed to add the shootout question to the team %s spreadsheet: %w", team, err)
+		}
+		teamRange := sheetRange(shootoutSheetTitle, cellName(column, 2))
+		managerValues = append(managerValues, []interface{}{
+			fmt.Sprintf("=IMPORTRANGE(\"%s\", \"%s\")", teamSheet.URL, strings.ReplaceAll(teamRange, "\"", "\"\"")),
+		})
+	}
+	_, err = valuesService.Update(gameSheets.manager.ID, sheetRange(shootoutSheetTitle, rangeName(column, 1, column, len(managerValues))), &sheets.ValueRange{
+		Values: managerValues,
+	}).ValueInputOption("USER_ENTERED").Context(a.commandContext()).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to add the shootout question to the manager spreadsheet: %w", err)
+	}
+	shootout.Questions = question
+	if err := a.store.saveShootout(shootout); err != nil {
+		return nil, err
+	}
+	return newShootoutResult(shootout), nil
+}
+
+// shootoutFetch reads the answers to the shootout question from the team
+// spreadsheets directly. The statuses of the unchanged answers are kept.
+func (a *app) shootoutFetch(shootout *shootoutState, question int) (*shootoutResult, error) {
+	gameSheets, err := a.GetGameSpreadsheets()
+	if err != nil {
+		return nil, err
+	}
+	valuesService := sheets.NewSpreadsheetsValuesService(a.service)
+	previous := shootout.Responses[question]
+	responses := make(map[string]*roundResponse, len(shootout.Teams))
+	for _, team := range shootout.Teams {
+		teamSheet, ok := gameSheets.teams[team]
+		if !ok {
+			return nil, fmt.Errorf("spreadsheet of the team %s is not found", team)
+		}
+		resp, err := valuesService.Get(teamSheet.ID, sheetRange(shootoutSheetTitle, cellName(question, 2))).Context(a.commandContext()).Do()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the team %s spreadsheet: %w", team, err)
+		}
+		response := ""
+		if len(resp.Values) != 0 && len(resp.Values[0]) != 0 {
+			response = strings.TrimSpace(fmt.Sprint(resp.Values[0][0]))
+		}
+		if prev, ok := previous[team]; ok && prev.Response == response {
+			responses[team] = prev
+			continue
+		}
+		status := ResponseStatusNotChecked
+		if len(response) == 0 {
+			status = ResponseStatusNoAnswer
+		}
+		responses[team] = &roundResponse{Response: response, Status: status}
+	}
+	shootout.Responses[question] = responses
+	if err := a.store.saveShootout(shootout); err != nil {
+		return nil, err
+	}
+	res := newShootoutResult(shootout)
+	res.Question, res.Responses = question, responses
+	return res, nil
+}
+
+// shootoutCheck asks for the verdicts on the fetched answers to the shootout
+// question, team by team.
+func (a *app) shootoutCheck(shootout *shootoutState, question int) (*shootoutResult, error) {
+	responses, ok := shootout.Responses[question]
+	if !ok {
+		return nil, fmt.Errorf("shootout question %d is not fetched, fetch it with shootout fetch %d", question, question)
+	}
+	verdicts, err := a.config.CheckKeys.verdicts()
+	if err != nil {
+		return nil, err
+	}
+	reader, err := newVerdictReader(a.config.CheckSingleKeystroke)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.close()
+	fmt.Printf("Checking the shootout question %d (%s back, %s skip)\n", question, a.config.CheckKeys.Back, a.config.CheckKeys.Skip)
+	teams := make([]string, 0, len(shootout.Teams))
+	for _, team := range shootout.Teams {
+		if _, ok := responses[team]; ok {
+			teams = append(teams, team)
+		}
+	}
+	for i := 0; i < len(teams); {
+		resp := responses[teams[i]]
+		response, _ := truncateAnswer(resp.Response, displayWidth(resp.Response))
+		fmt.Printf("[%d/%d] Team %s, response: %s, previous status: %v\n", i+1, len(teams), teams[i], response, resp.Status)
+		key, err := reader.readKey()
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan the command: %w", err)
+		}
+		switch key {
+		case a.config.CheckKeys.Back:
+			if i == 0 {
+				fmt.Println(tr("check.firstResponse"))
+				continue
+			}
+			i--
+			continue
+		case a.config.CheckKeys.Skip:
+			i++
+			continue
+		}
+		status, ok := verdicts[key]
+		if !ok {
+			fmt.Println(tr("check.unknownStatus"))
+			continue
+		}
+		resp.Status = status
+		if err := a.store.saveShootout(shootout); err != nil {
+			return nil, err
+		}
+		i++
+	}
+	res := newShootoutResult(shootout)
+	res.Question, res.Responses = question, responses
+	return res, nil
+}
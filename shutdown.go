@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// shutdownTimeout bounds the time the servers are given to finish the
+// requests in progress.
+const shutdownTimeout = 5 * time.Second
+
+// cancellableCommands is the number of the running commands cancelled by
+// Ctrl-C, the shutdown leaves Ctrl-C to them.
+var cancellableCommands int32
+
+// session is the state of the session saved and reported on the shutdown.
+type session struct {
+	mu          sync.Mutex
+	lastCommand string
+	check       *checkSession
+	servers     []*http.Server
+}
+
+// checkSession is the running interactive check.
+type checkSession struct {
+	round    int
+	progress *checkProgress
+	reader   *verdictReader
+	judged   int
+	total    int
+}
+
+// handleShutdown shuts the games down cleanly on SIGINT and SIGTERM: the
+// check progress is saved, the servers and the stores are closed and the
+// place the session has stopped at is printed.
+func handleShutdown(apps ...*app) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		for sig := range signals {
+			if sig == os.Interrupt && atomic.LoadInt32(&cancellableCommands) > 0 {
+				continue
+			}
+			fmt.Print(tr("shutdown.signal", sig))
+			for _, a := range apps {
+				a.shutdown()
+			}
+			code := 1
+			if s, ok := sig.(syscall.Signal); ok {
+				code = 128 + int(s)
+			}
+			os.Exit(code)
+		}
+	}()
+}
+
+// shutdown saves the check progress, stops the servers and closes the store.
+// The session lock is kept so that the check cannot save a verdict into the
+// closed store.
+func (a *app) shutdown() {
+	a.session.mu.Lock()
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	for _, srv := range a.session.servers {
+		if err := srv.Shutdown(ctx); err != nil {
+			log.Printf("[ERR]: failed to stop the server on %s: %v", srv.Addr, err)
+		}
+	}
+	if c := a.session.check; c != nil {
+		if err := a.store.saveCheckProgress(c.round, c.progress); err != nil {
+			log.Printf("[ERR]: failed to save the check progress: %v", err)
+		}
+		c.reader.close()
+		question := a.config.questionNumber(c.round)
+		fmt.Print(tr("shutdown.check", a.config.GameName, question, c.judged, c.total, question))
+	} else if len(a.session.lastCommand) != 0 {
+		fmt.Print(tr("shutdown.lastCommand", a.config.GameName, a.session.lastCommand))
+	}
+	a.close()
+}
+
+func (a *app) recordCommand(cmdStr string) {
+	a.session.mu.Lock()
+	defer a.session.mu.Unlock()
+	a.session.lastCommand = cmdStr
+}
+
+func (a *app) startCheck(c *checkSession) {
+	a.session.mu.Lock()
+	defer a.session.mu.Unlock()
+	a.session.check = c
+}
+
+func (a *app) finishCheck() {
+	a.session.mu.Lock()
+	defer a.session.mu.Unlock()
+	a.session.check = nil
+}
+
+// updateCheck applies the verdicts to the check progress and saves it, apply
+// returns the number of the judged teams.
+func (a *app) updateCheck(apply func() int) error {
+	a.session.mu.Lock()
+	defer a.session.mu.Unlock()
+	c := a.session.check
+	c.judged = apply()
+	return a.store.saveCheckProgress(c.round, c.progress)
+}
+
+// listenAndServe serves the requests until the shutdown.
+func (a *app) listenAndServe(srv *http.Server) error {
+	a.session.mu.Lock()
+	a.session.servers = append(a.session.servers, srv)
+	a.session.mu.Unlock()
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
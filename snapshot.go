@@ -0,0 +1,233 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"log"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	snapshotFontSize   = 20
+	snapshotPadding    = 24
+	snapshotLineHeight = 32
+	snapshotColumnGap  = 32
+)
+
+var snapshotHTMLTemplate = template.Must(template.New("snapshot").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; background: #fff; }
+td { padding: 4px 16px; }
+.ok { color: #1e8c32; }
+.ko { color: #c82828; }
+.in-question { color: #d28c00; }
+.partial { color: #2864c8; }
+.not-checked { color: #808080; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<table>
+{{range .Rows}}<tr class="{{.Class}}"><td>{{.Team}}</td><td>{{.Response}}</td><td>{{.Status}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+type snapshotRow struct {
+	Team     string
+	Response string
+	Status   ResponseStatus
+	Class    string
+}
+
+type snapshotData struct {
+	Title string
+	Rows  []snapshotRow
+}
+
+type snapshotResult struct {
+	HTMLFile string `json:"htmlFile" yaml:"htmlFile"`
+	PNGFile  string `json:"pngFile" yaml:"pngFile"`
+}
+
+func (r *snapshotResult) String() string {
+	return fmt.Sprintf("Snapshot is saved to %s and %s", r.PNGFile, r.HTMLFile)
+}
+
+func (a *app) CmdSnapshot(cmdStr string) (*snapshotResult, error) {
+	round, err := a.getRoundNumber(cmdStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot request: %w", err)
+	}
+	results, err := a.store.getRoundResults(round)
+	if err != nil {
+		return nil, err
+	}
+	data := newSnapshotData(a.config.GameName, a.numberResults(results))
+	snapshotsDir := path.Join(a.config.OutputDir, "snapshots")
+	if err := os.MkdirAll(snapshotsDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create the snapshots directory %s: %w", snapshotsDir, err)
+	}
+	res := &snapshotResult{
+		HTMLFile: path.Join(snapshotsDir, fmt.Sprintf("round-%d.html", a.config.questionNumber(round))),
+		PNGFile:  path.Join(snapshotsDir, fmt.Sprintf("round-%d.png", a.config.questionNumber(round))),
+	}
+	if err := writeSnapshotHTML(res.HTMLFile, data); err != nil {
+		return nil, err
+	}
+	if err := writeSnapshotPNG(res.PNGFile, data); err != nil {
+		return nil, err
+	}
+	log.Printf("saved the round %d snapshot to %s", a.config.questionNumber(round), res.PNGFile)
+	return res, nil
+}
+
+func newSnapshotData(gameName string, results *roundResults) *snapshotData {
+	data := &snapshotData{
+		Title: fmt.Sprintf("%s: вопрос %d", gameName, results.question()),
+		Rows:  make([]snapshotRow, 0, len(results.Results)),
+	}
+	for team, resp := range results.Results {
+		answer, _ := truncateAnswer(resp.Response, answerDisplayWidth)
+		// the bidi isolates are meant for terminals, HTML and PNG render the
+		// direction on their own
+		answer = strings.Trim(answer, string([]rune{bidiFirstStrongIsolate, bidiPopDirIsolate}))
+		data.Rows = append(data.Rows, snapshotRow{
+			Team:     team,
+			Response: answer,
+			Status:   resp.Status,
+			Class:    statusClass(resp.Status),
+		})
+	}
+	sort.Slice(data.Rows, func(i, j int) bool {
+		return data.Rows[i].Team < data.Rows[j].Team
+	})
+	return data
+}
+
+func statusClass(s ResponseStatus) string {
+	switch s {
+	case ResponseStatusOK:
+		return "ok"
+	case ResponseStatusKO:
+		return "ko"
+	case ResponseStatusInQuestion:
+		return "in-question"
+	case ResponseStatusPartial:
+		return "partial"
+	default:
+		return "not-checked"
+	}
+}
+
+func statusColor(s ResponseStatus) color.Color {
+	switch s {
+	case ResponseStatusOK:
+		return color.RGBA{R: 0x1e, G: 0x8c, B: 0x32, A: 0xff}
+	case ResponseStatusKO:
+		return color.RGBA{R: 0xc8, G: 0x28, B: 0x28, A: 0xff}
+	case ResponseStatusInQuestion:
+		return color.RGBA{R: 0xd2, G: 0x8c, B: 0x00, A: 0xff}
+	case ResponseStatusPartial:
+		return color.RGBA{R: 0x28, G: 0x64, B: 0xc8, A: 0xff}
+	default:
+		return color.RGBA{R: 0x80, G: 0x80, B: 0x80, A: 0xff}
+	}
+}
+
+func writeSnapshotHTML(file string, data *snapshotData) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return fmt.Errorf("failed to create the snapshot file %s: %w", file, err)
+	}
+	defer f.Close()
+	if err := snapshotHTMLTemplate.Execute(f, data); err != nil {
+		return fmt.Errorf("failed to render the snapshot HTML: %w", err)
+	}
+	return nil
+}
+
+// writeSnapshotPNG renders the snapshot table with the Go font, which covers
+// the Latin and Cyrillic scripts.
+func writeSnapshotPNG(file string, data *snapshotData) error {
+	ttf, err := opentype.Parse(goregular.TTF)
+	if err != nil {
+		return fmt.Errorf("failed to parse the snapshot font: %w", err)
+	}
+	face, err := opentype.NewFace(ttf, &opentype.FaceOptions{
+		Size:    snapshotFontSize,
+		DPI:     72,
+		Hinting: font.HintingFull,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create the snapshot font face: %w", err)
+	}
+	defer face.Close()
+	drawer := &font.Drawer{Face: face}
+	teamWidth, responseWidth := 0, 0
+	for _, row := range data.Rows {
+		if w := drawer.MeasureString(row.Team).Ceil(); w > teamWidth {
+			teamWidth = w
+		}
+		if w := drawer.MeasureString(row.Response).Ceil(); w > responseWidth {
+			responseWidth = w
+		}
+	}
+	statusWidth := 0
+	for _, row := range data.Rows {
+		if w := drawer.MeasureString(row.Status.String()).Ceil(); w > statusWidth {
+			statusWidth = w
+		}
+	}
+	width := 2*snapshotPadding + teamWidth + responseWidth + statusWidth + 2*snapshotColumnGap
+	if w := 2*snapshotPadding + drawer.MeasureString(data.Title).Ceil(); w > width {
+		width = w
+	}
+	height := 2*snapshotPadding + (len(data.Rows)+2)*snapshotLineHeight
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+	drawer.Dst = img
+	drawText := func(s string, x int, y int, c color.Color) {
+		drawer.Src = image.NewUniform(c)
+		drawer.Dot = fixed.P(x, y)
+		drawer.DrawString(s)
+	}
+	y := snapshotPadding + snapshotLineHeight
+	drawText(data.Title, snapshotPadding, y, color.Black)
+	y += snapshotLineHeight
+	for _, row := range data.Rows {
+		y += snapshotLineHeight
+		x := snapshotPadding
+		drawText(row.Team, x, y, color.Black)
+		x += teamWidth + snapshotColumnGap
+		drawText(row.Response, x, y, color.Black)
+		x += responseWidth + snapshotColumnGap
+		drawText(row.Status.String(), x, y, statusColor(row.Status))
+	}
+	f, err := os.Create(file)
+	if err != nil {
+		return fmt.Errorf("failed to create the snapshot file %s: %w", file, err)
+	}
+	defer f.Close()
+	if err := png.Encode(f, img); err != nil {
+		return fmt.Errorf("failed to encode the snapshot PNG: %w", err)
+	}
+	return nil
+}
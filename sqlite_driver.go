@@ -0,0 +1,8 @@
+//go:build sqlite
+// +build sqlite
+
+package main
+
+// The SQLite driver is linked in only with the sqlite build tag, as it needs
+// cgo: go build -tags sqlite.
+import _ "github.com/mattn/go-sqlite3"
@@ -0,0 +1,719 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+// sqliteDriver is the database/sql driver of the SQLite store, it is linked
+// in with the sqlite build tag.
+const sqliteDriver = "sqlite3"
+
+// sqlStore keeps the game in SQL tables. The responses table has a row per
+// team answer, so that the results can be analysed after the game.
+type sqlStore struct {
+	db *sql.DB
+	// numbering numbers the rounds in the errors
+	numbering roundNumbering
+}
+
+var sqlStoreSchema = []string{
+	`CREATE TABLE IF NOT EXISTS config (key TEXT PRIMARY KEY, value TEXT NOT NULL)`,
+	`CREATE TABLE IF NOT EXISTS team_spreadsheets (team TEXT PRIMARY KEY, spreadsheet TEXT NOT NULL, archived INTEGER NOT NULL DEFAULT 0)`,
+	`CREATE TABLE IF NOT EXISTS rounds (round INTEGER PRIMARY KEY, quarantined TEXT)`,
+	`CREATE TABLE IF NOT EXISTS responses (round INTEGER NOT NULL, team TEXT NOT NULL, response TEXT NOT NULL, status INTEGER NOT NULL, version INTEGER NOT NULL, data TEXT NOT NULL, PRIMARY KEY (round, team))`,
+	`CREATE TABLE IF NOT EXISTS setup_steps (step TEXT PRIMARY KEY)`,
+	`CREATE TABLE IF NOT EXISTS round_locks (round INTEGER PRIMARY KEY, locks TEXT NOT NULL)`,
+	`CREATE TABLE IF NOT EXISTS closed_rounds (round INTEGER PRIMARY KEY, closed_at TEXT NOT NULL)`,
+	`CREATE TABLE IF NOT EXISTS check_progress (round INTEGER PRIMARY KEY, progress TEXT NOT NULL)`,
+	`CREATE TABLE IF NOT EXISTS votes (round INTEGER NOT NULL, team TEXT NOT NULL, votes TEXT NOT NULL, PRIMARY KEY (round, team))`,
+	`CREATE TABLE IF NOT EXISTS round_meta (round INTEGER PRIMARY KEY, meta TEXT NOT NULL)`,
+	`CREATE TABLE IF NOT EXISTS read_cache (key TEXT PRIMARY KEY, value BLOB NOT NULL)`,
+	`CREATE TABLE IF NOT EXISTS adjustments (id INTEGER PRIMARY KEY AUTOINCREMENT, team TEXT NOT NULL, points REAL NOT NULL, reason TEXT NOT NULL, time TEXT NOT NULL)`,
+	`CREATE TABLE IF NOT EXISTS shootout (id INTEGER PRIMARY KEY CHECK (id = 1), state TEXT NOT NULL)`,
+	`CREATE TABLE IF NOT EXISTS standings_snapshot (id INTEGER PRIMARY KEY CHECK (id = 1), snapshot TEXT NOT NULL)`,
+	`CREATE TABLE IF NOT EXISTS response_history (id INTEGER PRIMARY KEY AUTOINCREMENT, round INTEGER NOT NULL, team TEXT NOT NULL, response TEXT NOT NULL, fetched_at TEXT NOT NULL)`,
+	`CREATE TABLE IF NOT EXISTS events (id INTEGER PRIMARY KEY AUTOINCREMENT, time TEXT NOT NULL, message TEXT NOT NULL)`,
+	`CREATE TABLE IF NOT EXISTS audit (id INTEGER PRIMARY KEY AUTOINCREMENT, time TEXT NOT NULL, command TEXT NOT NULL, args TEXT NOT NULL, origin TEXT NOT NULL, user TEXT NOT NULL, duration_ms INTEGER NOT NULL, error TEXT NOT NULL, changes TEXT NOT NULL)`,
+}
+
+// sqlStoreTables are the tables of the schema, reset empties all of them.
+var sqlStoreTables = []string{
+	"config", "team_spreadsheets", "rounds", "responses", "setup_steps", "round_locks", "closed_rounds", "check_progress",
+	"votes", "round_meta", "read_cache", "adjustments", "shootout", "standings_snapshot", "response_history", "events", "audit",
+}
+
+func (s *sqlStore) reset() error {
+	return s.update(func(tx *sql.Tx) error {
+		for _, table := range sqlStoreTables {
+			if _, err := tx.Exec(`DELETE FROM ` + table); err != nil {
+				return fmt.Errorf("failed to empty the table %s: %w", table, err)
+			}
+		}
+		return nil
+	})
+}
+
+// newSQLStore opens the store and creates its schema. A read-only store uses
+// a single connection that refuses the writes, the schema is left as is.
+func newSQLStore(driver string, dsn string, readOnly bool) (*sqlStore, error) {
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the %s store %s (is the binary built with the sqlite tag?): %w", driver, dsn, err)
+	}
+	if readOnly {
+		db.SetMaxOpenConns(1)
+		if _, err := db.Exec(`PRAGMA query_only = ON`); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to open the store read-only: %w", err)
+		}
+		return &sqlStore{db: db}, nil
+	}
+	for _, stmt := range sqlStoreSchema {
+		if _, err := db.Exec(stmt); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to create the store schema: %w", err)
+		}
+	}
+	return &sqlStore{db: db}, nil
+}
+
+func (s *sqlStore) close() error {
+	return s.db.Close()
+}
+
+func (s *sqlStore) putConfig(tx *sql.Tx, key string, value interface{}) error {
+	valueBytes, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`INSERT OR REPLACE INTO config (key, value) VALUES (?, ?)`, key, string(valueBytes))
+	return err
+}
+
+// getConfig unmarshals the config value into value, it reports whether the
+// key is found.
+func (s *sqlStore) getConfig(key string, value interface{}) (bool, error) {
+	var valueStr string
+	err := s.db.QueryRow(`SELECT value FROM config WHERE key = ?`, key).Scan(&valueStr)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, json.Unmarshal([]byte(valueStr), value)
+}
+
+func (s *sqlStore) update(fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *sqlStore) saveSpreadsheets(req *storeGameSpreadsheets) error {
+	return s.update(func(tx *sql.Tx) error {
+		if req.manager != nil {
+			if err := s.putConfig(tx, bucketGameConfiguration_managerSpreadsheet, req.manager); err != nil {
+				return err
+			}
+		}
+		return putSQLTeamsSpreadsheets(tx, req.teams)
+	})
+}
+
+func (s *sqlStore) saveTeamsSpreadsheets(req *storeGameSpreadsheets) error {
+	return s.update(func(tx *sql.Tx) error {
+		return putSQLTeamsSpreadsheets(tx, req.teams)
+	})
+}
+
+func putSQLTeamsSpreadsheets(tx *sql.Tx, teams map[string]*storeSpreadsheet) error {
+	for team, spreadsheet := range teams {
+		spreadsheetBytes, err := json.Marshal(spreadsheet)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(`INSERT OR REPLACE INTO team_spreadsheets (team, spreadsheet, archived) VALUES (?, ?, 0)`, team, string(spreadsheetBytes))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *sqlStore) archiveTeamSpreadsheet(team string) error {
+	_, err := s.db.Exec(`UPDATE team_spreadsheets SET archived = 1 WHERE team = ?`, team)
+	return err
+}
+
+func (s *sqlStore) getSpreadsheets() (*storeGameSpreadsheets, error) {
+	spreadsheets := &storeGameSpreadsheets{
+		teams: make(map[string]*storeSpreadsheet),
+	}
+	if _, err := s.getConfig(bucketGameConfiguration_managerSpreadsheet, &spreadsheets.manager); err != nil {
+		return nil, err
+	}
+	rows, err := s.db.Query(`SELECT team, spreadsheet FROM team_spreadsheets WHERE archived = 0`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var team, spreadsheetStr string
+		if err := rows.Scan(&team, &spreadsheetStr); err != nil {
+			return nil, err
+		}
+		var spreadsheet storeSpreadsheet
+		if err := json.Unmarshal([]byte(spreadsheetStr), &spreadsheet); err != nil {
+			return nil, err
+		}
+		spreadsheets.teams[team] = &spreadsheet
+	}
+	return spreadsheets, rows.Err()
+}
+
+func (s *sqlStore) saveTeams(teams []string) error {
+	return s.update(func(tx *sql.Tx) error {
+		return s.putConfig(tx, bucketGameConfiguration_teams, teams)
+	})
+}
+
+func (s *sqlStore) getTeams() ([]string, error) {
+	var teams []string
+	if _, err := s.getConfig(bucketGameConfiguration_teams, &teams); err != nil {
+		return nil, err
+	}
+	return teams, nil
+}
+
+func (s *sqlStore) saveDriveFolder(folder string) error {
+	return s.update(func(tx *sql.Tx) error {
+		return s.putConfig(tx, bucketGameConfiguration_driveFolder, folder)
+	})
+}
+
+func (s *sqlStore) getDriveFolder() (string, error) {
+	var folder string
+	if _, err := s.getConfig(bucketGameConfiguration_driveFolder, &folder); err != nil {
+		return "", err
+	}
+	return folder, nil
+}
+
+func (s *sqlStore) saveProjectorSpreadsheet(projector *sheets.Spreadsheet) error {
+	return s.update(func(tx *sql.Tx) error {
+		return s.putConfig(tx, bucketGameConfiguration_projector, newStoreSpreadsheet(projector))
+	})
+}
+
+func (s *sqlStore) getProjectorSpreadsheet() (*storeSpreadsheet, error) {
+	var projector *storeSpreadsheet
+	if _, err := s.getConfig(bucketGameConfiguration_projector, &projector); err != nil {
+		return nil, err
+	}
+	return projector, nil
+}
+
+func (s *sqlStore) saveRoundResults(req *roundResults) error {
+	return s.update(func(tx *sql.Tx) error {
+		return putSQLRoundResults(tx, req)
+	})
+}
+
+// putSQLRoundResults replaces the round results.
+func putSQLRoundResults(tx *sql.Tx, req *roundResults) error {
+	quarantinedBytes, err := json.Marshal(req.Quarantined)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`INSERT OR REPLACE INTO rounds (round, quarantined) VALUES (?, ?)`, req.Round, string(quarantinedBytes)); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM responses WHERE round = ?`, req.Round); err != nil {
+		return err
+	}
+	for team, resp := range req.Results {
+		respBytes, err := json.Marshal(resp)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(`INSERT INTO responses (round, team, response, status, version, data) VALUES (?, ?, ?, ?, ?, ?)`,
+			req.Round, team, resp.Response, int(resp.Status), resp.Version, string(respBytes))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type sqlQuerier interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+func (s *sqlStore) getSQLRoundResults(q sqlQuerier, round int) (*roundResults, error) {
+	var quarantinedStr sql.NullString
+	err := q.QueryRow(`SELECT quarantined FROM rounds WHERE round = ?`, round).Scan(&quarantinedStr)
+	if err == sql.ErrNoRows {
+		return nil, &errorRoundNotFound{round: s.numbering.question(round)}
+	}
+	if err != nil {
+		return nil, err
+	}
+	results := &roundResults{
+		Round:   round,
+		Results: make(map[string]*roundResponse),
+	}
+	if quarantinedStr.Valid {
+		if err := json.Unmarshal([]byte(quarantinedStr.String), &results.Quarantined); err != nil {
+			return nil, err
+		}
+	}
+	rows, err := q.Query(`SELECT team, data FROM responses WHERE round = ?`, round)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var team, data string
+		if err := rows.Scan(&team, &data); err != nil {
+			return nil, err
+		}
+		var resp roundResponse
+		if err := json.Unmarshal([]byte(data), &resp); err != nil {
+			return nil, err
+		}
+		results.Results[team] = &resp
+	}
+	return results, rows.Err()
+}
+
+func (s *sqlStore) getRoundResults(round int) (*roundResults, error) {
+	return s.getSQLRoundResults(s.db, round)
+}
+
+func (s *sqlStore) getAllRoundResults() ([]*roundResults, error) {
+	rows, err := s.db.Query(`SELECT round FROM rounds ORDER BY round`)
+	if err != nil {
+		return nil, err
+	}
+	rounds := make([]int, 0)
+	for rows.Next() {
+		var round int
+		if err := rows.Scan(&round); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		rounds = append(rounds, round)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	allResults := make([]*roundResults, 0, len(rounds))
+	for _, round := range rounds {
+		results, err := s.getRoundResults(round)
+		if err != nil {
+			return nil, err
+		}
+		allResults = append(allResults, results)
+	}
+	return allResults, nil
+}
+
+// saveVerdicts follows the boltManager.saveVerdicts conflict rules.
+func (s *sqlStore) saveVerdicts(checked *roundResults) (*roundResults, error) {
+	var stored *roundResults
+	conflicts := make([]string, 0)
+	err := s.update(func(tx *sql.Tx) error {
+		var err error
+		stored, err = s.getSQLRoundResults(tx, checked.Round)
+		if err != nil {
+			return err
+		}
+		for team, resp := range checked.Results {
+			storedResp, ok := stored.Results[team]
+			if !ok {
+				conflicts = append(conflicts, team)
+				continue
+			}
+			if storedResp.Status == resp.Status && sameSubStatuses(storedResp.SubStatuses, resp.SubStatuses) {
+				continue
+			}
+			if storedResp.Version != resp.Version {
+				conflicts = append(conflicts, team)
+				continue
+			}
+			storedResp.Status = resp.Status
+			storedResp.SubStatuses = resp.SubStatuses
+			storedResp.Version++
+		}
+		return putSQLRoundResults(tx, stored)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(conflicts) != 0 {
+		sort.Strings(conflicts)
+		return stored, &errorVerdictConflict{round: s.numbering.question(checked.Round), teams: conflicts}
+	}
+	return stored, nil
+}
+
+func (s *sqlStore) markSetupStep(step string) error {
+	_, err := s.db.Exec(`INSERT OR REPLACE INTO setup_steps (step) VALUES (?)`, step)
+	return err
+}
+
+func (s *sqlStore) getSetupSteps() (map[string]bool, error) {
+	rows, err := s.db.Query(`SELECT step FROM setup_steps`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	steps := make(map[string]bool)
+	for rows.Next() {
+		var step string
+		if err := rows.Scan(&step); err != nil {
+			return nil, err
+		}
+		steps[step] = true
+	}
+	return steps, rows.Err()
+}
+
+func (s *sqlStore) saveRoundLocks(round int, locks map[string]int64) error {
+	if len(locks) == 0 {
+		_, err := s.db.Exec(`DELETE FROM round_locks WHERE round = ?`, round)
+		return err
+	}
+	locksBytes, err := json.Marshal(locks)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(`INSERT OR REPLACE INTO round_locks (round, locks) VALUES (?, ?)`, round, string(locksBytes))
+	return err
+}
+
+func (s *sqlStore) getRoundLocks(round int) (map[string]int64, error) {
+	locks := make(map[string]int64)
+	var locksStr string
+	err := s.db.QueryRow(`SELECT locks FROM round_locks WHERE round = ?`, round).Scan(&locksStr)
+	if err == sql.ErrNoRows {
+		return locks, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return locks, json.Unmarshal([]byte(locksStr), &locks)
+}
+
+func (s *sqlStore) saveClosedRound(round int, closedAt time.Time) error {
+	_, err := s.db.Exec(`INSERT OR REPLACE INTO closed_rounds (round, closed_at) VALUES (?, ?)`, round, closedAt.Format(time.RFC3339Nano))
+	return err
+}
+
+func (s *sqlStore) getClosedRounds() (map[int]time.Time, error) {
+	rows, err := s.db.Query(`SELECT round, closed_at FROM closed_rounds`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	closed := make(map[int]time.Time)
+	for rows.Next() {
+		var round int
+		var closedAtStr string
+		if err := rows.Scan(&round, &closedAtStr); err != nil {
+			return nil, err
+		}
+		closedAt, err := time.Parse(time.RFC3339Nano, closedAtStr)
+		if err != nil {
+			return nil, err
+		}
+		closed[round] = closedAt
+	}
+	return closed, rows.Err()
+}
+
+func (s *sqlStore) saveCheckProgress(round int, progress *checkProgress) error {
+	if progress == nil {
+		_, err := s.db.Exec(`DELETE FROM check_progress WHERE round = ?`, round)
+		return err
+	}
+	progressBytes, err := json.Marshal(progress)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(`INSERT OR REPLACE INTO check_progress (round, progress) VALUES (?, ?)`, round, string(progressBytes))
+	return err
+}
+
+func (s *sqlStore) getCheckProgress(round int) (*checkProgress, error) {
+	var progressStr string
+	err := s.db.QueryRow(`SELECT progress FROM check_progress WHERE round = ?`, round).Scan(&progressStr)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	progress := &checkProgress{}
+	return progress, json.Unmarshal([]byte(progressStr), progress)
+}
+
+func (s *sqlStore) saveVotes(round int, team string, votes *roundVotes) error {
+	votesBytes, err := json.Marshal(votes)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(`INSERT OR REPLACE INTO votes (round, team, votes) VALUES (?, ?, ?)`, round, team, string(votesBytes))
+	return err
+}
+
+func (s *sqlStore) getVotes(round int, team string) (*roundVotes, error) {
+	var votesStr string
+	err := s.db.QueryRow(`SELECT votes FROM votes WHERE round = ? AND team = ?`, round, team).Scan(&votesStr)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	votes := &roundVotes{}
+	return votes, json.Unmarshal([]byte(votesStr), votes)
+}
+
+func (s *sqlStore) saveRoundMeta(round int, meta *roundMeta) error {
+	metaBytes, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(`INSERT OR REPLACE INTO round_meta (round, meta) VALUES (?, ?)`, round, string(metaBytes))
+	return err
+}
+
+func (s *sqlStore) getRoundMeta(round int) (*roundMeta, error) {
+	var metaStr string
+	err := s.db.QueryRow(`SELECT meta FROM round_meta WHERE round = ?`, round).Scan(&metaStr)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	meta := &roundMeta{}
+	return meta, json.Unmarshal([]byte(metaStr), meta)
+}
+
+func (s *sqlStore) getAllRoundMeta() (map[int]*roundMeta, error) {
+	rows, err := s.db.Query(`SELECT round, meta FROM round_meta`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	allMeta := make(map[int]*roundMeta)
+	for rows.Next() {
+		var round int
+		var metaStr string
+		if err := rows.Scan(&round, &metaStr); err != nil {
+			return nil, err
+		}
+		meta := &roundMeta{}
+		if err := json.Unmarshal([]byte(metaStr), meta); err != nil {
+			return nil, err
+		}
+		allMeta[round] = meta
+	}
+	return allMeta, rows.Err()
+}
+
+func (s *sqlStore) saveResponseRevisions(round int, responses map[string]string, fetchedAt time.Time) error {
+	return s.update(func(tx *sql.Tx) error {
+		for team, response := range responses {
+			var latest string
+			err := tx.QueryRow(`SELECT response FROM response_history WHERE round = ? AND team = ? ORDER BY id DESC LIMIT 1`, round, team).Scan(&latest)
+			if err != nil && err != sql.ErrNoRows {
+				return err
+			}
+			if err == nil && latest == response {
+				continue
+			}
+			_, err = tx.Exec(`INSERT INTO response_history (round, team, response, fetched_at) VALUES (?, ?, ?, ?)`, round, team, response, fetchedAt.Format(time.RFC3339Nano))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *sqlStore) getResponseHistory(round int, team string) ([]responseRevision, error) {
+	rows, err := s.db.Query(`SELECT response, fetched_at FROM response_history WHERE round = ? AND team = ? ORDER BY id`, round, team)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var revisions []responseRevision
+	for rows.Next() {
+		var r responseRevision
+		var fetchedAt string
+		if err := rows.Scan(&r.Response, &fetchedAt); err != nil {
+			return nil, err
+		}
+		if r.FetchedAt, err = time.Parse(time.RFC3339Nano, fetchedAt); err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, r)
+	}
+	return revisions, rows.Err()
+}
+
+func (s *sqlStore) saveShootout(shootout *shootoutState) error {
+	shootoutBytes, err := json.Marshal(shootout)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(`INSERT OR REPLACE INTO shootout (id, state) VALUES (1, ?)`, string(shootoutBytes))
+	return err
+}
+
+func (s *sqlStore) getShootout() (*shootoutState, error) {
+	var shootoutStr string
+	err := s.db.QueryRow(`SELECT state FROM shootout WHERE id = 1`).Scan(&shootoutStr)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	shootout := &shootoutState{}
+	return shootout, json.Unmarshal([]byte(shootoutStr), shootout)
+}
+
+func (s *sqlStore) saveStandingsSnapshot(snapshot *standingsSnapshot) error {
+	snapshotBytes, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(`INSERT OR REPLACE INTO standings_snapshot (id, snapshot) VALUES (1, ?)`, string(snapshotBytes))
+	return err
+}
+
+func (s *sqlStore) getStandingsSnapshot() (*standingsSnapshot, error) {
+	var snapshotStr string
+	err := s.db.QueryRow(`SELECT snapshot FROM standings_snapshot WHERE id = 1`).Scan(&snapshotStr)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	snapshot := &standingsSnapshot{}
+	return snapshot, json.Unmarshal([]byte(snapshotStr), snapshot)
+}
+
+func (s *sqlStore) saveAdjustment(adj scoreAdjustment) error {
+	_, err := s.db.Exec(`INSERT INTO adjustments (team, points, reason, time) VALUES (?, ?, ?, ?)`, adj.Team, adj.Points, adj.Reason, adj.Time.Format(time.RFC3339Nano))
+	return err
+}
+
+func (s *sqlStore) getAdjustments() ([]scoreAdjustment, error) {
+	rows, err := s.db.Query(`SELECT team, points, reason, time FROM adjustments ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var adjustments []scoreAdjustment
+	for rows.Next() {
+		var adj scoreAdjustment
+		var adjTime string
+		if err := rows.Scan(&adj.Team, &adj.Points, &adj.Reason, &adjTime); err != nil {
+			return nil, err
+		}
+		if adj.Time, err = time.Parse(time.RFC3339Nano, adjTime); err != nil {
+			return nil, err
+		}
+		adjustments = append(adjustments, adj)
+	}
+	return adjustments, rows.Err()
+}
+
+// saveAuditEntry stores the arguments and the changes of the entry as JSON.
+func (s *sqlStore) saveAuditEntry(entry *auditEntry) error {
+	args, err := json.Marshal(entry.Args)
+	if err != nil {
+		return err
+	}
+	changes, err := json.Marshal(entry.Changes)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(`INSERT INTO audit (time, command, args, origin, user, duration_ms, error, changes) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		entry.Time.Format(time.RFC3339Nano), entry.Command, string(args), entry.Origin, entry.User, entry.DurationMs, entry.Error, string(changes))
+	return err
+}
+
+func (s *sqlStore) getAuditEntries() ([]*auditEntry, error) {
+	rows, err := s.db.Query(`SELECT time, command, args, origin, user, duration_ms, error, changes FROM audit ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var entries []*auditEntry
+	for rows.Next() {
+		entry := &auditEntry{}
+		var entryTime, args, changes string
+		if err := rows.Scan(&entryTime, &entry.Command, &args, &entry.Origin, &entry.User, &entry.DurationMs, &entry.Error, &changes); err != nil {
+			return nil, err
+		}
+		if entry.Time, err = time.Parse(time.RFC3339Nano, entryTime); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(args), &entry.Args); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(changes), &entry.Changes); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+func (s *sqlStore) saveCacheEntry(key string, value []byte) error {
+	_, err := s.db.Exec(`INSERT OR REPLACE INTO read_cache (key, value) VALUES (?, ?)`, key, value)
+	return err
+}
+
+func (s *sqlStore) getCacheEntry(key string) ([]byte, error) {
+	var value []byte
+	err := s.db.QueryRow(`SELECT value FROM read_cache WHERE key = ?`, key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return value, err
+}
+
+func (s *sqlStore) deleteCacheEntries(prefix string) error {
+	// LIKE would treat the underscores of the spreadsheet IDs as wildcards
+	_, err := s.db.Exec(`DELETE FROM read_cache WHERE substr(key, 1, length(?)) = ?`, prefix, prefix)
+	return err
+}
+
+func (s *sqlStore) appendEvent(message string) error {
+	_, err := s.db.Exec(`INSERT INTO events (time, message) VALUES (?, ?)`, time.Now().Format(time.RFC3339Nano), strings.TrimSpace(message))
+	return err
+}
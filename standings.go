@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// outOfCompetitionMark marks the teams playing out of competition in place of
+// their place.
+const outOfCompetitionMark = "в/к"
+
+// standing is a place (or a range of shared places) in the final standings.
+type standing struct {
+	FirstPlace int      `json:"firstPlace" yaml:"firstPlace"`
+	LastPlace  int      `json:"lastPlace" yaml:"lastPlace"`
+	Score      float64  `json:"score" yaml:"score"`
+	Teams      []string `json:"teams" yaml:"teams"`
+	// OutOfCompetition is set for a team playing out of competition, it takes
+	// no place.
+	OutOfCompetition bool `json:"outOfCompetition,omitempty" yaml:"outOfCompetition,omitempty"`
+}
+
+// Places returns the place, e.g. "3", the shared places, e.g. "3-5", or the
+// out of competition mark.
+func (s *standing) places() string {
+	if s.OutOfCompetition {
+		return outOfCompetitionMark
+	}
+	if s.FirstPlace == s.LastPlace {
+		return fmt.Sprintf("%d", s.FirstPlace)
+	}
+	return fmt.Sprintf("%d-%d", s.FirstPlace, s.LastPlace)
+}
+
+// computeStandings orders the teams by their score, the teams with equal
+// scores share the places.
+func computeStandings(total map[string]float64) []*standing {
+	teams := make([]string, 0, len(total))
+	for team := range total {
+		teams = append(teams, team)
+	}
+	sort.Slice(teams, func(i, j int) bool {
+		if total[teams[i]] != total[teams[j]] {
+			return total[teams[i]] > total[teams[j]]
+		}
+		return teams[i] < teams[j]
+	})
+	standings := make([]*standing, 0)
+	for i, team := range teams {
+		if len(standings) != 0 {
+			last := standings[len(standings)-1]
+			if last.Score == total[team] {
+				last.Teams = append(last.Teams, team)
+				last.LastPlace = i + 1
+				continue
+			}
+		}
+		standings = append(standings, &standing{
+			FirstPlace: i + 1,
+			LastPlace:  i + 1,
+			Score:      total[team],
+			Teams:      []string{team},
+		})
+	}
+	return standings
+}
+
+// computeOfficialStandings orders the teams in competition like
+// computeStandings, the teams out of competition follow them ordered by their
+// score and take no place.
+func computeOfficialStandings(total map[string]float64, outOfCompetition map[string]bool) []*standing {
+	official := make(map[string]float64, len(total))
+	guests := make(map[string]float64)
+	for team, score := range total {
+		if outOfCompetition[team] {
+			guests[team] = score
+		} else {
+			official[team] = score
+		}
+	}
+	standings := computeStandings(official)
+	for _, s := range computeStandings(guests) {
+		for _, team := range s.Teams {
+			standings = append(standings, &standing{Score: s.Score, Teams: []string{team}, OutOfCompetition: true})
+		}
+	}
+	return standings
+}
+
+type announceResult struct {
+	Lines []string `json:"lines" yaml:"lines"`
+}
+
+func (r *announceResult) String() string {
+	return strings.Join(r.Lines, "\n")
+}
+
+// CmdAnnounce generates the final standings reveal script for the host: the
+// places are announced from the last to the first. With the --step argument
+// the lines are displayed one by one on Enter.
+func (a *app) CmdAnnounce(cmdStr string) (*announceResult, error) {
+	step := false
+	sSplitted := splitArgs(cmdStr)
+	switch {
+	case len(sSplitted) == 2 && sSplitted[1] == "--step":
+		step = true
+	case len(sSplitted) != 1:
+		return nil, fmt.Errorf("unexpected arguments, expected none or --step")
+	}
+	total, err := a.computeTotals()
+	if err != nil {
+		return nil, err
+	}
+	lines := announcementLines(a.standings(total))
+	if !step {
+		return &announceResult{Lines: lines}, nil
+	}
+	reader := bufio.NewReader(os.Stdin)
+	for _, line := range lines {
+		if _, err := reader.ReadString('\n'); err != nil {
+			return nil, fmt.Errorf("failed to scan the command: %w", err)
+		}
+		fmt.Println(line)
+	}
+	return nil, nil
+}
+
+func announcementLines(standings []*standing) []string {
+	lines := make([]string, 0, len(standings)+1)
+	for i := len(standings) - 1; i >= 0; i-- {
+		s := standings[i]
+		points := formatPoints(s.Score)
+		if s.OutOfCompetition {
+			lines = append(lines, fmt.Sprintf("Вне конкурса с результатом %s — команда «%s»!", points, s.Teams[0]))
+			continue
+		}
+		if len(s.Teams) == 1 {
+			lines = append(lines, fmt.Sprintf("%s место с результатом %s — команда «%s»!", s.places(), points, s.Teams[0]))
+			continue
+		}
+		quoted := make([]string, len(s.Teams))
+		for j, team := range s.Teams {
+			quoted[j] = fmt.Sprintf("«%s»", team)
+		}
+		lines = append(lines, fmt.Sprintf("%s места делят команды с результатом %s: %s!", s.places(), points, strings.Join(quoted, ", ")))
+	}
+	if len(standings) != 0 && len(standings[0].Teams) == 1 && !standings[0].OutOfCompetition {
+		lines = append(lines, fmt.Sprintf("Поздравляем победителя — команду «%s»!", standings[0].Teams[0]))
+	}
+	return lines
+}
+
+func formatPoints(score float64) string {
+	return fmt.Sprintf("%g", score)
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+const statsSheetTitle = "Stats"
+
+type questionStats struct {
+	Question int     `json:"question" yaml:"question"`
+	Correct  int     `json:"correct" yaml:"correct"`
+	Teams    int     `json:"teams" yaml:"teams"`
+	Percent  float64 `json:"percent" yaml:"percent"`
+	Voided   bool    `json:"voided,omitempty" yaml:"voided,omitempty"`
+}
+
+type teamStreak struct {
+	Team string `json:"team" yaml:"team"`
+	// Longest is the longest run of consecutive correct answers, Current is
+	// the run of correct answers to the latest questions.
+	Longest int `json:"longest" yaml:"longest"`
+	Current int `json:"current" yaml:"current"`
+}
+
+type statsResult struct {
+	Questions []*questionStats `json:"questions" yaml:"questions"`
+	Hardest   []int            `json:"hardest" yaml:"hardest"`
+	Easiest   []int            `json:"easiest" yaml:"easiest"`
+	Streaks   []*teamStreak    `json:"streaks" yaml:"streaks"`
+	// Adjustments are the net score adjustments of the adjusted teams.
+	Adjustments map[string]float64 `json:"adjustments,omitempty" yaml:"adjustments,omitempty"`
+	URL         string             `json:"url,omitempty" yaml:"url,omitempty"`
+}
+
+func (r *statsResult) String() string {
+	var sb strings.Builder
+	questions := &table{header: []string{"Question", "Correct", "%"}}
+	for _, q := range r.Questions {
+		correct := fmt.Sprintf("%d/%d", q.Correct, q.Teams)
+		if q.Voided {
+			correct += " (voided)"
+		}
+		questions.addRow(plainCell(strconv.Itoa(q.Question)), plainCell(correct), plainCell(fmt.Sprintf("%.0f", q.Percent)))
+	}
+	sb.WriteString(questions.String())
+	sb.WriteString(fmt.Sprintf("Hardest questions: %s\n", joinInts(r.Hardest)))
+	sb.WriteString(fmt.Sprintf("Easiest questions: %s\n", joinInts(r.Easiest)))
+	streaks := &table{header: []string{"Team", "Longest streak", "Current streak"}}
+	for _, s := range r.Streaks {
+		streaks.addRow(plainCell(s.Team), plainCell(strconv.Itoa(s.Longest)), plainCell(strconv.Itoa(s.Current)))
+	}
+	sb.WriteString(streaks.String())
+	if len(r.Adjustments) != 0 {
+		adjustments := &table{header: []string{"Team", "Adjustment"}}
+		for _, team := range adjustedTeams(r.Adjustments) {
+			adjustments.addRow(plainCell(team), plainCell(formatAdjustment(r.Adjustments[team])))
+		}
+		sb.WriteString(adjustments.String())
+	}
+	if len(r.URL) != 0 {
+		sb.WriteString(fmt.Sprintf("The statistics are written to %s\n", r.URL))
+	}
+	return sb.String()
+}
+
+func joinInts(ints []int) string {
+	s := make([]string, len(ints))
+	for i, n := range ints {
+		s[i] = strconv.Itoa(n)
+	}
+	return strings.Join(s, ", ")
+}
+
+// CmdStats computes the per-question statistics of the fetched rounds and the
+// streaks of correct answers of the teams: "stats [--sheet]". With --sheet
+// the statistics are also written to the Stats sheet of the manager
+// spreadsheet.
+func (a *app) CmdStats(cmdStr string) (*statsResult, error) {
+	sSplitted := strings.Fields(cmdStr)
+	writeSheet := false
+	switch {
+	case len(sSplitted) == 1:
+	case len(sSplitted) == 2 && sSplitted[1] == "--sheet":
+		writeSheet = true
+	default:
+		return nil, fmt.Errorf("expected no arguments or --sheet")
+	}
+	if writeSheet && a.config.ReadOnly {
+		return nil, &errorReadOnly{cmd: "stats --sheet"}
+	}
+	res, err := a.computeStats()
+	if err != nil {
+		return nil, err
+	}
+	if writeSheet {
+		if res.URL, err = a.writeStatsSheet(res); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+func (a *app) computeStats() (*statsResult, error) {
+	allMeta, err := a.store.getAllRoundMeta()
+	if err != nil {
+		return nil, err
+	}
+	allResults, err := a.store.getAllRoundResults()
+	if err != nil {
+		return nil, err
+	}
+	resultsByRound := make(map[int]*roundResults, len(allResults))
+	for _, results := range allResults {
+		resultsByRound[results.Round] = results
+	}
+	res := &statsResult{}
+	streaks := make(map[string]*teamStreak, len(a.config.Teams))
+	for _, team := range a.config.Teams {
+		streaks[team] = &teamStreak{Team: team}
+	}
+	for _, round := range a.scoredRounds() {
+		meta := allMeta[round]
+		results, fetched := resultsByRound[round]
+		voided := meta != nil && len(meta.Void) != 0
+		if !fetched && !voided {
+			continue
+		}
+		q := &questionStats{Question: a.config.questionNumber(round), Teams: len(a.config.Teams), Voided: voided}
+		for _, team := range a.config.Teams {
+			status := ResponseStatusNoAnswer
+			if fetched {
+				if resp, ok := results.Results[team]; ok {
+					status = resp.Status
+				}
+			}
+			correct := meta.points(status) == 1
+			if voided {
+				// a voided question neither makes nor breaks a streak
+				if correct {
+					q.Correct++
+				}
+				continue
+			}
+			s := streaks[team]
+			if correct {
+				q.Correct++
+				s.Current++
+				if s.Current > s.Longest {
+					s.Longest = s.Current
+				}
+			} else {
+				s.Current = 0
+			}
+		}
+		if q.Teams != 0 {
+			q.Percent = float64(q.Correct) * 100 / float64(q.Teams)
+		}
+		res.Questions = append(res.Questions, q)
+	}
+	res.Hardest, res.Easiest = extremeQuestions(res.Questions)
+	for _, team := range a.config.Teams {
+		res.Streaks = append(res.Streaks, streaks[team])
+	}
+	sort.SliceStable(res.Streaks, func(i, j int) bool {
+		return res.Streaks[i].Longest > res.Streaks[j].Longest
+	})
+	adjustments, err := a.store.getAdjustments()
+	if err != nil {
+		return nil, err
+	}
+	if len(adjustments) != 0 {
+		res.Adjustments = sumAdjustments(adjustments)
+	}
+	return res, nil
+}
+
+// extremeQuestions returns the questions with the fewest and the most correct
+// answers, the voided questions are left out.
+func extremeQuestions(questions []*questionStats) ([]int, []int) {
+	var hardest, easiest []int
+	minCorrect, maxCorrect := -1, -1
+	for _, q := range questions {
+		if q.Voided {
+			continue
+		}
+		switch {
+		case minCorrect < 0 || q.Correct < minCorrect:
+			minCorrect = q.Correct
+			hardest = []int{q.Question}
+		case q.Correct == minCorrect:
+			hardest = append(hardest, q.Question)
+		}
+		switch {
+		case maxCorrect < 0 || q.Correct > maxCorrect:
+			maxCorrect = q.Correct
+			easiest = []int{q.Question}
+		case q.Correct == maxCorrect:
+			easiest = append(easiest, q.Question)
+		}
+	}
+	return hardest, easiest
+}
+
+// writeStatsSheet writes the statistics to the Stats sheet of the manager
+// spreadsheet, the sheet is added on the first use.
+func (a *app) writeStatsSheet(res *statsResult) (string, error) {
+	gameSheets, err := a.GetGameSpreadsheets()
+	if err != nil {
+		return "", err
+	}
+	if gameSheets.manager == nil {
+		return "", errManagerSpreadsheetNotFound
+	}
+	managerID := gameSheets.manager.ID
+	metadata, err := a.getSpreadsheetMetadata(a.commandContext(), managerID)
+	if err != nil {
+		return "", err
+	}
+	spreadsheetsService := sheets.NewSpreadsheetsService(a.service)
+	if _, ok := metadata.sheetByTitle(statsSheetTitle); !ok {
+		_, err := spreadsheetsService.BatchUpdate(managerID, &sheets.BatchUpdateSpreadsheetRequest{
+			Requests: []*sheets.Request{
+				{
+					AddSheet: &sheets.AddSheetRequest{
+						Properties: &sheets.SheetProperties{Title: statsSheetTitle},
+					},
+				},
+			},
+		}).Context(a.commandContext()).Do()
+		if err != nil {
+			return "", fmt.Errorf("failed to add the stats sheet: %w", err)
+		}
+		a.metadata.invalidate(managerID)
+	}
+	questions := [][]interface{}{{"Question", "Correct", "Teams", "%"}}
+	for _, q := range res.Questions {
+		questions = append(questions, []interface{}{q.Question, q.Correct, q.Teams, q.Percent})
+	}
+	streaks := [][]interface{}{{"Team", "Longest streak", "Current streak"}}
+	for _, s := range res.Streaks {
+		streaks = append(streaks, []interface{}{s.Team, s.Longest, s.Current})
+	}
+	adjustments := [][]interface{}{{"Team", "Adjustment"}}
+	for _, team := range adjustedTeams(res.Adjustments) {
+		adjustments = append(adjustments, []interface{}{team, res.Adjustments[team]})
+	}
+	valuesService := sheets.NewSpreadsheetsValuesService(a.service)
+	_, err = valuesService.Clear(managerID, statsSheetTitle, &sheets.ClearValuesRequest{}).Context(a.commandContext()).Do()
+	if err != nil {
+		return "", fmt.Errorf("failed to clear the stats sheet: %w", err)
+	}
+	_, err = valuesService.BatchUpdate(managerID, &sheets.BatchUpdateValuesRequest{
+		ValueInputOption: "RAW",
+		Data: []*sheets.ValueRange{
+			{
+				Range:  sheetRange(statsSheetTitle, rangeName(0, 1, 3, len(questions))),
+				Values: questions,
+			},
+			{
+				Range:  sheetRange(statsSheetTitle, rangeName(5, 1, 7, len(streaks))),
+				Values: streaks,
+			},
+			{
+				Range:  sheetRange(statsSheetTitle, rangeName(9, 1, 10, len(adjustments))),
+				Values: adjustments,
+			},
+		},
+	}).Context(a.commandContext()).Do()
+	if err != nil {
+		return "", fmt.Errorf("failed to write the stats sheet: %w", err)
+	}
+	return gameSheets.manager.URL, nil
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+type statusResult struct {
+	Game            string `json:"game" yaml:"game"`
+	Teams           int    `json:"teams" yaml:"teams"`
+	Questions       int    `json:"questions" yaml:"questions"`
+	CheckedRounds   int    `json:"checkedRounds" yaml:"checkedRounds"`
+	UncheckedRounds int    `json:"uncheckedRounds" yaml:"uncheckedRounds"`
+	Online          bool   `json:"online" yaml:"online"`
+	ReadOnly        bool   `json:"readOnly" yaml:"readOnly"`
+	// TokenExpiry is the expiry of the access token, it is refreshed
+	// automatically.
+	TokenExpiry time.Time `json:"tokenExpiry,omitempty" yaml:"tokenExpiry,omitempty"`
+	AuthError   string    `json:"authError,omitempty" yaml:"authError,omitempty"`
+	// HealthCheckedAt is the time of the latest health check, HealthError
+	// is its error.
+	HealthCheckedAt time.Time `json:"healthCheckedAt,omitempty" yaml:"healthCheckedAt,omitempty"`
+	HealthError     string    `json:"healthError,omitempty" yaml:"healthError,omitempty"`
+}
+
+func (r *statusResult) String() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Game %s: %d teams, %d questions\n", r.Game, r.Teams, r.Questions))
+	sb.WriteString(fmt.Sprintf("Fetched rounds: %d checked, %d with unchecked answers\n", r.CheckedRounds, r.UncheckedRounds))
+	if !r.Online {
+		sb.WriteString("The Google API is unreachable\n")
+	}
+	if r.ReadOnly {
+		sb.WriteString("The session is read-only\n")
+	}
+	if len(r.AuthError) != 0 {
+		sb.WriteString(fmt.Sprintf("The authorization cannot be refreshed: %s\n", r.AuthError))
+	} else if !r.TokenExpiry.IsZero() {
+		sb.WriteString(fmt.Sprintf("The access token expires at %s\n", r.TokenExpiry.Format("15:04:05")))
+	}
+	if len(r.HealthError) != 0 {
+		sb.WriteString(fmt.Sprintf("The health check at %s failed: %s\n", r.HealthCheckedAt.Format("15:04:05"), r.HealthError))
+	}
+	return sb.String()
+}
+
+// CmdStatus shows the progress of the game: "status".
+func (a *app) CmdStatus() (*statusResult, error) {
+	checked, unchecked, err := a.countCheckedRounds()
+	if err != nil {
+		return nil, err
+	}
+	res := &statusResult{
+		Game:            a.config.GameName,
+		Teams:           len(a.config.Teams),
+		Questions:       a.config.NumberOfQuestions,
+		CheckedRounds:   checked,
+		UncheckedRounds: unchecked,
+		Online:          a.conn.isOnline(),
+		ReadOnly:        a.config.ReadOnly,
+	}
+	if a.tokens != nil {
+		expiry, err := a.tokens.status()
+		res.TokenExpiry = expiry
+		if err != nil {
+			res.AuthError = err.Error()
+		}
+	}
+	healthCheckedAt, healthErr := a.health.get()
+	res.HealthCheckedAt = healthCheckedAt
+	if healthErr != nil {
+		res.HealthError = healthErr.Error()
+	}
+	return res, nil
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+const statusesSheetTitle = "Statuses"
+
+// statusColors are the background colors of the answers with the statuses.
+var statusColors = []struct {
+	status ResponseStatus
+	color  *sheets.Color
+}{
+	{ResponseStatusOK, &sheets.Color{Red: 0.72, Green: 0.88, Blue: 0.8}},
+	{ResponseStatusKO, &sheets.Color{Red: 0.96, Green: 0.78, Blue: 0.76}},
+	{ResponseStatusInQuestion, &sheets.Color{Red: 0.99, Green: 0.91, Blue: 0.7}},
+	{ResponseStatusPartial, &sheets.Color{Red: 0.79, Green: 0.85, Blue: 0.97}},
+}
+
+func statusSheetsColor(status ResponseStatus) *sheets.Color {
+	for _, c := range statusColors {
+		if c.status == status {
+			return c.color
+		}
+	}
+	return &sheets.Color{Red: 1, Green: 1, Blue: 1}
+}
+
+type markStatusesResult struct {
+	Round  int `json:"round" yaml:"round"`
+	Marked int `json:"marked" yaml:"marked"`
+}
+
+func (r *markStatusesResult) String() string {
+	return fmt.Sprintf("Round %d: %d statuses are written to the manager spreadsheet", r.Round, r.Marked)
+}
+
+func (a *app) CmdMarkStatuses(cmdStr string) (*markStatusesResult, error) {
+	round, err := a.getRoundNumber(cmdStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse markStatuses request: %w", err)
+	}
+	results, err := a.store.getRoundResults(round)
+	if err != nil {
+		return nil, err
+	}
+	return a.markStatuses(results)
+}
+
+// markStatuses writes the round statuses into the statuses sheet of the
+// manager spreadsheet. The statuses sheet mirrors the layout of the answers
+// sheet, and the answers are colored by the conditional formatting rules
+// referring to it.
+func (a *app) markStatuses(results *roundResults) (*markStatusesResult, error) {
+	gameSheets, err := a.GetGameSpreadsheets()
+	if err != nil {
+		return nil, err
+	}
+	if gameSheets.manager == nil {
+		return nil, errManagerSpreadsheetNotFound
+	}
+	if err := a.ensureStatusesSheet(gameSheets.manager.ID); err != nil {
+		return nil, err
+	}
+	roundRange, err := a.getRoundRange(results.Round)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]interface{}, len(a.config.Teams))
+	marked := 0
+	for i, team := range a.config.Teams {
+		values[i] = ""
+		resp, ok := results.Results[team]
+		if !ok || resp.Status == ResponseStatusNotChecked {
+			continue
+		}
+		values[i] = resp.Status.String()
+		marked++
+	}
+	column := int(roundRange.StartColumnIndex)
+	r := sheetRange(statusesSheetTitle, rangeName(column, int(roundRange.StartRowIndex)+1, column, int(roundRange.EndRowIndex)))
+	valuesService := sheets.NewSpreadsheetsValuesService(a.service)
+	_, err = valuesService.Update(gameSheets.manager.ID, r, &sheets.ValueRange{
+		MajorDimension: "COLUMNS",
+		Range:          r,
+		Values:         [][]interface{}{values},
+	}).ValueInputOption("RAW").Context(a.commandContext()).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to write the statuses: %w", err)
+	}
+	logHistoryError(a.config.questionNumber(results.Round), a.updateHistory(results))
+	res := &markStatusesResult{
+		Round:  a.config.questionNumber(results.Round),
+		Marked: marked,
+	}
+	return res, nil
+}
+
+// ensureStatusesSheet adds the statuses sheet and the conditional formatting
+// rules to the manager spreadsheet unless it has been already done.
+func (a *app) ensureStatusesSheet(managerID string) error {
+	steps, err := a.store.getSetupSteps()
+	if err != nil {
+		return err
+	}
+	if steps[setupStepStatusesSheet] {
+		return nil
+	}
+	manager, err := a.getSpreadsheetMetadata(a.commandContext(), managerID)
+	if err != nil {
+		return err
+	}
+	if len(manager.sheets) == 0 {
+		return fmt.Errorf("the manager spreadsheet does not have sheets")
+	}
+	answersSheetID := manager.sheets[0].ID
+	statusesSheet, hasStatusesSheet := manager.sheetByTitle(statusesSheetTitle)
+	statusesSheetID := statusesSheet.ID
+	spreadsheetsService := sheets.NewSpreadsheetsService(a.service)
+	if !hasStatusesSheet {
+		resp, err := spreadsheetsService.BatchUpdate(managerID, &sheets.BatchUpdateSpreadsheetRequest{
+			Requests: []*sheets.Request{
+				{
+					AddSheet: &sheets.AddSheetRequest{
+						Properties: &sheets.SheetProperties{
+							Title: statusesSheetTitle,
+						},
+					},
+				},
+			},
+		}).Context(a.commandContext()).Do()
+		if err != nil {
+			return fmt.Errorf("failed to add the statuses sheet: %w", err)
+		}
+		statusesSheetID = resp.Replies[0].AddSheet.Properties.SheetId
+		a.metadata.invalidate(managerID)
+	}
+	requests := make([]*sheets.Request, 0, 2*len(statusColors))
+	for _, c := range statusColors {
+		answersFormula := fmt.Sprintf("=INDIRECT(\"%s!\"&ADDRESS(ROW(),COLUMN()))=\"%s\"", statusesSheetTitle, c.status)
+		statusesFormula := fmt.Sprintf("=INDIRECT(ADDRESS(ROW(),COLUMN()))=\"%s\"", c.status)
+		for _, rule := range []struct {
+			sheetID int64
+			formula string
+		}{{answersSheetID, answersFormula}, {statusesSheetID, statusesFormula}} {
+			requests = append(requests, &sheets.Request{
+				AddConditionalFormatRule: &sheets.AddConditionalFormatRuleRequest{
+					Rule: &sheets.ConditionalFormatRule{
+						Ranges: []*sheets.GridRange{{SheetId: rule.sheetID}},
+						BooleanRule: &sheets.BooleanRule{
+							Condition: &sheets.BooleanCondition{
+								Type:   "CUSTOM_FORMULA",
+								Values: []*sheets.ConditionValue{{UserEnteredValue: rule.formula}},
+							},
+							Format: &sheets.CellFormat{
+								BackgroundColor: c.color,
+							},
+						},
+					},
+				},
+			})
+		}
+	}
+	_, err = spreadsheetsService.BatchUpdate(managerID, &sheets.BatchUpdateSpreadsheetRequest{
+		Requests: requests,
+	}).Context(a.commandContext()).Do()
+	if err != nil {
+		return fmt.Errorf("failed to add the statuses conditional formatting: %w", err)
+	}
+	return a.store.markSetupStep(setupStepStatusesSheet)
+}
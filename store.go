@@ -1,11 +1,16 @@
 package main
 
 import (
+	"encoding/binary"
 	"encoding/json"
 	"fmt"
-	"log"
+	"io/ioutil"
+	"os"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	bolt "go.etcd.io/bbolt"
 	"google.golang.org/api/sheets/v4"
@@ -15,19 +20,72 @@ const (
 	bucketGameConfiguration = "game-configuration"
 	bucketTeamsSpreadsheets = "teams-spreadsheets"
 	bucketGameResults       = "game-results"
+	bucketArchivedTeams     = "archived-teams-spreadsheets"
+	bucketEventLog          = "event-log"
+	bucketSetupState        = "setup-state"
+	bucketRoundLocks        = "round-locks"
+	bucketClosedRounds      = "closed-rounds"
+	bucketVotes             = "votes"
+	bucketRoundMeta         = "round-meta"
+	bucketJournal           = "journal"
+	bucketCheckProgress     = "check-progress"
+	bucketResponseHistory   = "response-history"
+	bucketShootout          = "shootout"
+	bucketStandingsSnapshot = "standings-snapshot"
+	bucketAdjustments       = "adjustments"
+	bucketAudit             = "audit"
+	bucketReadCache         = "read-cache"
 )
 
+const (
+	setupStepManagerFilled = "manager-filled"
+	setupStepManagerLinked = "manager-linked"
+	setupStepStatusesSheet = "statuses-sheet"
+	setupStepHistorySheet  = "history-sheet"
+)
+
+func setupStepTeamFilled(team string) string {
+	return fmt.Sprintf("team-filled/%s", team)
+}
+
 const (
 	bucketGameConfiguration_managerSpreadsheet = "manager-spreadsheet"
+	bucketGameConfiguration_teams              = "teams"
+	bucketGameConfiguration_driveFolder        = "drive-folder"
+	bucketGameConfiguration_projector          = "projector-spreadsheet"
 )
 
 type boltManager struct {
 	dbFile string
+	// db is opened once for the game, as the file lock is held for as long as
+	// the database is open
+	db *bolt.DB
+	// dbMu guards db: it is held shared by the reads and the updates and
+	// exclusively while the database file is replaced by compact or restore,
+	// as the metrics and the timer use the store outside of the engine lock
+	dbMu sync.RWMutex
+	// numbering numbers the rounds in the journal and in the errors
+	numbering roundNumbering
+	// journalSize is the number of the latest mutations that can be undone
+	journalSize int
+	// backupDir receives a copy of the database after every update, at most
+	// backupCount copies are kept
+	backupDir   string
+	backupCount int
+	// backupMu serializes the backups of the concurrent updates
+	backupMu sync.Mutex
+	// readOnly is set for the observer sessions: the database is not opened,
+	// as the game instance holds its lock, the reads are done on a copy of it
+	readOnly bool
 }
 
 type storeSpreadsheet struct {
 	ID  string
 	URL string
+	// SheetID and SheetTitle identify the first sheet of the spreadsheet,
+	// which holds the answers.
+	SheetID    int64
+	SheetTitle string
 }
 
 func newStoreSpreadsheet(sheet *sheets.Spreadsheet) *storeSpreadsheet {
@@ -35,12 +93,50 @@ func newStoreSpreadsheet(sheet *sheets.Spreadsheet) *storeSpreadsheet {
 		return nil
 	}
 	s := &storeSpreadsheet{
-		ID:  sheet.SpreadsheetId,
-		URL: sheet.SpreadsheetUrl,
+		ID:         sheet.SpreadsheetId,
+		URL:        sheet.SpreadsheetUrl,
+		SheetTitle: firstSheetTitle(sheet),
+	}
+	if len(sheet.Sheets) != 0 && sheet.Sheets[0].Properties != nil {
+		s.SheetID = sheet.Sheets[0].Properties.SheetId
 	}
 	return s
 }
 
+// toSpreadsheet returns the spreadsheet with the fields the tool relies on.
+func (s *storeSpreadsheet) toSpreadsheet() *sheets.Spreadsheet {
+	title := s.SheetTitle
+	if len(title) == 0 {
+		title = defaultSheetTitle
+	}
+	return &sheets.Spreadsheet{
+		SpreadsheetId:  s.ID,
+		SpreadsheetUrl: s.URL,
+		Sheets: []*sheets.Sheet{
+			{
+				Properties: &sheets.SheetProperties{
+					SheetId: s.SheetID,
+					Title:   title,
+				},
+			},
+		},
+	}
+}
+
+const defaultSheetTitle = "Sheet1"
+
+func firstSheetTitle(sheet *sheets.Spreadsheet) string {
+	if len(sheet.Sheets) == 0 || sheet.Sheets[0].Properties == nil || len(sheet.Sheets[0].Properties.Title) == 0 {
+		return defaultSheetTitle
+	}
+	return sheet.Sheets[0].Properties.Title
+}
+
+// sheetRange returns the range in A1 notation prefixed with the sheet title.
+func sheetRange(sheetTitle string, r string) string {
+	return fmt.Sprintf("'%s'!%s", strings.ReplaceAll(sheetTitle, "'", "''"), r)
+}
+
 type storeGameSpreadsheets struct {
 	manager *storeSpreadsheet
 	teams   map[string]*storeSpreadsheet
@@ -76,30 +172,200 @@ func (b *boltManager) saveSpreadsheets(req *storeGameSpreadsheets) error {
 		if err != nil {
 			return err
 		}
-		managerBytes, err := json.Marshal(req.manager)
+		if req.manager != nil {
+			managerBytes, err := json.Marshal(req.manager)
+			if err != nil {
+				return err
+			}
+			if err := buckGameConfig.Put([]byte(bucketGameConfiguration_managerSpreadsheet), managerBytes); err != nil {
+				return err
+			}
+		}
+		return putTeamsSpreadsheets(tx, req.teams)
+	})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (b *boltManager) saveTeamsSpreadsheets(req *storeGameSpreadsheets) error {
+	err := b.update(func(tx *bolt.Tx) error {
+		return putTeamsSpreadsheets(tx, req.teams)
+	})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func putTeamsSpreadsheets(tx *bolt.Tx, teams map[string]*storeSpreadsheet) error {
+	if len(teams) == 0 {
+		return nil
+	}
+	buckTeamsSpreadsheets, err := getBucket(tx, bucketTeamsSpreadsheets)
+	if err != nil {
+		return err
+	}
+	for name, spreadsheet := range teams {
+		spreadsheetBytes, err := json.Marshal(spreadsheet)
 		if err != nil {
 			return err
 		}
-		if err := buckGameConfig.Put([]byte(bucketGameConfiguration_managerSpreadsheet), managerBytes); err != nil {
+		if err := buckTeamsSpreadsheets.Put([]byte(name), spreadsheetBytes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// archiveTeamSpreadsheet moves the team spreadsheet from the active teams
+// bucket to the archived teams bucket.
+func (b *boltManager) archiveTeamSpreadsheet(team string) error {
+	err := b.update(func(tx *bolt.Tx) error {
+		buckTeamsSpreadsheets, err := getBucket(tx, bucketTeamsSpreadsheets)
+		if err != nil {
 			return err
 		}
-		if len(req.teams) == 0 {
+		spreadsheetBytes := buckTeamsSpreadsheets.Get([]byte(team))
+		if spreadsheetBytes == nil {
 			return nil
 		}
-		buckTeamsSpreadsheets, err := getBucket(tx, bucketTeamsSpreadsheets)
+		buckArchivedTeams, err := getBucket(tx, bucketArchivedTeams)
 		if err != nil {
 			return err
 		}
-		for name, spreadsheet := range req.teams {
-			spreadsheetBytes, err := json.Marshal(spreadsheet)
-			if err != nil {
-				return err
+		if err := buckArchivedTeams.Put([]byte(team), spreadsheetBytes); err != nil {
+			return err
+		}
+		return buckTeamsSpreadsheets.Delete([]byte(team))
+	})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (b *boltManager) saveTeams(teams []string) error {
+	err := b.update(func(tx *bolt.Tx) error {
+		buckGameConfig, err := getBucket(tx, bucketGameConfiguration)
+		if err != nil {
+			return err
+		}
+		teamsBytes, err := json.Marshal(teams)
+		if err != nil {
+			return err
+		}
+		return buckGameConfig.Put([]byte(bucketGameConfiguration_teams), teamsBytes)
+	})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// getTeams returns the persisted teams roster, or nil if it has not been
+// saved yet.
+func (b *boltManager) getTeams() ([]string, error) {
+	var teams []string
+	err := b.read(func(tx *bolt.Tx) error {
+		buckGameConfig, err := getBucket(tx, bucketGameConfiguration)
+		if err != nil {
+			if _, ok := err.(*errorInexistantBucket); ok {
+				return nil
 			}
-			if err := buckTeamsSpreadsheets.Put([]byte(name), spreadsheetBytes); err != nil {
-				return err
+			return err
+		}
+		teamsBytes := buckGameConfig.Get([]byte(bucketGameConfiguration_teams))
+		if teamsBytes == nil {
+			return nil
+		}
+		return json.Unmarshal(teamsBytes, &teams)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return teams, nil
+}
+
+func (b *boltManager) saveDriveFolder(folder string) error {
+	folderBytes, err := json.Marshal(folder)
+	if err != nil {
+		return err
+	}
+	err = b.update(func(tx *bolt.Tx) error {
+		buckGameConfig, err := getBucket(tx, bucketGameConfiguration)
+		if err != nil {
+			return err
+		}
+		return buckGameConfig.Put([]byte(bucketGameConfiguration_driveFolder), folderBytes)
+	})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// getDriveFolder returns the ID of the created game Drive folder, or an empty
+// string if it has not been created.
+func (b *boltManager) getDriveFolder() (string, error) {
+	var folder string
+	err := b.read(func(tx *bolt.Tx) error {
+		buckGameConfig, err := getBucket(tx, bucketGameConfiguration)
+		if err != nil {
+			if _, ok := err.(*errorInexistantBucket); ok {
+				return nil
 			}
+			return err
 		}
+		folder = decodeDriveFolder(buckGameConfig.Get([]byte(bucketGameConfiguration_driveFolder)))
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return folder, nil
+}
+
+// decodeDriveFolder decodes the stored folder ID, the databases created before
+// the ID was stored as a JSON string hold the bare ID.
+func decodeDriveFolder(value []byte) string {
+	var folder string
+	if err := json.Unmarshal(value, &folder); err != nil {
+		return string(value)
+	}
+	return folder
+}
+
+// migrateDriveFolder stores the bare folder ID of an older database as a JSON
+// string, as the archive accepts only the JSON values.
+func migrateDriveFolder(tx *bolt.Tx) error {
+	buckGameConfig := tx.Bucket([]byte(bucketGameConfiguration))
+	if buckGameConfig == nil {
+		return nil
+	}
+	value := buckGameConfig.Get([]byte(bucketGameConfiguration_driveFolder))
+	if value == nil || json.Valid(value) {
 		return nil
+	}
+	folderBytes, err := json.Marshal(string(value))
+	if err != nil {
+		return err
+	}
+	return buckGameConfig.Put([]byte(bucketGameConfiguration_driveFolder), folderBytes)
+}
+
+func (b *boltManager) saveProjectorSpreadsheet(projector *sheets.Spreadsheet) error {
+	err := b.update(func(tx *bolt.Tx) error {
+		buckGameConfig, err := getBucket(tx, bucketGameConfiguration)
+		if err != nil {
+			return err
+		}
+		projectorBytes, err := json.Marshal(newStoreSpreadsheet(projector))
+		if err != nil {
+			return err
+		}
+		return buckGameConfig.Put([]byte(bucketGameConfiguration_projector), projectorBytes)
 	})
 	if err != nil {
 		return err
@@ -107,16 +373,46 @@ func (b *boltManager) saveSpreadsheets(req *storeGameSpreadsheets) error {
 	return nil
 }
 
+// getProjectorSpreadsheet returns the projector spreadsheet, or nil if it has
+// not been created.
+func (b *boltManager) getProjectorSpreadsheet() (*storeSpreadsheet, error) {
+	var projector *storeSpreadsheet
+	err := b.read(func(tx *bolt.Tx) error {
+		buckGameConfig, err := getBucket(tx, bucketGameConfiguration)
+		if err != nil {
+			if _, ok := err.(*errorInexistantBucket); ok {
+				return nil
+			}
+			return err
+		}
+		projectorBytes := buckGameConfig.Get([]byte(bucketGameConfiguration_projector))
+		if projectorBytes == nil {
+			return nil
+		}
+		projector = &storeSpreadsheet{}
+		return json.Unmarshal(projectorBytes, projector)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return projector, nil
+}
+
 func (b *boltManager) getSpreadsheets() (*storeGameSpreadsheets, error) {
 	spreadsheets := &storeGameSpreadsheets{}
 	err := b.read(func(tx *bolt.Tx) error {
 		buckGameConfig, err := getBucket(tx, bucketGameConfiguration)
 		if err != nil {
+			if _, ok := err.(*errorInexistantBucket); ok {
+				return nil
+			}
 			return err
 		}
 		managerBytes := buckGameConfig.Get([]byte(bucketGameConfiguration_managerSpreadsheet))
-		if err := json.Unmarshal(managerBytes, &spreadsheets.manager); err != nil {
-			return err
+		if managerBytes != nil {
+			if err := json.Unmarshal(managerBytes, &spreadsheets.manager); err != nil {
+				return err
+			}
 		}
 		buckTeamsSpreadsheets, err := getBucket(tx, bucketTeamsSpreadsheets)
 		if err != nil {
@@ -152,6 +448,8 @@ const (
 	ResponseStatusKO
 	ResponseStatusInQuestion
 	ResponseStatusNotChecked
+	ResponseStatusPartial
+	ResponseStatusNoAnswer
 )
 
 func (s ResponseStatus) String() string {
@@ -164,26 +462,102 @@ func (s ResponseStatus) String() string {
 		return "?"
 	case ResponseStatusNotChecked:
 		return "{}"
+	case ResponseStatusPartial:
+		return "±"
+	case ResponseStatusNoAnswer:
+		return "∅"
 	default:
 		return fmt.Sprintf("unexpected status %d", s)
 	}
 }
 
+// points returns the score a response with the status brings to the team.
+func (s ResponseStatus) points() float64 {
+	switch s {
+	case ResponseStatusOK:
+		return 1
+	case ResponseStatusPartial:
+		return 0.5
+	default:
+		return 0
+	}
+}
+
 type roundResponse struct {
-	Response string
-	Status   ResponseStatus
+	Response    string
+	Status      ResponseStatus
+	SubmittedAt time.Time
+	// Version is incremented on every change of the response, so that
+	// concurrent verdicts of several jurors are detected.
+	Version int
+	// SubResponses and SubStatuses are the answers of a blitz question and
+	// their statuses, Status is the combined status.
+	SubResponses []string         `json:",omitempty" yaml:",omitempty"`
+	SubStatuses  []ResponseStatus `json:",omitempty" yaml:",omitempty"`
+	// Source is responseSourceManual for the responses entered by the jury,
+	// empty for the fetched ones.
+	Source string `json:",omitempty" yaml:",omitempty"`
 }
 
 type roundResults struct {
 	Round   int
 	Results map[string]*roundResponse
+	// Quarantined are the fetched values that could not be taken as answers.
+	Quarantined []quarantinedEntry `json:",omitempty" yaml:",omitempty"`
+	// Meta are the jury notes on the round, they are stored apart from the
+	// results and attached when the results are shown.
+	Meta *roundMeta `json:"-" yaml:"-"`
+	// Question is the number of the round question in the tournament, it is
+	// set when the results are shown.
+	Question int `json:"-" yaml:"-"`
+}
+
+// question returns the number of the round question shown to the user.
+func (r *roundResults) question() int {
+	if r.Question == 0 {
+		return r.Round
+	}
+	return r.Question
 }
 
 func (r *roundResults) String() string {
 	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("Round %d results:\n", r.Round))
-	for team, result := range r.Results {
-		sb.WriteString(fmt.Sprintf("\t team %s: %s\t%v\n", team, result.Response, result.Status))
+	sb.WriteString(fmt.Sprintf("Round %d results:\n", r.question()))
+	teams := make([]string, 0, len(r.Results))
+	for team := range r.Results {
+		teams = append(teams, team)
+	}
+	sort.Strings(teams)
+	t := &table{
+		header: []string{tr("header.team"), tr("header.response"), tr("header.status"), tr("header.submitted")},
+		indent: "\t ",
+	}
+	for _, team := range teams {
+		result := r.Results[team]
+		response, _ := truncateAnswer(result.Response, answerDisplayWidth)
+		if result.Source == responseSourceManual {
+			response += " (manual)"
+		}
+		submittedAt := ""
+		if !result.SubmittedAt.IsZero() {
+			submittedAt = result.SubmittedAt.Local().Format("15:04:05")
+		}
+		t.addRow(plainCell(team), plainCell(response), statusCell(result.Status), plainCell(submittedAt))
+	}
+	sb.WriteString(t.String())
+	for _, q := range r.Quarantined {
+		sb.WriteString(fmt.Sprintf("\t quarantined %s\n", q))
+	}
+	if r.Meta != nil {
+		switch r.Meta.Void {
+		case roundVoidAll:
+			sb.WriteString("\t the question is voided, every team gets the point\n")
+		case roundVoidNone:
+			sb.WriteString("\t the question is voided, no team gets the point\n")
+		}
+		for _, note := range r.Meta.Notes {
+			sb.WriteString(fmt.Sprintf("\t note: %s\n", note))
+		}
 	}
 	return sb.String()
 }
@@ -198,7 +572,11 @@ func (b *boltManager) saveRoundResults(req *roundResults) error {
 		if err != nil {
 			return err
 		}
-		if err := buckGameResults.Put([]byte(strconv.Itoa(req.Round)), results); err != nil {
+		key := []byte(strconv.Itoa(req.Round))
+		if err := b.journal(tx, bucketGameResults, key, fmt.Sprintf("save round %d results", b.numbering.question(req.Round))); err != nil {
+			return err
+		}
+		if err := buckGameResults.Put(key, results); err != nil {
 			return err
 		}
 		return nil
@@ -209,6 +587,99 @@ func (b *boltManager) saveRoundResults(req *roundResults) error {
 	return nil
 }
 
+func (b *boltManager) getAllRoundResults() ([]*roundResults, error) {
+	allResults := make([]*roundResults, 0)
+	err := b.read(func(tx *bolt.Tx) error {
+		buckGameResults, err := getBucket(tx, bucketGameResults)
+		if err != nil {
+			if _, ok := err.(*errorInexistantBucket); ok {
+				return nil
+			}
+			return err
+		}
+		return buckGameResults.ForEach(func(_, resultsBytes []byte) error {
+			var results roundResults
+			if err := json.Unmarshal(resultsBytes, &results); err != nil {
+				return err
+			}
+			allResults = append(allResults, &results)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(allResults, func(i, j int) bool {
+		return allResults[i].Round < allResults[j].Round
+	})
+	return allResults, nil
+}
+
+// saveVerdicts stores the statuses of the checked responses. A verdict on a
+// response that has been changed since it was read (by another juror or by a
+// new fetch) is not stored and is reported in the errorVerdictConflict error,
+// the other verdicts are stored anyway. The stored results are returned.
+func (b *boltManager) saveVerdicts(checked *roundResults) (*roundResults, error) {
+	stored := &roundResults{}
+	conflicts := make([]string, 0)
+	err := b.update(func(tx *bolt.Tx) error {
+		buckGameResults, err := getBucket(tx, bucketGameResults)
+		if err != nil {
+			return err
+		}
+		key := []byte(strconv.Itoa(checked.Round))
+		storedBytes := buckGameResults.Get(key)
+		if len(storedBytes) == 0 {
+			return &errorRoundNotFound{round: b.numbering.question(checked.Round)}
+		}
+		if err := json.Unmarshal(storedBytes, stored); err != nil {
+			return err
+		}
+		for team, resp := range checked.Results {
+			storedResp, ok := stored.Results[team]
+			if !ok {
+				conflicts = append(conflicts, team)
+				continue
+			}
+			if storedResp.Status == resp.Status && sameSubStatuses(storedResp.SubStatuses, resp.SubStatuses) {
+				continue
+			}
+			if storedResp.Version != resp.Version {
+				conflicts = append(conflicts, team)
+				continue
+			}
+			storedResp.Status = resp.Status
+			storedResp.SubStatuses = resp.SubStatuses
+			storedResp.Version++
+		}
+		resultsBytes, err := json.Marshal(stored)
+		if err != nil {
+			return err
+		}
+		if err := b.journal(tx, bucketGameResults, key, fmt.Sprintf("check round %d", b.numbering.question(checked.Round))); err != nil {
+			return err
+		}
+		return buckGameResults.Put(key, resultsBytes)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(conflicts) != 0 {
+		sort.Strings(conflicts)
+		return stored, &errorVerdictConflict{round: b.numbering.question(checked.Round), teams: conflicts}
+	}
+	return stored, nil
+}
+
+type errorVerdictConflict struct {
+	round int
+	teams []string
+}
+
+func (e *errorVerdictConflict) Error() string {
+	return fmt.Sprintf("round %d responses of the teams %s have been changed concurrently, the verdicts on them are not saved, check them again", e.round, strings.Join(e.teams, ", "))
+}
+
 func (b *boltManager) getRoundResults(round int) (*roundResults, error) {
 	roundResults := &roundResults{}
 	err := b.read(func(tx *bolt.Tx) error {
@@ -221,7 +692,7 @@ func (b *boltManager) getRoundResults(round int) (*roundResults, error) {
 		}
 		results := buckGameResults.Get([]byte(strconv.Itoa(round)))
 		if len(results) == 0 {
-			return fmt.Errorf("round %d results are not found", round)
+			return &errorRoundNotFound{round: b.numbering.question(round)}
 		}
 		if err := json.Unmarshal(results, roundResults); err != nil {
 			return err
@@ -234,20 +705,13 @@ func (b *boltManager) getRoundResults(round int) (*roundResults, error) {
 	return roundResults, nil
 }
 
-func (b *boltManager) update(fn func(tx *bolt.Tx) error) error {
-	db, err := bolt.Open(b.dbFile, 0600, nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer db.Close()
-	err = db.Update(func(tx *bolt.Tx) error {
-		if err := createBuckets(tx); err != nil {
-			return err
-		}
-		if err := fn(tx); err != nil {
+func (b *boltManager) markSetupStep(step string) error {
+	err := b.update(func(tx *bolt.Tx) error {
+		buckSetupState, err := getBucket(tx, bucketSetupState)
+		if err != nil {
 			return err
 		}
-		return nil
+		return buckSetupState.Put([]byte(step), []byte("true"))
 	})
 	if err != nil {
 		return err
@@ -255,26 +719,1095 @@ func (b *boltManager) update(fn func(tx *bolt.Tx) error) error {
 	return nil
 }
 
-func (b *boltManager) read(fn func(tx *bolt.Tx) error) error {
-	db, err := bolt.Open(b.dbFile, 0600, nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer db.Close()
-	err = db.View(func(tx *bolt.Tx) error {
-		if err := fn(tx); err != nil {
+func (b *boltManager) getSetupSteps() (map[string]bool, error) {
+	steps := make(map[string]bool)
+	err := b.read(func(tx *bolt.Tx) error {
+		buckSetupState, err := getBucket(tx, bucketSetupState)
+		if err != nil {
+			if _, ok := err.(*errorInexistantBucket); ok {
+				return nil
+			}
 			return err
 		}
-		return nil
+		return buckSetupState.ForEach(func(step, _ []byte) error {
+			steps[string(step)] = true
+			return nil
+		})
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return steps, nil
+}
+
+// saveRoundLocks stores the IDs of the protected ranges locking the round
+// answers of the teams.
+func (b *boltManager) saveRoundLocks(round int, locks map[string]int64) error {
+	err := b.update(func(tx *bolt.Tx) error {
+		buckRoundLocks, err := getBucket(tx, bucketRoundLocks)
+		if err != nil {
+			return err
+		}
+		key := []byte(strconv.Itoa(round))
+		if len(locks) == 0 {
+			return buckRoundLocks.Delete(key)
+		}
+		locksBytes, err := json.Marshal(locks)
+		if err != nil {
+			return err
+		}
+		return buckRoundLocks.Put(key, locksBytes)
+	})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (b *boltManager) getRoundLocks(round int) (map[string]int64, error) {
+	locks := make(map[string]int64)
+	err := b.read(func(tx *bolt.Tx) error {
+		buckRoundLocks, err := getBucket(tx, bucketRoundLocks)
+		if err != nil {
+			if _, ok := err.(*errorInexistantBucket); ok {
+				return nil
+			}
+			return err
+		}
+		locksBytes := buckRoundLocks.Get([]byte(strconv.Itoa(round)))
+		if locksBytes == nil {
+			return nil
+		}
+		return json.Unmarshal(locksBytes, &locks)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return locks, nil
+}
+
+// saveClosedRound stores the time the round was closed.
+func (b *boltManager) saveClosedRound(round int, closedAt time.Time) error {
+	err := b.update(func(tx *bolt.Tx) error {
+		buckClosedRounds, err := getBucket(tx, bucketClosedRounds)
+		if err != nil {
+			return err
+		}
+		closedAtBytes, err := closedAt.MarshalText()
+		if err != nil {
+			return err
+		}
+		return buckClosedRounds.Put([]byte(strconv.Itoa(round)), closedAtBytes)
+	})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// getClosedRounds returns the closed rounds and the times they were closed.
+func (b *boltManager) getClosedRounds() (map[int]time.Time, error) {
+	closed := make(map[int]time.Time)
+	err := b.read(func(tx *bolt.Tx) error {
+		buckClosedRounds, err := getBucket(tx, bucketClosedRounds)
+		if err != nil {
+			if _, ok := err.(*errorInexistantBucket); ok {
+				return nil
+			}
+			return err
+		}
+		return buckClosedRounds.ForEach(func(k, v []byte) error {
+			round, err := strconv.Atoi(string(k))
+			if err != nil {
+				return err
+			}
+			var closedAt time.Time
+			if err := closedAt.UnmarshalText(v); err != nil {
+				return err
+			}
+			closed[round] = closedAt
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return closed, nil
+}
+
+func votesKey(round int, team string) []byte {
+	return []byte(fmt.Sprintf("%d/%s", round, team))
+}
+
+func (b *boltManager) saveVotes(round int, team string, votes *roundVotes) error {
+	err := b.update(func(tx *bolt.Tx) error {
+		buckVotes, err := getBucket(tx, bucketVotes)
+		if err != nil {
+			return err
+		}
+		votesBytes, err := json.Marshal(votes)
+		if err != nil {
+			return err
+		}
+		return buckVotes.Put(votesKey(round, team), votesBytes)
+	})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// getVotes returns the jury votes on the team response, or nil if no vote has
+// been cast.
+func (b *boltManager) getVotes(round int, team string) (*roundVotes, error) {
+	var votes *roundVotes
+	err := b.read(func(tx *bolt.Tx) error {
+		buckVotes, err := getBucket(tx, bucketVotes)
+		if err != nil {
+			if _, ok := err.(*errorInexistantBucket); ok {
+				return nil
+			}
+			return err
+		}
+		votesBytes := buckVotes.Get(votesKey(round, team))
+		if votesBytes == nil {
+			return nil
+		}
+		votes = &roundVotes{}
+		return json.Unmarshal(votesBytes, votes)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return votes, nil
+}
+
+func (b *boltManager) saveRoundMeta(round int, meta *roundMeta) error {
+	err := b.update(func(tx *bolt.Tx) error {
+		buckRoundMeta, err := getBucket(tx, bucketRoundMeta)
+		if err != nil {
+			return err
+		}
+		metaBytes, err := json.Marshal(meta)
+		if err != nil {
+			return err
+		}
+		return buckRoundMeta.Put([]byte(strconv.Itoa(round)), metaBytes)
+	})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// getRoundMeta returns the notes and the void mode of the round, or nil if
+// none are stored.
+func (b *boltManager) getRoundMeta(round int) (*roundMeta, error) {
+	var meta *roundMeta
+	err := b.read(func(tx *bolt.Tx) error {
+		buckRoundMeta, err := getBucket(tx, bucketRoundMeta)
+		if err != nil {
+			if _, ok := err.(*errorInexistantBucket); ok {
+				return nil
+			}
+			return err
+		}
+		metaBytes := buckRoundMeta.Get([]byte(strconv.Itoa(round)))
+		if metaBytes == nil {
+			return nil
+		}
+		meta = &roundMeta{}
+		return json.Unmarshal(metaBytes, meta)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return meta, nil
+}
+
+// saveResponseRevisions appends the fetched responses to the teams response
+// history, a response is appended only if it differs from the latest one.
+func (b *boltManager) saveResponseRevisions(round int, responses map[string]string, fetchedAt time.Time) error {
+	err := b.update(func(tx *bolt.Tx) error {
+		buckHistory, err := getBucket(tx, bucketResponseHistory)
+		if err != nil {
+			return err
+		}
+		for team, response := range responses {
+			key := votesKey(round, team)
+			var revisions []responseRevision
+			if revisionsBytes := buckHistory.Get(key); revisionsBytes != nil {
+				if err := json.Unmarshal(revisionsBytes, &revisions); err != nil {
+					return err
+				}
+			}
+			if len(revisions) != 0 && revisions[len(revisions)-1].Response == response {
+				continue
+			}
+			revisions = append(revisions, responseRevision{Response: response, FetchedAt: fetchedAt})
+			revisionsBytes, err := json.Marshal(revisions)
+			if err != nil {
+				return err
+			}
+			if err := buckHistory.Put(key, revisionsBytes); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// getResponseHistory returns the distinct responses of the team to the round
+// in the order they were fetched.
+func (b *boltManager) getResponseHistory(round int, team string) ([]responseRevision, error) {
+	var revisions []responseRevision
+	err := b.read(func(tx *bolt.Tx) error {
+		buckHistory, err := getBucket(tx, bucketResponseHistory)
+		if err != nil {
+			if _, ok := err.(*errorInexistantBucket); ok {
+				return nil
+			}
+			return err
+		}
+		revisionsBytes := buckHistory.Get(votesKey(round, team))
+		if revisionsBytes == nil {
+			return nil
+		}
+		return json.Unmarshal(revisionsBytes, &revisions)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+func (b *boltManager) saveShootout(shootout *shootoutState) error {
+	err := b.update(func(tx *bolt.Tx) error {
+		buckShootout, err := getBucket(tx, bucketShootout)
+		if err != nil {
+			return err
+		}
+		shootoutBytes, err := json.Marshal(shootout)
+		if err != nil {
+			return err
+		}
+		return buckShootout.Put([]byte("state"), shootoutBytes)
+	})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// getShootout returns the shootout of the game, or nil if none is started.
+func (b *boltManager) getShootout() (*shootoutState, error) {
+	var shootout *shootoutState
+	err := b.read(func(tx *bolt.Tx) error {
+		buckShootout, err := getBucket(tx, bucketShootout)
+		if err != nil {
+			if _, ok := err.(*errorInexistantBucket); ok {
+				return nil
+			}
+			return err
+		}
+		shootoutBytes := buckShootout.Get([]byte("state"))
+		if shootoutBytes == nil {
+			return nil
+		}
+		shootout = &shootoutState{}
+		return json.Unmarshal(shootoutBytes, shootout)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return shootout, nil
+}
+
+func (b *boltManager) saveStandingsSnapshot(snapshot *standingsSnapshot) error {
+	err := b.update(func(tx *bolt.Tx) error {
+		buckSnapshot, err := getBucket(tx, bucketStandingsSnapshot)
+		if err != nil {
+			return err
+		}
+		snapshotBytes, err := json.Marshal(snapshot)
+		if err != nil {
+			return err
+		}
+		return buckSnapshot.Put([]byte("snapshot"), snapshotBytes)
+	})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// getStandingsSnapshot returns the frozen standings, or nil if none is taken.
+func (b *boltManager) getStandingsSnapshot() (*standingsSnapshot, error) {
+	var snapshot *standingsSnapshot
+	err := b.read(func(tx *bolt.Tx) error {
+		buckSnapshot, err := getBucket(tx, bucketStandingsSnapshot)
+		if err != nil {
+			if _, ok := err.(*errorInexistantBucket); ok {
+				return nil
+			}
+			return err
+		}
+		snapshotBytes := buckSnapshot.Get([]byte("snapshot"))
+		if snapshotBytes == nil {
+			return nil
+		}
+		snapshot = &standingsSnapshot{}
+		return json.Unmarshal(snapshotBytes, snapshot)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+func (b *boltManager) saveAdjustment(adj scoreAdjustment) error {
+	err := b.update(func(tx *bolt.Tx) error {
+		buckAdjustments, err := getBucket(tx, bucketAdjustments)
+		if err != nil {
+			return err
+		}
+		id, err := buckAdjustments.NextSequence()
+		if err != nil {
+			return err
+		}
+		adjBytes, err := json.Marshal(&adj)
+		if err != nil {
+			return err
+		}
+		return buckAdjustments.Put(sequenceKey(id), adjBytes)
+	})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// getAdjustments returns the score adjustments in the order they are made.
+func (b *boltManager) getAdjustments() ([]scoreAdjustment, error) {
+	var adjustments []scoreAdjustment
+	err := b.read(func(tx *bolt.Tx) error {
+		buckAdjustments, err := getBucket(tx, bucketAdjustments)
+		if err != nil {
+			if _, ok := err.(*errorInexistantBucket); ok {
+				return nil
+			}
+			return err
+		}
+		return buckAdjustments.ForEach(func(_, v []byte) error {
+			var adj scoreAdjustment
+			if err := json.Unmarshal(v, &adj); err != nil {
+				return err
+			}
+			adjustments = append(adjustments, adj)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return adjustments, nil
+}
+
+// saveAuditEntry appends the entry to the audit log. As every command is
+// recorded, the database is not backed up after the write, the next update
+// backs the entry up.
+func (b *boltManager) saveAuditEntry(entry *auditEntry) error {
+	if b.readOnly {
+		return &errorReadOnly{}
+	}
+	b.dbMu.RLock()
+	defer b.dbMu.RUnlock()
+	if b.db == nil {
+		return fmt.Errorf("the database %s is closed", b.dbFile)
+	}
+	return b.db.Update(func(tx *bolt.Tx) error {
+		buckAudit, err := tx.CreateBucketIfNotExists([]byte(bucketAudit))
+		if err != nil {
+			return err
+		}
+		id, err := buckAudit.NextSequence()
+		if err != nil {
+			return err
+		}
+		entryBytes, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		return buckAudit.Put(sequenceKey(id), entryBytes)
+	})
+}
+
+// getAuditEntries returns the audit log in the order of the commands.
+func (b *boltManager) getAuditEntries() ([]*auditEntry, error) {
+	var entries []*auditEntry
+	err := b.read(func(tx *bolt.Tx) error {
+		buckAudit, err := getBucket(tx, bucketAudit)
+		if err != nil {
+			if _, ok := err.(*errorInexistantBucket); ok {
+				return nil
+			}
+			return err
+		}
+		return buckAudit.ForEach(func(_, v []byte) error {
+			entry := &auditEntry{}
+			if err := json.Unmarshal(v, entry); err != nil {
+				return err
+			}
+			entries = append(entries, entry)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// updateCache runs the update without backing the database up, the cache
+// entries change too often and the backups would push out the ones of the
+// results.
+func (b *boltManager) updateCache(fn func(buck *bolt.Bucket) error) error {
+	if b.readOnly {
+		return &errorReadOnly{}
+	}
+	b.dbMu.RLock()
+	defer b.dbMu.RUnlock()
+	if b.db == nil {
+		return fmt.Errorf("the database %s is closed", b.dbFile)
+	}
+	return b.db.Update(func(tx *bolt.Tx) error {
+		buckCache, err := tx.CreateBucketIfNotExists([]byte(bucketReadCache))
+		if err != nil {
+			return err
+		}
+		return fn(buckCache)
+	})
+}
+
+func (b *boltManager) saveCacheEntry(key string, value []byte) error {
+	return b.updateCache(func(buckCache *bolt.Bucket) error {
+		return buckCache.Put([]byte(key), value)
+	})
+}
+
+func (b *boltManager) getCacheEntry(key string) ([]byte, error) {
+	var value []byte
+	err := b.read(func(tx *bolt.Tx) error {
+		buckCache, err := getBucket(tx, bucketReadCache)
+		if err != nil {
+			if _, ok := err.(*errorInexistantBucket); ok {
+				return nil
+			}
+			return err
+		}
+		if v := buckCache.Get([]byte(key)); v != nil {
+			value = append([]byte(nil), v...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func (b *boltManager) deleteCacheEntries(prefix string) error {
+	return b.updateCache(func(buckCache *bolt.Bucket) error {
+		c := buckCache.Cursor()
+		for k, _ := c.Seek([]byte(prefix)); k != nil && strings.HasPrefix(string(k), prefix); k, _ = c.Seek([]byte(prefix)) {
+			if err := buckCache.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (b *boltManager) getAllRoundMeta() (map[int]*roundMeta, error) {
+	allMeta := make(map[int]*roundMeta)
+	err := b.read(func(tx *bolt.Tx) error {
+		buckRoundMeta, err := getBucket(tx, bucketRoundMeta)
+		if err != nil {
+			if _, ok := err.(*errorInexistantBucket); ok {
+				return nil
+			}
+			return err
+		}
+		return buckRoundMeta.ForEach(func(k, v []byte) error {
+			round, err := strconv.Atoi(string(k))
+			if err != nil {
+				return err
+			}
+			meta := &roundMeta{}
+			if err := json.Unmarshal(v, meta); err != nil {
+				return err
+			}
+			allMeta[round] = meta
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return allMeta, nil
+}
+
+// checkProgress holds the verdicts of an interrupted check, Versions are the
+// versions of the judged responses, so that a verdict is not applied to a
+// response fetched again since.
+type checkProgress struct {
+	Verdicts    map[string]ResponseStatus
+	SubVerdicts map[string][]ResponseStatus `json:",omitempty"`
+	Versions    map[string]int
+}
+
+// saveCheckProgress stores the check progress of the round, nil progress
+// deletes it.
+func (b *boltManager) saveCheckProgress(round int, progress *checkProgress) error {
+	err := b.update(func(tx *bolt.Tx) error {
+		buckCheckProgress, err := getBucket(tx, bucketCheckProgress)
+		if err != nil {
+			return err
+		}
+		key := []byte(strconv.Itoa(round))
+		if progress == nil {
+			return buckCheckProgress.Delete(key)
+		}
+		progressBytes, err := json.Marshal(progress)
+		if err != nil {
+			return err
+		}
+		return buckCheckProgress.Put(key, progressBytes)
+	})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// getCheckProgress returns the check progress of the round, or nil if there
+// is no interrupted check.
+func (b *boltManager) getCheckProgress(round int) (*checkProgress, error) {
+	var progress *checkProgress
+	err := b.read(func(tx *bolt.Tx) error {
+		buckCheckProgress, err := getBucket(tx, bucketCheckProgress)
+		if err != nil {
+			if _, ok := err.(*errorInexistantBucket); ok {
+				return nil
+			}
+			return err
+		}
+		progressBytes := buckCheckProgress.Get([]byte(strconv.Itoa(round)))
+		if progressBytes == nil {
+			return nil
+		}
+		progress = &checkProgress{}
+		return json.Unmarshal(progressBytes, progress)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return progress, nil
+}
+
+type gameEvent struct {
+	Time    time.Time
+	Message string
+}
+
+func (b *boltManager) appendEvent(message string) error {
+	err := b.update(func(tx *bolt.Tx) error {
+		buckEventLog, err := getBucket(tx, bucketEventLog)
+		if err != nil {
+			return err
+		}
+		id, err := buckEventLog.NextSequence()
+		if err != nil {
+			return err
+		}
+		eventBytes, err := json.Marshal(&gameEvent{
+			Time:    time.Now(),
+			Message: message,
+		})
+		if err != nil {
+			return err
+		}
+		return buckEventLog.Put(sequenceKey(id), eventBytes)
+	})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// lastActivity returns the time of the latest event of the game, or the
+// modification time of the database file if no event is logged or the game is
+// being run by another instance. A database that is not open is opened
+// read-only with a timeout.
+func (b *boltManager) lastActivity() (time.Time, error) {
+	info, err := os.Stat(b.dbFile)
+	if err != nil {
+		return time.Time{}, err
+	}
+	last := info.ModTime()
+	b.dbMu.RLock()
+	defer b.dbMu.RUnlock()
+	db := b.db
+	if db == nil {
+		db, err = bolt.Open(b.dbFile, 0600, &bolt.Options{ReadOnly: true, Timeout: boltLockTimeout})
+		if err == bolt.ErrTimeout {
+			// the game is being run by another instance
+			return last, nil
+		}
+		if err != nil {
+			return time.Time{}, err
+		}
+		defer db.Close()
+	}
+	err = db.View(func(tx *bolt.Tx) error {
+		buckEventLog, err := getBucket(tx, bucketEventLog)
+		if err != nil {
+			if _, ok := err.(*errorInexistantBucket); ok {
+				return nil
+			}
+			return err
+		}
+		_, v := buckEventLog.Cursor().Last()
+		if v == nil {
+			return nil
+		}
+		var event gameEvent
+		if err := json.Unmarshal(v, &event); err != nil {
+			return err
+		}
+		last = event.Time
+		return nil
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+	return last, nil
+}
+
+type bucketStats struct {
+	Name  string `json:"name" yaml:"name"`
+	Keys  int    `json:"keys" yaml:"keys"`
+	Bytes int    `json:"bytes" yaml:"bytes"`
+}
+
+func (b *boltManager) getBucketsStats() ([]bucketStats, error) {
+	stats := make([]bucketStats, 0)
+	err := b.read(func(tx *bolt.Tx) error {
+		return tx.ForEach(func(name []byte, buck *bolt.Bucket) error {
+			s := bucketStats{Name: string(name)}
+			err := buck.ForEach(func(k, v []byte) error {
+				s.Keys++
+				s.Bytes += len(k) + len(v)
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			stats = append(stats, s)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// compact rewrites the database into a new file, which drops the free pages
+// left after deletions, and replaces the database file with it.
+func (b *boltManager) compact() error {
+	b.dbMu.Lock()
+	defer b.dbMu.Unlock()
+	compactedFile := b.dbFile + ".compact"
+	if err := os.Remove(compactedFile); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	src := b.db
+	if src == nil {
+		return fmt.Errorf("the database %s is closed", b.dbFile)
+	}
+	dst, err := bolt.Open(compactedFile, 0600, nil)
+	if err != nil {
+		return err
+	}
+	err = src.View(func(srcTx *bolt.Tx) error {
+		return dst.Update(func(dstTx *bolt.Tx) error {
+			return srcTx.ForEach(func(name []byte, srcBuck *bolt.Bucket) error {
+				dstBuck, err := dstTx.CreateBucket(name)
+				if err != nil {
+					return err
+				}
+				dstBuck.FillPercent = 1
+				if err := dstBuck.SetSequence(srcBuck.Sequence()); err != nil {
+					return err
+				}
+				return srcBuck.ForEach(func(k, v []byte) error {
+					return dstBuck.Put(k, v)
+				})
+			})
+		})
+	})
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(compactedFile)
+		return err
+	}
+	if err := b.closeDB(); err != nil {
+		return err
+	}
+	if err := os.Rename(compactedFile, b.dbFile); err != nil {
+		return err
+	}
+	return b.openDB()
+}
+
+type journalEntry struct {
+	Time        time.Time
+	Description string
+	Bucket      string
+	Key         []byte
+	// Previous is the value before the mutation, nil if the key did not exist.
+	Previous []byte
+}
+
+// journal records the current value of the key before it is mutated, so that
+// the mutation can be undone. Only the journalSize latest entries are kept.
+func (b *boltManager) journal(tx *bolt.Tx, bucket string, key []byte, description string) error {
+	buck, err := getBucket(tx, bucket)
+	if err != nil {
+		return err
+	}
+	buckJournal, err := getBucket(tx, bucketJournal)
+	if err != nil {
+		return err
+	}
+	entry := &journalEntry{
+		Time:        time.Now(),
+		Description: description,
+		Bucket:      bucket,
+		Key:         key,
+	}
+	if previous := buck.Get(key); previous != nil {
+		entry.Previous = append([]byte(nil), previous...)
+	}
+	entryBytes, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	id, err := buckJournal.NextSequence()
+	if err != nil {
+		return err
+	}
+	if err := buckJournal.Put(sequenceKey(id), entryBytes); err != nil {
+		return err
+	}
+	size := b.journalSize
+	if size <= 0 {
+		size = 20
+	}
+	if id > uint64(size) {
+		return buckJournal.Delete(sequenceKey(id - uint64(size)))
+	}
+	return nil
+}
+
+// undo reverts the latest journaled mutation and returns its description.
+func (b *boltManager) undo() (string, error) {
+	var description string
+	err := b.update(func(tx *bolt.Tx) error {
+		buckJournal, err := getBucket(tx, bucketJournal)
+		if err != nil {
+			return err
+		}
+		c := buckJournal.Cursor()
+		k, v := c.Last()
+		if k == nil {
+			return fmt.Errorf("there is nothing to undo")
+		}
+		var entry journalEntry
+		if err := json.Unmarshal(v, &entry); err != nil {
+			return err
+		}
+		buck, err := getBucket(tx, entry.Bucket)
+		if err != nil {
+			return err
+		}
+		if entry.Previous == nil {
+			err = buck.Delete(entry.Key)
+		} else {
+			err = buck.Put(entry.Key, entry.Previous)
+		}
+		if err != nil {
+			return err
+		}
+		description = entry.Description
+		return buckJournal.Delete(k)
+	})
+	if err != nil {
+		return "", err
+	}
+	return description, nil
+}
+
+func sequenceKey(id uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, id)
+	return key
+}
+
+type archiveEntry struct {
+	Key   []byte
+	Value json.RawMessage
+}
+
+// dumpBuckets returns the contents and the sequences of all the buckets. All
+// the values stored by the tool are JSON documents.
+func (b *boltManager) dumpBuckets() (map[string][]archiveEntry, map[string]uint64, error) {
+	dump := make(map[string][]archiveEntry)
+	sequences := make(map[string]uint64)
+	err := b.read(func(tx *bolt.Tx) error {
+		return tx.ForEach(func(name []byte, buck *bolt.Bucket) error {
+			entries := make([]archiveEntry, 0)
+			err := buck.ForEach(func(k, v []byte) error {
+				if !json.Valid(v) {
+					return fmt.Errorf("bucket %s key %q value is not a valid JSON document", name, k)
+				}
+				entries = append(entries, archiveEntry{
+					Key:   append([]byte(nil), k...),
+					Value: append(json.RawMessage(nil), v...),
+				})
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			dump[string(name)] = entries
+			sequences[string(name)] = buck.Sequence()
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return dump, sequences, nil
+}
+
+// dropBuckets deletes every bucket of the database, all of them hold the
+// game data.
+func dropBuckets(tx *bolt.Tx) error {
+	names := make([][]byte, 0)
+	err := tx.ForEach(func(name []byte, _ *bolt.Bucket) error {
+		names = append(names, append([]byte(nil), name...))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if err := tx.DeleteBucket(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *boltManager) reset() error {
+	return b.update(dropBuckets)
+}
+
+// restoreBuckets replaces the contents of the database with the dump, the
+// buckets missing from the dump are left empty. The sequence of a bucket is
+// not set below its greatest sequence key, so that the archives without the
+// sequences do not make the new entries overwrite the restored ones.
+func (b *boltManager) restoreBuckets(dump map[string][]archiveEntry, sequences map[string]uint64) error {
+	err := b.update(func(tx *bolt.Tx) error {
+		if err := dropBuckets(tx); err != nil {
+			return err
+		}
+		for name, entries := range dump {
+			buck, err := tx.CreateBucket([]byte(name))
+			if err != nil {
+				return err
+			}
+			maxSeq := sequences[name]
+			for _, e := range entries {
+				if err := buck.Put(e.Key, e.Value); err != nil {
+					return err
+				}
+				if len(e.Key) == 8 {
+					if seq := binary.BigEndian.Uint64(e.Key); seq > maxSeq {
+						maxSeq = seq
+					}
+				}
+			}
+			if err := buck.SetSequence(maxSeq); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// boltLockTimeout is the time to wait for the lock of a database held by
+// another process.
+const boltLockTimeout = time.Second
+
+// open opens the database, the error says if the database is locked by
+// another process.
+func (b *boltManager) open() error {
+	b.dbMu.Lock()
+	defer b.dbMu.Unlock()
+	return b.openDB()
+}
+
+// openDB opens the database, dbMu is held by the caller.
+func (b *boltManager) openDB() error {
+	if b.readOnly {
+		if _, err := os.Stat(b.dbFile); err != nil {
+			return fmt.Errorf("failed to open the database %s: %w", b.dbFile, err)
+		}
+		return nil
+	}
+	db, err := bolt.Open(b.dbFile, 0600, &bolt.Options{Timeout: boltLockTimeout})
+	if err != nil {
+		if err == bolt.ErrTimeout {
+			return fmt.Errorf("the database %s is locked by another process, make sure that the game is not run twice: %w", b.dbFile, err)
+		}
+		return fmt.Errorf("failed to open the database %s: %w", b.dbFile, err)
+	}
+	if err := db.Update(migrateDriveFolder); err != nil {
+		db.Close()
+		return fmt.Errorf("failed to migrate the database %s: %w", b.dbFile, err)
+	}
+	b.db = db
+	return nil
+}
+
+func (b *boltManager) close() error {
+	b.dbMu.Lock()
+	defer b.dbMu.Unlock()
+	return b.closeDB()
+}
+
+// closeDB closes the database, dbMu is held by the caller.
+func (b *boltManager) closeDB() error {
+	if b.db == nil {
+		return nil
+	}
+	err := b.db.Close()
+	b.db = nil
+	return err
+}
+
+func (b *boltManager) update(fn func(tx *bolt.Tx) error) error {
+	if b.readOnly {
+		return &errorReadOnly{}
+	}
+	b.dbMu.RLock()
+	defer b.dbMu.RUnlock()
+	if b.db == nil {
+		return fmt.Errorf("the database %s is closed", b.dbFile)
+	}
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		if err := createBuckets(tx); err != nil {
+			return err
+		}
+		if err := fn(tx); err != nil {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	b.backup(b.db)
+	return nil
+}
+
+func (b *boltManager) read(fn func(tx *bolt.Tx) error) error {
+	if b.readOnly {
+		return b.readSnapshot(fn)
+	}
+	b.dbMu.RLock()
+	defer b.dbMu.RUnlock()
+	if b.db == nil {
+		return fmt.Errorf("the database %s is closed", b.dbFile)
+	}
+	err := b.db.View(func(tx *bolt.Tx) error {
+		if err := fn(tx); err != nil {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// snapshotAttempts is the number of the copies of the database taken by a
+// read-only session before giving up, a copy taken while the game instance
+// writes may be torn.
+const snapshotAttempts = 3
+
+// readSnapshot runs the read on a fresh copy of the database, so that the lock
+// held by the game instance is not waited for.
+func (b *boltManager) readSnapshot(fn func(tx *bolt.Tx) error) error {
+	var db *bolt.DB
+	for attempt := 1; db == nil; attempt++ {
+		snapshot, err := b.copySnapshot()
+		if err != nil {
+			return err
+		}
+		defer os.Remove(snapshot)
+		db, err = bolt.Open(snapshot, 0600, &bolt.Options{ReadOnly: true, Timeout: boltLockTimeout})
+		if err != nil && attempt == snapshotAttempts {
+			return fmt.Errorf("failed to read a copy of the database %s: %w", b.dbFile, err)
+		}
+	}
+	defer db.Close()
+	return db.View(fn)
+}
+
+func (b *boltManager) copySnapshot() (string, error) {
+	data, err := ioutil.ReadFile(b.dbFile)
+	if err != nil {
+		return "", err
+	}
+	f, err := ioutil.TempFile("", "bolt-db-snapshot-")
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), f.Close()
 }
 
 func createBuckets(tx *bolt.Tx) error {
-	buckets := []string{bucketGameConfiguration, bucketTeamsSpreadsheets, bucketGameResults}
+	buckets := []string{bucketGameConfiguration, bucketTeamsSpreadsheets, bucketGameResults, bucketArchivedTeams, bucketEventLog, bucketSetupState, bucketRoundLocks, bucketJournal, bucketCheckProgress, bucketClosedRounds, bucketVotes, bucketRoundMeta, bucketResponseHistory, bucketShootout, bucketStandingsSnapshot, bucketAdjustments, bucketAudit, bucketReadCache}
 	for _, buck := range buckets {
 		if _, err := tx.CreateBucketIfNotExists([]byte(buck)); err != nil {
 			return err
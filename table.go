@@ -0,0 +1,108 @@
+package main
+
+import (
+	"os"
+	"strings"
+)
+
+const (
+	colorReset  = "\033[0m"
+	colorRed    = "\033[31m"
+	colorGreen  = "\033[32m"
+	colorYellow = "\033[33m"
+	colorCyan   = "\033[36m"
+	colorGray   = "\033[90m"
+)
+
+// colorsEnabled reports whether the tables are colored, the colors are used
+// only when the standard output is a terminal.
+var colorsEnabled = false
+
+// setColors enables the colors unless disabled by the --no-color flag or the
+// NO_COLOR environment variable.
+func setColors(enabled bool) {
+	colorsEnabled = enabled && len(os.Getenv("NO_COLOR")) == 0 && isTerminal(os.Stdout)
+}
+
+type tableCell struct {
+	text  string
+	color string
+}
+
+func plainCell(text string) tableCell {
+	return tableCell{text: text}
+}
+
+func statusCell(s ResponseStatus) tableCell {
+	c := tableCell{text: s.String()}
+	switch s {
+	case ResponseStatusOK:
+		c.color = colorGreen
+	case ResponseStatusKO:
+		c.color = colorRed
+	case ResponseStatusInQuestion, ResponseStatusPartial:
+		c.color = colorYellow
+	case ResponseStatusNotChecked:
+		c.color = colorCyan
+	case ResponseStatusNoAnswer:
+		c.color = colorGray
+	}
+	return c
+}
+
+// table renders the rows in columns aligned by their display width, the
+// answers are expected to be truncated with truncateAnswer beforehand.
+type table struct {
+	header []string
+	rows   [][]tableCell
+	// indent is written before every line.
+	indent string
+}
+
+func (t *table) addRow(cells ...tableCell) {
+	t.rows = append(t.rows, cells)
+}
+
+func (t *table) String() string {
+	widths := make([]int, len(t.header))
+	for i, h := range t.header {
+		widths[i] = displayWidth(h)
+	}
+	for _, row := range t.rows {
+		for i, c := range row {
+			if i >= len(widths) {
+				widths = append(widths, 0)
+			}
+			if w := displayWidth(c.text); w > widths[i] {
+				widths[i] = w
+			}
+		}
+	}
+	var sb strings.Builder
+	writeRow := func(cells []tableCell) {
+		var line strings.Builder
+		for i, c := range cells {
+			if i != 0 {
+				line.WriteString("  ")
+			}
+			text := c.text
+			if colorsEnabled && len(c.color) != 0 {
+				text = c.color + text + colorReset
+			}
+			line.WriteString(text)
+			line.WriteString(strings.Repeat(" ", widths[i]-displayWidth(c.text)))
+		}
+		sb.WriteString(t.indent + strings.TrimRight(line.String(), " ") + "\n")
+	}
+	if len(t.header) != 0 {
+		header := make([]tableCell, len(t.header))
+		for i, h := range t.header {
+			header[i] = plainCell(h)
+		}
+		writeRow(header)
+	}
+	for _, row := range t.rows {
+		writeRow(row)
+	}
+	return sb.String()
+}
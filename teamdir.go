@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+)
+
+// teamDirectoryFile is the team directory of the tournament directory.
+const teamDirectoryFile = "teams.json"
+
+// DirectoryTeam is a team of the season team directory. The stable ID stays
+// the same when the team is renamed, so the games played under the previous
+// names are counted for the same team.
+type DirectoryTeam struct {
+	ID   string
+	Name string
+	// PreviousNames are the names the team has played under before.
+	PreviousNames []string
+	City          string
+	Captain       CaptainContacts
+	// RatingID is the ID of the team in the rating system, used by the rating
+	// export when TeamIDs has no ID for the team.
+	RatingID int
+}
+
+type CaptainContacts struct {
+	Name  string
+	Email string
+	Phone string
+}
+
+// teamDirectory is the teams.json file: a JSON array of the teams.
+type teamDirectory []*DirectoryTeam
+
+// readTeamDirectory reads and checks the team directory file.
+func readTeamDirectory(file string) (teamDirectory, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var dir teamDirectory
+	if err := json.Unmarshal(data, &dir); err != nil {
+		return nil, fmt.Errorf("failed to parse the team directory %s: %w", file, err)
+	}
+	if problems := dir.check(); len(problems) != 0 {
+		return nil, fmt.Errorf("team directory %s: %s", file, strings.Join(problems, "; "))
+	}
+	return dir, nil
+}
+
+// readTournamentTeams reads the teams.json of the tournament directory, the
+// directory is empty if there is no such file.
+func readTournamentTeams(dir string) (teamDirectory, error) {
+	teams, err := readTeamDirectory(path.Join(dir, teamDirectoryFile))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return teams, nil
+}
+
+func (d teamDirectory) check() []string {
+	var problems []string
+	ids := make(map[string]bool, len(d))
+	names := make(map[string]string, len(d))
+	for i, team := range d {
+		if len(team.ID) == 0 {
+			problems = append(problems, fmt.Sprintf("team #%d has no ID", i+1))
+		} else if ids[team.ID] {
+			problems = append(problems, fmt.Sprintf("ID %s is used by several teams", team.ID))
+		}
+		ids[team.ID] = true
+		if len(team.Name) == 0 {
+			problems = append(problems, fmt.Sprintf("team %s has no name", team.ID))
+		}
+		for _, name := range append([]string{team.Name}, team.PreviousNames...) {
+			if other, ok := names[name]; ok && other != team.ID {
+				problems = append(problems, fmt.Sprintf("name %s is used by the teams %s and %s", name, other, team.ID))
+			}
+			names[name] = team.ID
+		}
+	}
+	return problems
+}
+
+// byID returns the team with the stable ID.
+func (d teamDirectory) byID(id string) *DirectoryTeam {
+	for _, team := range d {
+		if team.ID == id {
+			return team
+		}
+	}
+	return nil
+}
+
+// byName returns the team with the current or a previous name.
+func (d teamDirectory) byName(name string) *DirectoryTeam {
+	for _, team := range d {
+		if team.Name == name {
+			return team
+		}
+	}
+	for _, team := range d {
+		for _, previous := range team.PreviousNames {
+			if previous == name {
+				return team
+			}
+		}
+	}
+	return nil
+}
+
+// resolveTeamDirectory adds the teams referenced by TeamRefs to Teams and
+// fills StableTeamIDs and the missing TeamIDs of the teams from the
+// directory.
+func (c *Config) resolveTeamDirectory() error {
+	if len(c.TeamDirectory) == 0 {
+		if len(c.TeamRefs) != 0 {
+			return fmt.Errorf("TeamRefs need the TeamDirectory")
+		}
+		return nil
+	}
+	dir, err := readTeamDirectory(c.TeamDirectory)
+	if err != nil {
+		return err
+	}
+	if c.StableTeamIDs == nil {
+		c.StableTeamIDs = make(map[string]string)
+	}
+	listed := make(map[string]bool, len(c.Teams))
+	for _, name := range c.Teams {
+		listed[name] = true
+	}
+	for _, id := range c.TeamRefs {
+		team := dir.byID(id)
+		if team == nil {
+			return fmt.Errorf("TeamRefs: team %s is not in the team directory %s", id, c.TeamDirectory)
+		}
+		if !listed[team.Name] {
+			c.Teams = append(c.Teams, team.Name)
+			listed[team.Name] = true
+		}
+		c.StableTeamIDs[team.Name] = team.ID
+	}
+	for _, name := range c.Teams {
+		team := dir.byName(name)
+		if team == nil {
+			continue
+		}
+		if _, ok := c.StableTeamIDs[name]; !ok {
+			c.StableTeamIDs[name] = team.ID
+		}
+		if _, ok := c.TeamIDs[name]; !ok && team.RatingID != 0 {
+			if c.TeamIDs == nil {
+				c.TeamIDs = make(map[string]int)
+			}
+			c.TeamIDs[name] = team.RatingID
+		}
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+// teamExtrasColumn is the zero-based column of the extra content of the team
+// spreadsheets, it is to the right of the answers grid so that the grid keeps
+// its layout.
+const teamExtrasColumn = 13
+
+// TeamSheetConfig is the extra content of the team spreadsheets. The texts
+// are templates in which {{.Team}} is replaced with the team name and
+// {{.Game}} with the game name.
+type TeamSheetConfig struct {
+	Header string
+	// Rules is written one line per cell under the header.
+	Rules   string
+	Contact string
+}
+
+type teamSheetData struct {
+	Team string
+	Game string
+}
+
+func (c *TeamSheetConfig) empty() bool {
+	return len(c.Header) == 0 && len(c.Rules) == 0 && len(c.Contact) == 0
+}
+
+// check parses the templates.
+func (c *TeamSheetConfig) check() []string {
+	var problems []string
+	texts := []struct {
+		name string
+		text string
+	}{{"Header", c.Header}, {"Rules", c.Rules}, {"Contact", c.Contact}}
+	for _, t := range texts {
+		if _, err := template.New(t.name).Parse(t.text); err != nil {
+			problems = append(problems, fmt.Sprintf("TeamSheet.%s is not a valid template: %v", t.name, err))
+		}
+	}
+	return problems
+}
+
+func renderTeamSheetText(text string, data *teamSheetData) (string, error) {
+	if len(text) == 0 {
+		return "", nil
+	}
+	tmpl, err := template.New("").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// fillTeamSheetExtras writes the header, the rules and the jury contact to the
+// team spreadsheet. The texts are written as strings, so that a text starting
+// with = is not taken for a formula.
+func (a *app) fillTeamSheetExtras(teamName string, team *sheets.Spreadsheet, batch *setupBatch) error {
+	c := &a.config.TeamSheet
+	if c.empty() {
+		return nil
+	}
+	data := &teamSheetData{Team: teamName, Game: a.config.GameName}
+	header, err := renderTeamSheetText(c.Header, data)
+	if err != nil {
+		return fmt.Errorf("failed to render the team sheet header: %w", err)
+	}
+	rules, err := renderTeamSheetText(c.Rules, data)
+	if err != nil {
+		return fmt.Errorf("failed to render the team sheet rules: %w", err)
+	}
+	contact, err := renderTeamSheetText(c.Contact, data)
+	if err != nil {
+		return fmt.Errorf("failed to render the team sheet contact: %w", err)
+	}
+	lines := []string{header, ""}
+	if len(rules) != 0 {
+		lines = append(lines, strings.Split(strings.TrimRight(rules, "\n"), "\n")...)
+		lines = append(lines, "")
+	}
+	lines = append(lines, contact)
+	var sheetID int64
+	if len(team.Sheets) != 0 {
+		sheetID = team.Sheets[0].Properties.SheetId
+	}
+	rows := make([]*sheets.RowData, len(lines))
+	for i, line := range lines {
+		rows[i] = &sheets.RowData{
+			Values: []*sheets.CellData{{UserEnteredValue: &sheets.ExtendedValue{StringValue: line}}},
+		}
+	}
+	batch.addRequests(&sheets.Request{
+		UpdateCells: &sheets.UpdateCellsRequest{
+			Start:  &sheets.GridCoordinate{SheetId: sheetID, ColumnIndex: teamExtrasColumn},
+			Rows:   rows,
+			Fields: "userEnteredValue",
+		},
+	})
+	if len(header) == 0 {
+		return nil
+	}
+	batch.addRequests(&sheets.Request{
+		RepeatCell: &sheets.RepeatCellRequest{
+			Range: &sheets.GridRange{
+				SheetId:          sheetID,
+				StartColumnIndex: teamExtrasColumn,
+				EndColumnIndex:   teamExtrasColumn + 1,
+				StartRowIndex:    0,
+				EndRowIndex:      1,
+			},
+			Cell: &sheets.CellData{
+				UserEnteredFormat: &sheets.CellFormat{
+					TextFormat: &sheets.TextFormat{Bold: true, FontSize: 14},
+				},
+			},
+			Fields: "userEnteredFormat.textFormat",
+		},
+	})
+	return nil
+}
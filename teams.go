@@ -0,0 +1,222 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+func (a *app) CmdAddTeam(cmdStr string) error {
+	team, err := getTeamName(cmdStr)
+	if err != nil {
+		return fmt.Errorf("failed to parse addTeam request: %w", err)
+	}
+	for _, t := range a.config.Teams {
+		if t == team {
+			return fmt.Errorf("team %s is already registered", team)
+		}
+	}
+	teamSheet, err := a.createTeamSpreadsheet(team)
+	if err != nil {
+		return err
+	}
+	storeSheets := &storeGameSpreadsheets{
+		teams: map[string]*storeSpreadsheet{
+			team: newStoreSpreadsheet(teamSheet),
+		},
+	}
+	if err := a.store.saveTeamsSpreadsheets(storeSheets); err != nil {
+		return err
+	}
+	if err := a.fillTeamSpreadsheet(team, teamSheet); err != nil {
+		return err
+	}
+	if err := a.store.markSetupStep(setupStepTeamFilled(team)); err != nil {
+		return err
+	}
+	previousTeams := len(a.config.Teams)
+	a.config.Teams = append(a.config.Teams, team)
+	if err := a.store.saveTeams(a.config.Teams); err != nil {
+		return err
+	}
+	if err := a.relayoutManagerSpreadsheet(previousTeams); err != nil {
+		return err
+	}
+	fmt.Printf("Team %s is added: %s\n", team, teamSheet.SpreadsheetUrl)
+	return nil
+}
+
+func (a *app) CmdRemoveTeam(cmdStr string) error {
+	team, err := getTeamName(cmdStr)
+	if err != nil {
+		return fmt.Errorf("failed to parse removeTeam request: %w", err)
+	}
+	teamIndex := -1
+	for i, t := range a.config.Teams {
+		if t == team {
+			teamIndex = i
+			break
+		}
+	}
+	if teamIndex == -1 {
+		return fmt.Errorf("team %s is not registered", team)
+	}
+	gameSheets, err := a.GetGameSpreadsheets()
+	if err != nil {
+		return err
+	}
+	if teamSheet, ok := gameSheets.teams[team]; ok {
+		if err := a.archiveTeamSpreadsheet(team, teamSheet); err != nil {
+			return err
+		}
+	}
+	if err := a.store.archiveTeamSpreadsheet(team); err != nil {
+		return err
+	}
+	previousTeams := len(a.config.Teams)
+	teams := make([]string, 0, len(a.config.Teams)-1)
+	teams = append(teams, a.config.Teams[:teamIndex]...)
+	teams = append(teams, a.config.Teams[teamIndex+1:]...)
+	a.config.Teams = teams
+	if err := a.store.saveTeams(a.config.Teams); err != nil {
+		return err
+	}
+	if err := a.relayoutManagerSpreadsheet(previousTeams); err != nil {
+		return err
+	}
+	fmt.Printf("Team %s is removed\n", team)
+	return nil
+}
+
+func (a *app) archiveTeamSpreadsheet(team string, teamSheet *storeSpreadsheet) error {
+	spreadsheetsService := sheets.NewSpreadsheetsService(a.service)
+	_, err := spreadsheetsService.BatchUpdate(teamSheet.ID, &sheets.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheets.Request{
+			{
+				UpdateSpreadsheetProperties: &sheets.UpdateSpreadsheetPropertiesRequest{
+					Properties: &sheets.SpreadsheetProperties{
+						Title: a.config.Labels.ArchivedPrefix + a.config.Labels.teamTitle(a.config.GameName, team),
+					},
+					Fields: "title",
+				},
+			},
+		},
+	}).Context(a.commandContext()).Do()
+	if err != nil {
+		return fmt.Errorf("failed to archive the team %s spreadsheet: %w", team, err)
+	}
+	log.Printf("archived the team %s spreadsheet: %s", team, teamSheet.URL)
+	return nil
+}
+
+// relayoutManagerSpreadsheet clears the answer groups laid out for the
+// previous number of teams and fills the manager spreadsheet again, as the
+// layout of the groups depends on the number of teams.
+func (a *app) relayoutManagerSpreadsheet(previousTeams int) error {
+	storeSheets, err := a.GetGameSpreadsheets()
+	if err != nil {
+		return err
+	}
+	if storeSheets.manager == nil {
+		return errManagerSpreadsheetNotFound
+	}
+	gameSheets := newGameSpreadsheets(storeSheets)
+	for _, team := range a.config.Teams {
+		if _, ok := gameSheets.teams[team]; !ok {
+			return fmt.Errorf("spreadsheet of the team %s is not found", team)
+		}
+	}
+	// only the answer groups of the previous layout are cleared, the cells
+	// around them, e.g. the jury notes, are kept
+	teams := len(a.config.Teams)
+	if previousTeams > teams {
+		teams = previousTeams
+	}
+	valuesService := sheets.NewSpreadsheetsValuesService(a.service)
+	_, err = valuesService.Clear(gameSheets.manager.SpreadsheetId, sheetRange(firstSheetTitle(gameSheets.manager), a.managerLayoutRange(teams)), &sheets.ClearValuesRequest{}).Context(a.commandContext()).Do()
+	if err != nil {
+		return fmt.Errorf("failed to clear the manager spreadsheet: %w", err)
+	}
+	if a.config.MaskAnswers {
+		if _, err := a.ensureRawSheet(gameSheets.manager.SpreadsheetId); err != nil {
+			return err
+		}
+		_, err = valuesService.Clear(gameSheets.manager.SpreadsheetId, rawSheetTitle, &sheets.ClearValuesRequest{}).Context(a.commandContext()).Do()
+		if err != nil {
+			return fmt.Errorf("failed to clear the raw sheet: %w", err)
+		}
+	}
+	if err := a.fillManagerSpreadsheet(gameSheets.manager); err != nil {
+		return err
+	}
+	if err := a.linkManagerTeams(gameSheets); err != nil {
+		return err
+	}
+	if a.config.MaskAnswers {
+		if err := a.revealClosedRounds(); err != nil {
+			return err
+		}
+	}
+	if err := a.remarkAllStatuses(gameSheets.manager.SpreadsheetId); err != nil {
+		return err
+	}
+	return a.rewriteHistory(gameSheets.manager.SpreadsheetId)
+}
+
+// managerLayoutRange returns the A1 range of the answer groups of the manager
+// sheet laid out for the number of teams, see getManagerRange.
+func (a *app) managerLayoutRange(teams int) string {
+	groups, width := 0, 0
+	if a.config.HasWarmUpQuestion {
+		groups, width = 1, 1
+	}
+	groups += (a.config.NumberOfQuestions + questionsGroupLength - 1) / questionsGroupLength
+	if a.config.NumberOfQuestions > width {
+		width = a.config.NumberOfQuestions
+	}
+	if width > questionsGroupLength {
+		width = questionsGroupLength
+	}
+	return rangeName(0, 1, width, groups*(teams+2))
+}
+
+// remarkAllStatuses rewrites the statuses sheet of the manager spreadsheet,
+// if it exists, according to the current layout.
+func (a *app) remarkAllStatuses(managerID string) error {
+	steps, err := a.store.getSetupSteps()
+	if err != nil {
+		return err
+	}
+	if !steps[setupStepStatusesSheet] {
+		return nil
+	}
+	valuesService := sheets.NewSpreadsheetsValuesService(a.service)
+	_, err = valuesService.Clear(managerID, statusesSheetTitle, &sheets.ClearValuesRequest{}).Context(a.commandContext()).Do()
+	if err != nil {
+		return fmt.Errorf("failed to clear the statuses sheet: %w", err)
+	}
+	allResults, err := a.store.getAllRoundResults()
+	if err != nil {
+		return err
+	}
+	for _, results := range allResults {
+		if _, err := a.markStatuses(results); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func getTeamName(cmdStr string) (string, error) {
+	sSplitted := splitArgs(cmdStr)
+	if len(sSplitted) < 2 {
+		return "", fmt.Errorf("expected a team name")
+	}
+	team := strings.TrimSpace(strings.Join(sSplitted[1:], " "))
+	if len(team) == 0 {
+		return "", fmt.Errorf("team name cannot be empty")
+	}
+	return team, nil
+}
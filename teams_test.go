@@ -0,0 +1,45 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+func TestManagerLayoutRange(t *testing.T) {
+	tests := []struct {
+		questions int
+		warmUp    bool
+		teams     int
+		name      string
+	}{
+		{1, true, 3, "A1:B10"},
+		{5, false, 200, "A1:F202"},
+		{12, false, 3, "A1:M5"},
+		{36, true, 3, "A1:M20"},
+		{100, false, 500, "A1:M4518"},
+	}
+	for _, tt := range tests {
+		a := &app{config: &Config{NumberOfQuestions: tt.questions, HasWarmUpQuestion: tt.warmUp, Teams: testTeams(tt.teams)}}
+		got := a.managerLayoutRange(tt.teams)
+		if got != tt.name {
+			t.Errorf("managerLayoutRange(%d) with %d questions = %s, want %s", tt.teams, tt.questions, got, tt.name)
+		}
+		// the layout ends with the last answer group of the manager sheet
+		groups, err := a.createGroups(func(length int, _ int, groups []*sheets.ValueRange) ([]*sheets.ValueRange, error) {
+			return append(groups, &sheets.ValueRange{Range: a.getManagerRange(len(groups), length)}), nil
+		})
+		if err != nil {
+			t.Fatalf("createGroups with %d questions: %v", tt.questions, err)
+		}
+		if last := groups[len(groups)-1].Range; endRow(last) != endRow(got) {
+			t.Errorf("the last manager group %s with %d questions does not end with the layout %s", last, tt.questions, got)
+		}
+	}
+}
+
+// endRow returns the row of the end cell of the A1 range.
+func endRow(r string) string {
+	return strings.TrimLeft(r[strings.Index(r, ":")+1:], "ABCDEFGHIJKLMNOPQRSTUVWXYZ")
+}
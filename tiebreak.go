@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func (a *app) CmdTiebreak(cmdStr string) (*tiebreakResult, error) {
+	teamA, teamB, err := getTiebreakTeams(cmdStr, a.config.Teams)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse tiebreak request: %w", err)
+	}
+	var winner, details string
+	switch a.config.Tiebreak.Procedure {
+	case TiebreakProcedureClosest:
+		winner, details, err = tiebreakClosest(teamA, teamB)
+	default:
+		winner, details = tiebreakRandom(teamA, teamB, a.config.Tiebreak.Seed)
+	}
+	if err != nil {
+		return nil, err
+	}
+	event := fmt.Sprintf("tiebreak %s vs %s (%s): %s, winner: %s", teamA, teamB, a.config.Tiebreak.Procedure, details, winner)
+	log.Println(event)
+	if err := a.store.appendEvent(event); err != nil {
+		return nil, fmt.Errorf("failed to record the tiebreak in the event log: %w", err)
+	}
+	res := &tiebreakResult{
+		TeamA:     teamA,
+		TeamB:     teamB,
+		Procedure: a.config.Tiebreak.Procedure,
+		Details:   details,
+		Winner:    winner,
+	}
+	return res, nil
+}
+
+func tiebreakRandom(teamA string, teamB string, seed int64) (string, string) {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	r := rand.New(rand.NewSource(seed))
+	winner := teamA
+	if r.Intn(2) == 1 {
+		winner = teamB
+	}
+	return winner, fmt.Sprintf("seed %d", seed)
+}
+
+func tiebreakClosest(teamA string, teamB string) (string, string, error) {
+	reader := bufio.NewReader(os.Stdin)
+	correct, err := readNumber(reader, "Correct answer: ")
+	if err != nil {
+		return "", "", err
+	}
+	answerA, err := readNumber(reader, fmt.Sprintf("Team %s answer: ", teamA))
+	if err != nil {
+		return "", "", err
+	}
+	answerB, err := readNumber(reader, fmt.Sprintf("Team %s answer: ", teamB))
+	if err != nil {
+		return "", "", err
+	}
+	details := fmt.Sprintf("correct answer %v, %s answered %v, %s answered %v", correct, teamA, answerA, teamB, answerB)
+	diffA := abs(correct - answerA)
+	diffB := abs(correct - answerB)
+	switch {
+	case diffA < diffB:
+		return teamA, details, nil
+	case diffB < diffA:
+		return teamB, details, nil
+	}
+	return "", "", fmt.Errorf("teams answers are equally close to the correct one, repeat the tiebreak")
+}
+
+func readNumber(reader *bufio.Reader, prompt string) (float64, error) {
+	for {
+		fmt.Print(prompt)
+		s, err := reader.ReadString('\n')
+		if err != nil {
+			return 0, fmt.Errorf("failed to scan the number: %w", err)
+		}
+		n, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		if err != nil {
+			fmt.Println("Not a number, try again")
+			continue
+		}
+		return n, nil
+	}
+}
+
+func abs(x float64) float64 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func getTiebreakTeams(cmdStr string, teams []string) (string, string, error) {
+	sSplitted := splitArgs(cmdStr)
+	if len(sSplitted) != 3 {
+		return "", "", fmt.Errorf("expected 2 arguments, got %d", len(sSplitted)-1)
+	}
+	teamA, teamB := sSplitted[1], sSplitted[2]
+	if teamA == teamB {
+		return "", "", fmt.Errorf("a team cannot tiebreak against itself")
+	}
+	for _, team := range []string{teamA, teamB} {
+		found := false
+		for _, t := range teams {
+			if t == team {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return "", "", fmt.Errorf("team %s is unknown", team)
+		}
+	}
+	return teamA, teamB, nil
+}
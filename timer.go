@@ -0,0 +1,265 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+const (
+	timerEventStart          = "start"
+	timerEventTenSecondsLeft = "10-seconds-left"
+	timerEventEnd            = "end"
+	timerEventAllAnswered    = "all-answered"
+)
+
+// CueConfig describes what happens when the timer reaches a mark. Both the
+// sound file and the HTTP trigger are optional.
+type CueConfig struct {
+	// SoundFile is played with the AudioCues.Player command.
+	SoundFile string
+	// URL receives a POST request with the timer event.
+	URL string
+}
+
+type AudioCuesConfig struct {
+	// Player is the command that plays a sound file passed as its last
+	// argument, e.g. "aplay" or "afplay".
+	Player         string
+	Start          CueConfig
+	TenSecondsLeft CueConfig
+	End            CueConfig
+	// AllAnswered is played by watch when every team has answered.
+	AllAnswered CueConfig
+}
+
+type TimerConfig struct {
+	// ThinkingSeconds and WritingSeconds make up the default timer duration,
+	// 60 and 10 seconds by default.
+	ThinkingSeconds int
+	WritingSeconds  int
+	// Cell of the team spreadsheets where the remaining time is written, e.g.
+	// "N1". The remaining time is not written if empty.
+	Cell string
+	// LockOnEnd locks the round answers when the time is up.
+	LockOnEnd bool
+	// WatchIntervalSeconds is the interval of the watch polls of the round
+	// answers, 10 by default.
+	WatchIntervalSeconds int
+}
+
+func (c *TimerConfig) watchInterval() time.Duration {
+	if c.WatchIntervalSeconds <= 0 {
+		return 10 * time.Second
+	}
+	return time.Duration(c.WatchIntervalSeconds) * time.Second
+}
+
+func (c *TimerConfig) duration() int {
+	thinking, writing := c.ThinkingSeconds, c.WritingSeconds
+	if thinking <= 0 {
+		thinking = 60
+	}
+	if writing <= 0 {
+		writing = 10
+	}
+	return thinking + writing
+}
+
+type roundTimer struct {
+	mu   sync.Mutex
+	stop chan struct{}
+}
+
+type timerResult struct {
+	Message string `json:"message" yaml:"message"`
+}
+
+func (r *timerResult) String() string {
+	return r.Message
+}
+
+// CmdTimer starts the countdown: "timer [seconds] [round]". Without the
+// seconds the configured duration is used. If the round is given, its answers
+// are locked when the time is up.
+func (a *app) CmdTimer(cmdStr string) (*timerResult, error) {
+	sSplitted := strings.Split(cmdStr, " ")
+	if len(sSplitted) > 3 {
+		return nil, fmt.Errorf("expected at most 2 arguments, got %d", len(sSplitted)-1)
+	}
+	if len(sSplitted) == 2 && sSplitted[1] == "stop" {
+		if !a.timer.cancel() {
+			return &timerResult{Message: "No timer is running"}, nil
+		}
+		return &timerResult{Message: "Timer is stopped"}, nil
+	}
+	seconds := a.config.Timer.duration()
+	if len(sSplitted) >= 2 {
+		var err error
+		seconds, err = strconv.Atoi(sSplitted[1])
+		if err != nil || seconds <= 0 {
+			return nil, fmt.Errorf("failed to parse argument %s as a positive number of seconds", sSplitted[1])
+		}
+	}
+	round := -1
+	if len(sSplitted) == 3 {
+		var err error
+		round, err = a.getRoundNumber(strings.Join(sSplitted[1:], " "))
+		if err != nil {
+			return nil, err
+		}
+		if _, _, err := a.getTeamRoundCellPosition(round); err != nil {
+			return nil, err
+		}
+	}
+	stop, err := a.timer.start()
+	if err != nil {
+		return nil, err
+	}
+	go a.runTimer(seconds, round, stop)
+	return &timerResult{Message: fmt.Sprintf("Timer is started for %d seconds", seconds)}, nil
+}
+
+func (t *roundTimer) start() (chan struct{}, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.stop != nil {
+		return nil, fmt.Errorf("a timer is already running, stop it first")
+	}
+	t.stop = make(chan struct{})
+	return t.stop, nil
+}
+
+func (t *roundTimer) cancel() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.stop == nil {
+		return false
+	}
+	close(t.stop)
+	t.stop = nil
+	return true
+}
+
+func (t *roundTimer) running() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.stop != nil
+}
+
+func (t *roundTimer) finish(stop chan struct{}) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.stop == stop {
+		t.stop = nil
+	}
+}
+
+// runTimer counts down every second, announcing the remaining time every 10
+// seconds in the terminal and, if configured, in the team spreadsheets. The
+// timer outlives the command that starts it, so its API calls are not
+// cancelled with the running command.
+func (a *app) runTimer(seconds int, round int, stop chan struct{}) {
+	defer a.timer.finish(stop)
+	ctx := context.Background()
+	a.fireCue(timerEventStart, a.config.AudioCues.Start)
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for remaining := seconds; remaining > 0; remaining-- {
+		if remaining%10 == 0 || remaining <= 5 {
+			fmt.Printf("\n%d seconds left\n", remaining)
+		}
+		if remaining%10 == 0 {
+			a.writeRemainingTime(ctx, remaining)
+		}
+		if remaining == 10 {
+			a.fireCue(timerEventTenSecondsLeft, a.config.AudioCues.TenSecondsLeft)
+		}
+		select {
+		case <-ticker.C:
+		case <-stop:
+			a.writeRemainingTime(ctx, 0)
+			return
+		}
+	}
+	fmt.Println("\nTime is up!")
+	a.fireCue(timerEventEnd, a.config.AudioCues.End)
+	a.writeRemainingTime(ctx, 0)
+	if round >= 0 && a.config.Timer.LockOnEnd {
+		if _, err := a.lockRound(ctx, round); err != nil {
+			log.Printf("[ERR]: failed to lock the round %d when the time is up: %v", a.config.questionNumber(round), err)
+		}
+	}
+}
+
+// writeRemainingTime writes the remaining time into the configured cell of
+// every team spreadsheet. Zero clears the cell.
+func (a *app) writeRemainingTime(ctx context.Context, remaining int) {
+	if len(a.config.Timer.Cell) == 0 {
+		return
+	}
+	gameSheets, err := a.GetGameSpreadsheets()
+	if err != nil {
+		log.Printf("[ERR]: failed to write the remaining time: %v", err)
+		return
+	}
+	var value interface{} = ""
+	if remaining > 0 {
+		value = remaining
+	}
+	valuesService := sheets.NewSpreadsheetsValuesService(a.service)
+	for team, teamSheet := range gameSheets.teams {
+		cell := sheetRange(teamSheet.toSpreadsheet().Sheets[0].Properties.Title, a.config.Timer.Cell)
+		_, err := valuesService.Update(teamSheet.ID, cell, &sheets.ValueRange{
+			Values: [][]interface{}{{value}},
+		}).ValueInputOption("RAW").Context(ctx).Do()
+		if err != nil {
+			log.Printf("[ERR]: failed to write the remaining time to the team %s spreadsheet: %v", team, err)
+		}
+	}
+}
+
+func (a *app) fireCue(event string, cue CueConfig) {
+	log.Printf("timer: %s", event)
+	if len(cue.SoundFile) != 0 && len(a.config.AudioCues.Player) != 0 {
+		playerArgs := strings.Fields(a.config.AudioCues.Player)
+		playerArgs = append(playerArgs, cue.SoundFile)
+		cmd := exec.Command(playerArgs[0], playerArgs[1:]...)
+		if err := cmd.Start(); err != nil {
+			log.Printf("[ERR]: failed to play the %s cue %s: %v", event, cue.SoundFile, err)
+		} else {
+			go cmd.Wait()
+		}
+	}
+	if len(cue.URL) != 0 {
+		go triggerCueURL(event, cue.URL)
+	}
+}
+
+func triggerCueURL(event string, url string) {
+	body, err := json.Marshal(map[string]string{"event": event})
+	if err != nil {
+		log.Printf("[ERR]: failed to marshal the %s cue: %v", event, err)
+		return
+	}
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		log.Printf("[ERR]: failed to trigger the %s cue %s: %v", event, url, err)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		log.Printf("[ERR]: the %s cue %s responded with status %s", event, url, resp.Status)
+	}
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"google.golang.org/api/script/v1"
+	"google.golang.org/api/sheets/v4"
+)
+
+// submissionTimesSheetTitle is the hidden sheet of the team spreadsheets
+// where the submission time script records the answer times. Only the owner
+// of the spreadsheets can edit it, so the teams cannot change the times.
+const submissionTimesSheetTitle = "Submission times"
+
+// submissionTimeInstallFunction replaces the triggers of the script with the
+// installable edit trigger, it is run by the tool on the owner's behalf.
+const submissionTimeInstallFunction = "installSubmissionTimeTrigger"
+
+// submissionTimeScript records the edit time of an answer cell into the same
+// cell of the submission times sheet, prefixed with a quote so that it is
+// stored as plain text. recordSubmissionTime is an installable edit trigger:
+// unlike the simple onEdit trigger, which runs as the editing team, it runs as
+// the owner and can write into the protected sheet.
+const submissionTimeScript = `function recordSubmissionTime(e) {
+  var range = e.range;
+  if (range.getSheet().getIndex() !== 1) {
+    return;
+  }
+  var times = e.source.getSheetByName(%q);
+  var now = "'" + new Date().toISOString();
+  for (var i = 0; i < range.getNumRows(); i++) {
+    for (var j = 0; j < range.getNumColumns(); j++) {
+      var row = range.getRow() + i;
+      var column = range.getColumn() + j;
+      if (%s) {
+        times.getRange(row, column).setValue(now);
+      }
+    }
+  }
+}
+
+function installSubmissionTimeTrigger() {
+  ScriptApp.getProjectTriggers().forEach(function(trigger) {
+    ScriptApp.deleteTrigger(trigger);
+  });
+  ScriptApp.newTrigger("recordSubmissionTime").forSpreadsheet(SpreadsheetApp.getActive()).onEdit().create();
+}
+`
+
+// The answer cell conditions of the script: in the horizontal layout the
+// answers are in every third row, in the vertical one in the second column.
+const (
+	submissionTimeHorizontalCell = "row % 3 === 2"
+	submissionTimeVerticalCell   = "column === 2"
+)
+
+// scriptAppScope lets the script install its trigger, the scripts run through
+// the Apps Script API need the caller to hold all the scopes of the script.
+const scriptAppScope = "https://www.googleapis.com/auth/script.scriptapp"
+
+const submissionTimeManifest = `{
+  "timeZone": "Etc/UTC",
+  "exceptionLogging": "STACKDRIVER",
+  "oauthScopes": [
+    "https://www.googleapis.com/auth/spreadsheets",
+    "https://www.googleapis.com/auth/script.scriptapp"
+  ],
+  "executionApi": {
+    "access": "MYSELF"
+  }
+}
+`
+
+// ensureSubmissionTimesSheet adds the submission times sheet hidden and
+// protected to the team spreadsheet if needed.
+func (a *app) ensureSubmissionTimesSheet(teamID string) error {
+	team, err := a.getSpreadsheetMetadata(a.commandContext(), teamID)
+	if err != nil {
+		return err
+	}
+	if _, ok := team.sheetByTitle(submissionTimesSheetTitle); ok {
+		return nil
+	}
+	spreadsheetsService := sheets.NewSpreadsheetsService(a.service)
+	resp, err := spreadsheetsService.BatchUpdate(teamID, &sheets.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheets.Request{
+			{
+				AddSheet: &sheets.AddSheetRequest{
+					Properties: &sheets.SheetProperties{
+						Title:  submissionTimesSheetTitle,
+						Hidden: true,
+					},
+				},
+			},
+		},
+	}).Context(a.commandContext()).Do()
+	if err != nil {
+		return fmt.Errorf("failed to add the submission times sheet: %w", err)
+	}
+	sheetID := resp.Replies[0].AddSheet.Properties.SheetId
+	a.metadata.invalidate(teamID)
+	_, err = spreadsheetsService.BatchUpdate(teamID, &sheets.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheets.Request{
+			{
+				AddProtectedRange: &sheets.AddProtectedRangeRequest{
+					ProtectedRange: &sheets.ProtectedRange{
+						Description: "submission times",
+						Range:       &sheets.GridRange{SheetId: sheetID},
+						Editors:     &sheets.Editors{},
+					},
+				},
+			},
+		},
+	}).Context(a.commandContext()).Do()
+	if err != nil {
+		return fmt.Errorf("failed to protect the submission times sheet: %w", err)
+	}
+	return nil
+}
+
+func (a *app) installSubmissionTimeScript(team *sheets.Spreadsheet) error {
+	if a.script == nil {
+		return fmt.Errorf("internal error: the Apps Script service is not initialized")
+	}
+	if err := a.ensureSubmissionTimesSheet(team.SpreadsheetId); err != nil {
+		return err
+	}
+	cell := submissionTimeHorizontalCell
+	if a.config.Layout.vertical() {
+		cell = submissionTimeVerticalCell
+	}
+	source := fmt.Sprintf(submissionTimeScript, submissionTimesSheetTitle, cell)
+	project, err := a.script.Projects.Create(&script.CreateProjectRequest{
+		ParentId: team.SpreadsheetId,
+		Title:    "submission-time",
+	}).Context(a.commandContext()).Do()
+	if err != nil {
+		return fmt.Errorf("failed to create the submission time script: %w", err)
+	}
+	_, err = a.script.Projects.UpdateContent(project.ScriptId, &script.Content{
+		Files: []*script.File{
+			{
+				Name:   "appsscript",
+				Type:   "JSON",
+				Source: submissionTimeManifest,
+			},
+			{
+				Name:   "submissionTime",
+				Type:   "SERVER_JS",
+				Source: source,
+			},
+		},
+	}).Context(a.commandContext()).Do()
+	if err != nil {
+		return fmt.Errorf("failed to upload the submission time script: %w", err)
+	}
+	// the trigger is installed on the owner's behalf, a failed run leaves the
+	// installation to the owner, as the Apps Script API runs only the scripts
+	// of the Cloud project of the credentials
+	op, err := a.script.Scripts.Run(project.ScriptId, &script.ExecutionRequest{
+		Function: submissionTimeInstallFunction,
+		DevMode:  true,
+	}).Context(a.commandContext()).Do()
+	if err == nil && op.Error != nil {
+		err = fmt.Errorf("%s", op.Error.Message)
+	}
+	if err != nil {
+		log.Printf("[ERR]: failed to install the submission time trigger into %s, run %s once in https://script.google.com/d/%s/edit: %v", team.SpreadsheetUrl, submissionTimeInstallFunction, project.ScriptId, err)
+		return nil
+	}
+	log.Printf("installed the submission time script into %s", team.SpreadsheetUrl)
+	return nil
+}
+
+// fetchTeamsSubmissionTimes reads the times recorded by the submission time
+// script for the round from the submission times sheet. Teams without a
+// recorded time are omitted.
+func (a *app) fetchTeamsSubmissionTimes(round int) (map[string]time.Time, error) {
+	gameSpreadsheets, err := a.GetGameSpreadsheets()
+	if err != nil {
+		return nil, err
+	}
+	column, row, err := a.getTeamRoundCellPosition(round)
+	if err != nil {
+		return nil, err
+	}
+	valuesService := sheets.NewSpreadsheetsValuesService(a.service)
+	times := make(map[string]time.Time, len(a.config.Teams))
+	for _, team := range a.config.Teams {
+		teamSheet, ok := gameSpreadsheets.teams[team]
+		if !ok {
+			return nil, fmt.Errorf("spreadsheet of the team %s is not found", team)
+		}
+		cell := sheetRange(submissionTimesSheetTitle, cellName(column, row))
+		resp, err := valuesService.Get(teamSheet.ID, cell).Context(a.commandContext()).Do()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the team %s spreadsheet: %w", team, err)
+		}
+		if len(resp.Values) == 0 || len(resp.Values[0]) == 0 {
+			continue
+		}
+		tStr, ok := resp.Values[0][0].(string)
+		if !ok {
+			return nil, fmt.Errorf("received value %v could not be cast to string", resp.Values[0][0])
+		}
+		t, err := time.Parse(time.RFC3339, tStr)
+		if err != nil {
+			log.Printf("team %s submission time %s could not be parsed: %v", team, tStr, err)
+			continue
+		}
+		times[team] = t
+	}
+	return times, nil
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+// totalsSheetTitle is the sheet of the manager spreadsheet with the totals and
+// the standings computed by formulas from the statuses sheet.
+const totalsSheetTitle = "Totals"
+
+// fillTotalsSheet writes the totals formulas and the standings block into the
+// totals sheet of the manager spreadsheet, so that the totals follow the
+// statuses entered into the statuses sheet by hand. A partial answer counts
+// as half a point, the round voids are not reflected by the formulas.
+func (a *app) fillTotalsSheet(managerID string) error {
+	if !a.config.TotalsFormulas || len(a.config.Teams) == 0 {
+		return nil
+	}
+	if err := a.ensureStatusesSheet(managerID); err != nil {
+		return err
+	}
+	metadata, err := a.getSpreadsheetMetadata(a.commandContext(), managerID)
+	if err != nil {
+		return err
+	}
+	if _, ok := metadata.sheetByTitle(totalsSheetTitle); !ok {
+		spreadsheetsService := sheets.NewSpreadsheetsService(a.service)
+		_, err := spreadsheetsService.BatchUpdate(managerID, &sheets.BatchUpdateSpreadsheetRequest{
+			Requests: []*sheets.Request{
+				{
+					AddSheet: &sheets.AddSheetRequest{
+						Properties: &sheets.SheetProperties{Title: totalsSheetTitle},
+					},
+				},
+			},
+		}).Context(a.commandContext()).Do()
+		if err != nil {
+			return fmt.Errorf("failed to add the totals sheet: %w", err)
+		}
+		a.metadata.invalidate(managerID)
+	}
+	statusRanges := a.teamStatusRanges()
+	// the teams out of competition follow the ranked ones and are not ranked
+	officialCount := len(a.config.officialTeams())
+	order := make([]int, 0, len(a.config.Teams))
+	for _, guests := range []bool{false, true} {
+		for i, team := range a.config.Teams {
+			if a.config.OutOfCompetition[team] == guests {
+				order = append(order, i)
+			}
+		}
+	}
+	values := [][]interface{}{{tr("header.team"), tr("header.score"), tr("header.place"), "", tr("header.team"), tr("header.score")}}
+	for j, i := range order {
+		team := a.config.Teams[i]
+		row := j + 2
+		terms := make([]string, 0, 2*len(statusRanges[i]))
+		for _, r := range statusRanges[i] {
+			terms = append(terms,
+				fmt.Sprintf("COUNTIF(%s,\"%s\")", r, ResponseStatusOK),
+				fmt.Sprintf("COUNTIF(%s,\"%s\")/2", r, ResponseStatusPartial))
+		}
+		total := "0"
+		if len(terms) != 0 {
+			total = "=" + strings.Join(terms, "+")
+		}
+		place := fmt.Sprintf("=RANK(B%d,B$2:B$%d)", row, officialCount+1)
+		if a.config.OutOfCompetition[team] {
+			place = outOfCompetitionMark
+		}
+		values = append(values, []interface{}{team, total, place})
+	}
+	if officialCount != 0 {
+		values[1] = append(values[1], "", fmt.Sprintf("=SORT(A2:B%d,2,FALSE,1,TRUE)", officialCount+1))
+	}
+	valuesService := sheets.NewSpreadsheetsValuesService(a.service)
+	_, err = valuesService.Clear(managerID, totalsSheetTitle, &sheets.ClearValuesRequest{}).Context(a.commandContext()).Do()
+	if err != nil {
+		return fmt.Errorf("failed to clear the totals sheet: %w", err)
+	}
+	_, err = valuesService.Update(managerID, sheetRange(totalsSheetTitle, rangeName(0, 1, 5, len(values))), &sheets.ValueRange{
+		Values: values,
+	}).ValueInputOption("USER_ENTERED").Context(a.commandContext()).Do()
+	if err != nil {
+		return fmt.Errorf("failed to write the totals formulas: %w", err)
+	}
+	return nil
+}
+
+// teamStatusRanges returns, for every team, the ranges of the statuses sheet
+// holding the team statuses of the scored rounds, one range per answer group.
+func (a *app) teamStatusRanges() [][]string {
+	type span struct {
+		first, last int
+	}
+	// the groups are keyed by the row of the first team
+	groups := make(map[int]*span)
+	for _, round := range a.scoredRounds() {
+		roundRange, err := a.getRoundRange(round)
+		if err != nil {
+			// no warm-up question
+			continue
+		}
+		row := int(roundRange.StartRowIndex) + 1
+		column := int(roundRange.StartColumnIndex)
+		s, ok := groups[row]
+		if !ok {
+			groups[row] = &span{first: column, last: column}
+			continue
+		}
+		if column < s.first {
+			s.first = column
+		}
+		if column > s.last {
+			s.last = column
+		}
+	}
+	rows := make([]int, 0, len(groups))
+	for row := range groups {
+		rows = append(rows, row)
+	}
+	sort.Ints(rows)
+	ranges := make([][]string, len(a.config.Teams))
+	for i := range a.config.Teams {
+		for _, row := range rows {
+			s := groups[row]
+			ranges[i] = append(ranges[i], sheetRange(statusesSheetTitle, rangeName(s.first, row+i, s.last, row+i)))
+		}
+	}
+	return ranges
+}
@@ -0,0 +1,403 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+const (
+	tournamentConfigFile      = "tournament.json"
+	tournamentSpreadsheetFile = "season-spreadsheet"
+)
+
+const (
+	TournamentScoringSum       = "sum"
+	TournamentScoringBest      = "best"
+	TournamentScoringPlacement = "placement"
+)
+
+// TournamentConfig is the tournament.json of the tournament directory, the
+// directory also holds the archives of the games saved with "archive save"
+// and may hold the teams.json team directory of the season.
+type TournamentConfig struct {
+	Name string
+	// Scoring is "sum" (the sum of the game scores, the default), "best"
+	// (the sum of the BestN best game scores) or "placement" (the sum of the
+	// PlacementPoints of the places taken in the games).
+	Scoring string
+	BestN   int
+	// PlacementPoints are the points of the 1st, 2nd, ... places, the teams
+	// sharing places get the average of their points. The places beyond the
+	// list bring no points.
+	PlacementPoints []float64
+	// Publish writes the standings to the season spreadsheet, it needs the
+	// --creds flag.
+	Publish bool
+}
+
+func (c *TournamentConfig) validate() error {
+	switch c.Scoring {
+	case TournamentScoringSum, TournamentScoringPlacement:
+	case TournamentScoringBest:
+		if c.BestN <= 0 {
+			return fmt.Errorf("BestN must be positive with the best scoring, got %d", c.BestN)
+		}
+	default:
+		return fmt.Errorf("unknown Scoring %s, expected %s, %s or %s", c.Scoring, TournamentScoringSum, TournamentScoringBest, TournamentScoringPlacement)
+	}
+	if c.Scoring == TournamentScoringPlacement && len(c.PlacementPoints) == 0 {
+		return fmt.Errorf("PlacementPoints cannot be empty with the placement scoring")
+	}
+	return nil
+}
+
+func readTournamentConfig(dir string) (*TournamentConfig, error) {
+	c := &TournamentConfig{}
+	data, err := ioutil.ReadFile(path.Join(dir, tournamentConfigFile))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, c); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", tournamentConfigFile, err)
+		}
+	}
+	if len(c.Name) == 0 {
+		c.Name = filepath.Base(filepath.Clean(dir))
+	}
+	if len(c.Scoring) == 0 {
+		c.Scoring = TournamentScoringSum
+	}
+	if err := c.validate(); err != nil {
+		return nil, &errorInvalidConfig{problems: []string{err.Error()}}
+	}
+	return c, nil
+}
+
+// tournamentGame is the result of a game of the tournament.
+type tournamentGame struct {
+	Name   string
+	Totals map[string]float64
+	// StableIDs are the stable IDs of the teams of the game in the team
+	// directory.
+	StableIDs map[string]string
+}
+
+// teamID returns the stable ID of the team of the game, the teams of the
+// games archived without the IDs are matched to the directory by name.
+func (g *tournamentGame) teamID(team string, directory teamDirectory) string {
+	if id, ok := g.StableIDs[team]; ok {
+		return id
+	}
+	if t := directory.byName(team); t != nil {
+		return t.ID
+	}
+	return ""
+}
+
+// archiveTotals computes the team totals of the archived game.
+func archiveTotals(archive *gameArchive) (map[string]float64, error) {
+	allResults := make(map[int]*roundResults)
+	for _, e := range archive.Buckets[bucketGameResults] {
+		results := &roundResults{}
+		if err := json.Unmarshal(e.Value, results); err != nil {
+			return nil, fmt.Errorf("failed to read the round %s results: %w", e.Key, err)
+		}
+		allResults[results.Round] = results
+	}
+	allMeta := make(map[int]*roundMeta)
+	for _, e := range archive.Buckets[bucketRoundMeta] {
+		round, err := strconv.Atoi(string(e.Key))
+		if err != nil {
+			return nil, err
+		}
+		meta := &roundMeta{}
+		if err := json.Unmarshal(e.Value, meta); err != nil {
+			return nil, fmt.Errorf("failed to read the round %d metadata: %w", round, err)
+		}
+		allMeta[round] = meta
+	}
+	total := sumTotals(archive.Config.Teams, archive.Config.scoredRounds(), allResults, allMeta)
+	for _, e := range archive.Buckets[bucketAdjustments] {
+		var adj scoreAdjustment
+		if err := json.Unmarshal(e.Value, &adj); err != nil {
+			return nil, fmt.Errorf("failed to read the score adjustment: %w", err)
+		}
+		if _, ok := total[adj.Team]; ok {
+			total[adj.Team] += adj.Points
+		}
+	}
+	return total, nil
+}
+
+// readTournamentGames reads the game archives of the directory, the games are
+// ordered by the time they were archived.
+func readTournamentGames(dir string) ([]*tournamentGame, error) {
+	files, err := filepath.Glob(path.Join(dir, "*.zip"))
+	if err != nil {
+		return nil, err
+	}
+	archives := make([]*gameArchive, 0, len(files))
+	for _, file := range files {
+		archive, err := readArchive(file)
+		if err != nil {
+			return nil, fmt.Errorf("archive %s: %w", file, err)
+		}
+		archives = append(archives, archive)
+	}
+	if len(archives) == 0 {
+		return nil, fmt.Errorf("no game archives are found in %s", dir)
+	}
+	sort.SliceStable(archives, func(i, j int) bool {
+		return archives[i].CreatedAt.Before(archives[j].CreatedAt)
+	})
+	games := make([]*tournamentGame, 0, len(archives))
+	names := make(map[string]int, len(archives))
+	for _, archive := range archives {
+		totals, err := archiveTotals(archive)
+		if err != nil {
+			return nil, fmt.Errorf("game %s: %w", archive.Config.GameName, err)
+		}
+		// the games with the same name, e.g. the weekly ones, are numbered
+		name := archive.Config.GameName
+		names[name]++
+		if names[name] > 1 {
+			name = fmt.Sprintf("%s (%d)", name, names[name])
+		}
+		games = append(games, &tournamentGame{Name: name, Totals: totals, StableIDs: archive.Config.StableTeamIDs})
+	}
+	return games, nil
+}
+
+type tournamentTeam struct {
+	Team string `json:"team" yaml:"team"`
+	// ID is the stable ID of the team in the team directory.
+	ID string `json:"id,omitempty" yaml:"id,omitempty"`
+	// Points are the points of the team in every game, the games the team
+	// has not played are missing.
+	Points map[string]float64 `json:"points" yaml:"points"`
+	Total  float64            `json:"total" yaml:"total"`
+}
+
+type tournamentResult struct {
+	Name      string            `json:"name" yaml:"name"`
+	Scoring   string            `json:"scoring" yaml:"scoring"`
+	Games     []string          `json:"games" yaml:"games"`
+	Standings []*standing       `json:"standings" yaml:"standings"`
+	Teams     []*tournamentTeam `json:"teams" yaml:"teams"`
+	URL       string            `json:"url,omitempty" yaml:"url,omitempty"`
+}
+
+func (r *tournamentResult) String() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Tournament %s, %d games, %s scoring:\n", r.Name, len(r.Games), r.Scoring))
+	t := &table{header: []string{tr("header.place"), tr("header.team")}, indent: "\t"}
+	t.header = append(t.header, r.Games...)
+	t.header = append(t.header, tr("header.score"))
+	for _, s := range r.Standings {
+		for _, team := range s.Teams {
+			row := []tableCell{plainCell(s.places()), plainCell(team)}
+			for _, game := range r.Games {
+				points, ok := r.teamPoints(team, game)
+				if !ok {
+					row = append(row, plainCell("-"))
+					continue
+				}
+				row = append(row, plainCell(formatPoints(points)))
+			}
+			row = append(row, plainCell(formatPoints(s.Score)))
+			t.addRow(row...)
+		}
+	}
+	sb.WriteString(t.String())
+	if len(r.URL) != 0 {
+		sb.WriteString(fmt.Sprintf("Season spreadsheet: %s\n", r.URL))
+	}
+	return sb.String()
+}
+
+// gamePoints returns the tournament points the teams get for the game.
+func (c *TournamentConfig) gamePoints(g *tournamentGame) map[string]float64 {
+	if c.Scoring != TournamentScoringPlacement {
+		return g.Totals
+	}
+	points := make(map[string]float64, len(g.Totals))
+	for _, s := range computeStandings(g.Totals) {
+		sum := 0.0
+		for place := s.FirstPlace; place <= s.LastPlace; place++ {
+			if place <= len(c.PlacementPoints) {
+				sum += c.PlacementPoints[place-1]
+			}
+		}
+		for _, team := range s.Teams {
+			points[team] = sum / float64(len(s.Teams))
+		}
+	}
+	return points
+}
+
+// computeTournament aggregates the games into the tournament standings. The
+// teams are matched by their stable IDs, a renamed team is listed under its
+// directory name or under the name of its latest game.
+func computeTournament(c *TournamentConfig, directory teamDirectory, games []*tournamentGame) *tournamentResult {
+	res := &tournamentResult{Name: c.Name, Scoring: c.Scoring, Games: make([]string, 0, len(games))}
+	teams := make(map[string]*tournamentTeam)
+	for _, game := range games {
+		res.Games = append(res.Games, game.Name)
+		for team, points := range c.gamePoints(game) {
+			id := game.teamID(team, directory)
+			key := "name:" + team
+			if len(id) != 0 {
+				key = "id:" + id
+			}
+			t, ok := teams[key]
+			if !ok {
+				t = &tournamentTeam{ID: id, Points: make(map[string]float64)}
+				teams[key] = t
+			}
+			t.Team = team
+			t.Points[game.Name] = points
+		}
+	}
+	byName := make(map[string]*tournamentTeam, len(teams))
+	totals := make(map[string]float64, len(teams))
+	for _, t := range teams {
+		if d := directory.byID(t.ID); d != nil {
+			t.Team = d.Name
+		}
+		byName[t.Team] = t
+		scores := make([]float64, 0, len(t.Points))
+		for _, points := range t.Points {
+			scores = append(scores, points)
+		}
+		sort.Sort(sort.Reverse(sort.Float64Slice(scores)))
+		if c.Scoring == TournamentScoringBest && len(scores) > c.BestN {
+			scores = scores[:c.BestN]
+		}
+		for _, s := range scores {
+			t.Total += s
+		}
+		totals[t.Team] = t.Total
+	}
+	res.Standings = computeStandings(totals)
+	for _, s := range res.Standings {
+		for _, team := range s.Teams {
+			res.Teams = append(res.Teams, byName[team])
+		}
+	}
+	return res
+}
+
+// runTournament prints the standings of the tournament of the directory and
+// publishes them to the season spreadsheet if configured.
+func runTournament(fl *parsedFlags) error {
+	dir := fl.tournament
+	c, err := readTournamentConfig(dir)
+	if err != nil {
+		return err
+	}
+	directory, err := readTournamentTeams(dir)
+	if err != nil {
+		return err
+	}
+	games, err := readTournamentGames(dir)
+	if err != nil {
+		return err
+	}
+	res := computeTournament(c, directory, games)
+	if c.Publish {
+		tokenDir := fl.outputDir
+		if len(tokenDir) == 0 {
+			tokenDir = dir
+		}
+		clients, err := newAPIClients([]*Config{{}}, fl.credsFile, tokenDir)
+		if err != nil {
+			return err
+		}
+		if res.URL, err = publishTournament(clients.sheets, dir, res); err != nil {
+			return err
+		}
+	}
+	return printResult(res, fl.outputFormat)
+}
+
+// publishTournament writes the standings to the season spreadsheet, it is
+// created on the first run and its ID is kept in the tournament directory.
+func publishTournament(service *sheets.Service, dir string, res *tournamentResult) (string, error) {
+	idFile := path.Join(dir, tournamentSpreadsheetFile)
+	var season *sheets.Spreadsheet
+	id, err := ioutil.ReadFile(idFile)
+	switch {
+	case err == nil:
+		season, err = service.Spreadsheets.Get(strings.TrimSpace(string(id))).Do()
+		if err != nil {
+			return "", fmt.Errorf("failed to get the season spreadsheet: %w", err)
+		}
+	case os.IsNotExist(err):
+		season, err = service.Spreadsheets.Create(&sheets.Spreadsheet{
+			Properties: &sheets.SpreadsheetProperties{Title: res.Name},
+		}).Do()
+		if err != nil {
+			return "", fmt.Errorf("failed to create the season spreadsheet: %w", err)
+		}
+		if err := ioutil.WriteFile(idFile, []byte(season.SpreadsheetId), 0600); err != nil {
+			return "", fmt.Errorf("failed to save the season spreadsheet ID: %w", err)
+		}
+		log.Printf("created the season spreadsheet %s", season.SpreadsheetUrl)
+	default:
+		return "", err
+	}
+	title := firstSheetTitle(season)
+	header := []interface{}{tr("header.place"), tr("header.team")}
+	for _, game := range res.Games {
+		header = append(header, game)
+	}
+	header = append(header, tr("header.score"))
+	rows := [][]interface{}{header}
+	for _, s := range res.Standings {
+		for _, team := range s.Teams {
+			row := []interface{}{s.places(), team}
+			for _, game := range res.Games {
+				points, ok := res.teamPoints(team, game)
+				if !ok {
+					row = append(row, "")
+					continue
+				}
+				row = append(row, formatPoints(points))
+			}
+			row = append(row, formatPoints(s.Score))
+			rows = append(rows, row)
+		}
+	}
+	valuesService := sheets.NewSpreadsheetsValuesService(service)
+	if _, err := valuesService.Clear(season.SpreadsheetId, title, &sheets.ClearValuesRequest{}).Do(); err != nil {
+		return "", fmt.Errorf("failed to clear the season spreadsheet: %w", err)
+	}
+	_, err = valuesService.Update(season.SpreadsheetId, sheetRange(title, rangeName(0, 1, len(header)-1, len(rows))), &sheets.ValueRange{
+		Values: rows,
+	}).ValueInputOption("RAW").Do()
+	if err != nil {
+		return "", fmt.Errorf("failed to write the season standings: %w", err)
+	}
+	return season.SpreadsheetUrl, nil
+}
+
+func (r *tournamentResult) teamPoints(team string, game string) (float64, bool) {
+	for _, t := range r.Teams {
+		if t.Team == team {
+			points, ok := t.Points[game]
+			return points, ok
+		}
+	}
+	return 0, false
+}
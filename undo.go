@@ -0,0 +1,28 @@
+package main
+
+import (
+	"fmt"
+)
+
+type undoResult struct {
+	Undone string `json:"undone" yaml:"undone"`
+}
+
+func (r *undoResult) String() string {
+	return fmt.Sprintf("Undone: %s", r.Undone)
+}
+
+func (a *app) CmdUndo() (*undoResult, error) {
+	b, err := a.boltStore()
+	if err != nil {
+		return nil, err
+	}
+	description, err := b.undo()
+	if err != nil {
+		return nil, err
+	}
+	if err := a.store.appendEvent(fmt.Sprintf("undo: %s", description)); err != nil {
+		return nil, err
+	}
+	return &undoResult{Undone: description}, nil
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// newSheetRowCount is the number of rows of a newly created sheet, the
+// manager layout must fit into it.
+const newSheetRowCount = 1000
+
+type errorInvalidConfig struct {
+	problems []string
+}
+
+func (e *errorInvalidConfig) Error() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("configuration has %d problem(s):", len(e.problems)))
+	for _, p := range e.problems {
+		sb.WriteString("\n\t- ")
+		sb.WriteString(p)
+	}
+	return sb.String()
+}
+
+// validate checks the whole configuration and reports all the problems at
+// once, so that they can be fixed in one go.
+func (c *Config) validate() error {
+	var problems []string
+	addProblem := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+	if len(c.GameName) == 0 {
+		addProblem("GameName cannot be empty")
+	}
+	problems = append(problems, c.TeamSheet.check()...)
+	problems = append(problems, c.Mail.check()...)
+	problems = append(problems, c.Report.check()...)
+	problems = append(problems, c.Sharing.check(c.Teams)...)
+	problems = append(problems, checkWebhooks(c.Webhooks)...)
+	problems = append(problems, c.checkOutOfCompetition()...)
+	if err := checkLanguage(c.Language); err != nil {
+		addProblem("Language: %v", err)
+	}
+	if c.NumberOfQuestions <= 0 {
+		addProblem("NumberOfQuestions must be positive, got %d", c.NumberOfQuestions)
+	}
+	if c.NewGame && len(c.Teams) == 0 {
+		addProblem("Teams cannot be empty when a new game is created, add at least one team")
+	}
+	teams := make(map[string]bool, len(c.Teams))
+	for i, team := range c.Teams {
+		name := strings.TrimSpace(team)
+		if len(name) == 0 {
+			addProblem("team #%d has an empty name", i+1)
+			continue
+		}
+		if name != team {
+			addProblem("team \"%s\" has leading or trailing spaces, remove them", team)
+		}
+		if teams[name] {
+			addProblem("team %s is listed several times, team names must be unique", name)
+		}
+		teams[name] = true
+	}
+	if c.NumberOfQuestions > 0 && len(c.Teams) > 0 {
+		groups := (c.NumberOfQuestions + questionsGroupLength - 1) / questionsGroupLength
+		if c.HasWarmUpQuestion {
+			groups++
+		}
+		rows := groups * (len(c.Teams) + 2)
+		if rows > newSheetRowCount {
+			addProblem("%d questions in groups of %d for %d teams need %d rows of the manager sheet, at most %d are available: reduce NumberOfQuestions or the number of teams",
+				c.NumberOfQuestions, questionsGroupLength, len(c.Teams), rows, newSheetRowCount)
+		}
+	}
+	for question := range c.Answers {
+		if !c.hasQuestion(question) {
+			addProblem("Answers are given for question %d that is not in the game", question)
+		}
+	}
+	switch c.Layout.Orientation {
+	case "", LayoutOrientationHorizontal, LayoutOrientationVertical:
+	default:
+		addProblem("unknown layout orientation %s, expected %s or %s", c.Layout.Orientation, LayoutOrientationHorizontal, LayoutOrientationVertical)
+	}
+	switch c.Tiebreak.Procedure {
+	case "", TiebreakProcedureRandom, TiebreakProcedureClosest:
+	default:
+		addProblem("unknown tiebreak procedure %s, expected %s or %s", c.Tiebreak.Procedure, TiebreakProcedureRandom, TiebreakProcedureClosest)
+	}
+	if _, err := newNormalizerPipeline(c.Normalizers); err != nil {
+		addProblem("Normalizers: %v", err)
+	}
+	if err := checkPluginsConfig(c.Plugins); err != nil {
+		addProblem("Plugins: %v", err)
+	}
+	for _, p := range c.Plugins {
+		if p.Kind == pluginKindExporter && p.Name == ratingExporter {
+			addProblem("Plugins: exporter %s clashes with the built-in rating exporter", p.Name)
+		}
+	}
+	for team, id := range c.TeamIDs {
+		if id <= 0 {
+			addProblem("TeamIDs: team %s has a non-positive rating ID %d", team, id)
+		}
+	}
+	if _, err := c.CheckKeys.verdicts(); err != nil {
+		addProblem("CheckKeys: %v", err)
+	}
+	if c.FirstQuestionNumber < 0 {
+		addProblem("FirstQuestionNumber: %d is negative", c.FirstQuestionNumber)
+	}
+	if c.CheckSuggestDistance < 0 {
+		addProblem("CheckSuggestDistance: %d is negative", c.CheckSuggestDistance)
+	}
+	if c.Timer.ThinkingSeconds < 0 || c.Timer.WritingSeconds < 0 {
+		addProblem("Timer durations cannot be negative")
+	}
+	if c.FetchSettle.DelaySeconds < 0 || c.FetchSettle.WindowSeconds < 0 || c.FetchSettle.IntervalSeconds < 0 {
+		addProblem("FetchSettle durations cannot be negative")
+	}
+	if c.JournalSize < 0 {
+		addProblem("JournalSize cannot be negative, got %d", c.JournalSize)
+	}
+	if c.Backups < -1 {
+		addProblem("Backups must be -1 to disable the backups or a non-negative count, got %d", c.Backups)
+	}
+	if c.StaleAfterDays < 0 {
+		addProblem("StaleAfterDays cannot be negative, got %d", c.StaleAfterDays)
+	}
+	if c.MetadataCacheSeconds < 0 {
+		addProblem("MetadataCacheSeconds cannot be negative, got %d", c.MetadataCacheSeconds)
+	}
+	if c.RequestsPerMinute < 0 {
+		addProblem("RequestsPerMinute cannot be negative, got %d", c.RequestsPerMinute)
+	}
+	if c.FetchConcurrency < 0 {
+		addProblem("FetchConcurrency cannot be negative, got %d", c.FetchConcurrency)
+	}
+	if c.CallTimeoutSeconds < 0 {
+		addProblem("CallTimeoutSeconds cannot be negative, got %d", c.CallTimeoutSeconds)
+	}
+	if len(c.Questions.File) != 0 {
+		if _, err := c.Questions.load(); err != nil {
+			addProblem("Questions: %v", err)
+		}
+	}
+	if c.AnswerCells.MaxLength < 0 {
+		addProblem("AnswerCells: MaxLength cannot be negative, got %d", c.AnswerCells.MaxLength)
+	}
+	for _, scope := range c.Scopes {
+		if !strings.HasPrefix(scope, "https://www.googleapis.com/auth/") {
+			addProblem("Scopes: %s is not a Google API scope, expected https://www.googleapis.com/auth/...", scope)
+		}
+	}
+	problems = append(problems, checkAliases(c.Aliases)...)
+	problems = append(problems, checkQuestionTypes(c)...)
+	for juror, weight := range c.Jury.Members {
+		if weight <= 0 {
+			addProblem("Jury: member %s has a non-positive weight %v", juror, weight)
+		}
+	}
+	if c.Jury.Majority < 0 || c.Jury.Majority >= 1 {
+		addProblem("Jury: Majority must be a share between 0 and 1, got %v", c.Jury.Majority)
+	}
+	if c.ReadOnly && c.NewGame {
+		addProblem("a read-only session cannot create a new game, remove --newGame or --read-only")
+	}
+	if len(c.APIAddr) != 0 && len(c.APITokens) == 0 {
+		addProblem("the control API requires at least one token in APITokens")
+	}
+	if len(problems) != 0 {
+		return &errorInvalidConfig{problems: problems}
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// JuryConfig describes the jury voting on the disputed responses.
+type JuryConfig struct {
+	// Members maps the jury members to the weights of their votes.
+	Members map[string]float64
+	// Majority is the share of the total weight a verdict needs, 0.5 by
+	// default: a verdict is taken once more than a half of the weight is
+	// cast for it.
+	Majority float64
+}
+
+func (c *JuryConfig) majority() float64 {
+	if c.Majority <= 0 {
+		return 0.5
+	}
+	return c.Majority
+}
+
+func (c *JuryConfig) totalWeight() float64 {
+	total := 0.0
+	for _, w := range c.Members {
+		total += w
+	}
+	return total
+}
+
+// roundVotes are the votes on a disputed response, Version is the version of
+// the response they are cast on.
+type roundVotes struct {
+	Version int
+	Votes   map[string]bool
+}
+
+type voteResult struct {
+	Round   int             `json:"round" yaml:"round"`
+	Team    string          `json:"team" yaml:"team"`
+	Accept  float64         `json:"accept" yaml:"accept"`
+	Reject  float64         `json:"reject" yaml:"reject"`
+	Needed  float64         `json:"needed" yaml:"needed"`
+	Votes   map[string]bool `json:"votes" yaml:"votes"`
+	Status  ResponseStatus  `json:"status" yaml:"status"`
+	Decided bool            `json:"decided" yaml:"decided"`
+}
+
+func (r *voteResult) String() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Round %d, team %s: accept %s, reject %s, more than %s needed\n",
+		r.Round, r.Team, formatPoints(r.Accept), formatPoints(r.Reject), formatPoints(r.Needed)))
+	jurors := make([]string, 0, len(r.Votes))
+	for juror := range r.Votes {
+		jurors = append(jurors, juror)
+	}
+	sort.Strings(jurors)
+	for _, juror := range jurors {
+		vote := "reject"
+		if r.Votes[juror] {
+			vote = "accept"
+		}
+		sb.WriteString(fmt.Sprintf("\t %s: %s\n", juror, vote))
+	}
+	if r.Decided {
+		sb.WriteString(fmt.Sprintf("The response status is %v\n", r.Status))
+	}
+	return sb.String()
+}
+
+// CmdVote records a jury vote on a disputed response: "vote <round> <team>
+// <juror> accept|reject". Without the juror and the vote the tally is shown.
+// The status of the response is set once the votes reach the majority.
+func (a *app) CmdVote(cmdStr string) (*voteResult, error) {
+	args := splitArgs(cmdStr)
+	if len(args) != 3 && len(args) != 5 {
+		return nil, fmt.Errorf("expected the round, the team and, to vote, the juror and accept or reject")
+	}
+	if len(a.config.Jury.Members) == 0 {
+		return nil, fmt.Errorf("no jury members are configured")
+	}
+	round, err := a.parseRound(args[1])
+	if err != nil {
+		return nil, err
+	}
+	team := args[2]
+	results, err := a.store.getRoundResults(round)
+	if err != nil {
+		return nil, err
+	}
+	resp, ok := results.Results[team]
+	if !ok {
+		return nil, fmt.Errorf("team %s has no response in the round %d", team, a.config.questionNumber(round))
+	}
+	votes, err := a.store.getVotes(round, team)
+	if err != nil {
+		return nil, err
+	}
+	if votes == nil || votes.Version != resp.Version {
+		// the votes on a previous version of the response are dropped
+		votes = &roundVotes{Version: resp.Version, Votes: make(map[string]bool)}
+	}
+	if len(args) == 3 {
+		return a.tallyVotes(round, team, votes, resp), nil
+	}
+	if resp.Status != ResponseStatusInQuestion {
+		return nil, fmt.Errorf("team %s response in the round %d is not disputed, its status is %v", team, a.config.questionNumber(round), resp.Status)
+	}
+	juror := args[3]
+	if _, ok := a.config.Jury.Members[juror]; !ok {
+		return nil, fmt.Errorf("%s is not a jury member", juror)
+	}
+	switch args[4] {
+	case "accept":
+		votes.Votes[juror] = true
+	case "reject":
+		votes.Votes[juror] = false
+	default:
+		return nil, fmt.Errorf("unknown vote %s, expected accept or reject", args[4])
+	}
+	if err := a.store.saveVotes(round, team, votes); err != nil {
+		return nil, err
+	}
+	if err := a.store.appendEvent(fmt.Sprintf("vote: round %d, team %s, %s %s", a.config.questionNumber(round), team, juror, args[4])); err != nil {
+		return nil, err
+	}
+	res := a.tallyVotes(round, team, votes, resp)
+	if !res.Decided {
+		return res, nil
+	}
+	resp.Status = res.Status
+	stored, err := a.store.saveVerdicts(&roundResults{
+		Round:   round,
+		Results: map[string]*roundResponse{team: resp},
+	})
+	if err != nil {
+		return nil, err
+	}
+	a.publishResults(eventTypeStatuses, stored)
+	if !a.isEmbargoed(round) {
+		a.notifyWebhooks(&webhookPayload{Event: webhookEventAppealResolved, Round: a.config.questionNumber(round), Team: team, Status: res.Status.String()})
+	}
+	if err := a.store.appendEvent(fmt.Sprintf("vote: round %d, team %s response is set to %v", a.config.questionNumber(round), team, res.Status)); err != nil {
+		return nil, err
+	}
+	if _, err := a.markStatuses(stored); err != nil {
+		return nil, fmt.Errorf("failed to mark the statuses in the manager spreadsheet: %w", err)
+	}
+	return res, nil
+}
+
+// tallyVotes sums the weights of the votes, the response is decided once a
+// side has more than the majority of the total weight.
+func (a *app) tallyVotes(round int, team string, votes *roundVotes, resp *roundResponse) *voteResult {
+	res := &voteResult{
+		Round:  a.config.questionNumber(round),
+		Team:   team,
+		Needed: a.config.Jury.totalWeight() * a.config.Jury.majority(),
+		Votes:  votes.Votes,
+		Status: resp.Status,
+	}
+	for juror, accept := range votes.Votes {
+		if accept {
+			res.Accept += a.config.Jury.Members[juror]
+		} else {
+			res.Reject += a.config.Jury.Members[juror]
+		}
+	}
+	switch {
+	case res.Accept > res.Needed:
+		res.Status, res.Decided = ResponseStatusOK, true
+	case res.Reject > res.Needed:
+		res.Status, res.Decided = ResponseStatusKO, true
+	}
+	return res
+}
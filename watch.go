@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+const (
+	watchReasonAllAnswered = "all the teams have answered"
+	watchReasonTimeIsUp    = "the time is up"
+)
+
+type watchResult struct {
+	Round    int    `json:"round" yaml:"round"`
+	Answered int    `json:"answered" yaml:"answered"`
+	Teams    int    `json:"teams" yaml:"teams"`
+	Reason   string `json:"reason" yaml:"reason"`
+	// Locked are the teams whose answer is locked by the watch.
+	Locked  []string      `json:"locked" yaml:"locked"`
+	Results *roundResults `json:"-" yaml:"-"`
+}
+
+func (r *watchResult) outputView() interface{} {
+	view := &struct {
+		Round    int               `json:"round" yaml:"round"`
+		Answered int               `json:"answered" yaml:"answered"`
+		Teams    int               `json:"teams" yaml:"teams"`
+		Reason   string            `json:"reason" yaml:"reason"`
+		Locked   []string          `json:"locked" yaml:"locked"`
+		Results  *roundResultsView `json:"results,omitempty" yaml:"results,omitempty"`
+	}{
+		Round:    r.Round,
+		Answered: r.Answered,
+		Teams:    r.Teams,
+		Reason:   r.Reason,
+		Locked:   r.Locked,
+	}
+	if r.Results != nil {
+		view.Results = r.Results.outputView().(*roundResultsView)
+	}
+	return view
+}
+
+func (r *watchResult) String() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Round %d: %s, %d of %d teams have answered\n", r.Round, r.Reason, r.Answered, r.Teams))
+	if len(r.Locked) != 0 {
+		sb.WriteString(fmt.Sprintf("The answers are locked for the teams: %s\n", strings.Join(r.Locked, ", ")))
+	}
+	if r.Results != nil {
+		sb.WriteString(r.Results.String())
+	}
+	return sb.String()
+}
+
+// CmdWatch polls the round answers in the manager spreadsheet and shows how
+// many teams have answered: "watch <round>". Once every team has answered or
+// the time is up, it beeps, plays the AllAnswered cue, then locks and fetches
+// the round. The time is up when the running timer ends, or after the
+// configured timer duration if no timer is running. Ctrl-C stops the watch.
+func (a *app) CmdWatch(cmdStr string) (*watchResult, error) {
+	round, err := a.getRoundNumber(cmdStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse watch request: %w", err)
+	}
+	if _, _, err := a.getTeamRoundCellPosition(round); err != nil {
+		return nil, err
+	}
+	ctx := a.commandContext()
+	followTimer := a.timer.running()
+	deadline := time.Now().Add(time.Duration(a.config.Timer.duration()) * time.Second)
+	res := &watchResult{Round: a.config.questionNumber(round), Teams: len(a.config.Teams)}
+	ticker := time.NewTicker(a.config.Timer.watchInterval())
+	defer ticker.Stop()
+	for {
+		results, _, err := a.fetchRoundResults(round)
+		switch {
+		case err == nil:
+			answered := countNonEmpty(results)
+			if answered != res.Answered {
+				fmt.Printf("Round %d: %d of %d teams have answered\n", a.config.questionNumber(round), answered, res.Teams)
+			}
+			res.Answered = answered
+		case !a.conn.isOnline():
+			log.Printf("[ERR]: the Google API is unreachable, the round %d answers are not polled: %v", a.config.questionNumber(round), err)
+		default:
+			return nil, fmt.Errorf("failed to poll the round %d answers: %w", a.config.questionNumber(round), err)
+		}
+		if res.Answered >= res.Teams {
+			res.Reason = watchReasonAllAnswered
+			a.fireCue(timerEventAllAnswered, a.config.AudioCues.AllAnswered)
+			break
+		}
+		if (followTimer && !a.timer.running()) || (!followTimer && time.Now().After(deadline)) {
+			res.Reason = watchReasonTimeIsUp
+			break
+		}
+		select {
+		case <-ticker.C:
+			continue
+		case <-ctx.Done():
+			return nil, fmt.Errorf("the round %d watch is stopped: %w", a.config.questionNumber(round), ctx.Err())
+		}
+	}
+	// the terminal bell
+	fmt.Print("\a")
+	fmt.Printf("Round %d: %s\n", a.config.questionNumber(round), res.Reason)
+	locked, err := a.lockRound(ctx, round)
+	if err != nil {
+		return nil, err
+	}
+	res.Locked = locked.Teams
+	if res.Results, err = a.fetchRound(round); err != nil {
+		return nil, err
+	}
+	if err := a.store.appendEvent(fmt.Sprintf("watch %d: %s, %d of %d teams have answered", a.config.questionNumber(round), res.Reason, res.Answered, res.Teams)); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// The game events the webhooks can subscribe to.
+const (
+	webhookEventRoundFetched    = "round.fetched"
+	webhookEventRoundChecked    = "round.checked"
+	webhookEventTotalsPublished = "totals.published"
+	webhookEventAppealResolved  = "appeal.resolved"
+)
+
+var webhookEvents = []string{webhookEventRoundFetched, webhookEventRoundChecked, webhookEventTotalsPublished, webhookEventAppealResolved}
+
+const (
+	webhookAttempts = 4
+	webhookTimeout  = 10 * time.Second
+	// webhookSignatureHeader holds the hex HMAC-SHA256 of the body keyed
+	// with the webhook secret, prefixed with "sha256=".
+	webhookSignatureHeader = "X-Chgk-Signature"
+	webhookEventHeader     = "X-Chgk-Event"
+)
+
+// WebhookConfig subscribes a URL to the game events, e.g. to post them to
+// Discord, Slack or a club site through a small adapter.
+type WebhookConfig struct {
+	URL string
+	// Events are the subscribed events: round.fetched, round.checked,
+	// totals.published and appeal.resolved. All the events are sent if empty.
+	Events []string
+	// Secret signs the payloads, the signature is sent in the
+	// X-Chgk-Signature header.
+	Secret string
+}
+
+func (c *WebhookConfig) subscribed(event string) bool {
+	if len(c.Events) == 0 {
+		return true
+	}
+	for _, e := range c.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// checkWebhooks returns the problems of the webhooks configuration.
+func checkWebhooks(webhooks []WebhookConfig) []string {
+	var problems []string
+	for i, w := range webhooks {
+		u, err := url.Parse(w.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 {
+			problems = append(problems, fmt.Sprintf("Webhooks[%d]: %s is not an http or https URL", i, w.URL))
+		}
+		for _, e := range w.Events {
+			known := false
+			for _, event := range webhookEvents {
+				known = known || e == event
+			}
+			if !known {
+				problems = append(problems, fmt.Sprintf("Webhooks[%d]: unknown event %s, expected one of %s", i, e, strings.Join(webhookEvents, ", ")))
+			}
+		}
+	}
+	return problems
+}
+
+// webhookPayload is the JSON body posted to the webhooks.
+type webhookPayload struct {
+	Event   string                       `json:"event"`
+	Game    string                       `json:"game"`
+	Time    time.Time                    `json:"time"`
+	Round   int                          `json:"round,omitempty"`
+	Results map[string]roundResponseView `json:"results,omitempty"`
+	// Team and Status are the appeal and its outcome.
+	Team   string `json:"team,omitempty"`
+	Status string `json:"status,omitempty"`
+	// Standings are the published standings.
+	Standings []*standing `json:"standings,omitempty"`
+}
+
+func webhookSignature(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// notifyRound sends the round event with the round results, unless the round
+// is under the results embargo.
+func (a *app) notifyRound(event string, results *roundResults) {
+	if len(a.config.Webhooks) == 0 || a.isEmbargoed(results.Round) {
+		return
+	}
+	view := a.numberResults(results).outputView().(*roundResultsView)
+	a.notifyWebhooks(&webhookPayload{Event: event, Round: results.question(), Results: view.Results})
+}
+
+// notifyWebhooks posts the payload to the subscribed webhooks in the
+// background, the failed deliveries are retried with a growing delay.
+func (a *app) notifyWebhooks(payload *webhookPayload) {
+	payload.Game = a.config.GameName
+	payload.Time = time.Now()
+	var body []byte
+	for _, w := range a.config.Webhooks {
+		if !w.subscribed(payload.Event) {
+			continue
+		}
+		if body == nil {
+			var err error
+			if body, err = json.Marshal(payload); err != nil {
+				log.Printf("[ERR]: failed to encode the %s webhook payload: %v", payload.Event, err)
+				return
+			}
+		}
+		go deliverWebhook(w, payload.Event, body)
+	}
+}
+
+func deliverWebhook(w WebhookConfig, event string, body []byte) {
+	client := &http.Client{Timeout: webhookTimeout}
+	delay := time.Second
+	for attempt := 1; ; attempt++ {
+		retry, err := postWebhook(client, w, event, body)
+		if err == nil {
+			return
+		}
+		if !retry || attempt == webhookAttempts {
+			log.Printf("[ERR]: the %s webhook to %s failed: %v", event, w.URL, err)
+			return
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// postWebhook posts the body once, retry reports whether a failure may pass
+// on another attempt.
+func postWebhook(client *http.Client, w WebhookConfig, event string, body []byte) (retry bool, err error) {
+	req, err := http.NewRequest(http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhookEventHeader, event)
+	if len(w.Secret) != 0 {
+		req.Header.Set(webhookSignatureHeader, webhookSignature(w.Secret, body))
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry = resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	return retry, fmt.Errorf("unexpected status %s", resp.Status)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+type teamCells struct {
+	Team        string `json:"team" yaml:"team"`
+	ManagerCell string `json:"managerCell" yaml:"managerCell"`
+	TeamCell    string `json:"teamCell" yaml:"teamCell"`
+}
+
+type whereResult struct {
+	Round        int         `json:"round" yaml:"round"`
+	ManagerRange string      `json:"managerRange" yaml:"managerRange"`
+	Teams        []teamCells `json:"teams" yaml:"teams"`
+}
+
+func (r *whereResult) String() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Round %d answers in the manager spreadsheet: %s\n", r.Round, r.ManagerRange))
+	for _, t := range r.Teams {
+		sb.WriteString(fmt.Sprintf("\t team %s: manager %s, team spreadsheet %s\n", t.Team, t.ManagerCell, t.TeamCell))
+	}
+	return sb.String()
+}
+
+// CmdWhere prints the cells holding the round answers under the current
+// layout.
+func (a *app) CmdWhere(cmdStr string) (*whereResult, error) {
+	round, err := a.getRoundNumber(cmdStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse where request: %w", err)
+	}
+	gameSheets, err := a.GetGameSpreadsheets()
+	if err != nil {
+		return nil, err
+	}
+	managerTitle := defaultSheetTitle
+	if gameSheets.manager != nil {
+		managerTitle = firstSheetTitle(gameSheets.manager.toSpreadsheet())
+	}
+	roundRange, err := a.getRoundRange(round)
+	if err != nil {
+		return nil, err
+	}
+	column, row, err := a.getTeamRoundCellPosition(round)
+	if err != nil {
+		return nil, err
+	}
+	managerColumn := columnName(int(roundRange.StartColumnIndex))
+	res := &whereResult{
+		Round: a.config.questionNumber(round),
+		ManagerRange: sheetRange(managerTitle, fmt.Sprintf("%s%d:%s%d",
+			managerColumn, roundRange.StartRowIndex+1, managerColumn, roundRange.EndRowIndex)),
+		Teams: make([]teamCells, 0, len(a.config.Teams)),
+	}
+	for i, team := range a.config.Teams {
+		teamTitle := defaultSheetTitle
+		if teamSheet, ok := gameSheets.teams[team]; ok {
+			teamTitle = firstSheetTitle(teamSheet.toSpreadsheet())
+		}
+		res.Teams = append(res.Teams, teamCells{
+			Team:        team,
+			ManagerCell: sheetRange(managerTitle, fmt.Sprintf("%s%d", managerColumn, int(roundRange.StartRowIndex)+i+1)),
+			TeamCell:    sheetRange(teamTitle, cellName(column, row)),
+		})
+	}
+	return res, nil
+}
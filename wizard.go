@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// wizardConfig is the part of the configuration written by the wizard, the
+// other settings keep their defaults and can be added to the file later.
+type wizardConfig struct {
+	GameName          string
+	NumberOfQuestions int
+	HasWarmUpQuestion bool
+	Teams             []string
+}
+
+// teamListNumbering matches the numbering of a pasted list, e.g. "1. " or
+// "12) ".
+var teamListNumbering = regexp.MustCompile(`^\d+[.)]\s+`)
+
+// runWizard asks for the game settings and writes them to the configuration
+// file. It is run for a new game whose configuration file does not exist.
+func runWizard(configFile string, in io.Reader) error {
+	reader := bufio.NewReader(in)
+	fmt.Printf(tr("wizard.intro"), configFile)
+	for {
+		c, err := askWizardConfig(reader)
+		if err != nil {
+			return err
+		}
+		config := &Config{
+			GameName:          c.GameName,
+			NumberOfQuestions: c.NumberOfQuestions,
+			HasWarmUpQuestion: c.HasWarmUpQuestion,
+			Teams:             c.Teams,
+			NewGame:           true,
+		}
+		config.setDefaults()
+		if err := config.validate(); err != nil {
+			fmt.Println(err)
+			fmt.Println(tr("wizard.retry"))
+			continue
+		}
+		data, err := json.MarshalIndent(c, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(configFile, append(data, '\n'), 0644); err != nil {
+			return fmt.Errorf("failed to write the configuration file %s: %w", configFile, err)
+		}
+		fmt.Printf(tr("wizard.written"), configFile)
+		return nil
+	}
+}
+
+func askWizardConfig(reader *bufio.Reader) (*wizardConfig, error) {
+	c := &wizardConfig{}
+	var err error
+	if c.GameName, err = readLine(reader, tr("wizard.gameName")); err != nil {
+		return nil, err
+	}
+	for {
+		s, err := readLine(reader, tr("wizard.questions"))
+		if err != nil {
+			return nil, err
+		}
+		if c.NumberOfQuestions, err = strconv.Atoi(s); err == nil && c.NumberOfQuestions > 0 {
+			break
+		}
+		fmt.Println(tr("wizard.notPositive"))
+	}
+	for {
+		s, err := readLine(reader, tr("wizard.warmUp"))
+		if err != nil {
+			return nil, err
+		}
+		if yes, ok := parseYesNo(s); ok {
+			c.HasWarmUpQuestion = yes
+			break
+		}
+		fmt.Println(tr("wizard.notYesNo"))
+	}
+	fmt.Println(tr("wizard.teams"))
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil && (err != io.EOF || len(line) == 0) {
+			return nil, fmt.Errorf("failed to scan the team names: %w", err)
+		}
+		team := strings.TrimSpace(teamListNumbering.ReplaceAllString(strings.TrimSpace(line), ""))
+		if len(team) == 0 {
+			if len(c.Teams) == 0 {
+				continue
+			}
+			break
+		}
+		c.Teams = append(c.Teams, team)
+		if err == io.EOF {
+			break
+		}
+	}
+	return c, nil
+}
+
+func readLine(reader *bufio.Reader, prompt string) (string, error) {
+	fmt.Print(prompt)
+	s, err := reader.ReadString('\n')
+	if err != nil && (err != io.EOF || len(s) == 0) {
+		return "", fmt.Errorf("failed to scan the answer: %w", err)
+	}
+	return strings.TrimSpace(s), nil
+}
+
+func parseYesNo(s string) (bool, bool) {
+	switch strings.ToLower(s) {
+	case "y", "yes", "д", "да":
+		return true, true
+	case "n", "no", "н", "нет":
+		return false, true
+	default:
+		return false, false
+	}
+}